@@ -17,6 +17,7 @@ import (
 	"github.com/riz/auto-lmk/internal/handler"
 	"github.com/riz/auto-lmk/internal/llm"
 	appMiddleware "github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
 	"github.com/riz/auto-lmk/internal/repository"
 	"github.com/riz/auto-lmk/internal/service"
 	"github.com/riz/auto-lmk/internal/whatsapp"
@@ -43,7 +44,7 @@ func main() {
 
 	// Connect to database
 	fmt.Printf("Connecting to database...\n")
-	db, err := database.Connect(cfg.DatabaseURL())
+	db, err := database.ConnectWithTenantScope(cfg.DatabaseURL(), repository.TenantScope(cfg.Database.AppRole))
 	if err != nil {
 		slog.Error("failed to connect to database", "error", err)
 		os.Exit(1)
@@ -54,7 +55,8 @@ func main() {
 	// Initialize LLM provider if configured
 	var llmProvider llm.Provider
 	fmt.Printf("LLM Provider: %s, API Key: %s\n", cfg.LLM.Provider, cfg.LLM.APIKey)
-	if cfg.LLM.Provider != "" && cfg.LLM.APIKey != "" {
+	// The mock provider needs no key
+	if cfg.LLM.Provider != "" && (cfg.LLM.APIKey != "" || cfg.LLM.Provider == "mock") {
 		fmt.Printf("Initializing LLM provider...\n")
 		llmCfg := llm.Config{
 			Provider:    cfg.LLM.Provider,
@@ -79,6 +81,23 @@ func main() {
 	// Setup router
 	r := setupRouter(cfg, db, llmProvider)
 
+	// Deliver queued webhooks in the background until shutdown
+	dispatchCtx, stopDispatch := context.WithCancel(context.Background())
+	go service.NewWebhookDispatcher(repository.NewWebhookRepository(db.DB)).Run(dispatchCtx)
+
+	// Run customer exports, erasures and tenant exports in the background
+	dataJobRepo := repository.NewDataJobRepository(db.DB)
+	dataJobCustomers := repository.NewCustomerRepository(db.DB)
+	if cfg.Security.PIIMasterKey != nil {
+		dataKeyRepo := repository.NewDataKeyRepository(db.DB, cfg.Security.PIIMasterKey)
+		dataJobRepo.SetDataKeys(dataKeyRepo)
+		dataJobCustomers.SetDataKeys(dataKeyRepo)
+	}
+	go service.NewDataJobRunner(dataJobRepo, dataJobCustomers, cfg.Server.ExportPath).Run(dispatchCtx)
+
+	// Purge deleted tenants once their grace period is over
+	go service.NewTenantService(repository.NewTenantRepository(db.DB)).RunPurger(dispatchCtx)
+
 	// Create HTTP server
 	srv := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
@@ -103,6 +122,7 @@ func main() {
 	<-quit
 
 	slog.Info("shutting down server...")
+	stopDispatch()
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -120,21 +140,35 @@ func setupRouter(cfg *config.Config, db *database.DB, llmProvider llm.Provider)
 
 	// Middleware
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(appMiddleware.ClientIP(cfg.Server.TrustedProxies))
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
 
-	// CORS
+	// Security headers (CSP, frame-ancestors, HSTS in production)
+	production := cfg.Server.Env == "production"
+	r.Use(appMiddleware.SecurityHeaders(production))
+
+	// Which tenant a domain belongs to, cached per instance. Outside production
+	// a tenant can be picked on localhost with the X-Tenant-Domain header or
+	// ?tenant= query parameter.
+	tenants := appMiddleware.NewTenantResolver(db.DB, cfg.Server.TenantCacheTTL)
+	tenantExtractor := appMiddleware.TenantExtractor(tenants, !production)
+
+	// CORS: only the registered domain of the tenant being addressed
+	allowOrigin := appMiddleware.TenantOrigins(tenants, production)
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"https://*", "http://*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		AllowOriginFunc:  allowOrigin,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-API-Key"},
+		ExposedHeaders:   []string{"Link", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
+	// CSRF tokens for requests authenticated by the admin session cookie
+	r.Use(appMiddleware.CSRFProtect(cfg.Security.JWTSecret, allowOrigin))
+
 	// Health check
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		if err := db.Health(); err != nil {
@@ -175,8 +209,80 @@ func setupRouter(cfg *config.Config, db *database.DB, llmProvider llm.Provider)
 	// Initialize showroom repository
 	showroomRepo := repository.NewShowroomRepository(db.DB)
 
+	// Initialize financing repository
+	financingRepo := repository.NewFinancingRepository(db.DB)
+
+	// Initialize lead and trade-in repositories
+	leadRepo := repository.NewLeadRepository(db.DB)
+	tradeInRepo := repository.NewTradeInRepository(db.DB)
+
+	// Initialize deal and commission repositories
+	dealRepo := repository.NewDealRepository(db.DB)
+	commissionRepo := repository.NewCommissionRepository(db.DB)
+
+	// Initialize customer repository
+	customerRepo := repository.NewCustomerRepository(db.DB)
+
+	// Initialize data export and erasure job repository
+	dataJobRepo := repository.NewDataJobRepository(db.DB)
+
+	// Initialize repository of the LLM providers tenants bring with their own keys
+	llmSettingsRepo := repository.NewLLMSettingsRepository(db.DB)
+
+	// Encrypt customer names, phone numbers and messages at rest when a master
+	// key is set; tenants' LLM API keys can only be stored then
+	if cfg.Security.PIIMasterKey != nil {
+		dataKeyRepo := repository.NewDataKeyRepository(db.DB, cfg.Security.PIIMasterKey)
+		conversationRepo.SetDataKeys(dataKeyRepo)
+		customerRepo.SetDataKeys(dataKeyRepo)
+		leadRepo.SetDataKeys(dataKeyRepo)
+		tradeInRepo.SetDataKeys(dataKeyRepo)
+		dealRepo.SetDataKeys(dataKeyRepo)
+		dataJobRepo.SetDataKeys(dataKeyRepo)
+		llmSettingsRepo.SetDataKeys(dataKeyRepo)
+	} else if production {
+		slog.Warn("PII_MASTER_KEY not set, customer names, phone numbers and messages are stored unencrypted")
+	}
+
+	// Initialize audit log repository
+	auditRepo := repository.NewAuditRepository(db.DB)
+
+	// Initialize user and session repositories
+	userRepo := repository.NewUserRepository(db.DB)
+	sessionRepo := repository.NewSessionRepository(db.DB)
+
+	// Initialize API key repository
+	apiKeyRepo := repository.NewAPIKeyRepository(db.DB)
+	webhookRepo := repository.NewWebhookRepository(db.DB)
+
+	// Initialize subscription plan repository (limits, features and usage)
+	planRepo := repository.NewPlanRepository(db.DB)
+
 	// Initialize services
 	carService := service.NewCarService(carRepo)
+	financingService := service.NewFinancingService(financingRepo, carRepo)
+	financingService.SetPlans(planRepo)
+	tradeInService := service.NewTradeInService(tradeInRepo, leadRepo)
+	dealService := service.NewDealService(dealRepo, commissionRepo, carRepo)
+	dealService.SetShowrooms(showroomRepo)
+	authService := service.NewAuthService(userRepo, sessionRepo, cfg.Security.JWTSecret, cfg.Security.SessionTTL)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	webhookService := service.NewWebhookService(webhookRepo)
+	dataJobService := service.NewDataJobService(dataJobRepo)
+
+	// The bot and AI features chat through the registry: a tenant's own
+	// provider when it has one, the platform provider otherwise
+	var llmRegistry *llm.Registry
+	if llmProvider != nil || llmSettingsRepo.KeysAvailable() {
+		llmRegistry = llm.NewRegistry(llmProvider, llmSettingsRepo)
+		llmProvider = llmRegistry
+	}
+
+	// Initialize repository of each tenant's bot persona (name, tone, greeting, rules)
+	botPersonaRepo := repository.NewBotPersonaRepository(db.DB)
+
+	// Initialize repository of each showroom's answers to common questions
+	faqRepo := repository.NewFAQRepository(db.DB)
 
 	// Initialize WhatsApp client if LLM is configured
 	var waClient *whatsapp.Client
@@ -193,9 +299,19 @@ func setupRouter(cfg *config.Config, db *database.DB, llmProvider llm.Provider)
 			convAdapter := llm.NewConversationRepoAdapter(conversationRepo)
 			carAdapter := llm.NewCarRepoAdapter(carRepo)
 			bot := llm.NewBot(llmProvider, convAdapter, carAdapter)
+			bot.SetCreditSimulator(financingService)
+			bot.SetTradeInEstimator(tradeInService)
+			bot.SetCustomerProfiles(customerRepo)
+			bot.SetSalesLeads(leadRepo)
+			bot.SetPersonas(botPersonaRepo)
+			bot.SetShowrooms(showroomRepo)
+			bot.SetFAQs(faqRepo)
 
 			// Initialize WhatsApp service
 			waService = service.NewWhatsAppService(waClient, bot, salesRepo, conversationRepo, carService)
+			waService.SetCustomerRepository(customerRepo)
+			waService.SetPlans(planRepo)
+			waService.SetAfterHours(showroomRepo, leadRepo)
 
 			// Set message handler
 			waClient.SetMessageHandler(waService.ProcessIncomingMessage)
@@ -205,7 +321,14 @@ func setupRouter(cfg *config.Config, db *database.DB, llmProvider llm.Provider)
 	}
 
 	// Initialize handlers
-	tenantHandler := handler.NewTenantHandler(tenantRepo)
+	tenantService := service.NewTenantService(tenantRepo)
+	tenantService.SetCache(tenants)
+	if waClient != nil {
+		tenantService.SetBot(waClient)
+	}
+	tenantHandler := handler.NewTenantHandler(tenantService)
+	onboardingService := service.NewOnboardingService(tenantService, authService, userRepo, repository.NewOnboardingRepository(db.DB))
+	onboardingHandler := handler.NewOnboardingHandler(onboardingService)
 	var carHandler *handler.CarHandler
 	if llmProvider != nil {
 		carHandler = handler.NewCarHandlerWithAnalyticsAndLLM(carRepo, analyticsRepo, llmProvider)
@@ -230,12 +353,73 @@ func setupRouter(cfg *config.Config, db *database.DB, llmProvider llm.Provider)
 	// Showroom handler
 	showroomHandler := handler.NewShowroomHandler(showroomRepo)
 
+	// Financing handler
+	financingHandler := handler.NewFinancingHandler(financingRepo, financingService)
+
+	// Trade-in handler
+	tradeInHandler := handler.NewTradeInHandler(tradeInRepo, tradeInService)
+
+	// Deal handler
+	dealHandler := handler.NewDealHandler(dealRepo, commissionRepo, dealService)
+
+	// Customer handler
+	customerHandler := handler.NewCustomerHandler(customerRepo)
+	auditHandler := handler.NewAuditHandler(auditRepo)
+	planHandler := handler.NewPlanHandler(planRepo)
+	dataJobHandler := handler.NewDataJobHandler(dataJobService)
+
+	// Lead handler
+	leadHandler := handler.NewLeadHandler(leadRepo)
+
+	// Auth and user handlers
+	authHandler := handler.NewAuthHandler(authService, cfg.Server.Env == "production")
+	userHandler := handler.NewUserHandler(userRepo, authService)
+
+	// Integration API handlers
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	// Custom domains brought by tenants
+	domainService := service.NewDomainService(repository.NewDomainRepository(db.DB), cfg.Server.PlatformDomain)
+	domainService.SetCache(tenants)
+	domainHandler := handler.NewDomainHandler(domainService)
+	// Bot persona editor with version history
+	botPersonaHandler := handler.NewBotPersonaHandler(botPersonaRepo)
+	faqHandler := handler.NewFAQHandler(faqRepo)
+
+	// AI provider settings (bring-your-own LLM key)
+	llmSettingsHandler := handler.NewLLMSettingsHandler(service.NewLLMSettingsService(llmSettingsRepo, llmRegistry))
+	apiV1Handler := handler.NewAPIV1Handler(carHandler, carRepo, leadRepo, conversationRepo)
+
+	// WhatsApp OTP login for sales staff (needs the paired bot to deliver codes)
+	if waClient != nil {
+		otpRepo := repository.NewOTPRepository(db.DB)
+		authHandler.SetOTPService(service.NewOTPService(otpRepo, salesRepo, userRepo, auditRepo, authService, waClient))
+	}
+
 	// WhatsApp handler (if WhatsApp client is initialized)
 	var whatsappHandler *handler.WhatsAppHandler
 	if waClient != nil {
 		whatsappHandler = handler.NewWhatsAppHandlerWithSettings(waClient, tenantRepo, whatsappSettingsRepo)
 	}
 
+	// Rate limits: per IP on public routes, per API key on /api/v1 and per
+	// tenant on the AI endpoints that spend LLM credits
+	var limiter appMiddleware.RateLimiter = appMiddleware.NewMemoryRateLimiter()
+	if cfg.RateLimit.Store == "postgres" {
+		limiter = repository.NewRateLimitRepository(db.DB)
+	}
+	publicLimit := appMiddleware.RateLimitByIP(limiter, "public", model.RateLimit(cfg.RateLimit.Public))
+	apiLimit := appMiddleware.RateLimitByAPIKey(limiter, "api", model.RateLimit(cfg.RateLimit.API))
+	aiLimit := appMiddleware.RateLimitByTenant(limiter, "ai", model.RateLimit(cfg.RateLimit.AI))
+
+	// Plan quotas and features; car and sales seat limits are checked where
+	// they are created
+	aiQuota := appMiddleware.RequireQuota(planRepo, model.LimitAIGenerations)
+	requireFeature := func(feature string) func(http.Handler) http.Handler {
+		return appMiddleware.RequireFeature(planRepo, feature)
+	}
+
 	// API routes
 	r.Route("/api", func(r chi.Router) {
 		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
@@ -243,50 +427,132 @@ func setupRouter(cfg *config.Config, db *database.DB, llmProvider llm.Provider)
 			w.Write([]byte(`{"message":"Auto LMK API","version":"1.0.0"}`))
 		})
 
-		// Root admin routes (no tenant middleware)
+		// Platform super-admin login (no tenant middleware)
+		r.Route("/platform/auth", func(r chi.Router) {
+			r.With(publicLimit).Post("/login", authHandler.APILogin)
+			r.Post("/logout", authHandler.APILogout)
+			r.With(appMiddleware.RequirePlatformAdmin(authService)).Get("/me", authHandler.Me)
+		})
+
+		// Versioned integration API (tenant comes from the API key, not the domain)
+		r.Route("/v1", func(r chi.Router) {
+			apiV1Handler.Register(r, apiKeyService, apiLimit)
+		})
+
+		// Root admin routes (no tenant middleware, platform super-admin only)
 		r.Route("/admin", func(r chi.Router) {
+			r.Use(appMiddleware.RequirePlatformAdmin(authService))
+
 			// Tenant management
 			r.Route("/tenants", func(r chi.Router) {
 				r.Post("/", tenantHandler.Create)
+				r.Post("/onboard", onboardingHandler.Onboard)
 				r.Get("/", tenantHandler.List)
 				r.Get("/{id}", tenantHandler.Get)
+				r.Patch("/{id}", tenantHandler.Update)
+				r.Delete("/{id}", tenantHandler.Delete)
+				r.Post("/{id}/suspend", tenantHandler.Suspend)
+				r.Post("/{id}/reactivate", tenantHandler.Reactivate)
 			})
+
+			// Subscription plans to assign with PATCH /tenants/{id}
+			r.Get("/plans", planHandler.List)
 		})
 
 		// Tenant-scoped routes (with tenant middleware)
 		r.Group(func(r chi.Router) {
-			r.Use(appMiddleware.TenantExtractor(db.DB))
+			r.Use(tenantExtractor)
+			r.Use(publicLimit)
+
+			// Authentication (JWT bearer tokens for API clients)
+			r.Route("/auth", func(r chi.Router) {
+				r.Post("/login", authHandler.APILogin)
+				r.Post("/logout", authHandler.APILogout)
+				r.With(appMiddleware.RequireAuth(authService)).Get("/me", authHandler.Me)
+			})
 
-			// Car management
+			// Car management (read-only routes are public for the storefront)
 			r.Route("/cars", func(r chi.Router) {
-				r.Post("/", carHandler.Create)
-				r.Post("/ai-generate", carHandler.AIGenerate)
 				r.Get("/", carHandler.List)
 				r.Get("/search", carHandler.Search)
 				r.Get("/{id}", carHandler.Get)
-				r.Put("/{id}", carHandler.Update)
-				r.Delete("/{id}", carHandler.Delete)
-				r.Post("/{id}/photos", carHandler.UploadPhotos)
-				r.Delete("/photos/{photoId}", carHandler.DeletePhoto)
+
+				r.Group(func(r chi.Router) {
+					r.Use(appMiddleware.RequireAuth(authService))
+					r.Use(appMiddleware.RequirePermission(model.PermManageInventory))
+					r.Post("/", carHandler.Create)
+					r.With(aiLimit, aiQuota).Post("/ai-generate", carHandler.AIGenerate)
+					r.Put("/{id}", carHandler.Update)
+					r.Delete("/{id}", carHandler.Delete)
+					r.Post("/{id}/photos", carHandler.UploadPhotos)
+					r.Delete("/photos/{photoId}", carHandler.DeletePhoto)
+				})
+			})
+
+			// Public showroom route
+			r.Get("/showroom", showroomHandler.GetSettings)
+
+			// Public blog listing (published posts only)
+			r.Get("/blog", blogHandler.PublicList)
+
+			// Public WhatsApp contact number for the storefront
+			if whatsappHandler != nil {
+				r.Get("/whatsapp/effective-number", whatsappHandler.GetEffectiveNumber)
+			}
+
+			// Public financing routes (credit calculator)
+			r.Route("/financing", func(r chi.Router) {
+				r.Use(requireFeature(model.FeatureFinancing))
+				r.Get("/partners", financingHandler.PublicPartners)
+				r.Post("/simulate", financingHandler.Simulate)
+			})
+
+			// Public trade-in form
+			r.Post("/trade-in", tradeInHandler.Submit)
+		})
+
+		// Tenant-scoped routes that require a logged-in user
+		r.Group(func(r chi.Router) {
+			r.Use(tenantExtractor)
+			r.Use(appMiddleware.RequireAuth(authService))
+
+			// User management
+			r.Route("/admin/users", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageUsers))
+				r.Get("/", userHandler.List)
+				r.Post("/", userHandler.Create)
+				r.Put("/{id}/password", userHandler.ChangePassword)
+				r.Delete("/{id}", userHandler.Delete)
 			})
 
 			// Sales management
 			r.Route("/sales", func(r chi.Router) {
-				r.Post("/", salesHandler.Create)
-				r.Get("/", salesHandler.List)
-				r.Get("/stats", salesHandler.Stats)
-				r.Delete("/{id}", salesHandler.Delete)
+				r.With(appMiddleware.RequirePermission(model.PermManageCustomers)).Get("/", salesHandler.List)
+				r.With(appMiddleware.RequirePermission(model.PermManageCustomers)).Get("/stats", salesHandler.Stats)
+				r.With(appMiddleware.RequirePermission(model.PermManageUsers)).Post("/", salesHandler.Create)
+				r.With(appMiddleware.RequirePermission(model.PermManageUsers)).Delete("/{id}", salesHandler.Delete)
 			})
 
-			// Conversations
+			// Conversations (sales users only see the ones assigned to them)
 			r.Route("/conversations", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageCustomers, model.PermViewAssigned))
 				r.Get("/", conversationHandler.List)
 				r.Get("/stats", conversationHandler.Stats)
 				r.Get("/{id}", conversationHandler.Get)
+				r.With(appMiddleware.RequirePermission(model.PermManageCustomers)).Put("/{id}/assign", conversationHandler.Assign)
+			})
+
+			// Lead admin routes (sales users only see the ones assigned to them)
+			r.Route("/admin/leads", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageCustomers, model.PermViewAssigned))
+				r.Get("/", leadHandler.List)
+				r.Get("/{id}", leadHandler.Get)
+				r.With(appMiddleware.RequirePermission(model.PermManageCustomers)).Put("/{id}/assign", leadHandler.Assign)
 			})
 
 			// Analytics admin routes (tenant-scoped)
 			r.Route("/admin/analytics", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermViewAnalytics))
 				r.Get("/search-keywords", analyticsHandler.GetTopKeywords)
 				r.Get("/car-views", analyticsHandler.GetTopCars)
 				r.Get("/trends", analyticsHandler.GetTrends)
@@ -296,6 +562,7 @@ func setupRouter(cfg *config.Config, db *database.DB, llmProvider llm.Provider)
 			// WhatsApp admin routes (tenant-scoped)
 			if whatsappHandler != nil {
 				r.Route("/admin/whatsapp", func(r chi.Router) {
+					r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
 					r.Get("/status", whatsappHandler.GetStatus)
 					r.Post("/pair", whatsappHandler.InitiatePairing)
 					r.Post("/disconnect", whatsappHandler.Disconnect)
@@ -309,16 +576,18 @@ func setupRouter(cfg *config.Config, db *database.DB, llmProvider llm.Provider)
 
 			// Blog admin routes (tenant-scoped)
 			r.Route("/admin/blog", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageBlog))
 				r.Get("/", blogHandler.List)
 				r.Post("/", blogHandler.Create)
 				r.Get("/{id}", blogHandler.Get)
 				r.Put("/{id}", blogHandler.Update)
 				r.Delete("/{id}", blogHandler.Delete)
-				r.Post("/generate-ai", blogHandler.GenerateAI)
+				r.With(aiLimit, requireFeature(model.FeatureBlogAI), aiQuota).Post("/generate-ai", blogHandler.GenerateAI)
 			})
 
 			// Branding admin routes (tenant-scoped)
 			r.Route("/admin/branding", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
 				r.Get("/", brandingHandler.GetSettings)
 				r.Put("/", brandingHandler.UpdateSettings)
 				r.Post("/upload-logo", brandingHandler.UploadLogo)
@@ -327,58 +596,251 @@ func setupRouter(cfg *config.Config, db *database.DB, llmProvider llm.Provider)
 
 			// Showroom admin routes (tenant-scoped)
 			r.Route("/admin/showroom", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
 				r.Get("/", showroomHandler.GetAdminSettings)
 				r.Put("/", showroomHandler.UpdateSettings)
 			})
 
-			// Public showroom route
-			r.Get("/showroom", showroomHandler.GetSettings)
+			// Financing admin routes (tenant-scoped)
+			r.Route("/admin/financing", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
+				r.Use(requireFeature(model.FeatureFinancing))
+				r.Get("/partners", financingHandler.ListPartners)
+				r.Post("/partners", financingHandler.CreatePartner)
+				r.Put("/partners/{id}", financingHandler.UpdatePartner)
+				r.Delete("/partners/{id}", financingHandler.DeletePartner)
+			})
+
+			// Trade-in admin routes (tenant-scoped)
+			r.Route("/admin/trade-ins", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageCustomers))
+				r.Get("/", tradeInHandler.List)
+				r.Get("/{id}", tradeInHandler.Get)
+				r.Put("/{id}/appraisal", tradeInHandler.Appraise)
+			})
+
+			// Deal admin routes (tenant-scoped)
+			r.Route("/admin/deals", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageCustomers))
+				r.Get("/", dealHandler.List)
+				r.Post("/", dealHandler.Create)
+				r.Get("/{id}", dealHandler.Get)
+				r.Put("/{id}", dealHandler.Update)
+				r.Post("/{id}/close", dealHandler.Close)
+				r.Post("/{id}/cancel", dealHandler.Cancel)
+			})
+
+			// Customer admin routes (tenant-scoped)
+			r.Route("/admin/customers", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageCustomers))
+				r.Get("/", customerHandler.List)
+				r.Get("/{id}", customerHandler.Get)
+				r.Put("/{id}", customerHandler.Update)
+				r.Get("/{id}/timeline", customerHandler.Timeline)
+				r.Post("/{id}/appointments", customerHandler.CreateAppointment)
+			})
+
+			// Customer data export and erasure jobs (tenant-scoped); tenant
+			// exports additionally need PermManageSettings, checked on create
+			r.Route("/admin/data-jobs", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageCustomers))
+				r.Get("/", dataJobHandler.List)
+				r.Post("/", dataJobHandler.Create)
+				r.Get("/{id}", dataJobHandler.Get)
+				r.Get("/{id}/download", dataJobHandler.Download)
+			})
+
+			// Onboarding wizard for new dealerships (tenant-scoped)
+			r.Route("/admin/onboarding", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
+				r.Get("/", onboardingHandler.Progress)
+				r.Post("/finish", onboardingHandler.Finish)
+				r.Post("/steps/{step}/skip", onboardingHandler.Skip)
+				r.Delete("/steps/{step}/skip", onboardingHandler.Unskip)
+				r.Post("/demo", onboardingHandler.SeedDemo)
+				r.Delete("/demo", onboardingHandler.RemoveDemo)
+			})
+
+			// Audit log routes (tenant-scoped)
+			r.Route("/admin/audit-logs", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermViewAudit))
+				r.Get("/", auditHandler.List)
+				r.Get("/export", auditHandler.Export)
+			})
+
+			// API key management for integrations (tenant-scoped)
+			r.Route("/admin/api-keys", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
+				r.Get("/", apiKeyHandler.List)
+				r.Post("/", apiKeyHandler.Create)
+				r.Delete("/{id}", apiKeyHandler.Revoke)
+			})
+
+			// Outgoing webhook endpoints and delivery log (tenant-scoped)
+			r.Route("/admin/webhooks", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
+				r.Use(requireFeature(model.FeatureWebhooks))
+				r.Get("/", webhookHandler.List)
+				r.Post("/", webhookHandler.Create)
+				r.Get("/deliveries", webhookHandler.Deliveries)
+				r.Post("/deliveries/{id}/redeliver", webhookHandler.Redeliver)
+				r.Put("/{id}", webhookHandler.Update)
+				r.Delete("/{id}", webhookHandler.Delete)
+				r.Post("/{id}/rotate-secret", webhookHandler.RotateSecret)
+				r.Post("/{id}/test", webhookHandler.Test)
+			})
+
+			// Custom domains: verification, canonical domain (tenant-scoped)
+			r.Route("/admin/domains", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
+				r.Get("/", domainHandler.List)
+				r.Post("/", domainHandler.Add)
+				r.Put("/canonical", domainHandler.SetCanonical)
+				r.Post("/{id}/verify", domainHandler.Verify)
+				r.Delete("/{id}", domainHandler.Delete)
+			})
+
+			// Own LLM provider and key for the bot and AI features (tenant-scoped)
+			r.Route("/admin/llm", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
+				r.Get("/", llmSettingsHandler.Get)
+				r.Put("/", llmSettingsHandler.Save)
+				r.Delete("/", llmSettingsHandler.Delete)
+				r.With(aiLimit).Post("/test", llmSettingsHandler.Test)
+			})
+
+			// Bot persona, its versions and previews (tenant-scoped)
+			r.Route("/admin/bot", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
+				r.Get("/", botPersonaHandler.Get)
+				r.Put("/", botPersonaHandler.Save)
+				r.Post("/preview", botPersonaHandler.Preview)
+				r.Get("/versions", botPersonaHandler.Versions)
+				r.Post("/versions/{version}/restore", botPersonaHandler.Restore)
+			})
+
+			// FAQ the bot answers customers from (tenant-scoped)
+			r.Route("/admin/faqs", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageSettings))
+				r.Get("/", faqHandler.List)
+				r.Post("/", faqHandler.Create)
+				r.Put("/{id}", faqHandler.Update)
+				r.Delete("/{id}", faqHandler.Delete)
+			})
+
+			// Plan usage and the plans to compare with (tenant-scoped)
+			r.Route("/admin/usage", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageBilling))
+				r.Get("/", planHandler.Usage)
+				r.Get("/plans", planHandler.List)
+			})
+
+			// Commission admin routes (tenant-scoped)
+			r.Route("/admin/commissions", func(r chi.Router) {
+				r.Use(appMiddleware.RequirePermission(model.PermManageBilling))
+				r.Get("/rules", dealHandler.ListRules)
+				r.Post("/rules", dealHandler.CreateRule)
+				r.Put("/rules/{id}", dealHandler.UpdateRule)
+				r.Delete("/rules/{id}", dealHandler.DeleteRule)
+				r.Get("/statements", dealHandler.Statements)
+				r.Get("/statements/export", dealHandler.ExportStatements)
+			})
+
 		})
 	})
 
-	// Public frontend routes (with tenant middleware for multi-tenant support)
+	// HTTP check of a custom domain that does not route to its tenant yet
+	r.With(publicLimit).Get(model.DomainHTTPPath+"{token}", domainHandler.Challenge)
+
+	// Public frontend routes (with tenant middleware for multi-tenant support).
+	// Pages redirect to the tenant's canonical domain.
 	r.Group(func(r chi.Router) {
-		r.Use(appMiddleware.TenantExtractor(db.DB))
+		r.Use(tenantExtractor)
+		r.Use(publicLimit)
+		r.Use(appMiddleware.CanonicalRedirect(tenants, production))
 
 		r.Get("/", pageHandler.Home)
 		r.Get("/mobil", pageHandler.Cars)
 		r.Get("/mobil/{id}", pageHandler.CarDetail)
 		r.Get("/kontak", pageHandler.Contact)
+		r.Get("/tukar-tambah", pageHandler.TradeIn)
 		r.Get("/blog", pageHandler.BlogList)
 		r.Get("/blog/{slug}", pageHandler.BlogDetail)
 	})
 
-	// Logout route
-	r.Get("/logout", func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, "/", http.StatusSeeOther)
+	// Login and logout (signed session cookie for the admin panel)
+	r.Group(func(r chi.Router) {
+		r.Use(tenantExtractor)
+		r.Use(publicLimit)
+
+		r.Get("/login", pageHandler.Login)
+		r.Post("/login", authHandler.Login)
+		r.Post("/login/otp", authHandler.RequestOTP)
+		r.Post("/login/otp/verify", authHandler.VerifyOTP)
+		r.Post("/logout", authHandler.Logout) // POST only, so CSRFProtect checks it
 	})
 
 	// Admin frontend routes (with tenant middleware)
 	r.Route("/admin", func(r chi.Router) {
-		r.Use(appMiddleware.TenantExtractor(db.DB))
+		r.Use(tenantExtractor)
+		r.Use(appMiddleware.RequireSession(authService))
 
 		r.Get("/", pageHandler.AdminDashboard)
 		r.Get("/dashboard", pageHandler.AdminDashboard)
-		r.Get("/cars", pageHandler.AdminCars)
-		r.Get("/analytics", pageHandler.AdminAnalytics)
-		r.Get("/cars/new", pageHandler.AdminCarsNew)
-		r.Get("/cars/{id}/edit", pageHandler.AdminCarsEdit)
-		r.Get("/cars/{id}/edit", pageHandler.AdminCarsEdit)
-		r.Get("/sales", pageHandler.AdminSales)
-		r.Get("/sales/new", func(w http.ResponseWriter, r *http.Request) {
-			http.Redirect(w, r, "/admin/sales", http.StatusSeeOther)
+
+		r.Group(func(r chi.Router) {
+			r.Use(appMiddleware.RequirePagePermission(model.PermManageInventory))
+			r.Get("/cars", pageHandler.AdminCars)
+			r.Get("/cars/new", pageHandler.AdminCarsNew)
+			r.Get("/cars/{id}/edit", pageHandler.AdminCarsEdit)
+		})
+
+		r.With(appMiddleware.RequirePagePermission(model.PermViewAnalytics)).Get("/analytics", pageHandler.AdminAnalytics)
+		r.With(appMiddleware.RequirePagePermission(model.PermViewAudit)).Get("/audit", pageHandler.AdminAudit)
+		r.With(appMiddleware.RequirePagePermission(model.PermManageBilling)).Get("/usage", pageHandler.AdminUsage)
+
+		r.Group(func(r chi.Router) {
+			r.Use(appMiddleware.RequirePagePermission(model.PermManageCustomers))
+			r.Get("/sales", pageHandler.AdminSales)
+			r.Get("/sales/new", func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/admin/sales", http.StatusSeeOther)
+			})
+			r.Get("/sales/table", pageHandler.AdminSalesTable)
+			r.Get("/trade-ins", pageHandler.AdminTradeIns)
+			r.Get("/deals", pageHandler.AdminDeals)
+			r.Get("/customers", pageHandler.AdminCustomers)
+			r.Get("/customers/{id}", pageHandler.AdminCustomerDetail)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(appMiddleware.RequirePagePermission(model.PermManageCustomers, model.PermViewAssigned))
+			r.Get("/conversations", pageHandler.AdminConversations)
+			r.Get("/conversations/table", pageHandler.AdminConversationsTable)
+			r.Get("/conversations/{id}", pageHandler.AdminConversationDetail)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(appMiddleware.RequirePagePermission(model.PermManageBlog))
+			r.Get("/blog", pageHandler.AdminBlog)
+			r.Get("/blog/new", pageHandler.AdminBlogNew)
+			r.Get("/blog/{id}/edit", pageHandler.AdminBlogEdit)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(appMiddleware.RequirePagePermission(model.PermManageSettings))
+			r.Get("/whatsapp", pageHandler.AdminWhatsApp)
+			r.Get("/settings", pageHandler.AdminSettings)
+			r.Get("/onboarding", pageHandler.AdminOnboarding)
+			r.Get("/branding", pageHandler.AdminBranding)
+			r.Get("/showroom", pageHandler.AdminShowroom)
+			r.Get("/financing", pageHandler.AdminFinancing)
+			r.Get("/api-keys", pageHandler.AdminAPIKeys)
+			r.Get("/webhooks", pageHandler.AdminWebhooks)
+			r.Get("/domains", pageHandler.AdminDomains)
+			r.Get("/ai", pageHandler.AdminLLM)
+			r.Get("/bot", pageHandler.AdminBotPersona)
+			r.Get("/faq", pageHandler.AdminFAQ)
 		})
-		r.Get("/sales/table", pageHandler.AdminSalesTable)
-		r.Get("/whatsapp", pageHandler.AdminWhatsApp)
-		r.Get("/conversations", pageHandler.AdminConversations)
-		r.Get("/conversations/table", pageHandler.AdminConversationsTable)
-		r.Get("/conversations/{id}", pageHandler.AdminConversationDetail)
-		r.Get("/blog", pageHandler.AdminBlog)
-		r.Get("/blog/new", pageHandler.AdminBlogNew)
-		r.Get("/blog/{id}/edit", pageHandler.AdminBlogEdit)
-		r.Get("/settings", pageHandler.AdminSettings)
-		r.Get("/branding", pageHandler.AdminBranding)
-		r.Get("/showroom", pageHandler.AdminShowroom)
 	})
 
 	// Suppress unused variable warnings (will be used when handlers are implemented)
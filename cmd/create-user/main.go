@@ -0,0 +1,84 @@
+// Command create-user creates an admin panel account for a tenant, e.g. the first owner:
+//
+//	go run ./cmd/create-user -tenant 1 -email owner@showroom.com -password 'secret123' -role owner
+//
+// Platform super-admins have no tenant:
+//
+//	go run ./cmd/create-user -email ops@autolmk.com -password 'secret123' -role super_admin
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/internal/service"
+	"github.com/riz/auto-lmk/pkg/config"
+	"github.com/riz/auto-lmk/pkg/database"
+)
+
+func main() {
+	tenantID := flag.Int("tenant", 0, "tenant ID")
+	email := flag.String("email", "", "login email")
+	password := flag.String("password", "", "password (min 8 characters)")
+	name := flag.String("name", "", "display name")
+	role := flag.String("role", "owner", "user role: owner, admin, sales or super_admin")
+	salesID := flag.Int("sales", 0, "sales record ID (required for role sales)")
+	flag.Parse()
+
+	if *tenantID == 0 && *role != model.RoleSuperAdmin {
+		fmt.Println("-tenant is required")
+		os.Exit(1)
+	}
+	if *tenantID != 0 && *role == model.RoleSuperAdmin {
+		fmt.Println("-tenant must be omitted for super_admin")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	db, err := database.ConnectWithTenantScope(cfg.DatabaseURL(), repository.TenantScope(cfg.Database.AppRole))
+	if err != nil {
+		fmt.Printf("Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	req := &model.CreateUserRequest{Email: *email, Password: *password, Role: *role}
+	if *name != "" {
+		req.Name = name
+	}
+	if *salesID != 0 {
+		req.SalesID = salesID
+	}
+	if err := req.Validate(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	userRepo := repository.NewUserRepository(db.DB)
+	authService := service.NewAuthService(userRepo, repository.NewSessionRepository(db.DB), cfg.Security.JWTSecret, cfg.Security.SessionTTL)
+
+	ctx := context.Background()
+	if *tenantID != 0 {
+		ctx = model.WithSystemActor(model.WithTenantID(ctx, *tenantID))
+	}
+	user, err := authService.CreateUser(ctx, req)
+	if err != nil {
+		fmt.Printf("Failed to create user: %v\n", err)
+		os.Exit(1)
+	}
+
+	if user.IsSuperAdmin() {
+		fmt.Printf("Created platform user %d (%s, %s)\n", user.ID, user.Email, user.Role)
+		return
+	}
+	fmt.Printf("Created user %d (%s, %s) for tenant %d\n", user.ID, user.Email, user.Role, user.TenantID)
+}
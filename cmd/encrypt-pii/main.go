@@ -0,0 +1,132 @@
+// Command encrypt-pii encrypts customer names, phone numbers and messages stored
+// before PII_MASTER_KEY was set, and re-encrypts them after a key rotation:
+//
+//	go run ./cmd/encrypt-pii                 # all tenants
+//	go run ./cmd/encrypt-pii -tenant 1 -rotate
+//
+// Rotating the master key: set PII_MASTER_KEY to the new key and pass the old one
+// to re-wrap the tenant keys (the data itself is not touched):
+//
+//	go run ./cmd/encrypt-pii -old-master-key '<old base64 key>'
+//
+// Before rolling back the migration, write everything back as plaintext:
+//
+//	go run ./cmd/encrypt-pii -decrypt
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/pkg/config"
+	"github.com/riz/auto-lmk/pkg/database"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+func main() {
+	tenantID := flag.Int("tenant", 0, "tenant ID (default: all tenants)")
+	rotate := flag.Bool("rotate", false, "rotate the tenant data keys first, then re-encrypt with the new version")
+	decrypt := flag.Bool("decrypt", false, "write the data back as plaintext")
+	oldMasterKey := flag.String("old-master-key", "", "previous PII_MASTER_KEY (base64) to re-wrap the tenant keys with the current one")
+	batch := flag.Int("batch", 500, "rows per transaction")
+	flag.Parse()
+
+	if *rotate && *decrypt {
+		fmt.Println("-rotate and -decrypt cannot be combined")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if cfg.Security.PIIMasterKey == nil {
+		fmt.Println("PII_MASTER_KEY is not set")
+		os.Exit(1)
+	}
+
+	db, err := database.ConnectWithTenantScope(cfg.DatabaseURL(), repository.TenantScope(cfg.Database.AppRole))
+	if err != nil {
+		fmt.Printf("Failed to connect to database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	keys := repository.NewDataKeyRepository(db.DB, cfg.Security.PIIMasterKey)
+	ctx := context.Background()
+
+	if *oldMasterKey != "" {
+		old, err := base64.StdEncoding.DecodeString(*oldMasterKey)
+		if err != nil || len(old) != security.EncryptionKeySize {
+			fmt.Println("-old-master-key must be 32 bytes, base64 encoded")
+			os.Exit(1)
+		}
+		n, err := keys.Rewrap(ctx, old)
+		if err != nil {
+			fmt.Printf("Failed to re-wrap keys: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Re-wrapped %d keys with the current master key\n", n)
+		return
+	}
+
+	tenantIDs := []int{*tenantID}
+	if *tenantID == 0 {
+		tenants, err := repository.NewTenantRepository(db.DB).List(model.WithSystemScope(ctx))
+		if err != nil {
+			fmt.Printf("Failed to list tenants: %v\n", err)
+			os.Exit(1)
+		}
+		tenantIDs = tenantIDs[:0]
+		for _, t := range tenants {
+			tenantIDs = append(tenantIDs, t.ID)
+		}
+	}
+
+	conversations := repository.NewConversationRepository(db.DB)
+	conversations.SetDataKeys(keys)
+	customers := repository.NewCustomerRepository(db.DB)
+	customers.SetDataKeys(keys)
+
+	failed := false
+	for _, id := range tenantIDs {
+		tenantCtx := model.WithTenantID(ctx, id)
+
+		if *rotate {
+			version, err := keys.Rotate(tenantCtx)
+			if err != nil {
+				fmt.Printf("Tenant %d: failed to rotate data key: %v\n", id, err)
+				failed = true
+				continue
+			}
+			fmt.Printf("Tenant %d: rotated to data key version %d\n", id, version)
+		}
+
+		n, err := conversations.EncryptStored(tenantCtx, *decrypt, *batch)
+		if err == nil {
+			var records int
+			records, err = customers.EncryptStored(tenantCtx, *decrypt, *batch)
+			n += records
+		}
+		if err != nil {
+			fmt.Printf("Tenant %d: %v (%d rows done)\n", id, err, n)
+			failed = true
+			continue
+		}
+		if *decrypt {
+			fmt.Printf("Tenant %d: decrypted %d rows\n", id, n)
+		} else {
+			fmt.Printf("Tenant %d: encrypted %d rows\n", id, n)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/service"
+)
+
+type APIKeyHandler struct {
+	service *service.APIKeyService
+}
+
+func NewAPIKeyHandler(service *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// List handles GET /api/admin/api-keys
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list API keys", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat API key")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":   keys,
+		"count":  len(keys),
+		"scopes": model.AllAPIScopes,
+	})
+}
+
+// Create handles POST /api/admin/api-keys. The plaintext key is only returned here.
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	apiKey, key, err := h.service.Create(r.Context(), &req)
+	if err != nil {
+		slog.Error("failed to create API key", "error", err)
+		middleware.InternalServerError(w, "Gagal membuat API key")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"api_key": apiKey,
+		"key":     key,
+	})
+}
+
+// Revoke handles DELETE /api/admin/api-keys/{id}
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID API key tidak valid")
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "API key tidak ditemukan atau sudah dicabut")
+			return
+		}
+		slog.Error("failed to revoke API key", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal mencabut API key")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
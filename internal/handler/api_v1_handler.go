@@ -0,0 +1,574 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+// APIV1Handler serves the versioned integration API at /api/v1, authenticated
+// by tenant API keys instead of the request domain
+type APIV1Handler struct {
+	cars          *CarHandler
+	carRepo       *repository.CarRepository
+	leadRepo      *repository.LeadRepository
+	conversations *repository.ConversationRepository
+}
+
+func NewAPIV1Handler(cars *CarHandler, carRepo *repository.CarRepository, leadRepo *repository.LeadRepository, conversations *repository.ConversationRepository) *APIV1Handler {
+	return &APIV1Handler{
+		cars:          cars,
+		carRepo:       carRepo,
+		leadRepo:      leadRepo,
+		conversations: conversations,
+	}
+}
+
+// apiRoute describes one /api/v1 endpoint. The same table registers the chi
+// routes and generates the OpenAPI document, so the two cannot drift apart.
+type apiRoute struct {
+	Method  string
+	Pattern string
+	Scope   string
+	Summary string
+	Query   []apiParam  // query parameters
+	Request interface{} // JSON request body, or multipartPhotos
+	Status  int         // success status
+	Result  interface{} // JSON response body; a slice is returned as a page
+	Handler http.HandlerFunc
+}
+
+type apiParam struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+// multipartPhotos marks an endpoint taking a multipart "photos" upload
+type multipartPhotos struct{}
+
+var (
+	cursorParams = []apiParam{
+		{Name: "cursor", Type: "string", Description: "next_cursor of the previous page"},
+		{Name: "limit", Type: "integer", Description: "Page size (1-200, default 50)"},
+	}
+	statusParam = apiParam{Name: "status", Type: "string", Description: "Filter by status"}
+)
+
+// routes lists every /api/v1 endpoint
+func (h *APIV1Handler) routes() []apiRoute {
+	return []apiRoute{
+		{Method: http.MethodGet, Pattern: "/cars", Scope: model.ScopeCarsRead, Summary: "List cars, newest first",
+			Query: append([]apiParam{statusParam}, cursorParams...), Result: []model.Car{}, Handler: h.ListCars},
+		{Method: http.MethodPost, Pattern: "/cars", Scope: model.ScopeCarsWrite, Summary: "Create a car",
+			Request: model.Car{}, Status: http.StatusCreated, Result: model.Car{}, Handler: h.CreateCar},
+		{Method: http.MethodGet, Pattern: "/cars/{id}", Scope: model.ScopeCarsRead, Summary: "Get a car",
+			Result: model.Car{}, Handler: h.GetCar},
+		{Method: http.MethodPatch, Pattern: "/cars/{id}", Scope: model.ScopeCarsWrite, Summary: "Update fields of a car",
+			Request: map[string]interface{}{}, Result: model.Car{}, Handler: h.UpdateCar},
+		{Method: http.MethodDelete, Pattern: "/cars/{id}", Scope: model.ScopeCarsWrite, Summary: "Delete a car",
+			Status: http.StatusNoContent, Handler: h.DeleteCar},
+		{Method: http.MethodGet, Pattern: "/cars/{id}/photos", Scope: model.ScopeCarsRead, Summary: "List photos of a car",
+			Result: []model.CarPhoto{}, Handler: h.ListPhotos},
+		{Method: http.MethodPost, Pattern: "/cars/{id}/photos", Scope: model.ScopeCarsWrite, Summary: "Upload photos of a car",
+			Request: multipartPhotos{}, Result: []model.CarPhoto{}, Handler: h.UploadPhotos},
+		{Method: http.MethodDelete, Pattern: "/cars/{id}/photos/{photoId}", Scope: model.ScopeCarsWrite, Summary: "Delete a photo of a car",
+			Status: http.StatusNoContent, Handler: h.DeletePhoto},
+		{Method: http.MethodGet, Pattern: "/leads", Scope: model.ScopeLeadsRead, Summary: "List leads, newest first",
+			Query: append([]apiParam{statusParam}, cursorParams...), Result: []model.Lead{}, Handler: h.ListLeads},
+		{Method: http.MethodPost, Pattern: "/leads", Scope: model.ScopeLeadsWrite, Summary: "Create a lead",
+			Request: model.CreateLeadRequest{}, Status: http.StatusCreated, Result: model.Lead{}, Handler: h.CreateLead},
+		{Method: http.MethodGet, Pattern: "/leads/{id}", Scope: model.ScopeLeadsRead, Summary: "Get a lead",
+			Result: model.Lead{}, Handler: h.GetLead},
+		{Method: http.MethodPut, Pattern: "/leads/{id}/status", Scope: model.ScopeLeadsWrite, Summary: "Change the status of a lead",
+			Request: leadStatusRequest{}, Result: model.Lead{}, Handler: h.UpdateLeadStatus},
+		{Method: http.MethodGet, Pattern: "/conversations", Scope: model.ScopeConversationsRead, Summary: "List WhatsApp conversations, newest first",
+			Query: cursorParams, Result: []model.Conversation{}, Handler: h.ListConversations},
+		{Method: http.MethodGet, Pattern: "/conversations/{id}", Scope: model.ScopeConversationsRead, Summary: "Get a conversation",
+			Result: model.Conversation{}, Handler: h.GetConversation},
+		{Method: http.MethodGet, Pattern: "/conversations/{id}/messages", Scope: model.ScopeConversationsRead, Summary: "List messages of a conversation, newest first",
+			Query: cursorParams, Result: []model.Message{}, Handler: h.ListMessages},
+	}
+}
+
+// Register mounts the API on r: the OpenAPI document is public, every other
+// route needs an API key holding the route's scope. limits (e.g. per-key rate
+// limits) run once the key is known.
+func (h *APIV1Handler) Register(r chi.Router, auth middleware.APIKeyAuthenticator, limits ...func(http.Handler) http.Handler) {
+	r.Get("/openapi.json", h.OpenAPI)
+
+	r.Group(func(r chi.Router) {
+		r.Use(middleware.RequireAPIKey(auth))
+		r.Use(limits...)
+		for _, route := range h.routes() {
+			r.With(middleware.RequireScope(route.Scope)).Method(route.Method, route.Pattern, route.Handler)
+		}
+	})
+}
+
+// OpenAPI handles GET /api/v1/openapi.json
+func (h *APIV1Handler) OpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(buildOpenAPI(h.routes()))
+}
+
+// encodeCursor makes an opaque page cursor from the last ID of a page
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("id:" + strconv.Itoa(id)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), "id:") {
+		return 0, errors.New("invalid cursor")
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(string(raw), "id:"))
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return id, nil
+}
+
+// parsePage reads ?cursor=&limit= from the query string
+func parsePage(w http.ResponseWriter, r *http.Request) (model.PageRequest, bool) {
+	var page model.PageRequest
+	q := r.URL.Query()
+
+	if v := q.Get("cursor"); v != "" {
+		id, err := decodeCursor(v)
+		if err != nil {
+			middleware.BadRequest(w, "Cursor tidak valid")
+			return page, false
+		}
+		page.Cursor = id
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > model.MaxPageLimit {
+			middleware.BadRequest(w, "Limit harus antara 1 dan 200")
+			return page, false
+		}
+		page.Limit = limit
+	}
+
+	return page, true
+}
+
+// writePage writes a list page with the cursor of the next one (null on the last page)
+func writePage(w http.ResponseWriter, data interface{}, count, next int) {
+	var nextCursor *string
+	if next > 0 {
+		c := encodeCursor(next)
+		nextCursor = &c
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":        data,
+		"count":       count,
+		"next_cursor": nextCursor,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func urlID(w http.ResponseWriter, r *http.Request, param, message string) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, param))
+	if err != nil {
+		middleware.BadRequest(w, message)
+		return 0, false
+	}
+	return id, true
+}
+
+// ListCars handles GET /api/v1/cars?status=&cursor=&limit=
+func (h *APIV1Handler) ListCars(w http.ResponseWriter, r *http.Request) {
+	page, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	cars, next, err := h.carRepo.ListPage(r.Context(), page, r.URL.Query().Get("status"))
+	if err != nil {
+		slog.Error("failed to list cars", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat data mobil")
+		return
+	}
+
+	writePage(w, cars, len(cars), next)
+}
+
+// GetCar handles GET /api/v1/cars/{id}
+func (h *APIV1Handler) GetCar(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(w, r, "id", "ID mobil tidak valid")
+	if !ok {
+		return
+	}
+
+	car, err := h.carRepo.GetByID(r.Context(), id)
+	if err != nil {
+		middleware.NotFound(w, "Mobil tidak ditemukan")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, car)
+}
+
+// CreateCar handles POST /api/v1/cars
+func (h *APIV1Handler) CreateCar(w http.ResponseWriter, r *http.Request) {
+	var car model.Car
+	if err := json.NewDecoder(r.Body).Decode(&car); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if strings.TrimSpace(car.Brand) == "" || strings.TrimSpace(car.Model) == "" || car.Year <= 0 || car.Price <= 0 {
+		middleware.BadRequest(w, "Merek, model, tahun, dan harga wajib diisi")
+		return
+	}
+	if car.Status == "" {
+		car.Status = "available"
+	}
+
+	if err := h.carRepo.Create(r.Context(), &car); err != nil {
+		if middleware.PlanError(w, err) {
+			return
+		}
+		slog.Error("failed to create car", "error", err)
+		middleware.InternalServerError(w, "Gagal menyimpan mobil")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, car)
+}
+
+// UpdateCar handles PATCH /api/v1/cars/{id} with the fields to change
+func (h *APIV1Handler) UpdateCar(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(w, r, "id", "ID mobil tidak valid")
+	if !ok {
+		return
+	}
+
+	var updates map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&updates); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := h.carRepo.Update(r.Context(), id, updates); err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			middleware.NotFound(w, "Mobil tidak ditemukan")
+		case strings.Contains(err.Error(), "invalid column"), strings.Contains(err.Error(), "no fields"):
+			middleware.BadRequest(w, "Field yang diubah tidak valid")
+		default:
+			slog.Error("failed to update car", "error", err, "id", id)
+			middleware.InternalServerError(w, "Gagal mengubah mobil")
+		}
+		return
+	}
+
+	h.GetCar(w, r)
+}
+
+// DeleteCar handles DELETE /api/v1/cars/{id}
+func (h *APIV1Handler) DeleteCar(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(w, r, "id", "ID mobil tidak valid")
+	if !ok {
+		return
+	}
+
+	if err := h.carRepo.Delete(r.Context(), id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Mobil tidak ditemukan")
+			return
+		}
+		slog.Error("failed to delete car", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal menghapus mobil")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListPhotos handles GET /api/v1/cars/{id}/photos
+func (h *APIV1Handler) ListPhotos(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(w, r, "id", "ID mobil tidak valid")
+	if !ok {
+		return
+	}
+
+	if _, err := h.carRepo.GetByID(r.Context(), id); err != nil {
+		middleware.NotFound(w, "Mobil tidak ditemukan")
+		return
+	}
+
+	photos, err := h.carRepo.GetCarPhotos(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to list car photos", "error", err, "car_id", id)
+		middleware.InternalServerError(w, "Gagal memuat foto mobil")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":  photos,
+		"count": len(photos),
+	})
+}
+
+// UploadPhotos handles POST /api/v1/cars/{id}/photos (multipart "photos" files)
+// and answers with the car's photos
+func (h *APIV1Handler) UploadPhotos(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(w, r, "id", "ID mobil tidak valid")
+	if !ok {
+		return
+	}
+
+	if _, err := h.carRepo.GetByID(r.Context(), id); err != nil {
+		middleware.NotFound(w, "Mobil tidak ditemukan")
+		return
+	}
+
+	rec := &statusRecorder{ResponseWriter: w}
+	h.cars.UploadPhotos(rec.discardOnSuccess(), r)
+	if rec.status != http.StatusOK {
+		return
+	}
+
+	h.ListPhotos(w, r)
+}
+
+// DeletePhoto handles DELETE /api/v1/cars/{id}/photos/{photoId}
+func (h *APIV1Handler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	carID, ok := urlID(w, r, "id", "ID mobil tidak valid")
+	if !ok {
+		return
+	}
+	photoID, ok := urlID(w, r, "photoId", "ID foto tidak valid")
+	if !ok {
+		return
+	}
+
+	photo, err := h.carRepo.GetPhotoByID(r.Context(), photoID)
+	if err != nil || photo.CarID != carID {
+		middleware.NotFound(w, "Foto tidak ditemukan")
+		return
+	}
+
+	rec := &statusRecorder{ResponseWriter: w}
+	h.cars.DeletePhoto(rec.discardOnSuccess(), r)
+	if rec.status != http.StatusOK {
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// statusRecorder lets the v1 API reuse CarHandler's upload code: errors are
+// passed through, while a successful response is held back so the v1 handler
+// can answer in its own format
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	discard bool
+}
+
+func (s *statusRecorder) discardOnSuccess() http.ResponseWriter {
+	s.discard = true
+	return s
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status != 0 {
+		return
+	}
+	s.status = status
+	if status != http.StatusOK {
+		s.discard = false
+		s.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.WriteHeader(http.StatusOK)
+	}
+	if s.discard {
+		return len(b), nil
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// ListLeads handles GET /api/v1/leads?status=&cursor=&limit=
+func (h *APIV1Handler) ListLeads(w http.ResponseWriter, r *http.Request) {
+	page, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	leads, next, err := h.leadRepo.ListPage(r.Context(), page, r.URL.Query().Get("status"))
+	if err != nil {
+		slog.Error("failed to list leads", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat data lead")
+		return
+	}
+
+	writePage(w, leads, len(leads), next)
+}
+
+// GetLead handles GET /api/v1/leads/{id}
+func (h *APIV1Handler) GetLead(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(w, r, "id", "ID lead tidak valid")
+	if !ok {
+		return
+	}
+
+	lead, err := h.leadRepo.GetByID(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Lead tidak ditemukan")
+			return
+		}
+		slog.Error("failed to get lead", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal memuat lead")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, lead)
+}
+
+// CreateLead handles POST /api/v1/leads
+func (h *APIV1Handler) CreateLead(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateLeadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+	if req.Source == "" {
+		req.Source = "api"
+	}
+
+	lead, err := h.leadRepo.Create(r.Context(), &req)
+	if err != nil {
+		slog.Error("failed to create lead", "error", err)
+		middleware.InternalServerError(w, "Gagal menyimpan lead")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, lead)
+}
+
+type leadStatusRequest struct {
+	Status string `json:"status"` // new, contacted, converted, lost
+}
+
+// UpdateLeadStatus handles PUT /api/v1/leads/{id}/status
+func (h *APIV1Handler) UpdateLeadStatus(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(w, r, "id", "ID lead tidak valid")
+	if !ok {
+		return
+	}
+
+	var req leadStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if !model.IsValidLeadStatus(req.Status) {
+		middleware.BadRequest(w, "Status harus new, contacted, converted, atau lost")
+		return
+	}
+
+	if err := h.leadRepo.UpdateStatus(r.Context(), id, req.Status); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Lead tidak ditemukan")
+			return
+		}
+		slog.Error("failed to update lead status", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal mengubah status lead")
+		return
+	}
+
+	h.GetLead(w, r)
+}
+
+// ListConversations handles GET /api/v1/conversations?cursor=&limit=
+func (h *APIV1Handler) ListConversations(w http.ResponseWriter, r *http.Request) {
+	page, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	conversations, next, err := h.conversations.ListPage(r.Context(), page)
+	if err != nil {
+		slog.Error("failed to list conversations", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat percakapan")
+		return
+	}
+
+	writePage(w, conversations, len(conversations), next)
+}
+
+// GetConversation handles GET /api/v1/conversations/{id}
+func (h *APIV1Handler) GetConversation(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(w, r, "id", "ID percakapan tidak valid")
+	if !ok {
+		return
+	}
+
+	conv, err := h.conversations.GetByID(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Percakapan tidak ditemukan")
+			return
+		}
+		slog.Error("failed to get conversation", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal memuat percakapan")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, conv)
+}
+
+// ListMessages handles GET /api/v1/conversations/{id}/messages?cursor=&limit=
+func (h *APIV1Handler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	id, ok := urlID(w, r, "id", "ID percakapan tidak valid")
+	if !ok {
+		return
+	}
+	page, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.conversations.GetByID(r.Context(), id); err != nil {
+		middleware.NotFound(w, "Percakapan tidak ditemukan")
+		return
+	}
+
+	messages, next, err := h.conversations.ListMessagesPage(r.Context(), id, page)
+	if err != nil {
+		slog.Error("failed to list messages", "error", err, "conversation_id", id)
+		middleware.InternalServerError(w, "Gagal memuat pesan")
+		return
+	}
+
+	writePage(w, messages, len(messages), next)
+}
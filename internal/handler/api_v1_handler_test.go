@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type noAPIKeys struct{}
+
+func (noAPIKeys) AuthenticateAPIKey(ctx context.Context, key string) (*model.APIKey, error) {
+	return nil, errors.New("invalid API key")
+}
+
+func TestOpenAPIMatchesRegisteredRoutes(t *testing.T) {
+	h := NewAPIV1Handler(nil, nil, nil, nil)
+	r := chi.NewRouter()
+	h.Register(r, noAPIKeys{})
+
+	var registered []string
+	err := chi.Walk(r, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		if route != "/openapi.json" {
+			registered = append(registered, method+" "+route)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk routes: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json = %d", rec.Code)
+	}
+	var doc struct {
+		OpenAPI string                            `json:"openapi"`
+		Paths   map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("openapi = %q, want 3.x", doc.OpenAPI)
+	}
+
+	var documented []string
+	for path, ops := range doc.Paths {
+		for method := range ops {
+			documented = append(documented, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(registered)
+	sort.Strings(documented)
+	if strings.Join(registered, "\n") != strings.Join(documented, "\n") {
+		t.Errorf("routes and OpenAPI paths differ\nroutes:\n%s\ndocumented:\n%s",
+			strings.Join(registered, "\n"), strings.Join(documented, "\n"))
+	}
+}
+
+func TestAPIV1RequiresAPIKey(t *testing.T) {
+	r := chi.NewRouter()
+	NewAPIV1Handler(nil, nil, nil, nil).Register(r, noAPIKeys{})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/cars", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /cars without key = %d, want 401", rec.Code)
+	}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	for _, id := range []int{1, 42, 1 << 30} {
+		got, err := decodeCursor(encodeCursor(id))
+		if err != nil || got != id {
+			t.Errorf("decodeCursor(encodeCursor(%d)) = %d, %v", id, got, err)
+		}
+	}
+
+	for _, cursor := range []string{"", "!!", "aWQ6", "aWQ6LTE", "MTIz"} {
+		if _, err := decodeCursor(cursor); err == nil {
+			t.Errorf("decodeCursor(%q) succeeded, want error", cursor)
+		}
+	}
+}
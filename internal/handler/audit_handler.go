@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+// maxAuditExport caps the rows written by a single export
+const maxAuditExport = 10000
+
+type AuditHandler struct {
+	repo *repository.AuditRepository
+}
+
+func NewAuditHandler(repo *repository.AuditRepository) *AuditHandler {
+	return &AuditHandler{repo: repo}
+}
+
+// List handles GET /api/admin/audit-logs?q=&entity_type=&entity_id=&actor_type=&action=&from=&to=&limit=&offset=
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+
+	entries, total, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		slog.Error("failed to list audit logs", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat audit log")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  entries,
+		"count": len(entries),
+		"total": total,
+	})
+}
+
+// Export handles GET /api/admin/audit-logs/export?format=csv|json with the same filters as List
+func (h *AuditHandler) Export(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	filter.Limit = maxAuditExport
+	filter.Offset = 0
+
+	entries, _, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		slog.Error("failed to export audit logs", "error", err)
+		middleware.InternalServerError(w, "Gagal mengekspor audit log")
+		return
+	}
+
+	filename := "audit_log_" + time.Now().Format("20060102")
+
+	switch r.URL.Query().Get("format") {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+".json\"")
+		json.NewEncoder(w).Encode(entries)
+	default:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+".csv\"")
+		writeAuditCSV(w, entries)
+	}
+}
+
+// parseAuditFilter reads the audit log filters from the query string. Dates are YYYY-MM-DD
+// and "to" is inclusive.
+func parseAuditFilter(w http.ResponseWriter, r *http.Request) (*model.AuditLogFilter, bool) {
+	q := r.URL.Query()
+	filter := &model.AuditLogFilter{
+		Search:     q.Get("q"),
+		EntityType: q.Get("entity_type"),
+		ActorType:  q.Get("actor_type"),
+		Action:     q.Get("action"),
+	}
+
+	if v := q.Get("entity_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.BadRequest(w, "ID entitas tidak valid")
+			return nil, false
+		}
+		filter.EntityID = &id
+	}
+	if v := q.Get("from"); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			middleware.BadRequest(w, "Format tanggal harus YYYY-MM-DD")
+			return nil, false
+		}
+		filter.From = &from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			middleware.BadRequest(w, "Format tanggal harus YYYY-MM-DD")
+			return nil, false
+		}
+		to = to.AddDate(0, 0, 1)
+		filter.To = &to
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 200 {
+			middleware.BadRequest(w, "Limit harus antara 1 dan 200")
+			return nil, false
+		}
+		filter.Limit = limit
+	}
+	if v := q.Get("offset"); v != "" {
+		offset, err := strconv.Atoi(v)
+		if err != nil || offset < 0 {
+			middleware.BadRequest(w, "Offset tidak valid")
+			return nil, false
+		}
+		filter.Offset = offset
+	}
+
+	return filter, true
+}
+
+func writeAuditCSV(w http.ResponseWriter, entries []*model.AuditLog) {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Time", "Actor Type", "Actor", "Entity", "Entity ID", "Action", "Before", "After"})
+	for _, e := range entries {
+		entityID := ""
+		if e.EntityID != nil {
+			entityID = strconv.Itoa(*e.EntityID)
+		}
+		cw.Write([]string{
+			e.CreatedAt.Format(time.RFC3339),
+			e.ActorType,
+			e.Actor,
+			e.EntityType,
+			entityID,
+			e.Action,
+			auditJSON(e.Before),
+			auditJSON(e.After),
+		})
+	}
+	cw.Flush()
+}
+
+func auditJSON(data map[string]interface{}) string {
+	if data == nil {
+		return ""
+	}
+	b, _ := json.Marshal(data)
+	return string(b)
+}
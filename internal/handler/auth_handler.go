@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/service"
+)
+
+type AuthHandler struct {
+	authService   *service.AuthService
+	otpService    *service.OTPService
+	secureCookies bool
+}
+
+func NewAuthHandler(authService *service.AuthService, secureCookies bool) *AuthHandler {
+	return &AuthHandler{
+		authService:   authService,
+		secureCookies: secureCookies,
+	}
+}
+
+// SetOTPService enables WhatsApp OTP login for sales staff
+func (h *AuthHandler) SetOTPService(otpService *service.OTPService) {
+	h.otpService = otpService
+}
+
+// Login handles POST /login from the admin login form
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.FormValue("next"))
+
+	req := &model.LoginRequest{
+		Email:    r.FormValue("email"),
+		Password: r.FormValue("password"),
+	}
+	if err := req.Validate(); err != nil {
+		http.Redirect(w, r, "/login?error=invalid&next="+url.QueryEscape(next), http.StatusSeeOther)
+		return
+	}
+
+	result, err := h.authService.Login(r.Context(), req, r.UserAgent(), r.RemoteAddr)
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidCredentials) {
+			slog.Error("login failed", "error", err)
+		}
+		http.Redirect(w, r, "/login?error=invalid&next="+url.QueryEscape(next), http.StatusSeeOther)
+		return
+	}
+
+	h.setSessionCookie(w, result)
+
+	slog.Info("user logged in", "user_id", result.User.ID, "tenant_id", result.User.TenantID)
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// RequestOTP handles POST /login/otp: sends a WhatsApp login code to a sales phone
+func (h *AuthHandler) RequestOTP(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.FormValue("next"))
+	phone := model.NormalizePhone(r.FormValue("phone"))
+	back := func(query string) {
+		http.Redirect(w, r, "/login?method=whatsapp&"+query+"&next="+url.QueryEscape(next), http.StatusSeeOther)
+	}
+
+	if h.otpService == nil {
+		back("error=otp_unavailable")
+		return
+	}
+	if len(phone) < 10 {
+		back("error=otp_phone")
+		return
+	}
+
+	if err := h.otpService.RequestOTP(r.Context(), phone, r.RemoteAddr); err != nil {
+		var rateErr *service.OTPRateLimitError
+		if errors.As(err, &rateErr) {
+			back("error=otp_rate&phone=" + url.QueryEscape(phone))
+			return
+		}
+		slog.Error("failed to send login OTP", "error", err)
+		back("error=otp_unavailable")
+		return
+	}
+
+	back("sent=1&phone=" + url.QueryEscape(phone))
+}
+
+// VerifyOTP handles POST /login/otp/verify: checks the code and logs the sales person in
+func (h *AuthHandler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
+	next := safeRedirect(r.FormValue("next"))
+	req := &model.OTPVerifyRequest{
+		PhoneNumber: r.FormValue("phone"),
+		Code:        strings.TrimSpace(r.FormValue("code")),
+	}
+	back := func(errCode string) {
+		http.Redirect(w, r, "/login?method=whatsapp&sent=1&error="+errCode+
+			"&phone="+url.QueryEscape(req.PhoneNumber)+"&next="+url.QueryEscape(next), http.StatusSeeOther)
+	}
+
+	if h.otpService == nil {
+		back("otp_unavailable")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		back("otp_invalid")
+		return
+	}
+
+	result, err := h.otpService.VerifyOTP(r.Context(), req, r.UserAgent(), r.RemoteAddr)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrOTPLocked):
+			back("otp_locked")
+		case errors.Is(err, service.ErrOTPInvalid):
+			back("otp_invalid")
+		default:
+			slog.Error("OTP login failed", "error", err)
+			back("otp_unavailable")
+		}
+		return
+	}
+
+	h.setSessionCookie(w, result)
+
+	slog.Info("sales logged in with WhatsApp OTP", "user_id", result.User.ID, "tenant_id", result.User.TenantID)
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+func (h *AuthHandler) setSessionCookie(w http.ResponseWriter, result *model.AuthResult) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    h.authService.SessionCookieValue(result.SessionToken),
+		Path:     "/",
+		Expires:  result.ExpiresAt,
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Logout handles POST /logout: revokes the session and clears the cookie
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if token, ok := middleware.SessionToken(h.authService, r); ok {
+		if err := h.authService.Logout(r.Context(), token); err != nil {
+			slog.Error("failed to revoke session", "error", err)
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     middleware.SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// APILogin handles POST /api/auth/login and returns a JWT bearer token.
+// Mounted without a tenant as POST /api/platform/auth/login it logs in super-admins.
+func (h *AuthHandler) APILogin(w http.ResponseWriter, r *http.Request) {
+	var req model.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	result, err := h.authService.Login(r.Context(), &req, r.UserAgent(), r.RemoteAddr)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCredentials) {
+			middleware.Unauthorized(w, "Email atau password salah")
+			return
+		}
+		slog.Error("api login failed", "error", err)
+		middleware.InternalServerError(w, "Gagal login")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// APILogout handles POST /api/auth/logout and revokes the bearer token's session
+func (h *AuthHandler) APILogout(w http.ResponseWriter, r *http.Request) {
+	token, ok := middleware.SessionToken(h.authService, r)
+	if !ok {
+		middleware.Unauthorized(w, "Token tidak valid")
+		return
+	}
+
+	if err := h.authService.Logout(r.Context(), token); err != nil {
+		slog.Error("failed to revoke session", "error", err)
+		middleware.InternalServerError(w, "Gagal logout")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Me handles GET /api/auth/me
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	user, err := model.GetUser(r.Context())
+	if err != nil {
+		middleware.Unauthorized(w, "Silakan login terlebih dahulu")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// safeRedirect only allows local paths, so ?next= cannot send users to another site
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/admin"
+	}
+	return next
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -47,6 +48,21 @@ func (h *BlogHandler) List(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// PublicList handles GET /api/blog - lists published posts for the public site
+func (h *BlogHandler) PublicList(w http.ResponseWriter, r *http.Request) {
+	posts, err := h.repo.ListPublished(r.Context(), 0)
+	if err != nil {
+		slog.Error("failed to list blog posts", "error", err)
+		http.Error(w, "Failed to list blog posts", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"posts": posts,
+	})
+}
+
 // Get handles GET /api/admin/blog/:id - gets a single blog post
 func (h *BlogHandler) Get(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
@@ -329,6 +345,10 @@ Use proper headings (H2, H3) and structure the content well.`, lengthInstruction
 	}
 
 	response, err := h.llmProvider.Chat(r.Context(), messages, nil)
+	if errors.Is(err, llm.ErrNotConfigured) {
+		http.Error(w, "AI service not available", http.StatusServiceUnavailable)
+		return
+	}
 	if err != nil {
 		slog.Error("AI generation failed", "error", err)
 		http.Error(w, "AI processing failed: "+err.Error(), http.StatusInternalServerError)
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/llm"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+// botPersonaHistory is how many persona versions the history shows
+const botPersonaHistory = 50
+
+type BotPersonaHandler struct {
+	repo *repository.BotPersonaRepository
+}
+
+func NewBotPersonaHandler(repo *repository.BotPersonaRepository) *BotPersonaHandler {
+	return &BotPersonaHandler{repo: repo}
+}
+
+// Get handles GET /api/admin/bot: the persona the tenant's bot uses now
+func (h *BotPersonaHandler) Get(w http.ResponseWriter, r *http.Request) {
+	persona, err := h.repo.Active(r.Context())
+	if err != nil {
+		slog.Error("failed to get bot persona", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat persona bot")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(persona)
+}
+
+// Save handles PUT /api/admin/bot. Every save adds a version; the bot uses it
+// from the next message on.
+func (h *BotPersonaHandler) Save(w http.ResponseWriter, r *http.Request) {
+	var persona model.BotPersona
+	if err := json.NewDecoder(r.Body).Decode(&persona); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := persona.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	version, err := h.repo.Save(r.Context(), &persona)
+	if err != nil {
+		slog.Error("failed to save bot persona", "error", err)
+		middleware.InternalServerError(w, "Gagal menyimpan persona bot")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(version)
+}
+
+// Versions handles GET /api/admin/bot/versions, newest first
+func (h *BotPersonaHandler) Versions(w http.ResponseWriter, r *http.Request) {
+	versions, err := h.repo.Versions(r.Context(), botPersonaHistory)
+	if err != nil {
+		slog.Error("failed to list bot persona versions", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat riwayat persona bot")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  versions,
+		"count": len(versions),
+	})
+}
+
+// Restore handles POST /api/admin/bot/versions/{version}/restore: the old
+// version comes back as the newest one
+func (h *BotPersonaHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil {
+		middleware.BadRequest(w, "Versi tidak valid")
+		return
+	}
+
+	version, err := h.repo.Restore(r.Context(), number)
+	if err != nil {
+		if errors.Is(err, repository.ErrPersonaVersionNotFound) {
+			middleware.NotFound(w, "Versi persona tidak ditemukan")
+			return
+		}
+		slog.Error("failed to restore bot persona", "error", err, "version", number)
+		middleware.InternalServerError(w, "Gagal memulihkan persona bot")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(version)
+}
+
+// Preview handles POST /api/admin/bot/preview: the system prompt of a draft
+// persona and a reply to a sample first message from the mock provider.
+// Nothing is saved and no LLM credits are spent.
+func (h *BotPersonaHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	var req model.BotPreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Persona.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		req.Message = "Halo, ada mobil matic di bawah 200 juta?"
+	}
+
+	active, err := h.repo.Active(r.Context())
+	if err != nil {
+		slog.Error("failed to get bot persona", "error", err)
+		middleware.InternalServerError(w, "Gagal membuat pratinjau")
+		return
+	}
+	req.Persona.ShowroomName = active.ShowroomName
+
+	preview, err := llm.Preview(r.Context(), &req.Persona, req.Message, req.IsSales)
+	if err != nil {
+		slog.Error("failed to preview bot persona", "error", err)
+		middleware.InternalServerError(w, "Gagal membuat pratinjau")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -16,6 +17,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/riz/auto-lmk/internal/llm"
+	"github.com/riz/auto-lmk/internal/middleware"
 	"github.com/riz/auto-lmk/internal/model"
 	"github.com/riz/auto-lmk/internal/repository"
 )
@@ -67,6 +69,13 @@ func (h *CarHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.repo.Create(r.Context(), &car); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if middleware.PlanError(w, err) {
+			return
+		}
 		slog.Error("failed to create car", "error", err)
 		http.Error(w, "Failed to create car", http.StatusInternalServerError)
 		return
@@ -325,6 +334,10 @@ func (h *CarHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.repo.Update(r.Context(), id, updates); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
 		slog.Error("failed to update car", "error", err, "id", id)
 		http.Error(w, "Failed to update car", http.StatusInternalServerError)
 		return
@@ -344,6 +357,10 @@ func (h *CarHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
 		slog.Error("failed to delete car", "error", err, "id", id)
 		http.Error(w, "Failed to delete car", http.StatusInternalServerError)
 		return
@@ -1505,6 +1522,10 @@ func (h *CarHandler) UploadPhotos(w http.ResponseWriter, r *http.Request) {
 
 	// Save photos to database using existing AddPhotos method
 	if err := h.repo.AddPhotos(r.Context(), carID, photoURLs); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
 		slog.Error("failed to save photos to database", "error", err, "car_id", carID)
 		http.Error(w, "Failed to save photos", http.StatusInternalServerError)
 		return
@@ -1538,6 +1559,10 @@ func (h *CarHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
 
 	// Delete photo from database
 	if err := h.repo.DeletePhoto(r.Context(), photoID); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
 		slog.Error("failed to delete photo", "error", err, "photo_id", photoID)
 		http.Error(w, "Failed to delete photo", http.StatusInternalServerError)
 		return
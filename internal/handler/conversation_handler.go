@@ -2,10 +2,12 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/riz/auto-lmk/internal/model"
@@ -218,3 +220,39 @@ func (h *ConversationHandler) Stats(w http.ResponseWriter, r *http.Request) {
 		"period":              "last_30_days",
 	})
 }
+
+// AssignRequest assigns a conversation or lead to a sales person; null unassigns
+type AssignRequest struct {
+	SalesID *int `json:"sales_id"`
+}
+
+// Assign handles PUT /api/conversations/:id/assign
+func (h *ConversationHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid conversation ID", http.StatusBadRequest)
+		return
+	}
+
+	var req AssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.repo.AssignSales(r.Context(), id, req.SalesID); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			http.Error(w, "Conversation or sales not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("failed to assign conversation", "error", err, "conversation_id", id)
+		http.Error(w, "Failed to assign conversation", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+type CustomerHandler struct {
+	repo *repository.CustomerRepository
+}
+
+func NewCustomerHandler(repo *repository.CustomerRepository) *CustomerHandler {
+	return &CustomerHandler{repo: repo}
+}
+
+// List handles GET /api/admin/customers?q=&tag=
+func (h *CustomerHandler) List(w http.ResponseWriter, r *http.Request) {
+	customers, err := h.repo.List(r.Context(), r.URL.Query().Get("q"), r.URL.Query().Get("tag"))
+	if err != nil {
+		slog.Error("failed to list customers", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat data customer")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  customers,
+		"count": len(customers),
+	})
+}
+
+// Get handles GET /api/admin/customers/{id}
+func (h *CustomerHandler) Get(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.loadCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+// Update handles PUT /api/admin/customers/{id}
+func (h *CustomerHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID customer tidak valid")
+		return
+	}
+
+	var req model.CustomerUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.repo.Update(r.Context(), id, &req); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Customer tidak ditemukan")
+			return
+		}
+		slog.Error("failed to update customer", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal menyimpan customer")
+		return
+	}
+
+	customer, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		middleware.InternalServerError(w, "Gagal memuat customer")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+// Timeline handles GET /api/admin/customers/{id}/timeline
+func (h *CustomerHandler) Timeline(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.loadCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 200
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 1000 {
+		limit = l
+	}
+
+	events, err := h.repo.Timeline(r.Context(), customer.PhoneNumber, limit)
+	if err != nil {
+		slog.Error("failed to load customer timeline", "error", err, "customer_id", customer.ID)
+		middleware.InternalServerError(w, "Gagal memuat timeline customer")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  events,
+		"count": len(events),
+	})
+}
+
+// CreateAppointment handles POST /api/admin/customers/{id}/appointments
+func (h *CustomerHandler) CreateAppointment(w http.ResponseWriter, r *http.Request) {
+	customer, ok := h.loadCustomer(w, r)
+	if !ok {
+		return
+	}
+
+	var appt model.Appointment
+	if err := json.NewDecoder(r.Body).Decode(&appt); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := appt.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	appt.CustomerPhone = customer.PhoneNumber
+	if err := h.repo.CreateAppointment(r.Context(), &appt); err != nil {
+		slog.Error("failed to create appointment", "error", err, "customer_id", customer.ID)
+		middleware.InternalServerError(w, "Gagal menyimpan janji temu")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(appt)
+}
+
+func (h *CustomerHandler) loadCustomer(w http.ResponseWriter, r *http.Request) (*model.Customer, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID customer tidak valid")
+		return nil, false
+	}
+
+	customer, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Customer tidak ditemukan")
+			return nil, false
+		}
+		slog.Error("failed to get customer", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal memuat customer")
+		return nil, false
+	}
+
+	return customer, true
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/internal/service"
+)
+
+type DataJobHandler struct {
+	service *service.DataJobService
+}
+
+func NewDataJobHandler(service *service.DataJobService) *DataJobHandler {
+	return &DataJobHandler{service: service}
+}
+
+// Create handles POST /api/admin/data-jobs. The job runs in the background;
+// poll GET /api/admin/data-jobs/{id} for its status.
+func (h *DataJobHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.DataJobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	job, err := h.service.Request(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			middleware.Forbidden(w, "Anda tidak memiliki akses untuk menjalankan job ini")
+			return
+		}
+		slog.Error("failed to create data job", "error", err, "type", req.Type)
+		middleware.InternalServerError(w, "Gagal membuat job")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// List handles GET /api/admin/data-jobs
+func (h *DataJobHandler) List(w http.ResponseWriter, r *http.Request) {
+	jobs, err := h.service.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list data jobs", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat daftar job")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  jobs,
+		"count": len(jobs),
+	})
+}
+
+// Get handles GET /api/admin/data-jobs/{id}
+func (h *DataJobHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, ok := dataJobID(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Job tidak ditemukan")
+			return
+		}
+		slog.Error("failed to get data job", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal memuat job")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// Download handles GET /api/admin/data-jobs/{id}/download
+func (h *DataJobHandler) Download(w http.ResponseWriter, r *http.Request) {
+	id, ok := dataJobID(w, r)
+	if !ok {
+		return
+	}
+
+	f, name, err := h.service.File(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Job tidak ditemukan")
+			return
+		}
+		if errors.Is(err, service.ErrExportNotReady) {
+			middleware.NotFound(w, "File ekspor belum siap atau sudah kedaluwarsa")
+			return
+		}
+		slog.Error("failed to open data export", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal mengunduh file ekspor")
+		return
+	}
+	defer f.Close()
+
+	if strings.HasSuffix(name, ".zip") {
+		w.Header().Set("Content-Type", "application/zip")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+name+"\"")
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := io.Copy(w, f); err != nil {
+		slog.Error("failed to send data export", "error", err, "id", id)
+	}
+}
+
+func dataJobID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID job tidak valid")
+		return 0, false
+	}
+	return id, true
+}
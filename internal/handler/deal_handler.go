@@ -0,0 +1,416 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/internal/service"
+	"github.com/riz/auto-lmk/pkg/pdf"
+)
+
+type DealHandler struct {
+	dealRepo       *repository.DealRepository
+	commissionRepo *repository.CommissionRepository
+	service        *service.DealService
+}
+
+func NewDealHandler(dealRepo *repository.DealRepository, commissionRepo *repository.CommissionRepository, dealService *service.DealService) *DealHandler {
+	return &DealHandler{
+		dealRepo:       dealRepo,
+		commissionRepo: commissionRepo,
+		service:        dealService,
+	}
+}
+
+// List handles GET /api/admin/deals
+func (h *DealHandler) List(w http.ResponseWriter, r *http.Request) {
+	filters := make(map[string]interface{})
+	if status := r.URL.Query().Get("status"); status != "" {
+		filters["status"] = status
+	}
+	if salesID, err := strconv.Atoi(r.URL.Query().Get("sales_id")); err == nil {
+		filters["sales_id"] = salesID
+	}
+
+	deals, err := h.dealRepo.List(r.Context(), filters)
+	if err != nil {
+		slog.Error("failed to list deals", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat data deal")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  deals,
+		"count": len(deals),
+	})
+}
+
+// Get handles GET /api/admin/deals/{id}
+func (h *DealHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID deal tidak valid")
+		return
+	}
+
+	deal, err := h.dealRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.writeDealError(w, err, "Gagal memuat deal")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deal)
+}
+
+// Create handles POST /api/admin/deals
+func (h *DealHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.DealRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	deal, err := h.service.CreateDeal(r.Context(), &req)
+	if err != nil {
+		h.writeDealError(w, err, "Gagal membuat deal")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(deal)
+}
+
+// Update handles PUT /api/admin/deals/{id}
+func (h *DealHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID deal tidak valid")
+		return
+	}
+
+	var req model.DealRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	deal, err := h.service.UpdateDeal(r.Context(), id, &req)
+	if err != nil {
+		h.writeDealError(w, err, "Gagal menyimpan deal")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deal)
+}
+
+// Close handles POST /api/admin/deals/{id}/close - closes the deal and marks the car sold
+func (h *DealHandler) Close(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID deal tidak valid")
+		return
+	}
+
+	var req model.CloseDealRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			middleware.BadRequest(w, "Format data tidak valid")
+			return
+		}
+	}
+
+	// Today is the showroom's date, not the server's
+	closingDate := time.Now().In(h.service.Location(r.Context()))
+	if req.ClosingDate != "" {
+		closingDate, err = time.Parse("2006-01-02", req.ClosingDate)
+		if err != nil {
+			middleware.BadRequest(w, "Format tanggal closing harus YYYY-MM-DD")
+			return
+		}
+	}
+
+	deal, err := h.service.CloseDeal(r.Context(), id, closingDate)
+	if err != nil {
+		h.writeDealError(w, err, "Gagal menutup deal")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deal)
+}
+
+// Cancel handles POST /api/admin/deals/{id}/cancel
+func (h *DealHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID deal tidak valid")
+		return
+	}
+
+	if err := h.dealRepo.Cancel(r.Context(), id); err != nil {
+		h.writeDealError(w, err, "Gagal membatalkan deal")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListRules handles GET /api/admin/commissions/rules
+func (h *DealHandler) ListRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.commissionRepo.ListRules(r.Context(), false)
+	if err != nil {
+		slog.Error("failed to list commission rules", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat aturan komisi")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  rules,
+		"count": len(rules),
+	})
+}
+
+// CreateRule handles POST /api/admin/commissions/rules
+func (h *DealHandler) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var rule model.CommissionRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := rule.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.commissionRepo.CreateRule(r.Context(), &rule); err != nil {
+		slog.Error("failed to create commission rule", "error", err)
+		middleware.InternalServerError(w, "Gagal menyimpan aturan komisi")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// UpdateRule handles PUT /api/admin/commissions/rules/{id}
+func (h *DealHandler) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID aturan tidak valid")
+		return
+	}
+
+	var rule model.CommissionRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := rule.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.commissionRepo.UpdateRule(r.Context(), id, &rule); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Aturan komisi tidak ditemukan")
+			return
+		}
+		slog.Error("failed to update commission rule", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal menyimpan aturan komisi")
+		return
+	}
+
+	rule.ID = id
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+// DeleteRule handles DELETE /api/admin/commissions/rules/{id}
+func (h *DealHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID aturan tidak valid")
+		return
+	}
+
+	if err := h.commissionRepo.DeleteRule(r.Context(), id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Aturan komisi tidak ditemukan")
+			return
+		}
+		slog.Error("failed to delete commission rule", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal menghapus aturan komisi")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Statements handles GET /api/admin/commissions/statements?month=YYYY-MM
+func (h *DealHandler) Statements(w http.ResponseWriter, r *http.Request) {
+	statements, month, ok := h.loadStatements(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"month": month.Format("2006-01"),
+		"data":  statements,
+		"count": len(statements),
+	})
+}
+
+// ExportStatements handles GET /api/admin/commissions/statements/export?month=YYYY-MM&format=csv|pdf
+func (h *DealHandler) ExportStatements(w http.ResponseWriter, r *http.Request) {
+	statements, month, ok := h.loadStatements(w, r)
+	if !ok {
+		return
+	}
+
+	filename := "komisi_" + month.Format("2006-01")
+
+	switch r.URL.Query().Get("format") {
+	case "pdf":
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+".pdf\"")
+		writeStatementsPDF(w, statements, month)
+	default:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", "attachment; filename=\""+filename+".csv\"")
+		writeStatementsCSV(w, statements)
+	}
+}
+
+func (h *DealHandler) loadStatements(w http.ResponseWriter, r *http.Request) ([]*model.CommissionStatement, time.Time, bool) {
+	month := time.Now().In(h.service.Location(r.Context()))
+	if m := r.URL.Query().Get("month"); m != "" {
+		parsed, err := time.Parse("2006-01", m)
+		if err != nil {
+			middleware.BadRequest(w, "Format bulan harus YYYY-MM")
+			return nil, month, false
+		}
+		month = parsed
+	}
+
+	statements, err := h.service.MonthlyStatements(r.Context(), month)
+	if err != nil {
+		slog.Error("failed to build commission statements", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat laporan komisi")
+		return nil, month, false
+	}
+
+	return statements, month, true
+}
+
+func (h *DealHandler) writeDealError(w http.ResponseWriter, err error, fallback string) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not found"):
+		middleware.NotFound(w, "Deal atau mobil tidak ditemukan")
+	case strings.Contains(msg, "idx_deals_active_car"):
+		middleware.Conflict(w, "Mobil ini sudah memiliki deal aktif", nil)
+	case strings.HasPrefix(msg, "mobil sudah") || strings.HasPrefix(msg, "deal sudah"):
+		middleware.Conflict(w, msg, nil)
+	default:
+		slog.Error("deal operation failed", "error", err)
+		middleware.InternalServerError(w, fallback)
+	}
+}
+
+func writeStatementsCSV(w http.ResponseWriter, statements []*model.CommissionStatement) {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"Sales", "Deal ID", "Closing Date", "Car", "Customer", "Payment", "Final Price", "Commission"})
+	for _, st := range statements {
+		for _, d := range st.Deals {
+			cw.Write([]string{
+				st.SalesName,
+				strconv.Itoa(d.ID),
+				formatDealDate(d.ClosingDate),
+				d.CarName,
+				d.CustomerName,
+				d.PaymentMethod,
+				strconv.FormatInt(d.FinalPrice(), 10),
+				strconv.FormatInt(d.CommissionAmount, 10),
+			})
+		}
+		cw.Write([]string{st.SalesName, "TOTAL", "", "", "", "", strconv.FormatInt(st.TotalSales, 10), strconv.FormatInt(st.TotalCommission, 10)})
+	}
+	cw.Flush()
+}
+
+func writeStatementsPDF(w http.ResponseWriter, statements []*model.CommissionStatement, month time.Time) {
+	doc := pdf.NewDocument()
+	doc.Heading("Laporan Komisi Sales - " + month.Format("01/2006"))
+
+	widths := []float64{0.10, 0.14, 0.30, 0.20, 0.13, 0.13}
+	if len(statements) == 0 {
+		doc.Line("Tidak ada deal yang closing pada bulan ini.")
+	}
+	for _, st := range statements {
+		doc.Space()
+		doc.Heading(st.SalesName)
+		doc.Row([]string{"Deal", "Tanggal", "Mobil", "Customer", "Harga", "Komisi"}, widths, true)
+		for _, d := range st.Deals {
+			doc.Row([]string{
+				fmt.Sprintf("#%d", d.ID),
+				formatDealDate(d.ClosingDate),
+				d.CarName,
+				d.CustomerName,
+				formatRupiah(d.FinalPrice()),
+				formatRupiah(d.CommissionAmount),
+			}, widths, false)
+		}
+		doc.Row([]string{"", "", fmt.Sprintf("%d deal", st.DealCount), "Total", formatRupiah(st.TotalSales), formatRupiah(st.TotalCommission)}, widths, true)
+	}
+
+	doc.WriteTo(w)
+}
+
+func formatDealDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// formatRupiah formats 250000000 as "Rp 250.000.000"
+func formatRupiah(amount int64) string {
+	s := strconv.FormatInt(amount, 10)
+	var b strings.Builder
+	for i, digit := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+	return "Rp " + b.String()
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/internal/service"
+)
+
+type DomainHandler struct {
+	service *service.DomainService
+}
+
+func NewDomainHandler(service *service.DomainService) *DomainHandler {
+	return &DomainHandler{service: service}
+}
+
+// List handles GET /api/admin/domains: the platform domain, the custom
+// domains with their verification status and the canonical domain
+func (h *DomainHandler) List(w http.ResponseWriter, r *http.Request) {
+	domains, err := h.service.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list domains", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat domain")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(domains)
+}
+
+// Add handles POST /api/admin/domains. The response holds the TXT record and
+// HTTP URL that prove ownership.
+func (h *DomainHandler) Add(w http.ResponseWriter, r *http.Request) {
+	var req model.AddDomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := h.service.Validate(&req); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	domain, err := h.service.Add(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrDomainTaken) {
+			middleware.Conflict(w, "Domain sudah terdaftar", nil)
+			return
+		}
+		slog.Error("failed to add domain", "error", err, "domain", req.Domain)
+		middleware.InternalServerError(w, "Gagal menambah domain")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(domain)
+}
+
+// Verify handles POST /api/admin/domains/{id}/verify. A failed check is not an
+// error: the domain comes back with status failed and the reason.
+func (h *DomainHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	id, ok := domainID(w, r)
+	if !ok {
+		return
+	}
+
+	domain, err := h.service.Verify(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDomainNotFound):
+			middleware.NotFound(w, "Domain tidak ditemukan")
+		case errors.Is(err, repository.ErrDomainTaken):
+			middleware.Conflict(w, "Domain sudah dipakai tenant lain", nil)
+		default:
+			slog.Error("failed to verify domain", "error", err, "domain_id", id)
+			middleware.InternalServerError(w, "Gagal memverifikasi domain")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(domain)
+}
+
+// SetCanonical handles PUT /api/admin/domains/canonical
+func (h *DomainHandler) SetCanonical(w http.ResponseWriter, r *http.Request) {
+	var req model.CanonicalDomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := h.service.SetCanonical(r.Context(), &req); err != nil {
+		if errors.Is(err, repository.ErrDomainNotVerified) {
+			middleware.BadRequest(w, "Hanya domain terverifikasi yang bisa dijadikan domain utama")
+			return
+		}
+		slog.Error("failed to set canonical domain", "error", err, "domain", req.Domain)
+		middleware.InternalServerError(w, "Gagal mengubah domain utama")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Domain utama diperbarui"})
+}
+
+// Delete handles DELETE /api/admin/domains/{id}
+func (h *DomainHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := domainID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrDomainNotFound) {
+			middleware.NotFound(w, "Domain tidak ditemukan")
+			return
+		}
+		slog.Error("failed to delete domain", "error", err, "domain_id", id)
+		middleware.InternalServerError(w, "Gagal menghapus domain")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Domain dihapus"})
+}
+
+// Challenge handles GET /.well-known/autolmk-verification/{token} on a domain
+// pointed at the platform but not verified yet: it answers with the token when
+// a tenant added the requested host with it, which completes the HTTP check
+func (h *DomainHandler) Challenge(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+
+	ok, err := h.service.HasChallenge(r.Context(), host, token)
+	if err != nil {
+		slog.Error("failed to check domain challenge", "error", err, "domain", host)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write([]byte(token))
+}
+
+func domainID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID domain tidak valid")
+		return 0, false
+	}
+	return id, true
+}
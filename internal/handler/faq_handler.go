@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+type FAQHandler struct {
+	repo *repository.FAQRepository
+}
+
+func NewFAQHandler(repo *repository.FAQRepository) *FAQHandler {
+	return &FAQHandler{repo: repo}
+}
+
+// List handles GET /api/admin/faqs
+func (h *FAQHandler) List(w http.ResponseWriter, r *http.Request) {
+	faqs, err := h.repo.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list faqs", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat FAQ")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  faqs,
+		"count": len(faqs),
+	})
+}
+
+// Create handles POST /api/admin/faqs
+func (h *FAQHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.FAQRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	faq, err := h.repo.Create(r.Context(), &req)
+	if err != nil {
+		slog.Error("failed to create faq", "error", err)
+		middleware.InternalServerError(w, "Gagal menyimpan FAQ")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(faq)
+}
+
+// Update handles PUT /api/admin/faqs/{id}
+func (h *FAQHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID FAQ tidak valid")
+		return
+	}
+
+	var req model.FAQRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.repo.Update(r.Context(), id, &req); err != nil {
+		if errors.Is(err, repository.ErrFAQNotFound) {
+			middleware.NotFound(w, "FAQ tidak ditemukan")
+			return
+		}
+		slog.Error("failed to update faq", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal menyimpan FAQ")
+		return
+	}
+
+	faq, err := h.repo.Get(r.Context(), id)
+	if err != nil {
+		middleware.InternalServerError(w, "Gagal memuat FAQ")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(faq)
+}
+
+// Delete handles DELETE /api/admin/faqs/{id}
+func (h *FAQHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID FAQ tidak valid")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrFAQNotFound) {
+			middleware.NotFound(w, "FAQ tidak ditemukan")
+			return
+		}
+		slog.Error("failed to delete faq", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal menghapus FAQ")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/internal/service"
+)
+
+type FinancingHandler struct {
+	repo    *repository.FinancingRepository
+	service *service.FinancingService
+}
+
+func NewFinancingHandler(repo *repository.FinancingRepository, financingService *service.FinancingService) *FinancingHandler {
+	return &FinancingHandler{
+		repo:    repo,
+		service: financingService,
+	}
+}
+
+// Simulate handles POST /api/financing/simulate
+func (h *FinancingHandler) Simulate(w http.ResponseWriter, r *http.Request) {
+	var req model.CreditSimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	sim, err := h.service.SimulateCredit(r.Context(), &req)
+	if err != nil {
+		if middleware.PlanError(w, err) {
+			return
+		}
+		if strings.Contains(err.Error(), "car not found") {
+			middleware.NotFound(w, "Mobil tidak ditemukan")
+			return
+		}
+		slog.Warn("credit simulation failed", "error", err, "car_id", req.CarID)
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sim)
+}
+
+// PublicPartners handles GET /api/financing/partners - active partners for the calculator
+func (h *FinancingHandler) PublicPartners(w http.ResponseWriter, r *http.Request) {
+	partners, err := h.repo.ListPartners(r.Context(), true)
+	if err != nil {
+		slog.Error("failed to list leasing partners", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat data leasing")
+		return
+	}
+
+	// Collect distinct tenors so the calculator can offer them
+	tenorSet := make(map[int]bool)
+	tenors := []int{}
+	for _, p := range partners {
+		for _, rate := range p.Rates {
+			if !tenorSet[rate.TenorMonths] {
+				tenorSet[rate.TenorMonths] = true
+				tenors = append(tenors, rate.TenorMonths)
+			}
+		}
+	}
+
+	type publicPartner struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	result := make([]publicPartner, len(partners))
+	for i, p := range partners {
+		result[i] = publicPartner{ID: p.ID, Name: p.Name}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"partners": result,
+		"tenors":   tenors,
+	})
+}
+
+// ListPartners handles GET /api/admin/financing/partners
+func (h *FinancingHandler) ListPartners(w http.ResponseWriter, r *http.Request) {
+	partners, err := h.repo.ListPartners(r.Context(), false)
+	if err != nil {
+		slog.Error("failed to list leasing partners", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat data leasing")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  partners,
+		"count": len(partners),
+	})
+}
+
+// CreatePartner handles POST /api/admin/financing/partners
+func (h *FinancingHandler) CreatePartner(w http.ResponseWriter, r *http.Request) {
+	var req model.LeasingPartnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	partner, err := h.repo.CreatePartner(r.Context(), &req)
+	if err != nil {
+		slog.Error("failed to create leasing partner", "error", err)
+		middleware.InternalServerError(w, "Gagal menyimpan leasing")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(partner)
+}
+
+// UpdatePartner handles PUT /api/admin/financing/partners/{id}
+func (h *FinancingHandler) UpdatePartner(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID leasing tidak valid")
+		return
+	}
+
+	var req model.LeasingPartnerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.repo.UpdatePartner(r.Context(), id, &req); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Leasing tidak ditemukan")
+			return
+		}
+		slog.Error("failed to update leasing partner", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal menyimpan leasing")
+		return
+	}
+
+	partner, err := h.repo.GetPartner(r.Context(), id)
+	if err != nil {
+		middleware.InternalServerError(w, "Gagal memuat leasing")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(partner)
+}
+
+// DeletePartner handles DELETE /api/admin/financing/partners/{id}
+func (h *FinancingHandler) DeletePartner(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID leasing tidak valid")
+		return
+	}
+
+	if err := h.repo.DeletePartner(r.Context(), id); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Leasing tidak ditemukan")
+			return
+		}
+		slog.Error("failed to delete leasing partner", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal menghapus leasing")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+type LeadHandler struct {
+	repo *repository.LeadRepository
+}
+
+func NewLeadHandler(repo *repository.LeadRepository) *LeadHandler {
+	return &LeadHandler{repo: repo}
+}
+
+// List handles GET /api/admin/leads?status= (sales users only see their assigned leads)
+func (h *LeadHandler) List(w http.ResponseWriter, r *http.Request) {
+	leads, err := h.repo.List(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		slog.Error("failed to list leads", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat data lead")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  leads,
+		"count": len(leads),
+	})
+}
+
+// Get handles GET /api/admin/leads/{id}
+func (h *LeadHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID lead tidak valid")
+		return
+	}
+
+	lead, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Lead tidak ditemukan")
+			return
+		}
+		slog.Error("failed to get lead", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal memuat lead")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(lead)
+}
+
+// Assign handles PUT /api/admin/leads/{id}/assign
+func (h *LeadHandler) Assign(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID lead tidak valid")
+		return
+	}
+
+	var req AssignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := h.repo.AssignSales(r.Context(), id, req.SalesID); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			middleware.Forbidden(w, "Anda tidak memiliki akses untuk membagi lead")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Lead atau sales tidak ditemukan")
+			return
+		}
+		slog.Error("failed to assign lead", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal membagi lead")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/riz/auto-lmk/internal/llm"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/internal/service"
+)
+
+type LLMSettingsHandler struct {
+	service *service.LLMSettingsService
+}
+
+func NewLLMSettingsHandler(service *service.LLMSettingsService) *LLMSettingsHandler {
+	return &LLMSettingsHandler{service: service}
+}
+
+// Get handles GET /api/admin/llm: the tenant's own provider, if any, and
+// whether the platform provider and own keys are available
+func (h *LLMSettingsHandler) Get(w http.ResponseWriter, r *http.Request) {
+	status, err := h.service.Status(r.Context())
+	if err != nil {
+		slog.Error("failed to get LLM settings", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat pengaturan AI")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// Save handles PUT /api/admin/llm. The API key may be left out to keep the
+// stored one.
+func (h *LLMSettingsHandler) Save(w http.ResponseWriter, r *http.Request) {
+	var req model.SaveLLMSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	settings, err := h.service.Save(r.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrLLMKeyRequired):
+			middleware.BadRequest(w, "API key wajib diisi untuk provider ini")
+		case errors.Is(err, repository.ErrLLMKeysUnavailable):
+			middleware.BadRequest(w, "Server belum mendukung penyimpanan API key")
+		default:
+			slog.Error("failed to save LLM settings", "error", err)
+			middleware.InternalServerError(w, "Gagal menyimpan pengaturan AI")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+// Delete handles DELETE /api/admin/llm: back to the platform provider
+func (h *LLMSettingsHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Delete(r.Context()); err != nil {
+		slog.Error("failed to delete LLM settings", "error", err)
+		middleware.InternalServerError(w, "Gagal menghapus pengaturan AI")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Kembali menggunakan AI bawaan platform"})
+}
+
+// Test handles POST /api/admin/llm/test. A failing provider is not a server
+// error: the response carries ok false and the provider's message.
+func (h *LLMSettingsHandler) Test(w http.ResponseWriter, r *http.Request) {
+	reply, err := h.service.Test(r.Context())
+
+	result := map[string]interface{}{"ok": err == nil, "reply": reply}
+	switch {
+	case err == nil:
+	case errors.Is(err, llm.ErrNotConfigured):
+		result["error"] = "Belum ada provider AI yang bisa digunakan"
+	case errors.Is(err, service.ErrPlatformLLMFailed):
+		result["error"] = "AI bawaan platform sedang tidak bisa dihubungi"
+	default:
+		slog.Warn("LLM test failed", "error", err)
+		result["error"] = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
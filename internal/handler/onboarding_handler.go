@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/internal/service"
+)
+
+type OnboardingHandler struct {
+	service *service.OnboardingService
+}
+
+func NewOnboardingHandler(service *service.OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{service: service}
+}
+
+// Onboard handles POST /api/admin/tenants/onboard (platform admins): creates a
+// tenant with its first owner and optionally seeds demo inventory. Repeating
+// the request returns the existing tenant with 200 instead of 201.
+func (h *OnboardingHandler) Onboard(w http.ResponseWriter, r *http.Request) {
+	var req model.OnboardTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	result, err := h.service.Onboard(r.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDomainTaken):
+			middleware.Conflict(w, "Domain sudah dipakai tenant lain", nil)
+		case strings.Contains(err.Error(), "already exists"):
+			middleware.Conflict(w, "Email sudah terdaftar dengan role lain", nil)
+		default:
+			slog.Error("failed to onboard tenant", "error", err, "domain", req.Tenant.Domain)
+			middleware.InternalServerError(w, "Gagal membuat tenant")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if result.Created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// Progress handles GET /api/admin/onboarding
+func (h *OnboardingHandler) Progress(w http.ResponseWriter, r *http.Request) {
+	progress, err := h.service.Progress(r.Context())
+	if err != nil {
+		slog.Error("failed to get onboarding progress", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat progres onboarding")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+// Skip handles POST /api/admin/onboarding/steps/{step}/skip
+func (h *OnboardingHandler) Skip(w http.ResponseWriter, r *http.Request) {
+	h.setSkipped(w, r, true)
+}
+
+// Unskip handles DELETE /api/admin/onboarding/steps/{step}/skip
+func (h *OnboardingHandler) Unskip(w http.ResponseWriter, r *http.Request) {
+	h.setSkipped(w, r, false)
+}
+
+func (h *OnboardingHandler) setSkipped(w http.ResponseWriter, r *http.Request, skipped bool) {
+	step := chi.URLParam(r, "step")
+	if !model.IsSkippableOnboardingStep(step) {
+		middleware.BadRequest(w, "Langkah ini tidak bisa dilewati")
+		return
+	}
+
+	progress, err := h.service.Skip(r.Context(), step, skipped)
+	if err != nil {
+		h.onboardingError(w, err, "Gagal menyimpan progres onboarding")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+// Finish handles POST /api/admin/onboarding/finish
+func (h *OnboardingHandler) Finish(w http.ResponseWriter, r *http.Request) {
+	progress, err := h.service.Finish(r.Context())
+	if err != nil {
+		h.onboardingError(w, err, "Gagal menyelesaikan onboarding")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+// SeedDemo handles POST /api/admin/onboarding/demo
+func (h *OnboardingHandler) SeedDemo(w http.ResponseWriter, r *http.Request) {
+	count, err := h.service.SeedDemo(r.Context())
+	if err != nil {
+		if errors.Is(err, repository.ErrDemoSeeded) {
+			middleware.Conflict(w, "Data demo sudah ditambahkan", nil)
+			return
+		}
+		h.onboardingError(w, err, "Gagal menambahkan data demo")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": fmt.Sprintf("%d mobil demo berhasil ditambahkan", count),
+		"cars":    count,
+	})
+}
+
+// RemoveDemo handles DELETE /api/admin/onboarding/demo
+func (h *OnboardingHandler) RemoveDemo(w http.ResponseWriter, r *http.Request) {
+	count, err := h.service.RemoveDemo(r.Context())
+	if err != nil {
+		h.onboardingError(w, err, "Gagal menghapus data demo")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Data demo berhasil dihapus",
+		"cars":    count,
+	})
+}
+
+func (h *OnboardingHandler) onboardingError(w http.ResponseWriter, err error, message string) {
+	if errors.Is(err, repository.ErrForbidden) {
+		middleware.Forbidden(w, "Anda tidak memiliki akses untuk mengubah onboarding")
+		return
+	}
+	if middleware.PlanError(w, err) {
+		return
+	}
+	slog.Error("onboarding operation failed", "error", err)
+	middleware.InternalServerError(w, message)
+}
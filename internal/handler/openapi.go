@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var pathParamPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// buildOpenAPI generates the OpenAPI 3 document of the /api/v1 routes. Schemas
+// are derived from the JSON tags of the request and result types.
+func buildOpenAPI(routes []apiRoute) map[string]interface{} {
+	schemas := openAPISchemas{}
+	schemas.defs = map[string]interface{}{
+		"Error": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"error": map[string]interface{}{"type": "string"},
+				"code":  map[string]interface{}{"type": "string"},
+			},
+		},
+	}
+
+	paths := map[string]map[string]interface{}{}
+	for _, route := range routes {
+		if paths[route.Pattern] == nil {
+			paths[route.Pattern] = map[string]interface{}{}
+		}
+		paths[route.Pattern][strings.ToLower(route.Method)] = schemas.operation(route)
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Auto LMK API",
+			"version":     "1.0.0",
+			"description": "Integration API for dealer management systems and marketplaces. Lists are newest first; pass next_cursor back as cursor for the next page.",
+		},
+		"servers":  []interface{}{map[string]interface{}{"url": "/api/v1"}},
+		"security": []interface{}{map[string]interface{}{"bearerKey": []string{}}, map[string]interface{}{"headerKey": []string{}}},
+		"paths":    paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.defs,
+			"securitySchemes": map[string]interface{}{
+				"bearerKey": map[string]interface{}{"type": "http", "scheme": "bearer", "description": "Authorization: Bearer lmk_..."},
+				"headerKey": map[string]interface{}{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			},
+		},
+	}
+}
+
+type openAPISchemas struct {
+	defs map[string]interface{}
+}
+
+func (s openAPISchemas) operation(route apiRoute) map[string]interface{} {
+	var params []interface{}
+	for _, match := range pathParamPattern.FindAllStringSubmatch(route.Pattern, -1) {
+		params = append(params, map[string]interface{}{
+			"name": match[1], "in": "path", "required": true,
+			"schema": map[string]interface{}{"type": "integer"},
+		})
+	}
+	paginated := false
+	for _, p := range route.Query {
+		paginated = paginated || p.Name == "cursor"
+		params = append(params, map[string]interface{}{
+			"name": p.Name, "in": "query", "description": p.Description,
+			"schema": map[string]interface{}{"type": p.Type},
+		})
+	}
+
+	op := map[string]interface{}{
+		"summary":     route.Summary,
+		"operationId": operationID(route),
+		"description": "Requires scope " + route.Scope + ".",
+		"tags":        []string{strings.Split(strings.TrimPrefix(route.Pattern, "/"), "/")[0]},
+		"responses":   s.responses(route, paginated),
+	}
+	if params != nil {
+		op["parameters"] = params
+	}
+
+	switch route.Request.(type) {
+	case nil:
+	case multipartPhotos:
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"multipart/form-data": map[string]interface{}{
+					"schema": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"photos": map[string]interface{}{
+								"type":  "array",
+								"items": map[string]interface{}{"type": "string", "format": "binary"},
+							},
+						},
+					},
+				},
+			},
+		}
+	default:
+		op["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content":  jsonContent(s.schema(reflect.TypeOf(route.Request))),
+		}
+	}
+
+	return op
+}
+
+func (s openAPISchemas) responses(route apiRoute, paginated bool) map[string]interface{} {
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	success := map[string]interface{}{"description": http.StatusText(status)}
+	if route.Result != nil {
+		t := reflect.TypeOf(route.Result)
+		schema := s.schema(t)
+		if t.Kind() == reflect.Slice {
+			props := map[string]interface{}{
+				"data":  schema,
+				"count": map[string]interface{}{"type": "integer"},
+			}
+			if paginated {
+				props["next_cursor"] = map[string]interface{}{"type": "string", "nullable": true}
+			}
+			schema = map[string]interface{}{"type": "object", "properties": props}
+		}
+		success["content"] = jsonContent(schema)
+	}
+
+	errorResponse := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content":     jsonContent(map[string]interface{}{"$ref": "#/components/schemas/Error"}),
+		}
+	}
+
+	responses := map[string]interface{}{
+		strconv.Itoa(status): success,
+		"401":                errorResponse("Missing or invalid API key"),
+		"403":                errorResponse("API key lacks scope " + route.Scope),
+		"429":                errorResponse("Rate limit of the API key exceeded; retry after the Retry-After seconds"),
+	}
+	if route.Request != nil || route.Query != nil {
+		responses["400"] = errorResponse("Invalid request")
+	}
+	if strings.Contains(route.Pattern, "{") {
+		responses["404"] = errorResponse("Not found")
+	}
+	return responses
+}
+
+// schema returns the JSON schema of t, registering structs as named components
+func (s openAPISchemas) schema(t reflect.Type) map[string]interface{} {
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := s.schema(t.Elem())
+		if _, isRef := schema["$ref"]; !isRef {
+			schema["nullable"] = true
+		}
+		return schema
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": true}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Struct:
+		name := schemaName(t)
+		if _, ok := s.defs[name]; !ok {
+			s.defs[name] = nil // reserve the name in case t refers to itself
+			s.defs[name] = s.object(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+
+	return map[string]interface{}{}
+}
+
+func (s openAPISchemas) object(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		props[name] = s.schema(field.Type)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Ptr {
+			required = append(required, name)
+		}
+	}
+
+	object := map[string]interface{}{"type": "object", "properties": props}
+	if required != nil {
+		object["required"] = required
+	}
+	return object
+}
+
+// schemaName names a component after its Go type, e.g. CreateLeadRequest
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// operationID turns "GET /cars/{id}/photos" into "get_cars_id_photos"
+func operationID(route apiRoute) string {
+	path := pathParamPattern.ReplaceAllString(route.Pattern, "$1")
+	return strings.ToLower(route.Method) + strings.ReplaceAll(path, "/", "_")
+}
+
+func jsonContent(schema map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{"schema": schema},
+	}
+}
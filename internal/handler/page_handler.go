@@ -161,10 +161,12 @@ func NewPageHandler(carRepo *repository.CarRepository, salesRepo *repository.Sal
 		"templates/pages/cars.html",
 		"templates/pages/car-detail.html",
 		"templates/pages/contact.html",
+		"templates/pages/trade-in.html",
 		"templates/pages/blog.html",
 		"templates/pages/blog-detail.html",
 		// Admin standalone pages (content templates parsed separately in renderAdminPage)
 		"templates/admin/whatsapp.html",
+		"templates/admin/login.html",
 	)
 	if err != nil {
 		fmt.Printf("FATAL: Failed to parse templates: %v\n", err)
@@ -385,6 +387,34 @@ func (h *PageHandler) Contact(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// TradeIn renders the public trade-in (tukar tambah) form
+func (h *PageHandler) TradeIn(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Tukar Tambah"
+	data["CurrentYear"] = time.Now().Year()
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := h.templates.ExecuteTemplate(w, "trade-in.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Login renders the admin login page
+func (h *PageHandler) Login(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Login"
+	data["LoginError"] = r.URL.Query().Get("error")
+	data["Next"] = safeRedirect(r.URL.Query().Get("next"))
+	data["Method"] = r.URL.Query().Get("method")
+	data["OTPSent"] = r.URL.Query().Get("sent") != ""
+	data["Phone"] = r.URL.Query().Get("phone")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := h.templates.ExecuteTemplate(w, "login.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // AdminDashboard renders the admin dashboard
 func (h *PageHandler) AdminDashboard(w http.ResponseWriter, r *http.Request) {
 	data := h.getDefaultData(r)
@@ -844,6 +874,28 @@ func (h *PageHandler) AdminSettings(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// AdminOnboarding renders the setup wizard for new dealerships
+func (h *PageHandler) AdminOnboarding(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Mulai"
+	data["ActiveMenu"] = "onboarding"
+
+	if err := h.renderAdminPage(w, "templates/admin/onboarding.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminUsage renders the tenant's plan with its limits and usage
+func (h *PageHandler) AdminUsage(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Paket & Pemakaian"
+	data["ActiveMenu"] = "usage"
+
+	if err := h.renderAdminPage(w, "templates/admin/usage.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // BlogList renders the public blog listing page
 func (h *PageHandler) BlogList(w http.ResponseWriter, r *http.Request) {
 	data := h.getDefaultData(r)
@@ -929,6 +981,80 @@ func (h *PageHandler) AdminShowroom(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// AdminFinancing renders the admin leasing partner and rate table page
+func (h *PageHandler) AdminFinancing(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Simulasi Kredit & Leasing"
+	data["ActiveMenu"] = "financing"
+
+	if err := h.renderAdminPage(w, "templates/admin/financing.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminTradeIns renders the trade-in appraisal page
+func (h *PageHandler) AdminTradeIns(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Tukar Tambah"
+	data["ActiveMenu"] = "trade-ins"
+
+	if err := h.renderAdminPage(w, "templates/admin/trade_ins.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminDeals renders the deals and commission page
+func (h *PageHandler) AdminDeals(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Deal & Komisi"
+	data["ActiveMenu"] = "deals"
+	data["CurrentMonth"] = time.Now().Format("2006-01")
+
+	if err := h.renderAdminPage(w, "templates/admin/deals.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminCustomers renders the customer list page
+func (h *PageHandler) AdminCustomers(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Customer"
+	data["ActiveMenu"] = "customers"
+
+	if err := h.renderAdminPage(w, "templates/admin/customers.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminAudit renders the searchable audit log page
+func (h *PageHandler) AdminAudit(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Audit Log"
+	data["ActiveMenu"] = "audit"
+
+	if err := h.renderAdminPage(w, "templates/admin/audit.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminCustomerDetail renders a customer profile with its activity timeline
+func (h *PageHandler) AdminCustomerDetail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid customer ID", http.StatusBadRequest)
+		return
+	}
+
+	data := h.getDefaultData(r)
+	data["Title"] = "Profil Customer"
+	data["ActiveMenu"] = "customers"
+	data["CustomerID"] = id
+
+	if err := h.renderAdminPage(w, "templates/admin/customer_detail.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 // getDefaultData returns default template data with tenant info
 func (h *PageHandler) getDefaultData(r *http.Request) map[string]interface{} {
 	// Default values
@@ -970,6 +1096,20 @@ func (h *PageHandler) getDefaultData(r *http.Request) map[string]interface{} {
 		}
 	}
 
+	// Logged-in admin user (set by RequireSession); Can drives which menus are shown
+	can := map[string]bool{}
+	if user, err := model.GetUser(r.Context()); err == nil {
+		data["UserName"] = user.DisplayName()
+		data["UserRole"] = user.Role
+		for _, perm := range model.AllPermissions {
+			can[string(perm)] = user.Can(perm)
+		}
+	}
+	data["Can"] = can
+
+	// Anti-CSRF token of the admin session (set by CSRFProtect), read by static/js/csrf.js
+	data["CSRFToken"] = model.GetCSRFToken(r.Context())
+
 	// Try to load showroom settings if tenant context is available
 	if tenantID > 0 && h.showroomRepo != nil {
 		showroom, err := h.showroomRepo.GetByTenantID(r.Context(), tenantID)
@@ -984,8 +1124,8 @@ func (h *PageHandler) getDefaultData(r *http.Request) map[string]interface{} {
 			if showroom.Email != nil {
 				data["ShowroomEmail"] = *showroom.Email
 			}
-			if showroom.BusinessHours != nil {
-				data["ShowroomBusinessHours"] = *showroom.BusinessHours
+			if hours := showroom.BusinessHoursText(); hours != "" {
+				data["ShowroomBusinessHours"] = hours
 			}
 			if showroom.Latitude != nil {
 				data["ShowroomLatitude"] = *showroom.Latitude
@@ -1001,3 +1141,73 @@ func (h *PageHandler) getDefaultData(r *http.Request) map[string]interface{} {
 
 	return data
 }
+
+// AdminAPIKeys renders the API key management page for integrations
+func (h *PageHandler) AdminAPIKeys(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "API Key"
+	data["ActiveMenu"] = "api-keys"
+	data["Scopes"] = model.AllAPIScopes
+
+	if err := h.renderAdminPage(w, "templates/admin/api_keys.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminDomains renders the custom domain status page
+func (h *PageHandler) AdminDomains(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Domain"
+	data["ActiveMenu"] = "domains"
+
+	if err := h.renderAdminPage(w, "templates/admin/domains.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminLLM renders the AI provider settings page
+func (h *PageHandler) AdminLLM(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Pengaturan AI"
+	data["ActiveMenu"] = "ai"
+
+	if err := h.renderAdminPage(w, "templates/admin/llm.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminBotPersona renders the bot persona editor
+func (h *PageHandler) AdminBotPersona(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Persona Bot"
+	data["ActiveMenu"] = "bot"
+	data["MaxRules"] = model.MaxBotRules
+
+	if err := h.renderAdminPage(w, "templates/admin/bot_persona.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminFAQ renders the FAQ the bot answers customers from
+func (h *PageHandler) AdminFAQ(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "FAQ Bot"
+	data["ActiveMenu"] = "faq"
+	data["Categories"] = model.FAQCategories
+
+	if err := h.renderAdminPage(w, "templates/admin/faq.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// AdminWebhooks renders the outgoing webhook endpoints and delivery log
+func (h *PageHandler) AdminWebhooks(w http.ResponseWriter, r *http.Request) {
+	data := h.getDefaultData(r)
+	data["Title"] = "Webhook"
+	data["ActiveMenu"] = "webhooks"
+	data["Events"] = model.AllWebhookEvents
+
+	if err := h.renderAdminPage(w, "templates/admin/webhooks.html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+type PlanHandler struct {
+	repo *repository.PlanRepository
+}
+
+func NewPlanHandler(repo *repository.PlanRepository) *PlanHandler {
+	return &PlanHandler{repo: repo}
+}
+
+// List handles GET /api/admin/plans (platform admins) and
+// GET /api/admin/usage/plans (tenant owners comparing plans)
+func (h *PlanHandler) List(w http.ResponseWriter, r *http.Request) {
+	plans, err := h.repo.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list plans", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat daftar paket")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  plans,
+		"count": len(plans),
+	})
+}
+
+// Usage handles GET /api/admin/usage: the tenant's plan with its limits, how
+// much of each is used and which features are included
+func (h *PlanHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	usage, err := h.repo.Usage(r.Context())
+	if err != nil {
+		slog.Error("failed to get plan usage", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat pemakaian paket")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(usage)
+}
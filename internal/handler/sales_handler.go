@@ -65,6 +65,9 @@ func (h *SalesHandler) Create(w http.ResponseWriter, r *http.Request) {
 	// Create sales member
 	sales, err := h.repo.Create(r.Context(), &req)
 	if err != nil {
+		if middleware.PlanError(w, err) {
+			return
+		}
 		slog.Error("failed to create sales", "error", err, "tenant_id", tenantID, "phone", req.PhoneNumber)
 		middleware.InternalServerError(w, "Gagal membuat sales")
 		return
@@ -67,17 +67,27 @@ func (h *ShowroomHandler) UpdateSettings(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if err := req.Validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// A schedule without opening days keeps the availability unknown
+	if !req.Hours.Configured() {
+		req.Hours = nil
+	}
 
 	// Create showroom settings object
 	showroom := &model.ShowroomSettings{
-		TenantID:      tenantID,
-		Address:       req.Address,
-		Phone:         req.Phone,
-		Email:         req.Email,
-		BusinessHours: req.BusinessHours,
-		Latitude:      req.Latitude,
-		Longitude:     req.Longitude,
-		MapEmbed:      req.MapEmbed,
+		TenantID:          tenantID,
+		Address:           req.Address,
+		Phone:             req.Phone,
+		Email:             req.Email,
+		Hours:             req.Hours,
+		AfterHoursMode:    req.AfterHoursMode,
+		AfterHoursMessage: req.AfterHoursMessage,
+		Latitude:          req.Latitude,
+		Longitude:         req.Longitude,
+		MapEmbed:          req.MapEmbed,
 	}
 
 	// Update in database
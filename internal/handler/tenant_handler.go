@@ -2,57 +2,68 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
 	"github.com/riz/auto-lmk/internal/model"
 	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/internal/service"
 )
 
 type TenantHandler struct {
-	repo *repository.TenantRepository
+	service *service.TenantService
 }
 
-func NewTenantHandler(repo *repository.TenantRepository) *TenantHandler {
-	return &TenantHandler{repo: repo}
+func NewTenantHandler(service *service.TenantService) *TenantHandler {
+	return &TenantHandler{service: service}
 }
 
-// Create handles POST /api/admin/tenants
+// Create handles POST /api/admin/tenants. Repeating the request returns the
+// tenant created the first time with 200 instead of 201.
 func (h *TenantHandler) Create(w http.ResponseWriter, r *http.Request) {
 	var req model.CreateTenantRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		slog.Error("failed to decode request", "error", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
 		return
 	}
 
-	tenant, err := h.repo.Create(&req)
+	tenant, created, err := h.service.Create(r.Context(), &req)
 	if err != nil {
+		if errors.Is(err, repository.ErrDomainTaken) {
+			middleware.Conflict(w, "Domain sudah dipakai tenant lain", nil)
+			return
+		}
 		slog.Error("failed to create tenant", "error", err)
-		http.Error(w, "Failed to create tenant", http.StatusInternalServerError)
+		middleware.InternalServerError(w, "Gagal membuat tenant")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
 	json.NewEncoder(w).Encode(tenant)
 }
 
 // Get handles GET /api/admin/tenants/:id
 func (h *TenantHandler) Get(w http.ResponseWriter, r *http.Request) {
-	idStr := chi.URLParam(r, "id")
-	id, err := strconv.Atoi(idStr)
-	if err != nil {
-		http.Error(w, "Invalid tenant ID", http.StatusBadRequest)
+	id, ok := tenantIDParam(w, r)
+	if !ok {
 		return
 	}
 
-	tenant, err := h.repo.GetByID(id)
+	tenant, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		slog.Error("failed to get tenant", "error", err, "id", id)
-		http.Error(w, "Tenant not found", http.StatusNotFound)
+		h.tenantError(w, err, "Gagal memuat tenant", id)
 		return
 	}
 
@@ -62,10 +73,10 @@ func (h *TenantHandler) Get(w http.ResponseWriter, r *http.Request) {
 
 // List handles GET /api/admin/tenants
 func (h *TenantHandler) List(w http.ResponseWriter, r *http.Request) {
-	tenants, err := h.repo.List()
+	tenants, err := h.service.List(r.Context())
 	if err != nil {
 		slog.Error("failed to list tenants", "error", err)
-		http.Error(w, "Failed to list tenants", http.StatusInternalServerError)
+		middleware.InternalServerError(w, "Gagal memuat daftar tenant")
 		return
 	}
 
@@ -75,3 +86,118 @@ func (h *TenantHandler) List(w http.ResponseWriter, r *http.Request) {
 		"count": len(tenants),
 	})
 }
+
+// Update handles PATCH /api/admin/tenants/:id (name, domain, plan and status)
+func (h *TenantHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateTenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	tenant, err := h.service.Update(r.Context(), id, &req)
+	if err != nil {
+		h.tenantError(w, err, "Gagal menyimpan tenant", id)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tenant)
+}
+
+// Suspend handles POST /api/admin/tenants/:id/suspend with an optional reason
+func (h *TenantHandler) Suspend(w http.ResponseWriter, r *http.Request) {
+	id, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			middleware.BadRequest(w, "Format data tidak valid")
+			return
+		}
+	}
+
+	tenant, err := h.service.Suspend(r.Context(), id, strings.TrimSpace(req.Reason))
+	if err != nil {
+		h.tenantError(w, err, "Gagal menangguhkan tenant", id)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tenant)
+}
+
+// Reactivate handles POST /api/admin/tenants/:id/reactivate, which also
+// restores a deleted tenant during its grace period
+func (h *TenantHandler) Reactivate(w http.ResponseWriter, r *http.Request) {
+	id, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	tenant, err := h.service.Reactivate(r.Context(), id)
+	if err != nil {
+		h.tenantError(w, err, "Gagal mengaktifkan tenant", id)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tenant)
+}
+
+// Delete handles DELETE /api/admin/tenants/:id. The tenant is purged once
+// purge_after in the response has passed.
+func (h *TenantHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := tenantIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	tenant, err := h.service.Delete(r.Context(), id)
+	if err != nil {
+		h.tenantError(w, err, "Gagal menghapus tenant", id)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tenant)
+}
+
+func (h *TenantHandler) tenantError(w http.ResponseWriter, err error, message string, id int) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		middleware.NotFound(w, "Tenant tidak ditemukan")
+	case errors.Is(err, repository.ErrDomainTaken):
+		middleware.Conflict(w, "Domain sudah dipakai tenant lain", nil)
+	case errors.Is(err, repository.ErrUnknownPlan):
+		middleware.BadRequest(w, "Paket tidak dikenal")
+	case errors.Is(err, repository.ErrTenantState):
+		middleware.Conflict(w, "Status tenant tidak memungkinkan perubahan ini", nil)
+	default:
+		slog.Error("tenant operation failed", "error", err, "id", id)
+		middleware.InternalServerError(w, message)
+	}
+}
+
+func tenantIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID tenant tidak valid")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+func TestCreateTenantRequest_Validate_NormalizesDomain(t *testing.T) {
+	tests := map[string]string{
+		"showroom.example.com":              "showroom.example.com",
+		"  Showroom.Example.COM ":           "showroom.example.com",
+		"https://showroom.example.com/":     "showroom.example.com",
+		"http://showroom.example.com:8080":  "showroom.example.com",
+		"showroom.example.com.":             "showroom.example.com",
+		"mobil-bekas.co.id":                 "mobil-bekas.co.id",
+		"https://demo.localhost:8080/admin": "demo.localhost",
+	}
+
+	for input, want := range tests {
+		req := model.CreateTenantRequest{Domain: input, Name: "Showroom"}
+		if err := req.Validate(); err != nil {
+			t.Errorf("Validate(%q) returned %v", input, err)
+			continue
+		}
+		if req.Domain != want {
+			t.Errorf("Validate(%q) domain = %q, want %q", input, req.Domain, want)
+		}
+	}
+}
+
+func TestCreateTenantRequest_Validate_InvalidDomain(t *testing.T) {
+	for _, domain := range []string{
+		"",
+		"showroom",
+		"-showroom.example.com",
+		"showroom..example.com",
+		"show room.example.com",
+		"showroom_1.example.com",
+		"localhost",
+		"admin.localhost",
+		"admin.platform.com",
+	} {
+		req := model.CreateTenantRequest{Domain: domain, Name: "Showroom"}
+		if err := req.Validate(); err == nil {
+			t.Errorf("Validate(%q) accepted an invalid domain", domain)
+		}
+	}
+}
+
+func TestCreateTenantRequest_Validate_EmptyName(t *testing.T) {
+	req := model.CreateTenantRequest{Domain: "showroom.example.com", Name: "  "}
+	if err := req.Validate(); err == nil {
+		t.Error("Expected error for empty name")
+	}
+}
@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/internal/service"
+)
+
+type TradeInHandler struct {
+	repo    *repository.TradeInRepository
+	service *service.TradeInService
+}
+
+func NewTradeInHandler(repo *repository.TradeInRepository, tradeInService *service.TradeInService) *TradeInHandler {
+	return &TradeInHandler{
+		repo:    repo,
+		service: tradeInService,
+	}
+}
+
+// Submit handles POST /api/trade-in - public trade-in form
+func (h *TradeInHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	var req model.TradeInRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	tradeIn, estimate, err := h.service.Submit(r.Context(), &req, "website")
+	if err != nil {
+		slog.Error("failed to submit trade-in", "error", err)
+		middleware.InternalServerError(w, "Gagal menghitung estimasi tukar tambah")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":       tradeIn.ID,
+		"estimate": estimate,
+	})
+}
+
+// List handles GET /api/admin/trade-ins
+func (h *TradeInHandler) List(w http.ResponseWriter, r *http.Request) {
+	tradeIns, err := h.repo.List(r.Context(), r.URL.Query().Get("status"))
+	if err != nil {
+		slog.Error("failed to list trade-ins", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat data tukar tambah")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  tradeIns,
+		"count": len(tradeIns),
+	})
+}
+
+// Get handles GET /api/admin/trade-ins/{id}
+func (h *TradeInHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID tukar tambah tidak valid")
+		return
+	}
+
+	tradeIn, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Data tukar tambah tidak ditemukan")
+			return
+		}
+		slog.Error("failed to get trade-in", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal memuat data tukar tambah")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tradeIn)
+}
+
+// Appraise handles PUT /api/admin/trade-ins/{id}/appraisal - sales override / final appraisal
+func (h *TradeInHandler) Appraise(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID tukar tambah tidak valid")
+		return
+	}
+
+	var req model.TradeInAppraisalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.repo.UpdateAppraisal(r.Context(), id, &req); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Data tukar tambah tidak ditemukan")
+			return
+		}
+		slog.Error("failed to update trade-in appraisal", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal menyimpan appraisal")
+		return
+	}
+
+	tradeIn, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		middleware.InternalServerError(w, "Gagal memuat data tukar tambah")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tradeIn)
+}
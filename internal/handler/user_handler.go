@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/internal/service"
+)
+
+type UserHandler struct {
+	repo        *repository.UserRepository
+	authService *service.AuthService
+}
+
+func NewUserHandler(repo *repository.UserRepository, authService *service.AuthService) *UserHandler {
+	return &UserHandler{repo: repo, authService: authService}
+}
+
+// List handles GET /api/admin/users
+func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
+	users, err := h.repo.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list users", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat data user")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":  users,
+		"count": len(users),
+	})
+}
+
+// Create handles POST /api/admin/users
+func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+	if !model.IsTenantRole(req.Role) {
+		middleware.BadRequest(w, "Role harus owner, admin, atau sales")
+		return
+	}
+
+	user, err := h.authService.CreateUser(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			middleware.Forbidden(w, "Anda tidak memiliki akses untuk membuat user")
+			return
+		}
+		if strings.Contains(err.Error(), "already exists") {
+			middleware.Conflict(w, "Email sudah terdaftar", nil)
+			return
+		}
+		slog.Error("failed to create user", "error", err)
+		middleware.InternalServerError(w, "Gagal membuat user")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+// ChangePassword handles PUT /api/admin/users/{id}/password
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID user tidak valid")
+		return
+	}
+
+	var req struct {
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+
+	if err := h.authService.ChangePassword(r.Context(), id, req.Password); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			middleware.Forbidden(w, "Anda tidak memiliki akses untuk mengubah password user ini")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "User tidak ditemukan")
+			return
+		}
+		if strings.Contains(err.Error(), "minimal") {
+			middleware.BadRequest(w, err.Error())
+			return
+		}
+		slog.Error("failed to change password", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal mengubah password")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Delete handles DELETE /api/admin/users/{id}
+func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID user tidak valid")
+		return
+	}
+
+	if currentID, err := model.GetUserID(r.Context()); err == nil && currentID == id {
+		middleware.BadRequest(w, "Tidak dapat menghapus akun sendiri")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, repository.ErrForbidden) {
+			middleware.Forbidden(w, "Anda tidak memiliki akses untuk menghapus user")
+			return
+		}
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "User tidak ditemukan")
+			return
+		}
+		slog.Error("failed to delete user", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal menghapus user")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
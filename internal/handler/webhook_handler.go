@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/middleware"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/service"
+)
+
+type WebhookHandler struct {
+	service *service.WebhookService
+}
+
+func NewWebhookHandler(service *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// List handles GET /api/admin/webhooks
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := h.service.ListEndpoints(r.Context())
+	if err != nil {
+		slog.Error("failed to list webhook endpoints", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":   endpoints,
+		"count":  len(endpoints),
+		"events": model.AllWebhookEvents,
+	})
+}
+
+// Create handles POST /api/admin/webhooks. The signing secret is only returned here.
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.WebhookEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	endpoint, secret, err := h.service.CreateEndpoint(r.Context(), &req)
+	if err != nil {
+		slog.Error("failed to create webhook endpoint", "error", err)
+		middleware.InternalServerError(w, "Gagal membuat webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"webhook": endpoint,
+		"secret":  secret,
+	})
+}
+
+// Update handles PUT /api/admin/webhooks/{id}
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	var req model.WebhookEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		middleware.BadRequest(w, "Format data tidak valid")
+		return
+	}
+	if err := req.Validate(); err != nil {
+		middleware.BadRequest(w, err.Error())
+		return
+	}
+
+	if err := h.service.UpdateEndpoint(r.Context(), id, &req); err != nil {
+		h.endpointError(w, err, "Gagal menyimpan webhook", id)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Webhook berhasil disimpan"})
+}
+
+// Delete handles DELETE /api/admin/webhooks/{id}
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteEndpoint(r.Context(), id); err != nil {
+		h.endpointError(w, err, "Gagal menghapus webhook", id)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RotateSecret handles POST /api/admin/webhooks/{id}/rotate-secret
+func (h *WebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	secret, err := h.service.RotateSecret(r.Context(), id)
+	if err != nil {
+		h.endpointError(w, err, "Gagal mengganti secret", id)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"secret": secret})
+}
+
+// Test handles POST /api/admin/webhooks/{id}/test by queueing a ping event
+func (h *WebhookHandler) Test(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.SendTest(r.Context(), id); err != nil {
+		if strings.Contains(err.Error(), "not active") {
+			middleware.BadRequest(w, "Aktifkan webhook sebelum mengirim tes")
+			return
+		}
+		h.endpointError(w, err, "Gagal mengirim tes webhook", id)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Event ping masuk antrean pengiriman"})
+}
+
+// Deliveries handles GET /api/admin/webhooks/deliveries with optional
+// endpoint_id, status, event, cursor and limit query parameters
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &model.WebhookDeliveryFilter{
+		Status:    query.Get("status"),
+		EventType: query.Get("event"),
+	}
+	if v := query.Get("endpoint_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.BadRequest(w, "ID webhook tidak valid")
+			return
+		}
+		filter.EndpointID = id
+	}
+	if v := query.Get("cursor"); v != "" {
+		cursor, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.BadRequest(w, "Cursor tidak valid")
+			return
+		}
+		filter.Page.Cursor = cursor
+	}
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			middleware.BadRequest(w, "Limit tidak valid")
+			return
+		}
+		filter.Page.Limit = limit
+	}
+
+	deliveries, next, err := h.service.ListDeliveries(r.Context(), filter)
+	if err != nil {
+		slog.Error("failed to list webhook deliveries", "error", err)
+		middleware.InternalServerError(w, "Gagal memuat log pengiriman")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data":        deliveries,
+		"count":       len(deliveries),
+		"next_cursor": next,
+	})
+}
+
+// Redeliver handles POST /api/admin/webhooks/deliveries/{id}/redeliver
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		middleware.BadRequest(w, "ID pengiriman tidak valid")
+		return
+	}
+
+	delivery, err := h.service.Redeliver(r.Context(), id)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			middleware.NotFound(w, "Pengiriman tidak ditemukan")
+			return
+		}
+		slog.Error("failed to redeliver webhook", "error", err, "id", id)
+		middleware.InternalServerError(w, "Gagal mengirim ulang webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
+
+func (h *WebhookHandler) endpointError(w http.ResponseWriter, err error, message string, id int) {
+	if strings.Contains(err.Error(), "not found") {
+		middleware.NotFound(w, "Webhook tidak ditemukan")
+		return
+	}
+	slog.Error("webhook endpoint request failed", "error", err, "id", id)
+	middleware.InternalServerError(w, message)
+}
+
+func webhookID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		middleware.BadRequest(w, "ID webhook tidak valid")
+		return 0, false
+	}
+	return id, true
+}
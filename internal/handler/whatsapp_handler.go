@@ -56,7 +56,7 @@ func (h *WhatsAppHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get tenant info
-	tenant, err := h.tenantRepo.GetByID(tenantID)
+	tenant, err := h.tenantRepo.GetByID(r.Context(), tenantID)
 	if err != nil {
 		http.Error(w, "Tenant not found", http.StatusNotFound)
 		return
@@ -74,7 +74,7 @@ func (h *WhatsAppHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 
 			// Save to database if not already saved
 			if tenant.WhatsAppNumber == nil || *tenant.WhatsAppNumber != detectedPhone {
-				if err := h.tenantRepo.UpdateWhatsAppNumber(tenantID, detectedPhone); err != nil {
+				if err := h.tenantRepo.UpdateWhatsAppNumber(r.Context(), tenantID, detectedPhone); err != nil {
 					slog.Error("failed to update WhatsApp number", "error", err, "tenant_id", tenantID, "phone", detectedPhone)
 				} else {
 					slog.Info("WhatsApp number saved to database", "tenant_id", tenantID, "phone", detectedPhone)
@@ -83,7 +83,7 @@ func (h *WhatsAppHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
 
 			// Update pairing status to "paired" if still "pairing_pending"
 			if tenant.PairingStatus != "paired" {
-				if err := h.tenantRepo.UpdatePairingStatus(tenantID, "paired"); err != nil {
+				if err := h.tenantRepo.UpdatePairingStatus(r.Context(), tenantID, "paired"); err != nil {
 					slog.Error("failed to update pairing status", "error", err, "tenant_id", tenantID)
 				}
 				tenant.PairingStatus = "paired"
@@ -155,7 +155,7 @@ func (h *WhatsAppHandler) InitiatePairing(w http.ResponseWriter, r *http.Request
 		// Save WhatsApp number to database
 		if result.PhoneNumber != "" {
 			slog.Info("[HANDLER-DB] Saving WhatsApp number", "tenant_id", tenantID, "phone", result.PhoneNumber)
-			if err := h.tenantRepo.UpdateWhatsAppNumber(tenantID, result.PhoneNumber); err != nil {
+			if err := h.tenantRepo.UpdateWhatsAppNumber(r.Context(), tenantID, result.PhoneNumber); err != nil {
 				slog.Error("[HANDLER-ERROR] Failed to update WhatsApp number", "error", err, "tenant_id", tenantID, "phone", result.PhoneNumber)
 				// Continue anyway - don't fail the request if DB update fails
 			} else {
@@ -167,7 +167,7 @@ func (h *WhatsAppHandler) InitiatePairing(w http.ResponseWriter, r *http.Request
 	}
 
 	slog.Info("[HANDLER-DB] Updating pairing status", "tenant_id", tenantID, "status", pairingStatus)
-	if err := h.tenantRepo.UpdatePairingStatus(tenantID, pairingStatus); err != nil {
+	if err := h.tenantRepo.UpdatePairingStatus(r.Context(), tenantID, pairingStatus); err != nil {
 		slog.Error("[HANDLER-ERROR] Failed to update pairing status", "error", err, "tenant_id", tenantID, "status", pairingStatus)
 		// Continue anyway - don't fail the request if DB update fails
 	} else {
@@ -215,7 +215,7 @@ func (h *WhatsAppHandler) Disconnect(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update tenant pairing status in database to "disconnected"
-	if err := h.tenantRepo.UpdatePairingStatus(tenantID, "disconnected"); err != nil {
+	if err := h.tenantRepo.UpdatePairingStatus(r.Context(), tenantID, "disconnected"); err != nil {
 		slog.Error("failed to update pairing status after disconnect", "error", err, "tenant_id", tenantID)
 		// Continue anyway - don't fail the request if DB update fails
 	} else {
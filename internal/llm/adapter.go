@@ -76,3 +76,19 @@ func (a *CarRepoAdapter) GetCarPhotos(ctx context.Context, carID int) (interface
 func (a *CarRepoAdapter) CreateWithPhotos(ctx context.Context, car *model.Car, photoURLs []string) (int, error) {
 	return a.car.CreateWithPhotos(ctx, car, photoURLs)
 }
+
+func (a *CarRepoAdapter) GetCar(ctx context.Context, carID int) (*model.Car, error) {
+	return a.car.GetByID(ctx, carID)
+}
+
+func (a *CarRepoAdapter) ListCars(ctx context.Context, filters map[string]interface{}) ([]*model.Car, error) {
+	return a.car.List(ctx, filters)
+}
+
+func (a *CarRepoAdapter) UpdateCar(ctx context.Context, carID int, updates map[string]interface{}) error {
+	return a.car.Update(ctx, carID, updates)
+}
+
+func (a *CarRepoAdapter) AddPhotos(ctx context.Context, carID int, photoURLs []string) error {
+	return a.car.AddPhotos(ctx, carID, photoURLs)
+}
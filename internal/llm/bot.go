@@ -4,18 +4,57 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/riz/auto-lmk/internal/model"
 )
 
-// Bot handles car sales conversations
+// Bot handles car sales conversations. One Bot serves every tenant's WhatsApp
+// client concurrently: the sender travels in ctx, and everything queued for a
+// sender is keyed by tenant and phone.
 type Bot struct {
 	provider         Provider
 	convRepo         ConversationRepository
 	carRepo          CarRepository
-	pendingImages    map[string][]string // senderPhone -> image paths
-	pendingCarID     map[string]int      // senderPhone -> car_id for image context
-	currentSender    string              // temporary storage for current sender during processing
+	creditSimulator  CreditSimulator
+	tradeInEstimator TradeInEstimator
+	customerProfiles CustomerProfiles
+	salesLeads       SalesLeads
+	personas         Personas
+	showrooms        ShowroomDirectory
+	faqs             FAQSearcher
+	mu               sync.Mutex                    // guards the pending maps
+	pendingImages    map[pendingKey][]string       // image paths to send or upload
+	pendingLocations map[pendingKey]*Location      // showroom pin to send
+	pendingCarID     map[pendingKey]int            // car_id for image context
+	pendingActions   map[pendingKey]*pendingAction // sales change awaiting confirmation
+	now              func() time.Time
+}
+
+// pendingKey identifies a sender of one tenant, the same phone may chat with several tenants
+type pendingKey struct {
+	tenantID int
+	phone    string
+}
+
+// sender is who the message being processed came from
+type sender struct {
+	pendingKey
+	isSales bool
+}
+
+type senderContextKey struct{}
+
+// withSender stores the message's sender for the functions the LLM calls
+func withSender(ctx context.Context, s sender) context.Context {
+	return context.WithValue(ctx, senderContextKey{}, s)
+}
+
+// senderFrom returns the message's sender, a customer without phone if none is set
+func senderFrom(ctx context.Context) sender {
+	s, _ := ctx.Value(senderContextKey{}).(sender)
+	return s
 }
 
 // Import internal model types for simpler interfaces
@@ -41,25 +80,92 @@ type CarRepository interface {
 	GetCarWithDetails(ctx context.Context, carID int) (interface{}, error)
 	GetCarPhotos(ctx context.Context, carID int) (interface{}, error)
 	CreateWithPhotos(ctx context.Context, car *model.Car, photoURLs []string) (int, error)
+	GetCar(ctx context.Context, carID int) (*model.Car, error)
+	ListCars(ctx context.Context, filters map[string]interface{}) ([]*model.Car, error)
+	UpdateCar(ctx context.Context, carID int, updates map[string]interface{}) error
+	AddPhotos(ctx context.Context, carID int, photoURLs []string) error
+}
+
+// CreditSimulator interface for car loan (kredit) simulations
+type CreditSimulator interface {
+	SimulateCredit(ctx context.Context, req *model.CreditSimulationRequest) (*model.CreditSimulation, error)
+}
+
+// TradeInEstimator interface for trade-in (tukar tambah) valuations
+type TradeInEstimator interface {
+	Submit(ctx context.Context, req *model.TradeInRequest, source string) (*model.TradeIn, *model.TradeInEstimate, error)
+}
+
+// CustomerProfiles interface for storing preferences the bot learns from chats
+type CustomerProfiles interface {
+	MergePreferences(ctx context.Context, phone string, prefs *model.CustomerPreferences) (*model.Customer, error)
+}
+
+// SalesLeads interface for listing leads handled by a sales person
+type SalesLeads interface {
+	ListForSales(ctx context.Context, salesPhone string, includeUnassigned bool) ([]*model.Lead, error)
 }
 
 // NewBot creates a new conversation bot
 func NewBot(provider Provider, convRepo ConversationRepository, carRepo CarRepository) *Bot {
 	return &Bot{
-		provider:      provider,
-		convRepo:      convRepo,
-		carRepo:       carRepo,
-		pendingImages: make(map[string][]string),
-		pendingCarID:  make(map[string]int),
+		provider:         provider,
+		convRepo:         convRepo,
+		carRepo:          carRepo,
+		pendingImages:    make(map[pendingKey][]string),
+		pendingLocations: make(map[pendingKey]*Location),
+		pendingCarID:     make(map[pendingKey]int),
+		pendingActions:   make(map[pendingKey]*pendingAction),
+		now:              time.Now,
 	}
 }
 
+// SetCreditSimulator enables the simulateCredit function
+func (b *Bot) SetCreditSimulator(simulator CreditSimulator) {
+	b.creditSimulator = simulator
+}
+
+// SetTradeInEstimator enables the estimateTradeIn function
+func (b *Bot) SetTradeInEstimator(estimator TradeInEstimator) {
+	b.tradeInEstimator = estimator
+}
+
+// SetCustomerProfiles enables the updateCustomerProfile function
+func (b *Bot) SetCustomerProfiles(profiles CustomerProfiles) {
+	b.customerProfiles = profiles
+}
+
+// SetSalesLeads enables the listMyLeads function
+func (b *Bot) SetSalesLeads(leads SalesLeads) {
+	b.salesLeads = leads
+}
+
+// SetPersonas gives each tenant's bot its own name, tone, greeting and rules
+func (b *Bot) SetPersonas(personas Personas) {
+	b.personas = personas
+}
+
+// SetShowrooms enables the getShowroomInfo function
+func (b *Bot) SetShowrooms(showrooms ShowroomDirectory) {
+	b.showrooms = showrooms
+}
+
+// SetFAQs lets searchFAQ answer from the showroom's FAQ
+func (b *Bot) SetFAQs(faqs FAQSearcher) {
+	b.faqs = faqs
+}
+
 // ProcessMessage processes incoming message and returns bot response
 func (b *Bot) ProcessMessage(ctx context.Context, tenantID int, senderPhone, messageText string, isSales bool) (string, error) {
 	slog.Info("processing message", "tenant_id", tenantID, "sender", senderPhone, "is_sales", isSales)
 
-	// Store current sender for function execution context
-	b.currentSender = senderPhone
+	// Functions called by the LLM act for this sender
+	ctx = withSender(ctx, sender{pendingKey: pendingKey{tenantID: tenantID, phone: senderPhone}, isSales: isSales})
+
+	// Inventory changes made through the bot are audited as this sales person
+	if isSales {
+		ctx = model.WithAuditActor(ctx, model.AuditActorSales, senderPhone)
+	}
 
 	// 1. Get conversation to access history
 	conv, err := b.convRepo.GetOrCreate(ctx, senderPhone, isSales)
@@ -68,32 +174,57 @@ func (b *Bot) ProcessMessage(ctx context.Context, tenantID int, senderPhone, mes
 		// Continue without history
 	}
 
-	// 2. Build messages for LLM
+	// 2. Build messages for LLM, the system prompt following the tenant's persona
+	var persona *model.BotPersona
+	if b.personas != nil {
+		if persona, err = b.personas.Active(ctx); err != nil {
+			slog.Error("failed to load bot persona", "error", err)
+		}
+	}
 	messages := []Message{
 		{
 			Role:    "system",
-			Content: b.buildSystemPrompt(isSales),
+			Content: SystemPrompt(persona, isSales),
 		},
 	}
 
-	// 3. Load recent conversation history (last 10 messages)
+	// Customers are told whether a sales person can take over right now
+	if !isSales && b.showrooms != nil {
+		if showroom, err := b.showrooms.GetByTenantID(ctx, tenantID); err != nil {
+			slog.Error("failed to load showroom settings", "error", err)
+		} else {
+			messages[0].Content += availabilityNote(showroom, b.now())
+		}
+	}
+
+	// 3. Load recent conversation history (last 10 messages). A customer the
+	// bot never answered yet gets the persona's greeting.
+	greeting := ""
 	if conv != nil {
 		history, err := b.convRepo.GetMessages(ctx, conv.ID, 10)
 		if err != nil {
 			slog.Error("failed to load history", "error", err)
 		} else {
+			answered := false
 			for _, msg := range history {
 				role := "user"
 				if msg.Direction == "outbound" {
 					role = "assistant"
+					answered = true
 				}
 				messages = append(messages, Message{
 					Role:    role,
 					Content: msg.MessageText,
 				})
 			}
+			if !answered && !isSales && persona != nil {
+				greeting = persona.GreetingText()
+			}
 		}
 	}
+	if greeting != "" {
+		messages[0].Content += greetingSentNote
+	}
 
 	// 4. Add current user message
 	messages = append(messages, Message{
@@ -138,7 +269,7 @@ func (b *Bot) ProcessMessage(ctx context.Context, tenantID int, senderPhone, mes
 		}
 	}
 
-	return response.Content, nil
+	return withGreeting(greeting, response.Content), nil
 }
 
 // executeFunction executes a function called by the LLM
@@ -172,8 +303,11 @@ func (b *Bot) executeFunction(ctx context.Context, functionName string, argument
 			for i, photo := range photoList {
 				imagePaths[i] = photo.FilePath
 			}
-			b.pendingImages[b.currentSender] = imagePaths
-			b.pendingCarID[b.currentSender] = int(carID)
+			key := senderFrom(ctx).pendingKey
+			b.mu.Lock()
+			b.pendingImages[key] = imagePaths
+			b.pendingCarID[key] = int(carID)
+			b.mu.Unlock()
 
 			return map[string]interface{}{
 				"status":      "images_queued",
@@ -190,6 +324,25 @@ func (b *Bot) executeFunction(ctx context.Context, functionName string, argument
 	case "uploadCar":
 		return b.executeUploadCar(ctx, arguments)
 
+	case "simulateCredit":
+		return b.executeSimulateCredit(ctx, arguments)
+
+	case "estimateTradeIn":
+		return b.executeEstimateTradeIn(ctx, arguments)
+
+	case "updateCustomerProfile":
+		return b.executeUpdateCustomerProfile(ctx, arguments)
+
+	case "getShowroomInfo":
+		return b.executeGetShowroomInfo(ctx, arguments)
+
+	case "searchFAQ":
+		return b.executeSearchFAQ(ctx, arguments)
+
+	case "updateCarPrice", "markCarSold", "markCarReserved", "confirmAction",
+		"listMyLeads", "listStock", "addCarPhotos":
+		return b.executeSalesCommand(ctx, functionName, arguments)
+
 	default:
 		return nil, fmt.Errorf("unknown function: %s", functionName)
 	}
@@ -231,7 +384,8 @@ func (b *Bot) executeUploadCar(ctx context.Context, arguments map[string]interfa
 	}
 
 	// Check for uploaded photos
-	photoURLs := b.GetPendingPhotos(b.currentSender)
+	from := senderFrom(ctx)
+	photoURLs := b.GetPendingPhotos(from.tenantID, from.phone)
 	if len(photoURLs) == 0 {
 		return map[string]interface{}{
 			"success": false,
@@ -262,7 +416,7 @@ func (b *Bot) executeUploadCar(ctx context.Context, arguments map[string]interfa
 	}
 
 	// Clear pending photos after successful upload
-	b.ClearPendingPhotos(b.currentSender)
+	b.ClearPendingPhotos(from.tenantID, from.phone)
 
 	// Get tenant ID for catalog URL
 	tenantID, _ := model.GetTenantID(ctx)
@@ -283,44 +437,126 @@ func (b *Bot) executeUploadCar(ctx context.Context, arguments map[string]interfa
 	}, nil
 }
 
-// buildSystemPrompt creates system prompt based on user type
-func (b *Bot) buildSystemPrompt(isSales bool) string {
-	basePrompt := `Anda adalah asisten penjualan mobil yang ramah dan profesional di Auto LMK.`
+// executeSimulateCredit handles credit simulation function call from LLM
+func (b *Bot) executeSimulateCredit(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+	if b.creditSimulator == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Simulasi kredit belum tersedia. Silakan hubungi sales kami.",
+		}, nil
+	}
 
-	if isSales {
-		return basePrompt + `
+	carID, _ := arguments["car_id"].(float64)
+	dpPercent, _ := arguments["dp_percent"].(float64)
+	dpAmount, _ := arguments["dp_amount"].(float64)
+	tenor, _ := arguments["tenor_months"].(float64)
 
-Anda sedang membantu SALES TEAM. Anda memiliki kemampuan tambahan:
-- Upload mobil baru ke catalog
-- Lihat dan kelola inventory
+	if carID == 0 {
+		return nil, fmt.Errorf("invalid car_id")
+	}
 
-Saat sales ingin upload mobil, minta mereka:
-1. Upload foto mobil (bisa multiple, maksimal 5 foto)
-2. Ketik detail: "Brand Model Tahun Harga Transmisi BahanBakar"
-   Contoh: "Toyota Avanza 2020 185juta AT Bensin"
+	sim, err := b.creditSimulator.SimulateCredit(ctx, &model.CreditSimulationRequest{
+		CarID:       int(carID),
+		DPPercent:   dpPercent,
+		DPAmount:    int64(dpAmount),
+		TenorMonths: int(tenor),
+	})
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		}, nil
+	}
 
-Cara upload:
-- Sales upload foto dulu
-- Setelah foto diterima, sales ketik detail mobil
-- Anda akan parse detail dan konfirmasi sebelum menyimpan
-- Format harga: "185juta" atau "185jt" → 185000000
-- Transmisi: "matic"/"AT" → AT, "manual"/"MT" → MT
-- Bahan bakar: "bensin" → Bensin, "diesel" → Diesel
+	return sim, nil
+}
 
-Gunakan bahasa Indonesia yang profesional dan efisien.`
+// executeEstimateTradeIn estimates the customer's car value and records it as a trade-in lead
+func (b *Bot) executeEstimateTradeIn(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+	if b.tradeInEstimator == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Estimasi tukar tambah belum tersedia. Silakan hubungi sales kami.",
+		}, nil
 	}
 
-	return basePrompt + `
+	brand, _ := arguments["brand"].(string)
+	carModel, _ := arguments["model"].(string)
+	year, _ := arguments["year"].(float64)
 
-Anda membantu CUSTOMER mencari mobil. Anda dapat:
-- Mencari mobil berdasarkan brand, budget, transmisi, dll
-- Menampilkan detail dan foto mobil
-- Memberikan rekomendasi
+	from := senderFrom(ctx)
+	req := &model.TradeInRequest{
+		PhoneNumber: from.phone,
+		Brand:       brand,
+		Model:       carModel,
+		Year:        int(year),
+	}
+	if mileage, ok := arguments["mileage"].(float64); ok && mileage > 0 {
+		km := int(mileage)
+		req.Mileage = &km
+	}
+	if transmission, ok := arguments["transmission"].(string); ok && transmission != "" {
+		req.Transmission = &transmission
+	}
+	if name, ok := arguments["customer_name"].(string); ok && name != "" {
+		req.Name = &name
+	}
 
-Jika customer tanya tentang upload atau tambah mobil, jelaskan bahwa fitur itu untuk sales team.
+	tradeIn, estimate, err := b.tradeInEstimator.Submit(ctx, req, "whatsapp")
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+		}, nil
+	}
+
+	slog.Info("trade-in estimate recorded", "trade_in_id", tradeIn.ID, "sender", from.phone)
 
-Gunakan bahasa Indonesia yang ramah, natural, dan helpful.
-Pahami istilah automotive Indonesia seperti: matic (automatic), bensin (gasoline), OTR (On The Road price).`
+	return map[string]interface{}{
+		"success":          true,
+		"estimate_low":     estimate.EstimateLow,
+		"estimate_high":    estimate.EstimateHigh,
+		"comparable_count": estimate.ComparableCount,
+		"note":             estimate.Note,
+	}, nil
+}
+
+// executeUpdateCustomerProfile stores preferences the customer mentioned in the chat
+func (b *Bot) executeUpdateCustomerProfile(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+	if b.customerProfiles == nil {
+		return map[string]interface{}{"success": true}, nil
+	}
+
+	prefs := &model.CustomerPreferences{}
+	prefs.Name, _ = arguments["name"].(string)
+	if v, ok := arguments["budget_min"].(float64); ok {
+		prefs.BudgetMin = int64(v)
+	}
+	if v, ok := arguments["budget_max"].(float64); ok {
+		prefs.BudgetMax = int64(v)
+	}
+	prefs.BodyTypes = stringList(arguments["body_types"])
+	prefs.Brands = stringList(arguments["brands"])
+
+	phone := senderFrom(ctx).phone
+	if _, err := b.customerProfiles.MergePreferences(ctx, phone, prefs); err != nil {
+		slog.Error("failed to update customer profile", "error", err, "sender", phone)
+		return map[string]interface{}{"success": false}, nil
+	}
+
+	return map[string]interface{}{"success": true}, nil
+}
+
+// stringList converts a JSON array argument to a string slice
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
 }
 
 // GetAvailableFunctions returns functions available to LLM
@@ -385,7 +621,100 @@ func (b *Bot) GetAvailableFunctions(isSales bool) []Function {
 				"required": []string{"car_id"},
 			},
 		},
+		{
+			Name:        "simulateCredit",
+			Description: "Simulasi kredit mobil: hitung DP, cicilan per bulan, dan total DP dari leasing partner",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"car_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "ID mobil yang akan dikredit",
+					},
+					"dp_percent": map[string]interface{}{
+						"type":        "number",
+						"description": "DP dalam persen dari harga (contoh: 20, 30)",
+					},
+					"dp_amount": map[string]interface{}{
+						"type":        "integer",
+						"description": "DP dalam Rupiah, jika customer menyebut nominal (contoh: 50juta → 50000000)",
+					},
+					"tenor_months": map[string]interface{}{
+						"type":        "integer",
+						"description": "Tenor dalam bulan (contoh: 12, 24, 36, 48, 60). Default 36",
+					},
+				},
+				"required": []string{"car_id"},
+			},
+		},
+		{
+			Name:        "estimateTradeIn",
+			Description: "Estimasi kisaran harga tukar tambah (trade-in) mobil lama customer dan catat sebagai lead",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"brand": map[string]interface{}{
+						"type":        "string",
+						"description": "Merek mobil lama (Toyota, Honda, dll)",
+					},
+					"model": map[string]interface{}{
+						"type":        "string",
+						"description": "Model mobil lama (Avanza, Jazz, dll)",
+					},
+					"year": map[string]interface{}{
+						"type":        "integer",
+						"description": "Tahun mobil lama",
+					},
+					"mileage": map[string]interface{}{
+						"type":        "integer",
+						"description": "Kilometer saat ini (contoh: 60rb km → 60000)",
+					},
+					"transmission": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"manual", "automatic"},
+						"description": "Jenis transmisi",
+					},
+					"customer_name": map[string]interface{}{
+						"type":        "string",
+						"description": "Nama customer jika sudah disebutkan",
+					},
+				},
+				"required": []string{"brand", "model", "year"},
+			},
+		},
+		{
+			Name:        "updateCustomerProfile",
+			Description: "Simpan preferensi customer yang disebut dalam chat (nama, budget, jenis bodi, merek)",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name": map[string]interface{}{
+						"type":        "string",
+						"description": "Nama customer",
+					},
+					"budget_min": map[string]interface{}{
+						"type":        "integer",
+						"description": "Budget minimal dalam Rupiah",
+					},
+					"budget_max": map[string]interface{}{
+						"type":        "integer",
+						"description": "Budget maksimal dalam Rupiah (contoh: 200juta → 200000000)",
+					},
+					"body_types": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Jenis bodi yang diminati (MPV, SUV, Sedan, Hatchback, Pickup)",
+					},
+					"brands": map[string]interface{}{
+						"type":        "array",
+						"items":       map[string]interface{}{"type": "string"},
+						"description": "Merek yang diminati",
+					},
+				},
+			},
+		},
 	}
+	baseFunctions = append(baseFunctions, showroomFunctions()...)
 
 	// Add uploadCar function for sales only
 	if isSales {
@@ -429,37 +758,47 @@ func (b *Bot) GetAvailableFunctions(isSales bool) []Function {
 				"required": []string{"brand", "model", "year", "price", "transmission", "fuel_type"},
 			},
 		})
+		baseFunctions = append(baseFunctions, salesFunctions()...)
 	}
 
 	return baseFunctions
 }
 
 // GetPendingImages returns images queued for sending to a sender
-func (b *Bot) GetPendingImages(senderPhone string) []string {
-	return b.pendingImages[senderPhone]
+func (b *Bot) GetPendingImages(tenantID int, senderPhone string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pendingImages[pendingKey{tenantID, senderPhone}]
 }
 
 // ClearPendingImages clears pending images for a sender
-func (b *Bot) ClearPendingImages(senderPhone string) {
-	delete(b.pendingImages, senderPhone)
-	delete(b.pendingCarID, senderPhone)
+func (b *Bot) ClearPendingImages(tenantID int, senderPhone string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := pendingKey{tenantID, senderPhone}
+	delete(b.pendingImages, key)
+	delete(b.pendingCarID, key)
 }
 
 // AddPendingPhoto adds a photo to pending uploads for a sender
-func (b *Bot) AddPendingPhoto(senderPhone, photoPath string) int {
-	if b.pendingImages[senderPhone] == nil {
-		b.pendingImages[senderPhone] = []string{}
-	}
-	b.pendingImages[senderPhone] = append(b.pendingImages[senderPhone], photoPath)
-	return len(b.pendingImages[senderPhone])
+func (b *Bot) AddPendingPhoto(tenantID int, senderPhone, photoPath string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := pendingKey{tenantID, senderPhone}
+	b.pendingImages[key] = append(b.pendingImages[key], photoPath)
+	return len(b.pendingImages[key])
 }
 
 // GetPendingPhotos returns pending photo uploads for a sender
-func (b *Bot) GetPendingPhotos(senderPhone string) []string {
-	return b.pendingImages[senderPhone]
+func (b *Bot) GetPendingPhotos(tenantID int, senderPhone string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pendingImages[pendingKey{tenantID, senderPhone}]
 }
 
 // ClearPendingPhotos clears pending photo uploads for a sender
-func (b *Bot) ClearPendingPhotos(senderPhone string) {
-	delete(b.pendingImages, senderPhone)
+func (b *Bot) ClearPendingPhotos(tenantID int, senderPhone string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.pendingImages, pendingKey{tenantID, senderPhone})
 }
@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// markSoldProvider asks to mark car 1 sold, then echoes the function result
+type markSoldProvider struct{}
+
+func (markSoldProvider) Chat(ctx context.Context, messages []Message, functions []Function) (*Response, error) {
+	if last := messages[len(messages)-1]; last.Role == "function" {
+		return &Response{Content: last.Content}, nil
+	}
+	return &Response{FunctionCall: &FunctionCall{Name: "markCarSold", Arguments: map[string]interface{}{"car_id": float64(1)}}}, nil
+}
+
+// availableCars serves car 1 as available for any tenant
+type availableCars struct{ CarRepository }
+
+func (availableCars) GetCar(ctx context.Context, carID int) (*model.Car, error) {
+	return &model.Car{ID: carID, Brand: "Toyota", Model: "Avanza", Year: 2020, Status: "available"}, nil
+}
+
+// TestConcurrentTenants runs a sales person of one tenant and a customer of
+// another, chatting from the same phone, through the shared bot at once. Run
+// with -race.
+func TestConcurrentTenants(t *testing.T) {
+	bot := NewBot(markSoldProvider{}, &fakeConversations{}, availableCars{})
+	const phone, rounds = "628123", 50
+
+	var wg sync.WaitGroup
+	for _, tenant := range []struct {
+		id      int
+		isSales bool
+	}{{1, true}, {2, false}} {
+		tenant := tenant
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := model.WithTenantID(context.Background(), tenant.id)
+			for i := 0; i < rounds; i++ {
+				reply, err := bot.ProcessMessage(ctx, tenant.id, phone, "Avanza laku", tenant.isSales)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if staged := strings.Contains(reply, "needs_confirmation:true"); staged != tenant.isSales {
+					t.Errorf("tenant %d: reply = %q", tenant.id, reply)
+					return
+				}
+				bot.AddPendingPhoto(tenant.id, phone, "/static/uploads/cars/1.jpg")
+				bot.ClearPendingPhotos(tenant.id, phone)
+			}
+		}()
+	}
+	wg.Wait()
+
+	bot.mu.Lock()
+	defer bot.mu.Unlock()
+	if bot.pendingActions[pendingKey{1, phone}] == nil {
+		t.Error("sales change not staged")
+	}
+	if bot.pendingActions[pendingKey{2, phone}] != nil {
+		t.Error("customer staged a sales change")
+	}
+}
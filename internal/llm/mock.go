@@ -0,0 +1,32 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+)
+
+// MockProvider answers without calling an LLM, for previews and for running
+// the bot in development without an API key (LLM_PROVIDER=mock). It never
+// calls functions.
+type MockProvider struct{}
+
+func NewMockProvider() *MockProvider {
+	return &MockProvider{}
+}
+
+func (p *MockProvider) Chat(ctx context.Context, messages []Message, functions []Function) (*Response, error) {
+	var system, last string
+	for _, msg := range messages {
+		switch msg.Role {
+		case "system":
+			system += msg.Content
+		case "user":
+			last = msg.Content
+		}
+	}
+
+	return &Response{
+		Content: fmt.Sprintf("[Simulasi] Jawaban AI untuk \"%s\" muncul di sini, mengikuti instruksi sistem (%d karakter).",
+			last, len([]rune(system))),
+	}, nil
+}
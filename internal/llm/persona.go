@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// Personas loads the bot persona of the tenant in the context
+type Personas interface {
+	Active(ctx context.Context) (*model.BotPersona, error)
+}
+
+// toneInstructions describe each tone to the LLM
+var toneInstructions = map[string]string{
+	model.BotToneFriendly: "Gunakan bahasa Indonesia yang ramah, natural, dan helpful.",
+	model.BotToneFormal:   "Gunakan bahasa Indonesia yang sopan dan formal. Sapa customer dengan Bapak/Ibu dan hindari bahasa gaul.",
+	model.BotToneCasual:   "Gunakan bahasa Indonesia yang santai dan akrab seperti teman ngobrol (boleh pakai kak, ya, nih), tetap sopan.",
+}
+
+// greetingSentNote keeps the LLM from greeting again after the persona's
+// greeting opened the reply
+const greetingSentNote = "\n\nIni pesan pertama customer. Sapaan pembuka sudah dikirim di awal balasan Anda, jadi jangan menyapa lagi."
+
+// SystemPrompt builds the system prompt for a tenant's persona; a nil persona
+// gives the default one. The showroom's rules, tone and instructions apply to
+// customer chats; sales staff get the same identity with their tools.
+func SystemPrompt(persona *model.BotPersona, isSales bool) string {
+	if persona == nil {
+		persona = &model.BotPersona{}
+	}
+
+	identity := "asisten penjualan mobil"
+	if persona.Name != "" {
+		identity = persona.Name + ", asisten penjualan mobil"
+	}
+	showroom := persona.ShowroomName
+	if showroom == "" {
+		showroom = "showroom ini"
+	}
+	basePrompt := fmt.Sprintf("Anda adalah %s yang ramah dan profesional di %s.", identity, showroom)
+
+	if isSales {
+		return basePrompt + `
+
+Anda sedang membantu SALES TEAM. Anda memiliki kemampuan tambahan:
+- Upload mobil baru ke catalog
+- Lihat stok dengan listStock (filter brand, status, harga maksimal, transmisi)
+- Ubah harga (updateCarPrice), tandai terjual (markCarSold) atau booking (markCarReserved)
+- Tambah foto ke mobil yang sudah ada (addCarPhotos) setelah sales mengirim fotonya
+- Lihat lead yang sedang ditangani dengan listMyLeads
+
+updateCarPrice, markCarSold, dan markCarReserved TIDAK langsung menyimpan perubahan. Tampilkan ringkasan
+perubahan ke sales dan minta konfirmasi "ya" atau "tidak", lalu panggil confirmAction sesuai jawabannya.
+
+Saat sales ingin upload mobil, minta mereka:
+1. Upload foto mobil (bisa multiple, maksimal 5 foto)
+2. Ketik detail: "Brand Model Tahun Harga Transmisi BahanBakar"
+   Contoh: "Toyota Avanza 2020 185juta AT Bensin"
+
+Cara upload:
+- Sales upload foto dulu
+- Setelah foto diterima, sales ketik detail mobil
+- Anda akan parse detail dan konfirmasi sebelum menyimpan
+- Format harga: "185juta" atau "185jt" → 185000000
+- Transmisi: "matic"/"AT" → AT, "manual"/"MT" → MT
+- Bahan bakar: "bensin" → Bensin, "diesel" → Diesel
+
+Gunakan bahasa Indonesia yang profesional dan efisien.`
+	}
+
+	tone, ok := toneInstructions[persona.Tone]
+	if !ok {
+		tone = toneInstructions[model.BotToneFriendly]
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(basePrompt + `
+
+Anda membantu CUSTOMER mencari mobil. Anda dapat:
+- Mencari mobil berdasarkan brand, budget, transmisi, dll
+- Menampilkan detail dan foto mobil
+- Memberikan rekomendasi
+- Simulasi kredit (DP, tenor, cicilan per bulan) dengan simulateCredit
+- Estimasi harga tukar tambah mobil lama customer dengan estimateTradeIn
+- Memberi alamat, kontak, jam buka, dan lokasi showroom dengan getShowroomInfo
+- Menjawab pertanyaan tentang cara bayar, garansi, dokumen, dan layanan dengan searchFAQ
+
+Saat menyebut hasil simulasi kredit, tampilkan TDP (total DP), cicilan per bulan, dan nama leasing,
+lalu jelaskan bahwa angka final mengikuti persetujuan leasing.
+
+Untuk tukar tambah, tanyakan merek, model, tahun, kilometer, dan transmisi mobil lama customer
+sebelum memanggil estimateTradeIn. Sampaikan hasilnya sebagai kisaran harga dan jelaskan bahwa
+harga final ditentukan setelah inspeksi oleh tim sales.
+
+Jika customer tanya lokasi atau minta share loc, panggil getShowroomInfo dengan send_location true.
+Untuk cara bayar, garansi, dokumen, atau layanan showroom, selalu panggil searchFAQ dan jawab
+berdasarkan hasilnya. Jangan mengarang kebijakan showroom yang tidak ada di hasil pencarian.
+
+Jika customer menyebut nama, budget, jenis mobil (MPV, SUV, sedan, dll), atau merek favorit,
+panggil updateCustomerProfile untuk menyimpannya. Jangan beri tahu customer bahwa data disimpan.
+
+Jika customer tanya tentang upload atau tambah mobil, jelaskan bahwa fitur itu untuk sales team.
+
+` + tone + `
+Pahami istilah automotive Indonesia seperti: matic (automatic), bensin (gasoline), OTR (On The Road price).`)
+
+	if len(persona.Rules) > 0 {
+		prompt.WriteString("\n\nAturan dari " + showroom + " yang WAJIB selalu diikuti:")
+		for _, rule := range persona.Rules {
+			prompt.WriteString("\n- " + rule)
+		}
+	}
+	if persona.Instructions != "" {
+		prompt.WriteString("\n\nInstruksi tambahan dari " + showroom + ":\n" + persona.Instructions)
+	}
+	return prompt.String()
+}
+
+// PersonaPreview shows what a draft persona does to a conversation
+type PersonaPreview struct {
+	SystemPrompt string `json:"system_prompt"`
+	Reply        string `json:"reply"`
+}
+
+// Preview runs a sample first message through a draft persona against the
+// mock provider, so the persona can be tried without spending LLM credits or
+// changing the live bot
+func Preview(ctx context.Context, persona *model.BotPersona, message string, isSales bool) (*PersonaPreview, error) {
+	greeting := ""
+	if !isSales {
+		greeting = persona.GreetingText()
+	}
+
+	systemPrompt := SystemPrompt(persona, isSales)
+	if greeting != "" {
+		systemPrompt += greetingSentNote
+	}
+
+	response, err := NewMockProvider().Chat(ctx, []Message{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: message},
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PersonaPreview{
+		SystemPrompt: systemPrompt,
+		Reply:        withGreeting(greeting, response.Content),
+	}, nil
+}
+
+// withGreeting opens a reply with the persona's greeting
+func withGreeting(greeting, reply string) string {
+	if greeting == "" {
+		return reply
+	}
+	return greeting + "\n\n" + reply
+}
@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// recordingProvider replies with a fixed text and keeps the last messages it got
+type recordingProvider struct {
+	reply    string
+	messages []Message
+}
+
+func (p *recordingProvider) Chat(ctx context.Context, messages []Message, functions []Function) (*Response, error) {
+	p.messages = messages
+	return &Response{Content: p.reply}, nil
+}
+
+// fakeConversations serves a fixed history
+type fakeConversations struct{ history []*BotMessage }
+
+func (f *fakeConversations) GetOrCreate(ctx context.Context, senderPhone string, isSales bool) (*Conversation, error) {
+	return &Conversation{ID: 1}, nil
+}
+
+func (f *fakeConversations) AddMessage(ctx context.Context, conversationID int, senderPhone, messageText, direction string) error {
+	return nil
+}
+
+func (f *fakeConversations) GetMessages(ctx context.Context, conversationID int, limit int) ([]*BotMessage, error) {
+	return f.history, nil
+}
+
+type fixedPersona struct{ persona *model.BotPersona }
+
+func (f fixedPersona) Active(ctx context.Context) (*model.BotPersona, error) {
+	persona := *f.persona
+	return &persona, nil
+}
+
+func testPersona() *model.BotPersona {
+	return &model.BotPersona{
+		Name:         "Rina",
+		Tone:         model.BotToneFormal,
+		Greeting:     "Selamat datang di {showroom}, saya {nama}.",
+		Rules:        []string{"Jangan menawarkan harga di bawah harga tercantum"},
+		ShowroomName: "Jaya Motor",
+	}
+}
+
+func TestSystemPrompt(t *testing.T) {
+	customer := SystemPrompt(testPersona(), false)
+	for _, want := range []string{"Anda adalah Rina", "di Jaya Motor", "Bapak/Ibu", "- Jangan menawarkan harga di bawah harga tercantum"} {
+		if !strings.Contains(customer, want) {
+			t.Errorf("customer prompt lacks %q", want)
+		}
+	}
+
+	sales := SystemPrompt(testPersona(), true)
+	if !strings.Contains(sales, "Anda adalah Rina") || strings.Contains(sales, "harga tercantum") {
+		t.Error("sales prompt should keep the identity without the customer rules")
+	}
+
+	if strings.Contains(SystemPrompt(nil, false), "Auto LMK") {
+		t.Error("default prompt names the platform instead of the showroom")
+	}
+}
+
+func TestBotGreeting(t *testing.T) {
+	inbound := &BotMessage{Direction: "inbound", MessageText: "Halo"}
+	outbound := &BotMessage{Direction: "outbound", MessageText: "Halo juga"}
+
+	tests := []struct {
+		name         string
+		history      []*BotMessage
+		isSales      bool
+		wantGreeting bool
+	}{
+		{"new customer", []*BotMessage{inbound}, false, true},
+		{"answered before", []*BotMessage{inbound, outbound, inbound}, false, false},
+		{"sales", []*BotMessage{inbound}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &recordingProvider{reply: "Ada, ini pilihannya."}
+			bot := NewBot(provider, &fakeConversations{history: tt.history}, nil)
+			bot.SetPersonas(fixedPersona{testPersona()})
+
+			reply, err := bot.ProcessMessage(model.WithTenantID(context.Background(), 1), 1, "628123", "Halo", tt.isSales)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			greeted := strings.HasPrefix(reply, "Selamat datang di Jaya Motor, saya Rina.\n\n")
+			if greeted != tt.wantGreeting || !strings.HasSuffix(reply, "Ada, ini pilihannya.") {
+				t.Errorf("reply = %q", reply)
+			}
+			if noted := strings.Contains(provider.messages[0].Content, "jangan menyapa lagi"); noted != tt.wantGreeting {
+				t.Errorf("system prompt greeting note = %v", noted)
+			}
+		})
+	}
+}
+
+func TestPreview(t *testing.T) {
+	preview, err := Preview(context.Background(), testPersona(), "Ada Avanza?", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(preview.Reply, "Selamat datang di Jaya Motor, saya Rina.") || !strings.Contains(preview.Reply, "Ada Avanza?") {
+		t.Errorf("reply = %q", preview.Reply)
+	}
+	if !strings.Contains(preview.SystemPrompt, "Anda adalah Rina") {
+		t.Errorf("system prompt = %q", preview.SystemPrompt)
+	}
+}
@@ -47,17 +47,20 @@ type Config struct {
 	APIKey      string
 	Model       string
 	ZAIEndpoint string
+	Temperature *float64 // nil leaves the provider's default
 }
 
 // NewProvider creates appropriate LLM provider
 func NewProvider(cfg Config) (Provider, error) {
 	switch cfg.Provider {
 	case "openai":
-		return NewOpenAIProvider(cfg.APIKey, cfg.Model)
+		return NewOpenAIProvider(cfg.APIKey, cfg.Model, cfg.Temperature)
 	case "anthropic":
-		return NewAnthropicProvider(cfg.APIKey, cfg.Model)
+		return NewAnthropicProvider(cfg.APIKey, cfg.Model, cfg.Temperature)
 	case "zai":
-		return NewZAIProvider(cfg.APIKey, cfg.Model, cfg.ZAIEndpoint)
+		return NewZAIProvider(cfg.APIKey, cfg.Model, cfg.ZAIEndpoint, cfg.Temperature)
+	case "mock":
+		return NewMockProvider(), nil
 	default:
 		return nil, fmt.Errorf("unsupported provider: %s", cfg.Provider)
 	}
@@ -65,67 +68,169 @@ func NewProvider(cfg Config) (Provider, error) {
 
 // OpenAIProvider implements OpenAI
 type OpenAIProvider struct {
-	apiKey string
-	model  string
+	chatCompletions
 }
 
-func NewOpenAIProvider(apiKey, model string) (*OpenAIProvider, error) {
+func NewOpenAIProvider(apiKey, model string, temperature *float64) (*OpenAIProvider, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("OpenAI API key required")
 	}
 	if model == "" {
 		model = "gpt-4o-mini" // Default to cheaper model
 	}
-	return &OpenAIProvider{apiKey: apiKey, model: model}, nil
-}
-
-func (p *OpenAIProvider) Chat(ctx context.Context, messages []Message, functions []Function) (*Response, error) {
-	// TODO: Implement OpenAI API call
-	// 1. Build request with messages and functions
-	// 2. Call OpenAI API
-	// 3. Parse response
-	// 4. Handle function calls if present
-	// 5. Return response
-
-	return nil, fmt.Errorf("OpenAI integration not yet implemented")
+	return &OpenAIProvider{chatCompletions{
+		name:        "OpenAI",
+		apiKey:      apiKey,
+		model:       model,
+		endpoint:    "https://api.openai.com/v1/chat/completions",
+		temperature: temperature,
+		client:      &http.Client{},
+		// OpenAI wants a name on function messages, which Message lacks
+		functionResultsAsUser: true,
+	}}, nil
 }
 
 // AnthropicProvider implements Anthropic Claude
 type AnthropicProvider struct {
-	apiKey string
-	model  string
+	apiKey      string
+	model       string
+	endpoint    string
+	temperature *float64
+	client      *http.Client
 }
 
-func NewAnthropicProvider(apiKey, model string) (*AnthropicProvider, error) {
+func NewAnthropicProvider(apiKey, model string, temperature *float64) (*AnthropicProvider, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("Anthropic API key required")
 	}
 	if model == "" {
 		model = "claude-3-5-haiku-20241022" // Default to Haiku
 	}
-	return &AnthropicProvider{apiKey: apiKey, model: model}, nil
+	return &AnthropicProvider{
+		apiKey:      apiKey,
+		model:       model,
+		endpoint:    "https://api.anthropic.com/v1/messages",
+		temperature: temperature,
+		client:      &http.Client{},
+	}, nil
+}
+
+const (
+	anthropicVersion = "2023-06-01"
+	// anthropicMaxTokens caps a reply; the Messages API requires a limit
+	anthropicMaxTokens = 2048
+)
+
+// Anthropic Messages API request/response structures
+type anthropicRequest struct {
+	Model       string          `json:"model"`
+	MaxTokens   int             `json:"max_tokens"`
+	System      string          `json:"system,omitempty"`
+	Messages    []Message       `json:"messages"`
+	Tools       []anthropicTool `json:"tools,omitempty"`
+	Temperature *float64        `json:"temperature,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type  string                 `json:"type"` // text or tool_use
+		Text  string                 `json:"text"`
+		Name  string                 `json:"name"`
+		Input map[string]interface{} `json:"input"`
+	} `json:"content"`
 }
 
 func (p *AnthropicProvider) Chat(ctx context.Context, messages []Message, functions []Function) (*Response, error) {
-	// TODO: Implement Anthropic API call
-	// 1. Build request with messages and tools
-	// 2. Call Anthropic API
-	// 3. Parse response
-	// 4. Handle tool use if present
-	// 5. Return response
-
-	return nil, fmt.Errorf("Anthropic integration not yet implemented")
+	req := anthropicRequest{
+		Model:       p.model,
+		MaxTokens:   anthropicMaxTokens,
+		Temperature: p.temperature,
+	}
+	// Anthropic accepts temperatures up to 1 only
+	if req.Temperature != nil && *req.Temperature > 1 {
+		one := 1.0
+		req.Temperature = &one
+	}
+
+	// The system prompt is a field of its own; function results go back as
+	// user turns and the conversation has to open with one
+	var system []string
+	for _, msg := range messages {
+		switch {
+		case msg.Role == "system":
+			system = append(system, msg.Content)
+		case strings.TrimSpace(msg.Content) == "":
+			// Empty turns, e.g. an assistant turn that only called a function, are rejected
+		case msg.Role == "assistant":
+			if len(req.Messages) > 0 {
+				req.Messages = append(req.Messages, msg)
+			}
+		default:
+			req.Messages = append(req.Messages, Message{Role: "user", Content: msg.Content})
+		}
+	}
+	req.System = strings.Join(system, "\n\n")
+
+	for _, fn := range functions {
+		req.Tools = append(req.Tools, anthropicTool{
+			Name:        fn.Name,
+			Description: fn.Description,
+			InputSchema: fn.Parameters,
+		})
+	}
+
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	body, err := postWithRetry(ctx, p.client, "Anthropic", func() (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("x-api-key", p.apiKey)
+		httpReq.Header.Set("anthropic-version", anthropicVersion)
+		return httpReq, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var anthropicResp anthropicResponse
+	if err := json.Unmarshal(body, &anthropicResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	response := &Response{}
+	var text []string
+	for _, block := range anthropicResp.Content {
+		switch block.Type {
+		case "text":
+			text = append(text, block.Text)
+		case "tool_use":
+			if response.FunctionCall == nil {
+				response.FunctionCall = &FunctionCall{Name: block.Name, Arguments: block.Input}
+			}
+		}
+	}
+	response.Content = strings.Join(text, "\n")
+	return response, nil
 }
 
 // ZAIProvider implements Z.AI API
 type ZAIProvider struct {
-	apiKey   string
-	model    string
-	endpoint string
-	client   *http.Client
+	chatCompletions
 }
 
-func NewZAIProvider(apiKey, model, endpoint string) (*ZAIProvider, error) {
+func NewZAIProvider(apiKey, model, endpoint string, temperature *float64) (*ZAIProvider, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("Z.AI API key required")
 	}
@@ -135,34 +240,50 @@ func NewZAIProvider(apiKey, model, endpoint string) (*ZAIProvider, error) {
 	if endpoint == "" {
 		endpoint = "https://api.z.ai/api/coding/paas/v4/chat/completions"
 	}
-	return &ZAIProvider{
-		apiKey:   apiKey,
-		model:    model,
-		endpoint: endpoint,
-		client:   &http.Client{},
-	}, nil
+	return &ZAIProvider{chatCompletions{
+		name:        "Z.AI",
+		apiKey:      apiKey,
+		model:       model,
+		endpoint:    endpoint,
+		temperature: temperature,
+		client:      &http.Client{},
+	}}, nil
 }
 
-// Z.AI API request/response structures
-type zaiRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
-	Tools    []zaiTool `json:"tools,omitempty"`
-	Stream   bool      `json:"stream"`
+// chatCompletions calls an OpenAI-compatible chat completions endpoint, which
+// both OpenAI and Z.AI serve
+type chatCompletions struct {
+	name        string // provider name for errors
+	apiKey      string
+	model       string
+	endpoint    string
+	temperature *float64
+	client      *http.Client
+
+	functionResultsAsUser bool // send function results as user turns
 }
 
-type zaiTool struct {
-	Type     string      `json:"type"`
-	Function zaiFunction `json:"function"`
+// Chat completions request/response structures
+type chatRequest struct {
+	Model       string     `json:"model"`
+	Messages    []Message  `json:"messages"`
+	Tools       []chatTool `json:"tools,omitempty"`
+	Temperature *float64   `json:"temperature,omitempty"`
+	Stream      bool       `json:"stream"`
 }
 
-type zaiFunction struct {
+type chatTool struct {
+	Type     string       `json:"type"`
+	Function chatFunction `json:"function"`
+}
+
+type chatFunction struct {
 	Name        string                 `json:"name"`
 	Description string                 `json:"description"`
 	Parameters  map[string]interface{} `json:"parameters"`
 }
 
-type zaiResponse struct {
+type chatResponse struct {
 	Choices []struct {
 		Message struct {
 			Role      string `json:"role"`
@@ -180,21 +301,31 @@ type zaiResponse struct {
 	} `json:"choices"`
 }
 
-func (p *ZAIProvider) Chat(ctx context.Context, messages []Message, functions []Function) (*Response, error) {
+func (p *chatCompletions) Chat(ctx context.Context, messages []Message, functions []Function) (*Response, error) {
 	// Build request
-	req := zaiRequest{
-		Model:    p.model,
-		Messages: messages,
-		Stream:   false,
+	req := chatRequest{
+		Model:       p.model,
+		Messages:    messages,
+		Temperature: p.temperature,
+		Stream:      false,
+	}
+	if p.functionResultsAsUser {
+		req.Messages = make([]Message, len(messages))
+		for i, msg := range messages {
+			if msg.Role == "function" {
+				msg.Role = "user"
+			}
+			req.Messages[i] = msg
+		}
 	}
 
 	// Convert functions to tools format
 	if len(functions) > 0 {
-		req.Tools = make([]zaiTool, len(functions))
+		req.Tools = make([]chatTool, len(functions))
 		for i, fn := range functions {
-			req.Tools[i] = zaiTool{
+			req.Tools[i] = chatTool{
 				Type: "function",
-				Function: zaiFunction{
+				Function: chatFunction{
 					Name:        fn.Name,
 					Description: fn.Description,
 					Parameters:  fn.Parameters,
@@ -209,68 +340,30 @@ func (p *ZAIProvider) Chat(ctx context.Context, messages []Message, functions []
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewReader(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-
-	// Retry configuration
-	maxRetries := 3
-	var resp *http.Response
-	var body []byte
-
-	// Retry loop for DNS and network errors
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		// Send request
-		var err error
-		resp, err = p.client.Do(httpReq)
-		if err != nil {
-			// Check if it's a DNS or network error
-			if isRetryableError(err) && attempt < maxRetries {
-				// Exponential backoff: 2s, 4s
-				waitTime := time.Duration(attempt*2) * time.Second
-				time.Sleep(waitTime)
-
-				// Need to recreate the request body for retry
-				httpReq, _ = http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewReader(reqBody))
-				httpReq.Header.Set("Content-Type", "application/json")
-				httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
-				continue
-			}
-			return nil, fmt.Errorf("failed to send request after %d attempts: %w", attempt, err)
-		}
-
-		// Read response
-		body, err = io.ReadAll(resp.Body)
-		resp.Body.Close()
+	body, err := postWithRetry(ctx, p.client, p.name, func() (*http.Request, error) {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", p.endpoint, bytes.NewReader(reqBody))
 		if err != nil {
-			return nil, fmt.Errorf("failed to read response: %w", err)
+			return nil, err
 		}
-
-		// Check status code
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("Z.AI API error (status %d): %s", resp.StatusCode, string(body))
-		}
-
-		// Success! Break out of retry loop
-		break
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+		return httpReq, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse response
-	var zaiResp zaiResponse
-	if err := json.Unmarshal(body, &zaiResp); err != nil {
+	var chatResp chatResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	if len(zaiResp.Choices) == 0 {
+	if len(chatResp.Choices) == 0 {
 		return nil, fmt.Errorf("no choices in response")
 	}
 
-	choice := zaiResp.Choices[0]
+	choice := chatResp.Choices[0]
 	response := &Response{
 		Content: choice.Message.Content,
 	}
@@ -292,6 +385,44 @@ func (p *ZAIProvider) Chat(ctx context.Context, messages []Message, functions []
 	return response, nil
 }
 
+// postWithRetry sends the request newRequest builds and returns the body of
+// a 200 response, retrying DNS and network errors with backoff
+func postWithRetry(ctx context.Context, client *http.Client, name string, newRequest func() (*http.Request, error)) ([]byte, error) {
+	maxRetries := 3
+
+	for attempt := 1; ; attempt++ {
+		httpReq, err := newRequest()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		resp, err := client.Do(httpReq)
+		if err != nil {
+			if isRetryableError(err) && attempt < maxRetries {
+				// Exponential backoff: 2s, 4s
+				select {
+				case <-time.After(time.Duration(attempt*2) * time.Second):
+					continue
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+			return nil, fmt.Errorf("failed to send request after %d attempts: %w", attempt, err)
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s API error (status %d): %s", name, resp.StatusCode, string(body))
+		}
+		return body, nil
+	}
+}
+
 // Helper to parse function arguments
 func ParseFunctionArguments(jsonStr string) (map[string]interface{}, error) {
 	var args map[string]interface{}
@@ -0,0 +1,52 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAnthropicChat(t *testing.T) {
+	var got anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "sk-ant-test" || r.Header.Get("anthropic-version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		w.Write([]byte(`{"content":[{"type":"text","text":"Saya cek dulu."},{"type":"tool_use","name":"searchCars","input":{"brand":"Toyota"}}]}`))
+	}))
+	defer server.Close()
+
+	temperature := 1.5
+	p, _ := NewAnthropicProvider("sk-ant-test", "", &temperature)
+	p.endpoint = server.URL
+
+	response, err := p.Chat(context.Background(), []Message{
+		{Role: "system", Content: "Anda asisten showroom."},
+		{Role: "assistant", Content: "Halo, ada yang bisa dibantu?"},
+		{Role: "user", Content: "Ada Avanza?"},
+		{Role: "assistant", Content: ""},
+		{Role: "function", Content: "Hasil fungsi searchCars: []"},
+	}, []Function{{Name: "searchCars", Parameters: map[string]interface{}{"type": "object"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.System != "Anda asisten showroom." || got.Temperature == nil || *got.Temperature != 1 {
+		t.Errorf("system %q, temperature %v", got.System, got.Temperature)
+	}
+	// The greeting before the first user turn and the empty turn are dropped,
+	// the function result goes back as a user turn
+	if len(got.Messages) != 2 || got.Messages[0].Content != "Ada Avanza?" || got.Messages[1].Role != "user" {
+		t.Errorf("messages = %+v", got.Messages)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].InputSchema["type"] != "object" {
+		t.Errorf("tools = %+v", got.Tools)
+	}
+	if response.Content != "Saya cek dulu." || response.FunctionCall == nil || response.FunctionCall.Arguments["brand"] != "Toyota" {
+		t.Errorf("response = %+v", response)
+	}
+}
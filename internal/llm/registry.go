@@ -0,0 +1,134 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// tenantProviderTTL bounds how long an instance keeps using a tenant's
+// provider after the tenant changed it through another instance
+const tenantProviderTTL = 5 * time.Minute
+
+// ErrNotConfigured is returned when neither the tenant nor the platform has an
+// LLM provider
+var ErrNotConfigured = errors.New("LLM provider not configured")
+
+// TenantSettings loads the own LLM provider of the tenant in the context; nil
+// credentials mean the tenant uses the platform provider
+type TenantSettings interface {
+	Credentials(ctx context.Context) (*model.LLMCredentials, error)
+}
+
+// Registry is the Provider of the bot and AI features. Each chat goes to the
+// provider of the tenant in the context: one built from the tenant's own
+// settings and cached, or the platform provider for tenants without any.
+type Registry struct {
+	platform Provider
+	settings TenantSettings
+	ttl      time.Duration
+	build    func(Config) (Provider, error)
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[int]registryEntry
+	gen     uint64 // bumped by Invalidate so in-flight loads don't store stale providers
+}
+
+type registryEntry struct {
+	provider Provider // nil for the platform provider
+	expires  time.Time
+}
+
+// NewRegistry returns a registry falling back to platform, which may be nil
+// when the platform has no provider of its own
+func NewRegistry(platform Provider, settings TenantSettings) *Registry {
+	return &Registry{
+		platform: platform,
+		settings: settings,
+		ttl:      tenantProviderTTL,
+		build:    NewProvider,
+		now:      time.Now,
+		entries:  make(map[int]registryEntry),
+	}
+}
+
+// Chat sends the chat to the provider of the tenant in the context
+func (r *Registry) Chat(ctx context.Context, messages []Message, functions []Function) (*Response, error) {
+	provider, err := r.For(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Chat(ctx, messages, functions)
+}
+
+// For returns the provider of the tenant in the context, the platform provider
+// when the tenant has no settings or there is no tenant. A tenant's broken
+// settings are an error rather than a silent switch to the platform provider.
+func (r *Registry) For(ctx context.Context) (Provider, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return r.platformProvider()
+	}
+
+	now := r.now()
+	r.mu.Lock()
+	entry, ok := r.entries[tenantID]
+	gen := r.gen
+	r.mu.Unlock()
+
+	if !ok || !now.Before(entry.expires) {
+		creds, err := r.settings.Credentials(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load LLM settings: %w", err)
+		}
+
+		entry = registryEntry{expires: now.Add(r.ttl)}
+		if creds != nil {
+			entry.provider, err = r.build(Config{
+				Provider:    creds.Provider,
+				APIKey:      creds.APIKey,
+				Model:       creds.Model,
+				Temperature: creds.Temperature,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create tenant LLM provider: %w", err)
+			}
+		}
+
+		r.mu.Lock()
+		if r.gen == gen {
+			r.entries[tenantID] = entry
+		}
+		r.mu.Unlock()
+	}
+
+	if entry.provider == nil {
+		return r.platformProvider()
+	}
+	return entry.provider, nil
+}
+
+// Invalidate drops the cached provider of a tenant after its settings changed
+func (r *Registry) Invalidate(tenantID int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, tenantID)
+	r.gen++
+}
+
+// HasPlatform reports whether tenants without settings of their own have a provider
+func (r *Registry) HasPlatform() bool {
+	return r.platform != nil
+}
+
+func (r *Registry) platformProvider() (Provider, error) {
+	if r.platform == nil {
+		return nil, ErrNotConfigured
+	}
+	return r.platform, nil
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// fakeProvider answers every chat with its name
+type fakeProvider struct{ name string }
+
+func (p *fakeProvider) Chat(ctx context.Context, messages []Message, functions []Function) (*Response, error) {
+	return &Response{Content: p.name}, nil
+}
+
+// fakeSettings serves credentials per tenant and counts the loads
+type fakeSettings struct {
+	creds map[int]*model.LLMCredentials
+	loads int
+}
+
+func (f *fakeSettings) Credentials(ctx context.Context) (*model.LLMCredentials, error) {
+	f.loads++
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return f.creds[tenantID], nil
+}
+
+func newTestRegistry(platform Provider, settings *fakeSettings) *Registry {
+	r := NewRegistry(platform, settings)
+	r.build = func(cfg Config) (Provider, error) {
+		return &fakeProvider{name: cfg.Provider + "/" + cfg.APIKey}, nil
+	}
+	return r
+}
+
+func chatWith(t *testing.T, r *Registry, tenantID int) string {
+	t.Helper()
+	ctx := context.Background()
+	if tenantID != 0 {
+		ctx = model.WithTenantID(ctx, tenantID)
+	}
+	response, err := r.Chat(ctx, nil, nil)
+	if err != nil {
+		t.Fatalf("tenant %d: %v", tenantID, err)
+	}
+	return response.Content
+}
+
+func TestRegistry(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	settings := &fakeSettings{creds: map[int]*model.LLMCredentials{
+		1: {Provider: "openai", APIKey: "key-1"},
+	}}
+	r := newTestRegistry(&fakeProvider{name: "platform"}, settings)
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if got := chatWith(t, r, 1); got != "openai/key-1" {
+			t.Errorf("tenant with own key chatted with %q", got)
+		}
+		if got := chatWith(t, r, 2); got != "platform" {
+			t.Errorf("tenant without settings chatted with %q", got)
+		}
+	}
+	if got := chatWith(t, r, 0); got != "platform" {
+		t.Errorf("no tenant chatted with %q", got)
+	}
+	if settings.loads != 2 {
+		t.Errorf("loads = %d, want 2 (one per tenant)", settings.loads)
+	}
+
+	// A changed key applies at once after invalidation, elsewhere after the TTL
+	settings.creds[1] = &model.LLMCredentials{Provider: "anthropic", APIKey: "key-2"}
+	settings.creds[2] = &model.LLMCredentials{Provider: "zai", APIKey: "key-3"}
+	r.Invalidate(1)
+	if got := chatWith(t, r, 1); got != "anthropic/key-2" {
+		t.Errorf("after invalidate chatted with %q", got)
+	}
+	if got := chatWith(t, r, 2); got != "platform" {
+		t.Errorf("before the TTL chatted with %q", got)
+	}
+	now = now.Add(tenantProviderTTL)
+	if got := chatWith(t, r, 2); got != "zai/key-3" {
+		t.Errorf("after the TTL chatted with %q", got)
+	}
+}
+
+func TestRegistryWithoutPlatform(t *testing.T) {
+	settings := &fakeSettings{creds: map[int]*model.LLMCredentials{
+		1: {Provider: "openai", APIKey: "key-1"},
+	}}
+	r := newTestRegistry(nil, settings)
+
+	if got := chatWith(t, r, 1); got != "openai/key-1" {
+		t.Errorf("tenant with own key chatted with %q", got)
+	}
+	_, err := r.Chat(model.WithTenantID(context.Background(), 2), nil, nil)
+	if !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("tenant without settings: err = %v, want ErrNotConfigured", err)
+	}
+}
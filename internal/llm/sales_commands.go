@@ -0,0 +1,384 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// pendingActionTTL is how long a staged change waits for the sales person's confirmation
+const pendingActionTTL = 5 * time.Minute
+
+// pendingAction is an inventory change staged by a sales command, applied by confirmAction
+type pendingAction struct {
+	action    string // update_price, mark_sold, mark_reserved
+	carID     int
+	before    map[string]interface{}
+	updates   map[string]interface{}
+	expiresAt time.Time
+}
+
+// executeSalesCommand runs the sales-only inventory functions. The LLM only offers these to
+// sales, but the check is repeated here so a customer chat can never trigger them.
+func (b *Bot) executeSalesCommand(ctx context.Context, functionName string, arguments map[string]interface{}) (interface{}, error) {
+	if !senderFrom(ctx).isSales {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Fitur ini hanya untuk sales team.",
+		}, nil
+	}
+
+	switch functionName {
+	case "updateCarPrice":
+		price, _ := arguments["price"].(float64)
+		if price < 10000000 || price > 10000000000 {
+			return map[string]interface{}{
+				"success": false,
+				"error":   "Harga harus antara 10 juta dan 10 miliar",
+			}, nil
+		}
+		return b.stageCarChange(ctx, arguments, "update_price", map[string]interface{}{"price": int64(price)})
+
+	case "markCarSold":
+		return b.stageCarChange(ctx, arguments, "mark_sold", map[string]interface{}{"status": "sold"})
+
+	case "markCarReserved":
+		return b.stageCarChange(ctx, arguments, "mark_reserved", map[string]interface{}{"status": "reserved"})
+
+	case "confirmAction":
+		return b.executeConfirmAction(ctx, arguments)
+
+	case "listMyLeads":
+		return b.executeListMyLeads(ctx, arguments)
+
+	case "listStock":
+		return b.executeListStock(ctx, arguments)
+
+	case "addCarPhotos":
+		return b.executeAddCarPhotos(ctx, arguments)
+	}
+
+	return nil, fmt.Errorf("unknown function: %s", functionName)
+}
+
+// stageCarChange validates a change against the current car and keeps it until confirmed
+func (b *Bot) stageCarChange(ctx context.Context, arguments map[string]interface{}, action string, updates map[string]interface{}) (interface{}, error) {
+	carID, ok := arguments["car_id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("invalid car_id")
+	}
+
+	car, err := b.carRepo.GetCar(ctx, int(carID))
+	if err != nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Mobil dengan ID %d tidak ditemukan", int(carID)),
+		}, nil
+	}
+
+	before := map[string]interface{}{}
+	for key := range updates {
+		switch key {
+		case "price":
+			before[key] = car.Price
+		case "status":
+			before[key] = car.Status
+		}
+	}
+
+	if status, ok := updates["status"].(string); ok && car.Status == status {
+		return map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Mobil ini sudah berstatus %s", status),
+		}, nil
+	}
+	if car.Status == "sold" && action != "mark_sold" {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Mobil ini sudah terjual dan tidak bisa diubah lewat WhatsApp",
+		}, nil
+	}
+
+	b.mu.Lock()
+	b.pendingActions[senderFrom(ctx).pendingKey] = &pendingAction{
+		action:    action,
+		carID:     car.ID,
+		before:    before,
+		updates:   updates,
+		expiresAt: time.Now().Add(pendingActionTTL),
+	}
+	b.mu.Unlock()
+
+	return map[string]interface{}{
+		"needs_confirmation": true,
+		"action":             action,
+		"car_id":             car.ID,
+		"car":                fmt.Sprintf("%s %s %d", car.Brand, car.Model, car.Year),
+		"before":             before,
+		"after":              updates,
+		"message":            "Perubahan belum disimpan. Minta sales membalas ya/tidak, lalu panggil confirmAction.",
+	}, nil
+}
+
+// executeConfirmAction applies or discards the sender's staged change
+func (b *Bot) executeConfirmAction(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+	key := senderFrom(ctx).pendingKey
+	b.mu.Lock()
+	pending := b.pendingActions[key]
+	delete(b.pendingActions, key)
+	b.mu.Unlock()
+
+	if pending == nil || time.Now().After(pending.expiresAt) {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Tidak ada perubahan yang menunggu konfirmasi. Silakan ulangi perintahnya.",
+		}, nil
+	}
+
+	if confirm, _ := arguments["confirm"].(bool); !confirm {
+		return map[string]interface{}{
+			"success": true,
+			"message": "Perubahan dibatalkan.",
+		}, nil
+	}
+
+	if err := b.carRepo.UpdateCar(ctx, pending.carID, pending.updates); err != nil {
+		slog.Error("failed to apply sales change", "error", err, "car_id", pending.carID, "action", pending.action)
+		return map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Gagal menyimpan perubahan: %v", err),
+		}, nil
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"car_id":  pending.carID,
+		"changes": pending.updates,
+		"message": "Perubahan berhasil disimpan.",
+	}, nil
+}
+
+// executeListMyLeads lists open leads for the current sales person
+func (b *Bot) executeListMyLeads(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+	if b.salesLeads == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Daftar lead belum tersedia.",
+		}, nil
+	}
+
+	includeUnassigned := true
+	if v, ok := arguments["include_unassigned"].(bool); ok {
+		includeUnassigned = v
+	}
+
+	leads, err := b.salesLeads.ListForSales(ctx, senderFrom(ctx).phone, includeUnassigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leads: %w", err)
+	}
+
+	items := make([]map[string]interface{}, 0, len(leads))
+	for _, lead := range leads {
+		item := map[string]interface{}{
+			"lead_id": lead.ID,
+			"phone":   lead.PhoneNumber,
+			"source":  lead.Source,
+			"status":  lead.Status,
+			"created": lead.CreatedAt.Format("02 Jan 15:04"),
+		}
+		if lead.Name != nil {
+			item["name"] = *lead.Name
+		}
+		if lead.InterestedCarID != nil {
+			item["car_id"] = *lead.InterestedCarID
+		}
+		items = append(items, item)
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"count":   len(items),
+		"leads":   items,
+	}, nil
+}
+
+// executeListStock lists inventory with optional filters
+func (b *Bot) executeListStock(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+	filters := map[string]interface{}{"status": "available"}
+	if status, ok := arguments["status"].(string); ok {
+		filters["status"] = status // "" lists every status
+	}
+	if brand, ok := arguments["brand"].(string); ok && brand != "" {
+		filters["brand"] = brand
+	}
+	if maxPrice, ok := arguments["max_price"].(float64); ok && maxPrice > 0 {
+		filters["max_price"] = int64(maxPrice)
+	}
+	if transmission, ok := arguments["transmission"].(string); ok && transmission != "" {
+		filters["transmission"] = transmission
+	}
+
+	cars, err := b.carRepo.ListCars(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stock: %w", err)
+	}
+
+	const maxItems = 20
+	items := make([]map[string]interface{}, 0, maxItems)
+	for _, car := range cars {
+		if len(items) == maxItems {
+			break
+		}
+		items = append(items, map[string]interface{}{
+			"car_id": car.ID,
+			"car":    fmt.Sprintf("%s %s %d", car.Brand, car.Model, car.Year),
+			"price":  car.Price,
+			"status": car.Status,
+		})
+	}
+
+	return map[string]interface{}{
+		"success": true,
+		"total":   len(cars),
+		"shown":   len(items),
+		"cars":    items,
+	}, nil
+}
+
+// executeAddCarPhotos attaches the sender's uploaded photos to an existing car
+func (b *Bot) executeAddCarPhotos(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+	carID, ok := arguments["car_id"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("invalid car_id")
+	}
+
+	from := senderFrom(ctx)
+	photoURLs := b.GetPendingPhotos(from.tenantID, from.phone)
+	if len(photoURLs) == 0 {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Silakan kirim foto mobil terlebih dahulu",
+		}, nil
+	}
+
+	if err := b.carRepo.AddPhotos(ctx, int(carID), photoURLs); err != nil {
+		slog.Error("failed to add car photos", "error", err, "car_id", int(carID))
+		return map[string]interface{}{
+			"success": false,
+			"error":   fmt.Sprintf("Gagal menambahkan foto: %v", err),
+		}, nil
+	}
+
+	b.ClearPendingPhotos(from.tenantID, from.phone)
+
+	return map[string]interface{}{
+		"success":     true,
+		"car_id":      int(carID),
+		"photo_count": len(photoURLs),
+		"message":     "Foto berhasil ditambahkan!",
+	}, nil
+}
+
+// salesFunctions returns the inventory functions available to sales only
+func salesFunctions() []Function {
+	carIDParam := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"car_id": map[string]interface{}{
+				"type":        "integer",
+				"description": "ID mobil",
+			},
+		},
+		"required": []string{"car_id"},
+	}
+
+	return []Function{
+		{
+			Name:        "updateCarPrice",
+			Description: "Ubah harga mobil. Perubahan baru disimpan setelah sales konfirmasi lewat confirmAction",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"car_id": map[string]interface{}{
+						"type":        "integer",
+						"description": "ID mobil",
+					},
+					"price": map[string]interface{}{
+						"type":        "integer",
+						"description": "Harga baru dalam rupiah (contoh: 179jt → 179000000)",
+					},
+				},
+				"required": []string{"car_id", "price"},
+			},
+		},
+		{
+			Name:        "markCarSold",
+			Description: "Tandai mobil sudah terjual. Perubahan baru disimpan setelah sales konfirmasi lewat confirmAction",
+			Parameters:  carIDParam,
+		},
+		{
+			Name:        "markCarReserved",
+			Description: "Tandai mobil sudah dibooking/DP. Perubahan baru disimpan setelah sales konfirmasi lewat confirmAction",
+			Parameters:  carIDParam,
+		},
+		{
+			Name:        "confirmAction",
+			Description: "Simpan atau batalkan perubahan yang menunggu konfirmasi sales",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"confirm": map[string]interface{}{
+						"type":        "boolean",
+						"description": "true jika sales menjawab ya, false jika tidak",
+					},
+				},
+				"required": []string{"confirm"},
+			},
+		},
+		{
+			Name:        "listMyLeads",
+			Description: "Lihat lead yang sedang ditangani sales ini dan lead baru yang belum diambil",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"include_unassigned": map[string]interface{}{
+						"type":        "boolean",
+						"description": "Sertakan lead baru yang belum ditangani siapa pun (default true)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "listStock",
+			Description: "Lihat stok mobil dengan filter",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"brand": map[string]interface{}{
+						"type":        "string",
+						"description": "Merek mobil",
+					},
+					"status": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"available", "reserved", "sold", "draft", ""},
+						"description": "Status mobil, default available. Kosongkan untuk semua status",
+					},
+					"max_price": map[string]interface{}{
+						"type":        "integer",
+						"description": "Harga maksimal dalam rupiah",
+					},
+					"transmission": map[string]interface{}{
+						"type":        "string",
+						"description": "Jenis transmisi",
+					},
+				},
+			},
+		},
+		{
+			Name:        "addCarPhotos",
+			Description: "Tambahkan foto yang baru dikirim sales ke mobil yang sudah ada di catalog",
+			Parameters:  carIDParam,
+		},
+	}
+}
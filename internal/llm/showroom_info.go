@@ -0,0 +1,225 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// faqSearchLimit is how many FAQ answers the bot gets per search
+const faqSearchLimit = 3
+
+// ShowroomDirectory interface for the showroom's address, contacts and hours
+type ShowroomDirectory interface {
+	GetByTenantID(ctx context.Context, tenantID int) (*model.ShowroomSettings, error)
+}
+
+// FAQSearcher interface for the showroom's answers to common questions
+type FAQSearcher interface {
+	Search(ctx context.Context, text, category string, limit int) ([]*model.FAQ, error)
+}
+
+// Location is a map pin queued for sending as a native WhatsApp location
+type Location struct {
+	Latitude  float64
+	Longitude float64
+	Name      string
+	Address   string
+}
+
+// executeGetShowroomInfo answers where the showroom is and when it opens. With
+// send_location the showroom's pin is queued for WhatsAppService to send.
+func (b *Bot) executeGetShowroomInfo(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+	if b.showrooms == nil {
+		return map[string]interface{}{
+			"success": false,
+			"error":   "Informasi showroom belum tersedia, tawarkan untuk menghubungkan dengan sales.",
+		}, nil
+	}
+
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+	showroom, err := b.showrooms.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get showroom settings: %w", err)
+	}
+
+	name := ""
+	if b.personas != nil {
+		if persona, err := b.personas.Active(ctx); err != nil {
+			slog.Error("failed to load bot persona", "error", err)
+		} else {
+			name = persona.ShowroomName
+		}
+	}
+
+	hours := showroom.BusinessHoursText()
+	if hours == "" {
+		hours = "belum diisi"
+	}
+	result := map[string]interface{}{
+		"success":        true,
+		"name":           name,
+		"address":        valueOr(showroom.Address, "belum diisi"),
+		"phone":          valueOr(showroom.Phone, "belum diisi"),
+		"email":          valueOr(showroom.Email, "belum diisi"),
+		"business_hours": hours,
+	}
+	if showroom.Hours.Configured() {
+		now := b.now()
+		open := showroom.Hours.IsOpen(now)
+		result["open_now"] = open
+		if !open {
+			result["opens"] = showroom.NextOpeningText(now)
+		}
+	}
+
+	if showroom.Latitude == nil || showroom.Longitude == nil {
+		result["location"] = "Titik lokasi belum diatur, berikan alamatnya saja."
+		return result, nil
+	}
+
+	result["maps_url"] = fmt.Sprintf("https://www.google.com/maps/search/?api=1&query=%f,%f", *showroom.Latitude, *showroom.Longitude)
+	if sendLocation, _ := arguments["send_location"].(bool); sendLocation {
+		b.mu.Lock()
+		b.pendingLocations[senderFrom(ctx).pendingKey] = &Location{
+			Latitude:  *showroom.Latitude,
+			Longitude: *showroom.Longitude,
+			Name:      name,
+			Address:   valueOr(showroom.Address, ""),
+		}
+		b.mu.Unlock()
+		result["location"] = "Lokasi showroom dikirim sebagai pesan lokasi WhatsApp setelah balasan Anda."
+	}
+	return result, nil
+}
+
+// executeSearchFAQ looks up the showroom's own answers, so the bot does not
+// make up payment, warranty or document policies
+func (b *Bot) executeSearchFAQ(ctx context.Context, arguments map[string]interface{}) (interface{}, error) {
+	text, _ := arguments["query"].(string)
+	category, _ := arguments["category"].(string)
+	if strings.TrimSpace(text) == "" {
+		return nil, fmt.Errorf("invalid query")
+	}
+	if !model.IsFAQCategory(category) {
+		category = ""
+	}
+
+	var faqs []*model.FAQ
+	if b.faqs != nil {
+		var err error
+		if faqs, err = b.faqs.Search(ctx, text, category, faqSearchLimit); err != nil {
+			return nil, fmt.Errorf("failed to search faqs: %w", err)
+		}
+	}
+
+	if len(faqs) == 0 {
+		return map[string]interface{}{
+			"found":   false,
+			"message": "Tidak ada jawaban dari showroom untuk pertanyaan ini. Jangan mengarang; tawarkan untuk menanyakan ke tim sales.",
+		}, nil
+	}
+
+	answers := make([]map[string]interface{}, len(faqs))
+	for i, f := range faqs {
+		answers[i] = map[string]interface{}{
+			"question": f.Question,
+			"answer":   f.Answer,
+		}
+	}
+	return map[string]interface{}{
+		"found":   true,
+		"answers": answers,
+	}, nil
+}
+
+// availabilityNote tells the LLM whether a sales person can take over the chat
+// now. Without structured business hours nothing is said.
+func availabilityNote(showroom *model.ShowroomSettings, now time.Time) string {
+	if !showroom.Hours.Configured() {
+		return ""
+	}
+	if showroom.Hours.IsOpen(now) {
+		return "\n\nSaat ini showroom BUKA dan tim sales tersedia. Jika customer ingin bicara dengan sales, " +
+			"test drive, atau nego harga, sampaikan bahwa sales akan segera menghubungi."
+	}
+
+	reason := "di luar jam operasional"
+	if holiday := showroom.Hours.HolidayOn(now); holiday != nil {
+		reason = "hari libur"
+		if holiday.Name != "" {
+			reason += " " + holiday.Name
+		}
+	}
+	return fmt.Sprintf("\n\nSaat ini showroom TUTUP (%s) dan buka kembali %s. Tidak ada sales yang bisa membalas "+
+		"sampai showroom buka: jangan menjanjikan sales akan segera menghubungi, sampaikan bahwa sales akan "+
+		"menindaklanjuti setelah showroom buka. Anda tetap bisa membantu mencari mobil, simulasi kredit, dan "+
+		"menjawab pertanyaan.", reason, showroom.NextOpeningText(now))
+}
+
+// valueOr dereferences an optional setting, fallback when it is unset or empty
+func valueOr(value *string, fallback string) string {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return fallback
+	}
+	return *value
+}
+
+// GetPendingLocation returns the location queued for sending to a sender
+func (b *Bot) GetPendingLocation(tenantID int, senderPhone string) *Location {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pendingLocations[pendingKey{tenantID, senderPhone}]
+}
+
+// ClearPendingLocation clears the queued location after sending
+func (b *Bot) ClearPendingLocation(tenantID int, senderPhone string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.pendingLocations, pendingKey{tenantID, senderPhone})
+}
+
+// showroomFunctions returns the functions answering questions about the showroom itself
+func showroomFunctions() []Function {
+	return []Function{
+		{
+			Name:        "getShowroomInfo",
+			Description: "Ambil alamat, nomor telepon, email, jam buka, dan lokasi peta showroom",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"send_location": map[string]interface{}{
+						"type":        "boolean",
+						"description": "true untuk mengirim titik lokasi showroom ke WhatsApp customer (saat customer tanya lokasi, arah, atau share loc)",
+					},
+				},
+			},
+		},
+		{
+			Name:        "searchFAQ",
+			Description: "Cari jawaban showroom untuk pertanyaan umum: cara bayar, garansi, dokumen (BPKB, STNK), layanan",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"query": map[string]interface{}{
+						"type":        "string",
+						"description": "Pertanyaan customer atau kata kuncinya (contoh: garansi mesin, bayar transfer)",
+					},
+					"category": map[string]interface{}{
+						"type":        "string",
+						"enum":        model.FAQCategories,
+						"description": "Kategori pertanyaan, kosongkan jika ragu",
+					},
+				},
+				"required": []string{"query"},
+			},
+		},
+	}
+}
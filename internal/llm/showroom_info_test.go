@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type fixedShowroom struct{ settings *model.ShowroomSettings }
+
+func (f fixedShowroom) GetByTenantID(ctx context.Context, tenantID int) (*model.ShowroomSettings, error) {
+	settings := *f.settings
+	settings.TenantID = tenantID
+	return &settings, nil
+}
+
+// fakeFAQs returns its FAQs for any text, keeping the last search
+type fakeFAQs struct {
+	faqs     []*model.FAQ
+	text     string
+	category string
+}
+
+func (f *fakeFAQs) Search(ctx context.Context, text, category string, limit int) ([]*model.FAQ, error) {
+	f.text, f.category = text, category
+	return f.faqs, nil
+}
+
+func TestGetShowroomInfo(t *testing.T) {
+	address := "Jl. Sudirman No. 1, Jakarta"
+	lat, lng := -6.2, 106.8
+	ctx := withSender(model.WithTenantID(context.Background(), 1), sender{pendingKey: pendingKey{1, "628123"}})
+
+	bot := NewBot(&recordingProvider{}, &fakeConversations{}, nil)
+	bot.SetPersonas(fixedPersona{testPersona()})
+	bot.SetShowrooms(fixedShowroom{&model.ShowroomSettings{Address: &address, Latitude: &lat, Longitude: &lng}})
+
+	result, err := bot.executeFunction(ctx, "getShowroomInfo", map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := result.(map[string]interface{})
+	if info["address"] != address || info["phone"] != "belum diisi" || !strings.Contains(info["maps_url"].(string), "-6.200000,106.800000") {
+		t.Errorf("info = %v", info)
+	}
+	if bot.GetPendingLocation(1, "628123") != nil {
+		t.Error("location queued without send_location")
+	}
+
+	if _, err := bot.executeFunction(ctx, "getShowroomInfo", map[string]interface{}{"send_location": true}); err != nil {
+		t.Fatal(err)
+	}
+	location := bot.GetPendingLocation(1, "628123")
+	if location == nil || location.Latitude != lat || location.Name != "Jaya Motor" || location.Address != address {
+		t.Fatalf("location = %+v", location)
+	}
+	bot.ClearPendingLocation(1, "628123")
+
+	// Without coordinates the bot gives the address only
+	bot.SetShowrooms(fixedShowroom{&model.ShowroomSettings{Address: &address}})
+	if _, err := bot.executeFunction(ctx, "getShowroomInfo", map[string]interface{}{"send_location": true}); err != nil {
+		t.Fatal(err)
+	}
+	if bot.GetPendingLocation(1, "628123") != nil {
+		t.Error("location queued without coordinates")
+	}
+}
+
+func TestSearchFAQ(t *testing.T) {
+	ctx := model.WithTenantID(context.Background(), 1)
+	faqs := &fakeFAQs{}
+
+	bot := NewBot(&recordingProvider{}, &fakeConversations{}, nil)
+	bot.SetFAQs(faqs)
+
+	result, err := bot.executeFunction(ctx, "searchFAQ", map[string]interface{}{"query": "garansi mesin", "category": "asuransi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.(map[string]interface{})["found"] != false || faqs.category != "" {
+		t.Errorf("result = %v, category = %q", result, faqs.category)
+	}
+
+	faqs.faqs = []*model.FAQ{{Question: "Ada garansi?", Answer: "Garansi mesin 1 tahun."}}
+	result, err = bot.executeFunction(ctx, "searchFAQ", map[string]interface{}{"query": "garansi mesin", "category": "garansi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	answers := result.(map[string]interface{})["answers"].([]map[string]interface{})
+	if len(answers) != 1 || answers[0]["answer"] != "Garansi mesin 1 tahun." || faqs.category != "garansi" {
+		t.Errorf("answers = %v", answers)
+	}
+
+	if _, err := bot.executeFunction(ctx, "searchFAQ", map[string]interface{}{"query": " "}); err == nil {
+		t.Error("empty query accepted")
+	}
+}
+
+func TestAvailabilityNote(t *testing.T) {
+	hours := &model.BusinessHours{
+		Weekly:   []model.OpeningPeriod{{Day: time.Monday, Open: "09:00", Close: "17:00"}},
+		Holidays: []model.Holiday{{Date: "2026-10-19", Name: "Cuti bersama"}},
+	}
+	if err := hours.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	wib := hours.Location()
+
+	tests := []struct {
+		name    string
+		now     time.Time
+		isSales bool
+		want    string
+	}{
+		{"open", time.Date(2026, 10, 12, 10, 0, 0, 0, wib), false, "showroom BUKA"},
+		{"closed", time.Date(2026, 10, 12, 20, 0, 0, 0, wib), false, "TUTUP (di luar jam operasional) dan buka kembali Senin, 26 Oktober"},
+		{"holiday", time.Date(2026, 10, 19, 10, 0, 0, 0, wib), false, "TUTUP (hari libur Cuti bersama) dan buka kembali Senin, 26 Oktober pukul 09:00 WIB"},
+		{"sales", time.Date(2026, 10, 12, 20, 0, 0, 0, wib), true, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &recordingProvider{reply: "Baik."}
+			bot := NewBot(provider, &fakeConversations{}, nil)
+			bot.SetShowrooms(fixedShowroom{&model.ShowroomSettings{Hours: hours}})
+			bot.now = func() time.Time { return tt.now }
+
+			if _, err := bot.ProcessMessage(model.WithTenantID(context.Background(), 1), 1, "628123", "Halo", tt.isSales); err != nil {
+				t.Fatal(err)
+			}
+			prompt := provider.messages[0].Content
+			if tt.want == "" {
+				if strings.Contains(prompt, "Saat ini showroom") {
+					t.Errorf("sales prompt mentions availability: %q", prompt)
+				}
+				return
+			}
+			if !strings.Contains(prompt, tt.want) {
+				t.Errorf("prompt lacks %q:\n%s", tt.want, prompt)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// APIKeyHeader carries a tenant API key for clients that cannot set Authorization
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator resolves tenant API keys
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*model.APIKey, error)
+}
+
+// apiKeyFromRequest reads the key from "Authorization: Bearer <key>" or X-API-Key
+func apiKeyFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	return r.Header.Get(APIKeyHeader)
+}
+
+// RequireAPIKey protects /api/v1 routes. It takes the tenant from the key instead
+// of the domain, so it replaces TenantExtractor; changes are audited as the key.
+// The key's scopes, not user roles, decide what the request may do.
+func RequireAPIKey(auth APIKeyAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := apiKeyFromRequest(r)
+			if key == "" {
+				Unauthorized(w, "API key wajib diisi")
+				return
+			}
+
+			apiKey, err := auth.AuthenticateAPIKey(r.Context(), key)
+			if err != nil {
+				Unauthorized(w, "API key tidak valid")
+				return
+			}
+
+			ctx := model.WithTenantID(r.Context(), apiKey.TenantID)
+			ctx = model.WithAPIKey(ctx, apiKey)
+			ctx = model.WithAuditActor(ctx, model.AuditActorAPIKey, apiKey.Label())
+			ctx = model.WithSystemActor(ctx)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireScope answers 403 unless the request's API key holds scope. Must run
+// after RequireAPIKey.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey, err := model.GetAPIKey(r.Context())
+			if err != nil || !apiKey.HasScope(scope) {
+				Forbidden(w, "API key tidak memiliki scope "+scope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
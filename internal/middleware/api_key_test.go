@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type fakeAPIKeys map[string]*model.APIKey
+
+func (f fakeAPIKeys) AuthenticateAPIKey(ctx context.Context, key string) (*model.APIKey, error) {
+	apiKey, ok := f[key]
+	if !ok {
+		return nil, errors.New("invalid API key")
+	}
+	return apiKey, nil
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	keys := fakeAPIKeys{
+		"lmk_reader": {ID: 1, TenantID: 3, Name: "DMS", Prefix: "lmk_read", Scopes: []string{model.ScopeCarsRead}},
+	}
+
+	var gotTenant int
+	var gotActor string
+	r := chi.NewRouter()
+	r.Use(RequireAPIKey(keys))
+	r.With(RequireScope(model.ScopeCarsRead)).Get("/cars", func(w http.ResponseWriter, r *http.Request) {
+		gotTenant, _ = model.GetTenantID(r.Context())
+		_, gotActor = model.GetAuditActor(r.Context())
+	})
+	r.With(RequireScope(model.ScopeCarsWrite)).Post("/cars", func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name   string
+		method string
+		header string
+		value  string
+		want   int
+	}{
+		{"no key", http.MethodGet, "", "", http.StatusUnauthorized},
+		{"unknown key", http.MethodGet, "Authorization", "Bearer lmk_other", http.StatusUnauthorized},
+		{"bearer key", http.MethodGet, "Authorization", "Bearer lmk_reader", http.StatusOK},
+		{"header key", http.MethodGet, APIKeyHeader, "lmk_reader", http.StatusOK},
+		{"missing scope", http.MethodPost, APIKeyHeader, "lmk_reader", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/cars", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	if gotTenant != 3 {
+		t.Errorf("tenant = %d, want the key's tenant 3", gotTenant)
+	}
+	if gotActor != "DMS (lmk_read)" {
+		t.Errorf("audit actor = %q, want the key label", gotActor)
+	}
+}
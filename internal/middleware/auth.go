@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// SessionCookieName is the cookie carrying the signed admin session
+const SessionCookieName = "auto_lmk_session"
+
+// Authenticator resolves session tokens from request credentials
+type Authenticator interface {
+	SessionFromCookie(value string) (string, error)
+	SessionFromBearer(accessToken string) (string, error)
+	ValidateSession(ctx context.Context, sessionToken string) (*model.User, error)
+}
+
+// SessionToken extracts the session token from a Bearer JWT or the session cookie.
+// It only checks signatures; use ValidateSession to check the session is still live.
+func SessionToken(auth Authenticator, r *http.Request) (string, bool) {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		token, err := auth.SessionFromBearer(strings.TrimPrefix(header, "Bearer "))
+		return token, err == nil
+	}
+
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return "", false
+	}
+	token, err := auth.SessionFromCookie(cookie.Value)
+	return token, err == nil
+}
+
+// authenticate returns the request with the user in context, or false if not logged in.
+// Must run after TenantExtractor: sessions only count on their own tenant's domain.
+func authenticate(auth Authenticator, r *http.Request) (*http.Request, bool) {
+	token, ok := SessionToken(auth, r)
+	if !ok {
+		return r, false
+	}
+
+	user, err := auth.ValidateSession(r.Context(), token)
+	if err != nil {
+		return r, false
+	}
+
+	// A tenant account is never valid on another tenant's domain
+	if !user.IsSuperAdmin() {
+		if tenantID, err := model.GetTenantID(r.Context()); err != nil || tenantID != user.TenantID {
+			return r, false
+		}
+	}
+
+	return r.WithContext(model.WithUser(r.Context(), user)), true
+}
+
+// RequireSession protects admin pages, redirecting to /login when not logged in
+func RequireSession(auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, ok := authenticate(auth, r)
+			if !ok {
+				loginURL := "/login?next=" + url.QueryEscape(r.URL.RequestURI())
+				if r.Header.Get("HX-Request") == "true" {
+					// HTMX swaps the response body; ask it to navigate instead
+					w.Header().Set("HX-Redirect", loginURL)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				http.Redirect(w, r, loginURL, http.StatusSeeOther)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAuth protects API routes, answering 401 when not logged in
+func RequireAuth(auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, ok := authenticate(auth, r)
+			if !ok {
+				Unauthorized(w, "Silakan login terlebih dahulu")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasAnyPermission reports whether the logged-in user holds at least one of perms
+func hasAnyPermission(r *http.Request, perms []model.Permission) bool {
+	user, err := model.GetUser(r.Context())
+	if err != nil {
+		return false
+	}
+	for _, perm := range perms {
+		if user.Can(perm) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequirePermission protects API routes by role, answering 403 unless the user
+// holds one of perms. Must run after RequireAuth.
+func RequirePermission(perms ...model.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !hasAnyPermission(r, perms) {
+				Forbidden(w, "Anda tidak memiliki akses ke fitur ini")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePagePermission is RequirePermission for admin pages. Must run after RequireSession.
+func RequirePagePermission(perms ...model.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !hasAnyPermission(r, perms) {
+				http.Error(w, "Anda tidak memiliki akses ke halaman ini", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePlatformAdmin protects platform routes that are not tied to a tenant,
+// such as tenant management. Only super-admin sessions are accepted.
+func RequirePlatformAdmin(auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r, ok := authenticate(auth, r)
+			if !ok {
+				Unauthorized(w, "Silakan login terlebih dahulu")
+				return
+			}
+			if !hasAnyPermission(r, []model.Permission{model.PermManageTenants}) {
+				Forbidden(w, "Hanya super admin platform yang dapat mengakses fitur ini")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// fakeAuthenticator treats the bearer token itself as the session token
+type fakeAuthenticator struct {
+	users map[string]*model.User
+}
+
+func (f *fakeAuthenticator) SessionFromCookie(value string) (string, error) {
+	return value, nil
+}
+
+func (f *fakeAuthenticator) SessionFromBearer(accessToken string) (string, error) {
+	return accessToken, nil
+}
+
+func (f *fakeAuthenticator) ValidateSession(ctx context.Context, sessionToken string) (*model.User, error) {
+	user, ok := f.users[sessionToken]
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+	return user, nil
+}
+
+func newTestAuthenticator() *fakeAuthenticator {
+	salesID := 7
+	return &fakeAuthenticator{users: map[string]*model.User{
+		"owner":       {ID: 1, TenantID: 1, Role: model.RoleOwner},
+		"admin":       {ID: 2, TenantID: 1, Role: model.RoleAdmin},
+		"sales":       {ID: 3, TenantID: 1, Role: model.RoleSales, SalesID: &salesID},
+		"other-admin": {ID: 4, TenantID: 2, Role: model.RoleAdmin},
+		"platform":    {ID: 5, Role: model.RoleSuperAdmin},
+	}}
+}
+
+// withTenant stands in for TenantExtractor
+func withTenant(tenantID int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(model.WithTenantID(r.Context(), tenantID)))
+		})
+	}
+}
+
+func newTestRouter(auth Authenticator) *chi.Mux {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+
+	r := chi.NewRouter()
+	r.Route("/api/admin/tenants", func(r chi.Router) {
+		r.Use(RequirePlatformAdmin(auth))
+		r.Get("/", ok)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(withTenant(1))
+		r.Use(RequireAuth(auth))
+		r.With(RequirePermission(model.PermManageInventory)).Delete("/api/cars/{id}", ok)
+		r.With(RequirePermission(model.PermManageCustomers, model.PermViewAssigned)).Get("/api/conversations", ok)
+		r.With(RequirePermission(model.PermManageUsers)).Get("/api/admin/users", ok)
+	})
+	return r
+}
+
+func doRequest(r http.Handler, method, path, token string) int {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestRequirePermission_SalesCannotDeleteCars(t *testing.T) {
+	r := newTestRouter(newTestAuthenticator())
+
+	if code := doRequest(r, http.MethodDelete, "/api/cars/10", "sales"); code != http.StatusForbidden {
+		t.Errorf("Expected 403 for sales deleting a car, got %d", code)
+	}
+	if code := doRequest(r, http.MethodDelete, "/api/cars/10", "admin"); code != http.StatusOK {
+		t.Errorf("Expected 200 for admin deleting a car, got %d", code)
+	}
+}
+
+func TestRequirePermission_SalesSeesAssignedConversations(t *testing.T) {
+	r := newTestRouter(newTestAuthenticator())
+
+	if code := doRequest(r, http.MethodGet, "/api/conversations", "sales"); code != http.StatusOK {
+		t.Errorf("Expected 200 for sales listing conversations, got %d", code)
+	}
+}
+
+func TestRequirePermission_OnlyOwnerManagesUsers(t *testing.T) {
+	r := newTestRouter(newTestAuthenticator())
+
+	tests := map[string]int{
+		"owner": http.StatusOK,
+		"admin": http.StatusForbidden,
+		"sales": http.StatusForbidden,
+	}
+	for token, want := range tests {
+		if code := doRequest(r, http.MethodGet, "/api/admin/users", token); code != want {
+			t.Errorf("Expected %d for %s, got %d", want, token, code)
+		}
+	}
+}
+
+func TestRequireAuth_RejectsOtherTenantUser(t *testing.T) {
+	r := newTestRouter(newTestAuthenticator())
+
+	if code := doRequest(r, http.MethodGet, "/api/conversations", "other-admin"); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a user of another tenant, got %d", code)
+	}
+}
+
+func TestRequireAuth_NoSession(t *testing.T) {
+	r := newTestRouter(newTestAuthenticator())
+
+	if code := doRequest(r, http.MethodGet, "/api/conversations", ""); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a session, got %d", code)
+	}
+}
+
+func TestRequirePlatformAdmin(t *testing.T) {
+	r := newTestRouter(newTestAuthenticator())
+
+	// Tenant sessions are not valid outside their tenant, so owners are not even logged in here
+	tests := map[string]int{
+		"platform": http.StatusOK,
+		"owner":    http.StatusUnauthorized,
+		"":         http.StatusUnauthorized,
+	}
+	for token, want := range tests {
+		if code := doRequest(r, http.MethodGet, "/api/admin/tenants", token); code != want {
+			t.Errorf("Expected %d for %q, got %d", want, token, code)
+		}
+	}
+}
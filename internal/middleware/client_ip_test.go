@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+func TestClientIP(t *testing.T) {
+	_, loopback, _ := net.ParseCIDR("127.0.0.1/32")
+	_, internal, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := []*net.IPNet{loopback, internal}
+
+	tests := []struct {
+		name       string
+		trusted    []*net.IPNet
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"no trusted proxies", nil, "127.0.0.1:5000", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "127.0.0.1:5000"},
+		{"untrusted peer", trusted, "203.0.113.9:5000", map[string]string{"X-Forwarded-For": "1.2.3.4"}, "203.0.113.9:5000"},
+		{"trusted proxy", trusted, "127.0.0.1:5000", map[string]string{"X-Forwarded-For": "203.0.113.9"}, "203.0.113.9"},
+		{"spoofed hop before the client", trusted, "127.0.0.1:5000", map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.9"}, "203.0.113.9"},
+		{"chain of trusted proxies", trusted, "127.0.0.1:5000", map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.9, 10.0.0.7"}, "203.0.113.9"},
+		{"other headers ignored", trusted, "127.0.0.1:5000", map[string]string{"True-Client-IP": "1.2.3.4", "X-Real-IP": "1.2.3.4"}, "127.0.0.1"},
+		{"garbage hop", trusted, "127.0.0.1:5000", map[string]string{"X-Forwarded-For": "203.0.113.9, nonsense"}, "127.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := ClientIP(tt.trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitByIPIgnoresSpoofedHeaders(t *testing.T) {
+	_, loopback, _ := net.ParseCIDR("127.0.0.1/32")
+	limit := RateLimitByIP(NewMemoryRateLimiter(), "public", model.RateLimit{Requests: 1, Per: time.Hour})
+	handler := ClientIP([]*net.IPNet{loopback})(limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	for i, spoofed := range []string{"1.1.1.1", "2.2.2.2"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/login", nil)
+		req.RemoteAddr = "127.0.0.1:5000"
+		req.Header.Set("X-Forwarded-For", spoofed+", 203.0.113.9")
+		req.Header.Set("True-Client-IP", spoofed)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if want := []int{http.StatusOK, http.StatusTooManyRequests}[i]; w.Code != want {
+			t.Errorf("request %d = %d, want %d", i+1, w.Code, want)
+		}
+	}
+}
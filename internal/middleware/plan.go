@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// PlanEnforcer checks requests against the plan of the context tenant
+type PlanEnforcer interface {
+	// CheckFeature fails with *model.FeatureUnavailableError when the plan lacks feature
+	CheckFeature(ctx context.Context, feature string) error
+	// Consume uses one unit of a metered limit or fails with *model.PlanLimitError
+	Consume(ctx context.Context, limit string) error
+}
+
+// RequireFeature answers 402 when the tenant's plan does not include feature.
+// Must run after TenantExtractor.
+func RequireFeature(plans PlanEnforcer, feature string) func(http.Handler) http.Handler {
+	return planCheck(func(ctx context.Context) error {
+		return plans.CheckFeature(ctx, feature)
+	}, "feature", feature)
+}
+
+// RequireQuota uses one unit of a metered limit per request and answers 402
+// once the period's quota is used up. Must run after TenantExtractor.
+func RequireQuota(plans PlanEnforcer, limit string) func(http.Handler) http.Handler {
+	return planCheck(func(ctx context.Context) error {
+		return plans.Consume(ctx, limit)
+	}, "limit", limit)
+}
+
+// planCheck runs check before the handler. Like rate limiting it fails open:
+// when the plan cannot be read the request passes rather than the site going down.
+func planCheck(check func(context.Context) error, kind, name string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := check(r.Context()); err != nil {
+				if PlanError(w, err) {
+					return
+				}
+				slog.Error("plan check failed", "error", err, kind, name)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PlanError writes a 402 response with an upgrade hint when err is a plan
+// limit or a feature missing from the plan, and reports whether it was
+func PlanError(w http.ResponseWriter, err error) bool {
+	var limitErr *model.PlanLimitError
+	if errors.As(err, &limitErr) {
+		WriteError(w, http.StatusPaymentRequired, limitErr.Error(), "PLAN_LIMIT_REACHED", map[string]interface{}{
+			"limit": limitErr.Limit,
+			"max":   limitErr.Max,
+			"plan":  limitErr.Plan,
+		})
+		return true
+	}
+
+	var featureErr *model.FeatureUnavailableError
+	if errors.As(err, &featureErr) {
+		WriteError(w, http.StatusPaymentRequired, featureErr.Error(), "FEATURE_NOT_IN_PLAN", map[string]interface{}{
+			"feature": featureErr.Feature,
+			"plan":    featureErr.Plan,
+		})
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// fakePlan allows quota units until used reaches quota; fail simulates an outage
+type fakePlan struct {
+	features []string
+	quota    int
+	used     int
+	fail     bool
+}
+
+func (p *fakePlan) CheckFeature(ctx context.Context, feature string) error {
+	if p.fail {
+		return errors.New("database down")
+	}
+	for _, f := range p.features {
+		if f == feature {
+			return nil
+		}
+	}
+	return &model.FeatureUnavailableError{Feature: feature, Plan: "Starter"}
+}
+
+func (p *fakePlan) Consume(ctx context.Context, limit string) error {
+	if p.fail {
+		return errors.New("database down")
+	}
+	if p.used >= p.quota {
+		return &model.PlanLimitError{Limit: limit, Max: p.quota, Plan: "Starter"}
+	}
+	p.used++
+	return nil
+}
+
+func servePlan(mw func(http.Handler) http.Handler) *httptest.ResponseRecorder {
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/admin/blog/generate-ai", nil))
+	return w
+}
+
+func TestRequireQuota(t *testing.T) {
+	plan := &fakePlan{quota: 2}
+	mw := RequireQuota(plan, model.LimitAIGenerations)
+
+	for i := 0; i < 2; i++ {
+		if w := servePlan(mw); w.Code != http.StatusOK {
+			t.Fatalf("request %d within quota = %d", i+1, w.Code)
+		}
+	}
+
+	w := servePlan(mw)
+	if w.Code != http.StatusPaymentRequired {
+		t.Fatalf("request over quota = %d, want 402", w.Code)
+	}
+	var body ErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != "PLAN_LIMIT_REACHED" || !strings.Contains(body.Error, "maksimal 2 generate AI per bulan") {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestRequireFeature(t *testing.T) {
+	plan := &fakePlan{features: []string{model.FeatureFinancing}}
+
+	if w := servePlan(RequireFeature(plan, model.FeatureFinancing)); w.Code != http.StatusOK {
+		t.Errorf("included feature = %d", w.Code)
+	}
+
+	w := servePlan(RequireFeature(plan, model.FeatureBlogAI))
+	if w.Code != http.StatusPaymentRequired || !strings.Contains(w.Body.String(), "FEATURE_NOT_IN_PLAN") {
+		t.Errorf("missing feature = %d %s", w.Code, w.Body)
+	}
+
+	// An outage of the plan store lets requests through
+	plan.fail = true
+	if w := servePlan(RequireFeature(plan, model.FeatureBlogAI)); w.Code != http.StatusOK {
+		t.Errorf("failing plan store = %d, want pass", w.Code)
+	}
+}
@@ -0,0 +1,161 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// RateLimiter takes one token from the bucket of key. When the bucket is empty
+// it returns false with the wait until a token is available.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit model.RateLimit) (bool, time.Duration, error)
+}
+
+// RateLimitByIP limits requests per client IP. Must run after ClientIP.
+func RateLimitByIP(limiter RateLimiter, name string, limit model.RateLimit) func(http.Handler) http.Handler {
+	return rateLimit(limiter, name, limit, func(r *http.Request) string {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		return "ip:" + host
+	})
+}
+
+// RateLimitByTenant limits requests per tenant. Must run after TenantExtractor.
+func RateLimitByTenant(limiter RateLimiter, name string, limit model.RateLimit) func(http.Handler) http.Handler {
+	return rateLimit(limiter, name, limit, func(r *http.Request) string {
+		tenantID, err := model.GetTenantID(r.Context())
+		if err != nil {
+			return ""
+		}
+		return "tenant:" + strconv.Itoa(tenantID)
+	})
+}
+
+// RateLimitByAPIKey limits requests per API key. Must run after RequireAPIKey.
+func RateLimitByAPIKey(limiter RateLimiter, name string, limit model.RateLimit) func(http.Handler) http.Handler {
+	return rateLimit(limiter, name, limit, func(r *http.Request) string {
+		apiKey, err := model.GetAPIKey(r.Context())
+		if err != nil {
+			return ""
+		}
+		return "key:" + strconv.Itoa(apiKey.ID)
+	})
+}
+
+// rateLimit answers 429 with Retry-After once the bucket of the request's key
+// is empty. Requests without a key pass, and so does everything if the limiter
+// fails: an outage of the shared store must not take the site down.
+func rateLimit(limiter RateLimiter, name string, limit model.RateLimit, key func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !limit.Enabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, retryAfter, err := limiter.Allow(r.Context(), name+":"+k, limit)
+			if err != nil {
+				slog.Error("rate limiter failed", "error", err, "limit", name)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !allowed {
+				seconds := int(math.Ceil(retryAfter.Seconds()))
+				if seconds < 1 {
+					seconds = 1
+				}
+				slog.Warn("rate limit exceeded", "limit", name, "key", k, "path", r.URL.Path)
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				WriteError(w, http.StatusTooManyRequests, "Terlalu banyak permintaan, silakan coba lagi nanti", "RATE_LIMITED",
+					map[string]interface{}{"retry_after": seconds})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MemoryRateLimiter keeps token buckets in process memory. Each app instance
+// counts separately; use the Postgres store to share budgets between instances.
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	fullAt  time.Time
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of key
+func (l *MemoryRateLimiter) Allow(ctx context.Context, key string, limit model.RateLimit) (bool, time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	capacity := float64(limit.Requests)
+	rate := limit.RefillRate()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, updated: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.fullAt = now.Add(time.Duration((capacity - b.tokens) / rate * float64(time.Second)))
+
+	if allowed {
+		return true, 0, nil
+	}
+	return false, time.Duration((1 - b.tokens) / rate * float64(time.Second)), nil
+}
+
+// sweep drops full buckets once a minute; they hold no state
+func (l *MemoryRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if !now.Before(b.fullAt) {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+func TestMemoryRateLimiter(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	limiter := NewMemoryRateLimiter()
+	limiter.now = func() time.Time { return now }
+	limit := model.RateLimit{Requests: 3, Per: time.Minute}
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if ok, _, _ := limiter.Allow(ctx, "a", limit); !ok {
+			t.Fatalf("request %d denied within burst", i+1)
+		}
+	}
+
+	ok, retryAfter, _ := limiter.Allow(ctx, "a", limit)
+	if ok || retryAfter != 20*time.Second {
+		t.Fatalf("4th request = %v, retry after %v; want denied, 20s", ok, retryAfter)
+	}
+
+	if ok, _, _ := limiter.Allow(ctx, "b", limit); !ok {
+		t.Error("other key shares the bucket")
+	}
+
+	now = now.Add(20 * time.Second)
+	if ok, _, _ := limiter.Allow(ctx, "a", limit); !ok {
+		t.Error("request denied after a token refilled")
+	}
+	if ok, _, _ := limiter.Allow(ctx, "a", limit); ok {
+		t.Error("refill allowed more than one request")
+	}
+}
+
+func TestRateLimitByTenant(t *testing.T) {
+	limit := RateLimitByTenant(NewMemoryRateLimiter(), "ai", model.RateLimit{Requests: 1, Per: time.Hour})
+	handler := limit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(tenantID int) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/cars/ai-generate", nil)
+		req = req.WithContext(model.WithTenantID(req.Context(), tenantID))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := request(1); w.Code != http.StatusOK {
+		t.Fatalf("first request = %d", w.Code)
+	}
+
+	w := request(1)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "3600" {
+		t.Errorf("Retry-After = %q, want 3600", got)
+	}
+
+	if w := request(2); w.Code != http.StatusOK {
+		t.Errorf("other tenant = %d, want its own budget", w.Code)
+	}
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+// CSRF token transport: HTMX and fetch send the header, plain HTML forms the field
+const (
+	CSRFHeader    = "X-CSRF-Token"
+	CSRFFormField = "csrf_token"
+)
+
+// contentSecurityPolicy allows the CDNs the templates load from. Inline scripts
+// and eval stay allowed because the admin pages use inline handlers and Alpine.js.
+var contentSecurityPolicy = strings.Join([]string{
+	"default-src 'self'",
+	"script-src 'self' 'unsafe-inline' 'unsafe-eval' https://unpkg.com https://cdn.jsdelivr.net https://cdnjs.cloudflare.com https://cdn.tailwindcss.com",
+	"style-src 'self' 'unsafe-inline' https://unpkg.com https://cdn.jsdelivr.net https://cdnjs.cloudflare.com https://fonts.googleapis.com",
+	"font-src 'self' data: https://fonts.gstatic.com https://cdn.jsdelivr.net https://cdnjs.cloudflare.com",
+	"img-src 'self' data: blob: https:",
+	"connect-src 'self'",
+	"frame-src https://www.google.com https://maps.google.com",
+	"frame-ancestors 'self'",
+	"form-action 'self'",
+	"base-uri 'self'",
+	"object-src 'none'",
+}, "; ")
+
+// SecurityHeaders sets CSP, framing and sniffing protections on every response,
+// and HSTS in production where the site is only served over HTTPS
+func SecurityHeaders(production bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("Content-Security-Policy", contentSecurityPolicy)
+			h.Set("X-Frame-Options", "SAMEORIGIN")
+			h.Set("X-Content-Type-Options", "nosniff")
+			h.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+			if production {
+				h.Set("Strict-Transport-Security", "max-age=31536000")
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// TenantOrigins returns the CORS origin check: a browser origin is allowed when
+// it is a registered domain of an active tenant and the request addresses that
+// same tenant. Outside production, localhost origins are allowed as well.
+func TenantOrigins(tenants *TenantResolver, production bool) func(r *http.Request, origin string) bool {
+	return func(r *http.Request, origin string) bool {
+		u, err := url.Parse(origin)
+		if err != nil || u.Hostname() == "" {
+			return false
+		}
+		if !production && isLocalHost(u.Hostname()) && isLocalHost(requestHost(r)) {
+			return true
+		}
+		if u.Hostname() != requestHost(r) || (production && u.Scheme != "https") {
+			return false
+		}
+
+		tenant, err := tenants.Resolve(r.Context(), u.Hostname())
+		if err != nil {
+			if !errors.Is(err, ErrTenantNotFound) {
+				slog.Error("failed to check CORS origin", "error", err, "origin", origin)
+			}
+			return false
+		}
+		return tenant.Status == model.TenantActive
+	}
+}
+
+// CSRFProtect rejects state-changing requests that a third-party page could
+// have sent with the admin's session cookie. Such requests must come from an
+// allowed origin and carry the session's CSRF token. Requests authenticated by
+// a bearer token or API key are exempt: browsers never attach those on their own.
+// The token is put in the context for templates. secret is the one signing the
+// session cookie.
+func CSRFProtect(secret string, allowOrigin func(r *http.Request, origin string) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sessionToken, hasSession := cookieSession(r, secret)
+			if hasSession {
+				r = r.WithContext(model.WithCSRFToken(r.Context(), security.CSRFToken(sessionToken, secret)))
+			}
+
+			exempt := strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") || r.Header.Get(APIKeyHeader) != ""
+			if isSafeMethod(r.Method) || exempt {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if origin := requestOrigin(r); origin != "" && !sameOrigin(r, origin) && !allowOrigin(r, origin) {
+				slog.Warn("cross-site request rejected", "origin", origin, "path", r.URL.Path)
+				Forbidden(w, "Permintaan dari situs lain ditolak")
+				return
+			}
+
+			if hasSession && !security.CheckCSRFToken(csrfTokenFromRequest(r), sessionToken, secret) {
+				Forbidden(w, "Token keamanan tidak valid. Muat ulang halaman lalu coba lagi.")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// cookieSession returns the session token of the admin cookie, if signed by us
+func cookieSession(r *http.Request, secret string) (string, bool) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return "", false
+	}
+	return security.VerifySignedValue(cookie.Value, secret)
+}
+
+func csrfTokenFromRequest(r *http.Request) string {
+	if token := r.Header.Get(CSRFHeader); token != "" {
+		return token
+	}
+	// Only url-encoded bodies: parsing multipart here would bypass the
+	// upload handlers' own size limits
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		return r.PostFormValue(CSRFFormField)
+	}
+	return ""
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// requestOrigin returns the Origin header, falling back to the Referer's origin.
+// An opaque "null" origin is returned as is and never matches.
+func requestOrigin(r *http.Request) string {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		return origin
+	}
+	if referer, err := url.Parse(r.Header.Get("Referer")); err == nil && referer.Host != "" {
+		return referer.Scheme + "://" + referer.Host
+	}
+	return ""
+}
+
+func sameOrigin(r *http.Request, origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// requestHost returns the host name of the request without its port
+func requestHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		return r.Host
+	}
+	return host
+}
+
+func isLocalHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || strings.HasSuffix(host, ".localhost")
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+func TestCSRFProtect(t *testing.T) {
+	const secret = "test-secret"
+	cookie := &http.Cookie{Name: SessionCookieName, Value: security.SignValue("session-1", secret)}
+	token := security.CSRFToken("session-1", secret)
+	noOrigins := func(r *http.Request, origin string) bool { return false }
+
+	var gotToken string
+	handler := CSRFProtect(secret, noOrigins)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotToken = model.GetCSRFToken(r.Context())
+	}))
+
+	tests := []struct {
+		name    string
+		method  string
+		cookie  bool
+		headers map[string]string
+		form    url.Values
+		want    int
+	}{
+		{name: "page load", method: http.MethodGet, cookie: true, want: http.StatusOK},
+		{name: "no session", method: http.MethodPost, want: http.StatusOK},
+		{name: "missing token", method: http.MethodPost, cookie: true, want: http.StatusForbidden},
+		{name: "wrong token", method: http.MethodDelete, cookie: true, headers: map[string]string{CSRFHeader: "forged"}, want: http.StatusForbidden},
+		{name: "header token", method: http.MethodPut, cookie: true, headers: map[string]string{CSRFHeader: token}, want: http.StatusOK},
+		{name: "form token", method: http.MethodPost, cookie: true, form: url.Values{CSRFFormField: {token}}, want: http.StatusOK},
+		{name: "bearer exempt", method: http.MethodPost, cookie: true, headers: map[string]string{"Authorization": "Bearer jwt"}, want: http.StatusOK},
+		{name: "same origin", method: http.MethodPost, cookie: true, headers: map[string]string{CSRFHeader: token, "Origin": "https://dealer.test"}, want: http.StatusOK},
+		{name: "cross origin", method: http.MethodPost, cookie: true, headers: map[string]string{CSRFHeader: token, "Origin": "https://evil.test"}, want: http.StatusForbidden},
+		{name: "cross origin login", method: http.MethodPost, headers: map[string]string{"Origin": "https://evil.test"}, want: http.StatusForbidden},
+		{name: "opaque origin", method: http.MethodPost, headers: map[string]string{"Origin": "null"}, want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "https://dealer.test/api/cars", nil)
+			if tt.form != nil {
+				req = httptest.NewRequest(tt.method, "https://dealer.test/login", strings.NewReader(tt.form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			if tt.cookie {
+				req.AddCookie(cookie)
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			gotToken = ""
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if w.Code == http.StatusOK && tt.cookie && gotToken != token {
+				t.Errorf("context token = %q, want the session's token", gotToken)
+			}
+		})
+	}
+}
@@ -2,58 +2,172 @@ package middleware
 
 import (
 	"database/sql"
+	"errors"
+	"html/template"
 	"log/slog"
+	"net"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/riz/auto-lmk/internal/model"
 )
 
-// TenantExtractor extracts tenant ID from domain and adds to context
-func TenantExtractor(db *sql.DB) func(http.Handler) http.Handler {
+// Hosts that belong to no tenant: the platform admin domain and, in
+// development, the bare local server
+var platformHosts = map[string]bool{
+	"admin.platform.com": true,
+	"admin.localhost":    true,
+	"localhost":          true,
+	"127.0.0.1":          true,
+}
+
+// DevTenantHeader and DevTenantParam choose the tenant by domain on a platform
+// host outside production, e.g.
+// curl -H "X-Tenant-Domain: showroom-jaya.localhost" localhost:8080/api/cars
+const (
+	DevTenantHeader = "X-Tenant-Domain"
+	DevTenantParam  = "tenant"
+)
+
+// TenantExtractor extracts tenant ID from domain and adds to context. Deleted
+// tenants are not found; suspended ones get the suspended page (or a JSON
+// error under /api/). Platform hosts have no tenant unless devOverride allows
+// picking one with DevTenantHeader or DevTenantParam.
+func TenantExtractor(tenants *TenantResolver, devOverride bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Extract domain from request host
-			host := r.Host
-
-			// Remove port if present
-			if colonIdx := strings.Index(host, ":"); colonIdx != -1 {
-				host = host[:colonIdx]
-			}
+			domain := requestHost(r)
 
-			// Special handling for root admin domain and localhost (development)
-			if host == "admin.localhost" || host == "admin.platform.com" || host == "localhost" {
-				// Root admin or development - try to get default tenant
-				var tenantID int
-				err := db.QueryRow("SELECT id FROM tenants WHERE status = 'active' ORDER BY id LIMIT 1").Scan(&tenantID)
-				if err == nil {
-					// Found a tenant, add to context
-					ctx := model.WithTenantID(r.Context(), tenantID)
-					next.ServeHTTP(w, r.WithContext(ctx))
+			if platformHosts[domain] {
+				domain = ""
+				if devOverride {
+					domain = devTenantDomain(r)
+				}
+				if domain == "" {
+					// Root admin or development without a chosen tenant
+					next.ServeHTTP(w, r)
 					return
 				}
-				// No tenant found, continue without tenant context
-				next.ServeHTTP(w, r)
-				return
 			}
 
-			// Look up tenant by domain
-			var tenantID int
-			err := db.QueryRow("SELECT id FROM tenants WHERE domain = $1 AND status = 'active'", host).Scan(&tenantID)
+			tenant, err := tenants.Resolve(r.Context(), domain)
 			if err != nil {
-				if err == sql.ErrNoRows {
-					slog.Warn("tenant not found", "domain", host)
+				if errors.Is(err, ErrTenantNotFound) {
+					slog.Warn("tenant not found", "domain", domain)
 					http.Error(w, "Tenant not found", http.StatusNotFound)
 					return
 				}
-				slog.Error("failed to lookup tenant", "error", err, "domain", host)
+				slog.Error("failed to lookup tenant", "error", err, "domain", domain)
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
 
+			if tenant.Status == model.TenantSuspended {
+				tenantSuspended(w, r, tenants.db, tenant.ID)
+				return
+			}
+
 			// Add tenant ID to context
-			ctx := model.WithTenantID(r.Context(), tenantID)
+			ctx := model.WithTenantID(r.Context(), tenant.ID)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// devTenantDomain returns the tenant domain a development request asks for
+func devTenantDomain(r *http.Request) string {
+	if domain := r.Header.Get(DevTenantHeader); domain != "" {
+		return strings.TrimSpace(domain)
+	}
+	return strings.TrimSpace(r.URL.Query().Get(DevTenantParam))
+}
+
+// CanonicalRedirect sends GET and HEAD requests on a tenant's other domains to
+// its canonical domain, so the storefront is indexed under one name. Use it on
+// public pages only: the admin panel and API answer on every domain, so a
+// canonical domain whose DNS breaks cannot lock the tenant out.
+func CanonicalRedirect(tenants *TenantResolver, production bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			host := requestHost(r)
+			if (r.Method != http.MethodGet && r.Method != http.MethodHead) || platformHosts[host] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tenant, err := tenants.Resolve(r.Context(), host)
+			if err != nil || tenant.Canonical == "" || tenant.Canonical == host {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			target := "http://" + tenant.Canonical
+			if production || r.TLS != nil {
+				target = "https://" + tenant.Canonical
+			}
+			if _, port, err := net.SplitHostPort(r.Host); err == nil && !production {
+				target += ":" + port
+			}
+
+			// Browsers keep a 301 for good; let them ask again should the canonical domain change
+			w.Header().Set("Cache-Control", "public, max-age=3600")
+			http.Redirect(w, r, target+r.URL.RequestURI(), http.StatusMovedPermanently)
+		})
+	}
+}
+
+var (
+	suspendedOnce     sync.Once
+	suspendedTemplate *template.Template
+)
+
+// suspendedPage is what a suspended tenant's visitors see, branded with the
+// tenant's logo, title and contact details
+type suspendedPage struct {
+	Title    string
+	LogoPath string
+	Phone    string
+	Email    string
+}
+
+// tenantSuspended answers a request for a suspended tenant
+func tenantSuspended(w http.ResponseWriter, r *http.Request, db *sql.DB, tenantID int) {
+	if strings.HasPrefix(r.URL.Path, "/api/") {
+		WriteError(w, http.StatusForbidden, "Layanan showroom ini sedang dinonaktifkan", "TENANT_SUSPENDED", nil)
+		return
+	}
+
+	suspendedOnce.Do(func() {
+		tmpl, err := template.ParseFiles("templates/pages/suspended.html")
+		if err != nil {
+			slog.Error("failed to parse suspended page template", "error", err)
+			return
+		}
+		suspendedTemplate = tmpl
+	})
+
+	var title, logoPath, phone, email sql.NullString
+	err := db.QueryRowContext(model.WithTenantID(r.Context(), tenantID), `
+		SELECT COALESCE(b.custom_title, t.name), b.logo_path, s.phone, s.email
+		FROM tenants t
+		LEFT JOIN tenant_branding b ON b.tenant_id = t.id
+		LEFT JOIN showroom_settings s ON s.tenant_id = t.id
+		WHERE t.id = $1
+	`, tenantID).Scan(&title, &logoPath, &phone, &email)
+	if err != nil && err != sql.ErrNoRows {
+		slog.Error("failed to load branding for suspended page", "error", err, "tenant_id", tenantID)
+	}
+	page := suspendedPage{Title: title.String, LogoPath: logoPath.String, Phone: phone.String, Email: email.String}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if suspendedTemplate == nil {
+		http.Error(w, "Website sedang tidak aktif", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	if err := suspendedTemplate.Execute(w, page); err != nil {
+		slog.Error("failed to render suspended page", "error", err, "tenant_id", tenantID)
+	}
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// ErrTenantNotFound means no active or suspended tenant uses the domain, as
+// platform domain or verified custom domain
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// maxTenantCacheEntries bounds the cache, which also remembers unknown
+// domains and so grows with whatever Host headers clients send
+const maxTenantCacheEntries = 10000
+
+// ResolvedTenant is the tenant serving a domain
+type ResolvedTenant struct {
+	ID        int
+	Status    string
+	Canonical string // the domain the tenant's other domains redirect to
+}
+
+// tenantLookupQuery finds the tenant by its platform domain or a verified
+// custom domain. tenant_domains is read across tenants, so it runs in system
+// scope.
+const tenantLookupQuery = `
+	SELECT t.id, t.status, COALESCE(c.domain, t.domain)
+	FROM tenants t
+	LEFT JOIN tenant_domains c ON c.tenant_id = t.id AND c.is_canonical
+	WHERE t.status <> 'deleted' AND (
+		t.domain = $1
+		OR t.id IN (SELECT tenant_id FROM tenant_domains WHERE domain = $1 AND verified_at IS NOT NULL)
+	)
+	ORDER BY t.domain = $1 DESC
+	LIMIT 1`
+
+// TenantResolver maps request domains to tenants, caching each answer
+// (including "not found") in process memory for ttl. Each app instance caches
+// separately: changes made through TenantService invalidate this instance at
+// once, other instances pick them up when the entry expires. A zero ttl
+// disables the cache.
+type TenantResolver struct {
+	db     *sql.DB
+	ttl    time.Duration
+	lookup func(ctx context.Context, domain string) (ResolvedTenant, error)
+	now    func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string]tenantCacheEntry
+	gen     uint64 // bumped by Invalidate so lookups racing it are not cached
+}
+
+type tenantCacheEntry struct {
+	tenant  ResolvedTenant
+	found   bool
+	expires time.Time
+}
+
+func NewTenantResolver(db *sql.DB, ttl time.Duration) *TenantResolver {
+	return newTenantResolver(db, ttl, func(ctx context.Context, domain string) (ResolvedTenant, error) {
+		var t ResolvedTenant
+		err := db.QueryRowContext(model.WithSystemScope(ctx), tenantLookupQuery, domain).Scan(&t.ID, &t.Status, &t.Canonical)
+		if err == sql.ErrNoRows {
+			return t, ErrTenantNotFound
+		}
+		if err != nil {
+			return t, fmt.Errorf("failed to lookup tenant: %w", err)
+		}
+		return t, nil
+	})
+}
+
+func newTenantResolver(db *sql.DB, ttl time.Duration, lookup func(context.Context, string) (ResolvedTenant, error)) *TenantResolver {
+	return &TenantResolver{
+		db:      db,
+		ttl:     ttl,
+		lookup:  lookup,
+		now:     time.Now,
+		entries: make(map[string]tenantCacheEntry),
+	}
+}
+
+// Resolve returns the tenant using domain, or ErrTenantNotFound
+func (c *TenantResolver) Resolve(ctx context.Context, domain string) (ResolvedTenant, error) {
+	now := c.now()
+
+	c.mu.RLock()
+	entry, ok := c.entries[domain]
+	gen := c.gen
+	c.mu.RUnlock()
+
+	if ok && now.Before(entry.expires) {
+		if !entry.found {
+			return ResolvedTenant{}, ErrTenantNotFound
+		}
+		return entry.tenant, nil
+	}
+
+	tenant, err := c.lookup(ctx, domain)
+	if err != nil && !errors.Is(err, ErrTenantNotFound) {
+		// Database errors are not cached
+		return ResolvedTenant{}, err
+	}
+
+	if c.ttl > 0 {
+		c.store(domain, tenantCacheEntry{tenant: tenant, found: err == nil, expires: now.Add(c.ttl)}, gen, now)
+	}
+	return tenant, err
+}
+
+func (c *TenantResolver) store(domain string, entry tenantCacheEntry, gen uint64, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gen != gen {
+		// The tenants changed while the lookup ran; its answer may be stale
+		return
+	}
+
+	if len(c.entries) >= maxTenantCacheEntries {
+		for d, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, d)
+			}
+		}
+		if len(c.entries) >= maxTenantCacheEntries {
+			clear(c.entries)
+		}
+	}
+	c.entries[domain] = entry
+}
+
+// Invalidate forgets the domains of tenantID, plus every domain cached as
+// unknown since a created or renamed tenant may now use it
+func (c *TenantResolver) Invalidate(tenantID int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.gen++
+	for domain, entry := range c.entries {
+		if !entry.found || entry.tenant.ID == tenantID {
+			delete(c.entries, domain)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// fakeTenants answers lookups from a domain map and counts them
+type fakeTenants struct {
+	domains map[string]ResolvedTenant
+	lookups int
+}
+
+func (f *fakeTenants) lookup(ctx context.Context, domain string) (ResolvedTenant, error) {
+	f.lookups++
+	t, ok := f.domains[domain]
+	if !ok {
+		return ResolvedTenant{}, ErrTenantNotFound
+	}
+	return t, nil
+}
+
+func newFakeTenants() *fakeTenants {
+	return &fakeTenants{domains: map[string]ResolvedTenant{
+		"jaya.localhost":    {ID: 1, Status: model.TenantActive},
+		"makmur.co.id":      {ID: 2, Status: model.TenantActive},
+		"tutup.localhost":   {ID: 3, Status: model.TenantSuspended},
+		"sentosa.localhost": {ID: 5, Status: model.TenantActive, Canonical: "sentosa.com"},
+		"www.sentosa.com":   {ID: 5, Status: model.TenantActive, Canonical: "sentosa.com"},
+		"sentosa.com":       {ID: 5, Status: model.TenantActive, Canonical: "sentosa.com"},
+	}}
+}
+
+// serveTenant returns the status and the tenant ID the handler saw (0 for none)
+func serveTenant(mw func(http.Handler) http.Handler, r *http.Request) (int, int) {
+	var tenantID int
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID, _ = model.GetTenantID(r.Context())
+	}))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w.Code, tenantID
+}
+
+func TestTenantResolverCache(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	fake := newFakeTenants()
+	tenants := newTenantResolver(nil, time.Minute, fake.lookup)
+	tenants.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if tenant, err := tenants.Resolve(ctx, "jaya.localhost"); err != nil || tenant.ID != 1 {
+			t.Fatalf("resolve = %+v, %v", tenant, err)
+		}
+		if _, err := tenants.Resolve(ctx, "unknown.localhost"); err != ErrTenantNotFound {
+			t.Fatalf("unknown domain = %v", err)
+		}
+	}
+	if fake.lookups != 2 {
+		t.Errorf("lookups = %d, want 2 (one per domain)", fake.lookups)
+	}
+
+	// Suspending tenant 1 and registering the unknown domain take effect at once
+	fake.domains["jaya.localhost"] = ResolvedTenant{ID: 1, Status: model.TenantSuspended}
+	fake.domains["unknown.localhost"] = ResolvedTenant{ID: 4, Status: model.TenantActive}
+	tenants.Invalidate(1)
+	if tenant, _ := tenants.Resolve(ctx, "jaya.localhost"); tenant.Status != model.TenantSuspended {
+		t.Errorf("after invalidate status = %q", tenant.Status)
+	}
+	if tenant, err := tenants.Resolve(ctx, "unknown.localhost"); err != nil || tenant.ID != 4 {
+		t.Errorf("new domain after invalidate = %+v, %v", tenant, err)
+	}
+
+	// Other tenants stay cached until the TTL is over
+	tenants.Resolve(ctx, "makmur.co.id")
+	fake.domains["makmur.co.id"] = ResolvedTenant{ID: 2, Status: model.TenantSuspended}
+	tenants.Invalidate(1)
+	if tenant, _ := tenants.Resolve(ctx, "makmur.co.id"); tenant.Status != model.TenantActive {
+		t.Error("invalidating one tenant dropped another")
+	}
+	now = now.Add(time.Minute)
+	if tenant, _ := tenants.Resolve(ctx, "makmur.co.id"); tenant.Status != model.TenantSuspended {
+		t.Error("entry served after its TTL")
+	}
+}
+
+func TestTenantExtractor(t *testing.T) {
+	tenants := newTenantResolver(nil, time.Minute, newFakeTenants().lookup)
+	dev := TenantExtractor(tenants, true)
+	prod := TenantExtractor(tenants, false)
+
+	header := httptest.NewRequest(http.MethodGet, "http://localhost:8080/api/cars", nil)
+	header.Header.Set(DevTenantHeader, "makmur.co.id")
+
+	suspended := httptest.NewRequest(http.MethodGet, "http://tutup.localhost/api/cars", nil)
+
+	tests := []struct {
+		name       string
+		mw         func(http.Handler) http.Handler
+		r          *http.Request
+		wantStatus int
+		wantTenant int
+	}{
+		{"tenant domain", dev, httptest.NewRequest(http.MethodGet, "http://jaya.localhost:8080/", nil), http.StatusOK, 1},
+		{"unknown domain", prod, httptest.NewRequest(http.MethodGet, "http://nowhere.com/", nil), http.StatusNotFound, 0},
+		{"suspended tenant", prod, suspended, http.StatusForbidden, 0},
+		{"localhost without override", dev, httptest.NewRequest(http.MethodGet, "http://localhost:8080/", nil), http.StatusOK, 0},
+		{"override header", dev, header, http.StatusOK, 2},
+		{"override query", dev, httptest.NewRequest(http.MethodGet, "http://localhost:8080/?tenant=jaya.localhost", nil), http.StatusOK, 1},
+		{"override unknown tenant", dev, httptest.NewRequest(http.MethodGet, "http://localhost:8080/?tenant=nowhere.com", nil), http.StatusNotFound, 0},
+		{"override ignored in production", prod, header, http.StatusOK, 0},
+		{"override ignored on tenant domain", dev, httptest.NewRequest(http.MethodGet, "http://jaya.localhost/?tenant=makmur.co.id", nil), http.StatusOK, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, tenantID := serveTenant(tt.mw, tt.r)
+			if status != tt.wantStatus || tenantID != tt.wantTenant {
+				t.Errorf("got %d with tenant %d, want %d with tenant %d", status, tenantID, tt.wantStatus, tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestCanonicalRedirect(t *testing.T) {
+	tenants := newTenantResolver(nil, time.Minute, newFakeTenants().lookup)
+
+	tests := []struct {
+		name       string
+		production bool
+		method     string
+		url        string
+		want       string // redirect target; "" serves the page
+	}{
+		{"other domain", true, http.MethodGet, "http://www.sentosa.com/mobil?page=2", "https://sentosa.com/mobil?page=2"},
+		{"platform domain in development", false, http.MethodGet, "http://sentosa.localhost:8080/", "http://sentosa.com:8080/"},
+		{"canonical domain", true, http.MethodGet, "https://sentosa.com/mobil", ""},
+		{"form post", true, http.MethodPost, "http://www.sentosa.com/tukar-tambah", ""},
+		{"no custom canonical", true, http.MethodGet, "http://jaya.localhost/", ""},
+		{"platform host", false, http.MethodGet, "http://localhost:8080/?tenant=www.sentosa.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := CanonicalRedirect(tenants, tt.production)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+			if tt.want == "" {
+				if w.Code != http.StatusOK {
+					t.Errorf("status = %d, want page served", w.Code)
+				}
+				return
+			}
+			if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != tt.want {
+				t.Errorf("got %d to %q, want 301 to %q", w.Code, w.Header().Get("Location"), tt.want)
+			}
+		})
+	}
+}
+
+// benchmarkTenantResolver measures the resolver's own cost over 100 domains.
+// The database round trip it saves is not simulated: with TTL 0 every op is a
+// lookup, and hit% shows how many of them the cache answers.
+func benchmarkTenantResolver(b *testing.B, ttl time.Duration) {
+	fake := newFakeTenants()
+	domains := make([]string, 100)
+	for i := range domains {
+		domains[i] = "showroom" + strconv.Itoa(i) + ".co.id"
+		fake.domains[domains[i]] = ResolvedTenant{ID: 10 + i, Status: model.TenantActive}
+	}
+	tenants := newTenantResolver(nil, ttl, fake.lookup)
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := tenants.Resolve(ctx, domains[i%len(domains)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(100*(1-float64(fake.lookups)/float64(b.N)), "hit%")
+}
+
+func BenchmarkTenantResolver_Uncached(b *testing.B) {
+	benchmarkTenantResolver(b, 0)
+}
+
+func BenchmarkTenantResolver_Cached(b *testing.B) {
+	benchmarkTenantResolver(b, 30*time.Second)
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// API key scopes granted to integrations such as a dealer's DMS
+const (
+	ScopeCarsRead          = "cars:read"
+	ScopeCarsWrite         = "cars:write"
+	ScopeLeadsRead         = "leads:read"
+	ScopeLeadsWrite        = "leads:write"
+	ScopeConversationsRead = "conversations:read"
+)
+
+// AllAPIScopes lists every scope an API key can hold
+var AllAPIScopes = []string{
+	ScopeCarsRead, ScopeCarsWrite, ScopeLeadsRead, ScopeLeadsWrite, ScopeConversationsRead,
+}
+
+// APIKey authenticates a tenant's integration against /api/v1. Only a hash of
+// the key is stored; Prefix identifies it in the admin panel.
+type APIKey struct {
+	ID         int        `json:"id"`
+	TenantID   int        `json:"tenant_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	KeyHash    string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *int       `json:"created_by,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope reports whether the key was granted scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Label identifies the key in audit entries without revealing it
+func (k *APIKey) Label() string {
+	return k.Name + " (" + k.Prefix + ")"
+}
+
+// CreateAPIKeyRequest represents a new API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// Validate checks the create API key request
+func (r *CreateAPIKeyRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("Nama API key wajib diisi")
+	}
+	if len(r.Scopes) == 0 {
+		return errors.New("Pilih minimal satu scope")
+	}
+	for _, scope := range r.Scopes {
+		if !isAPIScope(scope) {
+			return errors.New("Scope tidak dikenal: " + scope)
+		}
+	}
+	if r.ExpiresAt != nil && r.ExpiresAt.Before(time.Now()) {
+		return errors.New("Tanggal kedaluwarsa harus di masa depan")
+	}
+	return nil
+}
+
+func isAPIScope(scope string) bool {
+	for _, s := range AllAPIScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+const apiKeyKey contextKey = "api_key"
+
+// WithAPIKey adds the API key authenticating the request to context
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyKey, key)
+}
+
+// GetAPIKey retrieves the API key authenticating the request from context
+func GetAPIKey(ctx context.Context) (*APIKey, error) {
+	key, ok := ctx.Value(apiKeyKey).(*APIKey)
+	if !ok {
+		return nil, errors.New("API key not found in context")
+	}
+	return key, nil
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Audit actor types
+const (
+	AuditActorUser   = "user"
+	AuditActorSales  = "sales"
+	AuditActorSystem = "system"
+	AuditActorAPIKey = "api_key"
+)
+
+// AuditLog records who changed what on a tenant's data
+type AuditLog struct {
+	ID         int                    `json:"id"`
+	TenantID   int                    `json:"tenant_id"`
+	ActorType  string                 `json:"actor_type"` // user, sales, system, api_key
+	Actor      string                 `json:"actor"`      // user email, sales phone, job name, or API key label
+	EntityType string                 `json:"entity_type"`
+	EntityID   *int                   `json:"entity_id,omitempty"`
+	Action     string                 `json:"action"`
+	Before     map[string]interface{} `json:"before,omitempty"`
+	After      map[string]interface{} `json:"after,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// AuditLogFilter narrows the audit log list. Empty fields match everything.
+type AuditLogFilter struct {
+	Search     string // matches actor, entity type or action
+	EntityType string
+	EntityID   *int
+	ActorType  string
+	Action     string
+	From       *time.Time
+	To         *time.Time // exclusive
+	Limit      int
+	Offset     int
+}
+
+type auditActor struct {
+	actorType string
+	actor     string
+}
+
+const auditActorKey contextKey = "audit_actor"
+
+// WithAuditActor attributes changes made with ctx to an actor that is not a
+// logged-in user, such as a sales phone number talking to the bot
+func WithAuditActor(ctx context.Context, actorType, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey, auditActor{actorType: actorType, actor: actor})
+}
+
+// GetAuditActor returns who changes made with ctx are attributed to: the logged-in
+// user, an actor set with WithAuditActor, or the system
+func GetAuditActor(ctx context.Context) (actorType, actor string) {
+	if user, err := GetUser(ctx); err == nil {
+		return AuditActorUser, user.Email
+	}
+	if a, ok := ctx.Value(auditActorKey).(auditActor); ok {
+		return a.actorType, a.actor
+	}
+	return AuditActorSystem, AuditActorSystem
+}
+
+// auditIgnoredFields change on every write and would only add noise to a diff
+var auditIgnoredFields = map[string]bool{"updated_at": true}
+
+// AuditDiff reduces two snapshots of an entity to the fields that changed. A nil
+// before (create) or after (delete) keeps the other snapshot whole.
+func AuditDiff(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	if before == nil || after == nil {
+		return before, after
+	}
+
+	changedBefore := map[string]interface{}{}
+	changedAfter := map[string]interface{}{}
+	for key, value := range after {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if old, ok := before[key]; !ok || !reflect.DeepEqual(old, value) {
+			changedBefore[key] = before[key]
+			changedAfter[key] = value
+		}
+	}
+	for key, value := range before {
+		if _, ok := after[key]; !ok && !auditIgnoredFields[key] {
+			changedBefore[key] = value
+		}
+	}
+
+	return changedBefore, changedAfter
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Tones the bot can take with customers
+const (
+	BotToneFriendly = "ramah"
+	BotToneFormal   = "formal"
+	BotToneCasual   = "santai"
+)
+
+// Bot persona limits, keeping the system prompt a reasonable size
+const (
+	MaxBotRules        = 20
+	maxBotRuleLength   = 300
+	maxBotGreeting     = 500
+	maxBotInstructions = 2000
+	maxBotPersonaName  = 50
+)
+
+// BotPersona is how a tenant's WhatsApp bot presents itself to customers: its
+// name, tone, the greeting opening a new conversation and the showroom's
+// rules. Empty fields keep the default behaviour.
+type BotPersona struct {
+	Name         string   `json:"name"`
+	Tone         string   `json:"tone"`
+	Greeting     string   `json:"greeting"`
+	Rules        []string `json:"rules"`
+	Instructions string   `json:"instructions"`
+
+	// ShowroomName is the tenant's name, which the bot says it works for
+	ShowroomName string `json:"showroom_name"`
+}
+
+// BotPersonaVersion is a saved persona. Every change adds a version, so an
+// earlier one can be restored.
+type BotPersonaVersion struct {
+	BotPersona
+	Version      int       `json:"version"`
+	RestoredFrom *int      `json:"restored_from,omitempty"`
+	CreatedBy    string    `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Validate normalizes the persona and checks the lengths and tone
+func (p *BotPersona) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	p.Tone = strings.TrimSpace(p.Tone)
+	p.Greeting = strings.TrimSpace(p.Greeting)
+	p.Instructions = strings.TrimSpace(p.Instructions)
+
+	rules := make([]string, 0, len(p.Rules))
+	for _, rule := range p.Rules {
+		if rule = strings.TrimSpace(rule); rule != "" {
+			rules = append(rules, rule)
+		}
+	}
+	p.Rules = rules
+
+	switch p.Tone {
+	case "":
+		p.Tone = BotToneFriendly
+	case BotToneFriendly, BotToneFormal, BotToneCasual:
+	default:
+		return errors.New("Gaya bahasa harus ramah, formal atau santai")
+	}
+
+	switch {
+	case len([]rune(p.Name)) > maxBotPersonaName:
+		return errors.New("Nama bot maksimal 50 karakter")
+	case len([]rune(p.Greeting)) > maxBotGreeting:
+		return errors.New("Sapaan maksimal 500 karakter")
+	case len([]rune(p.Instructions)) > maxBotInstructions:
+		return errors.New("Instruksi tambahan maksimal 2000 karakter")
+	case len(p.Rules) > MaxBotRules:
+		return errors.New("Maksimal 20 aturan")
+	}
+	for _, rule := range p.Rules {
+		if len([]rune(rule)) > maxBotRuleLength {
+			return errors.New("Setiap aturan maksimal 300 karakter")
+		}
+	}
+	return nil
+}
+
+// GreetingText returns the greeting with {nama} and {showroom} filled in
+func (p *BotPersona) GreetingText() string {
+	name := p.Name
+	if name == "" {
+		name = "asisten virtual"
+	}
+	return strings.NewReplacer("{nama}", name, "{showroom}", p.ShowroomName).Replace(p.Greeting)
+}
+
+// BotPreviewRequest tries a draft persona on a sample message
+type BotPreviewRequest struct {
+	Persona BotPersona `json:"persona"`
+	Message string     `json:"message"`
+	IsSales bool       `json:"is_sales"`
+}
@@ -0,0 +1,345 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// What the WhatsApp bot does with customer messages while the showroom is closed
+const (
+	AfterHoursBotOnly   = "bot_only"   // the bot answers, knowing no sales person is around
+	AfterHoursAutoReply = "auto_reply" // one fixed reply per closed period, a lead is queued for sales
+	AfterHoursSilent    = "silent"     // messages are stored without a reply
+)
+
+// Indonesian time zones. None of them observes daylight saving time, so fixed
+// offsets work without the tz database on the host.
+var businessTimezones = map[string]int{
+	"WIB":  7,
+	"WITA": 8,
+	"WIT":  9,
+}
+
+// DefaultBusinessTimezone is used when the tenant did not choose one
+const DefaultBusinessTimezone = "WIB"
+
+// Business hours limits, keeping the settings a reasonable size
+const (
+	maxOpeningPeriods     = 21
+	maxHolidays           = 60
+	maxHolidayName        = 100
+	maxAfterHoursMessage  = 1000
+	businessHoursLookDays = 62 // how far NextOpening and LastClosing search
+)
+
+// dayNames are the Indonesian names of time.Weekday values
+var dayNames = [...]string{"Minggu", "Senin", "Selasa", "Rabu", "Kamis", "Jumat", "Sabtu"}
+
+// OpeningPeriod is a time range the showroom is open on a day of the week.
+// A day can have several periods, e.g. around a Friday prayer break.
+type OpeningPeriod struct {
+	Day   time.Weekday `json:"day"`
+	Open  string       `json:"open"`  // HH:MM
+	Close string       `json:"close"` // HH:MM, after Open on the same day
+}
+
+// Holiday is a date the showroom is closed all day
+type Holiday struct {
+	Date string `json:"date"` // YYYY-MM-DD
+	Name string `json:"name"`
+}
+
+// BusinessHours is a showroom's weekly schedule with its holidays, in the
+// showroom's time zone
+type BusinessHours struct {
+	Timezone string          `json:"timezone"`
+	Weekly   []OpeningPeriod `json:"weekly"`
+	Holidays []Holiday       `json:"holidays"`
+}
+
+// Validate normalizes the schedule, sorting periods and holidays, and checks
+// the times, dates and time zone
+func (h *BusinessHours) Validate() error {
+	h.Timezone = strings.ToUpper(strings.TrimSpace(h.Timezone))
+	if h.Timezone == "" {
+		h.Timezone = DefaultBusinessTimezone
+	}
+	if _, ok := businessTimezones[h.Timezone]; !ok {
+		return errors.New("Zona waktu harus WIB, WITA atau WIT")
+	}
+
+	if len(h.Weekly) > maxOpeningPeriods {
+		return errors.New("Maksimal 21 jam buka per minggu")
+	}
+	for i := range h.Weekly {
+		p := &h.Weekly[i]
+		if p.Day < time.Sunday || p.Day > time.Saturday {
+			return errors.New("Hari tidak valid")
+		}
+		opens, okOpen := clockMinutes(p.Open)
+		closes, okClose := clockMinutes(p.Close)
+		if !okOpen || !okClose {
+			return fmt.Errorf("Jam %s harus berformat JJ:MM", dayNames[p.Day])
+		}
+		if closes <= opens {
+			return fmt.Errorf("Jam tutup %s harus setelah jam buka", dayNames[p.Day])
+		}
+	}
+	sort.Slice(h.Weekly, func(i, j int) bool {
+		if h.Weekly[i].Day != h.Weekly[j].Day {
+			return h.Weekly[i].Day < h.Weekly[j].Day
+		}
+		return h.Weekly[i].Open < h.Weekly[j].Open
+	})
+	for i := 1; i < len(h.Weekly); i++ {
+		prev, p := h.Weekly[i-1], h.Weekly[i]
+		if prev.Day == p.Day && p.Open < prev.Close {
+			return fmt.Errorf("Jam buka %s saling tumpang tindih", dayNames[p.Day])
+		}
+	}
+
+	if len(h.Holidays) > maxHolidays {
+		return errors.New("Maksimal 60 hari libur")
+	}
+	seen := make(map[string]bool, len(h.Holidays))
+	for i := range h.Holidays {
+		d := &h.Holidays[i]
+		d.Name = strings.TrimSpace(d.Name)
+		if _, err := time.Parse("2006-01-02", d.Date); err != nil {
+			return errors.New("Tanggal libur harus berformat YYYY-MM-DD")
+		}
+		if len([]rune(d.Name)) > maxHolidayName {
+			return errors.New("Nama hari libur maksimal 100 karakter")
+		}
+		if seen[d.Date] {
+			return errors.New("Tanggal libur " + d.Date + " tercantum dua kali")
+		}
+		seen[d.Date] = true
+	}
+	sort.Slice(h.Holidays, func(i, j int) bool { return h.Holidays[i].Date < h.Holidays[j].Date })
+	return nil
+}
+
+// Configured reports whether the showroom set its opening hours. Without them
+// the showroom's availability is unknown.
+func (h *BusinessHours) Configured() bool {
+	return h != nil && len(h.Weekly) > 0
+}
+
+// Location returns the showroom's time zone; WIB when no hours are set
+func (h *BusinessHours) Location() *time.Location {
+	var name string
+	if h != nil {
+		name = h.Timezone
+	}
+	offset, ok := businessTimezones[name]
+	if !ok {
+		name, offset = DefaultBusinessTimezone, businessTimezones[DefaultBusinessTimezone]
+	}
+	return time.FixedZone(name, offset*3600)
+}
+
+// HolidayOn returns the holiday on t's date in the showroom's time zone, if any
+func (h *BusinessHours) HolidayOn(t time.Time) *Holiday {
+	date := t.In(h.Location()).Format("2006-01-02")
+	for i := range h.Holidays {
+		if h.Holidays[i].Date == date {
+			return &h.Holidays[i]
+		}
+	}
+	return nil
+}
+
+// IsOpen reports whether the showroom is open at t
+func (h *BusinessHours) IsOpen(t time.Time) bool {
+	if !h.Configured() || h.HolidayOn(t) != nil {
+		return false
+	}
+	local := t.In(h.Location())
+	minute := local.Hour()*60 + local.Minute()
+	for _, p := range h.Weekly {
+		opens, _ := clockMinutes(p.Open)
+		closes, _ := clockMinutes(p.Close)
+		if p.Day == local.Weekday() && minute >= opens && minute < closes {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpening returns when the showroom opens next after t, false when it has
+// no opening in the coming weeks
+func (h *BusinessHours) NextOpening(t time.Time) (time.Time, bool) {
+	return h.search(t, 1, func(opens, closes time.Time) (time.Time, bool) {
+		return opens, opens.After(t)
+	})
+}
+
+// LastClosing returns when the showroom closed last at or before t, false when
+// it was not open in the past weeks
+func (h *BusinessHours) LastClosing(t time.Time) (time.Time, bool) {
+	return h.search(t, -1, func(opens, closes time.Time) (time.Time, bool) {
+		return closes, !closes.After(t)
+	})
+}
+
+// search walks the opening periods day by day from t's date in direction (1
+// forwards, -1 backwards), skipping holidays, and returns the first time match
+// accepts
+func (h *BusinessHours) search(t time.Time, direction int, match func(opens, closes time.Time) (time.Time, bool)) (time.Time, bool) {
+	if !h.Configured() {
+		return time.Time{}, false
+	}
+	local := t.In(h.Location())
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+
+	for i := 0; i <= businessHoursLookDays; i++ {
+		day := midnight.AddDate(0, 0, i*direction)
+		if h.HolidayOn(day) != nil {
+			continue
+		}
+
+		periods := h.periodsOn(day.Weekday())
+		if direction < 0 {
+			for l, r := 0, len(periods)-1; l < r; l, r = l+1, r-1 {
+				periods[l], periods[r] = periods[r], periods[l]
+			}
+		}
+		for _, p := range periods {
+			opens, _ := clockMinutes(p.Open)
+			closes, _ := clockMinutes(p.Close)
+			if found, ok := match(day.Add(time.Duration(opens)*time.Minute), day.Add(time.Duration(closes)*time.Minute)); ok {
+				return found, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// periodsOn returns the opening periods of a weekday, earliest first
+func (h *BusinessHours) periodsOn(day time.Weekday) []OpeningPeriod {
+	var periods []OpeningPeriod
+	for _, p := range h.Weekly {
+		if p.Day == day {
+			periods = append(periods, p)
+		}
+	}
+	return periods
+}
+
+// Describe lists the opening hours per day, Monday first, for customers
+func (h *BusinessHours) Describe() string {
+	if !h.Configured() {
+		return ""
+	}
+	lines := make([]string, 0, 7)
+	for i := 1; i <= 7; i++ {
+		day := time.Weekday(i % 7)
+		ranges := []string{}
+		for _, p := range h.periodsOn(day) {
+			ranges = append(ranges, p.Open+" - "+p.Close)
+		}
+		if len(ranges) == 0 {
+			ranges = append(ranges, "Tutup")
+		}
+		lines = append(lines, dayNames[day]+": "+strings.Join(ranges, ", "))
+	}
+	return strings.Join(lines, "\n") + "\n(" + h.Location().String() + ")"
+}
+
+// FormatOpening describes when the showroom opens from now's point of view,
+// e.g. "hari ini pukul 09:00 WIB" or "Senin, 5 Januari pukul 09:00 WIB"
+func (h *BusinessHours) FormatOpening(opening, now time.Time) string {
+	loc := h.Location()
+	opening, now = opening.In(loc), now.In(loc)
+	clock := opening.Format("15:04") + " " + loc.String()
+
+	switch {
+	case sameDate(opening, now):
+		return "hari ini pukul " + clock
+	case sameDate(opening, now.AddDate(0, 0, 1)):
+		return "besok pukul " + clock
+	}
+	return fmt.Sprintf("%s, %d %s pukul %s", dayNames[opening.Weekday()], opening.Day(), monthNames[opening.Month()-1], clock)
+}
+
+var monthNames = [...]string{"Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli",
+	"Agustus", "September", "Oktober", "November", "Desember"}
+
+func sameDate(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// clockMinutes parses an HH:MM time of day into minutes after midnight
+func clockMinutes(clock string) (int, bool) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// DefaultAfterHoursMessage is the auto-reply when the showroom wrote none.
+// {buka} becomes when the showroom opens next.
+const DefaultAfterHoursMessage = "Terima kasih sudah menghubungi kami. Saat ini showroom sedang tutup dan buka kembali {buka}. " +
+	"Pesan Anda sudah kami terima, tim sales akan membalas setelah showroom buka."
+
+// Validate normalizes the update and checks the business hours and after-hours behaviour
+func (r *ShowroomUpdateRequest) Validate() error {
+	if r.Hours != nil {
+		if err := r.Hours.Validate(); err != nil {
+			return err
+		}
+	}
+
+	r.AfterHoursMessage = strings.TrimSpace(r.AfterHoursMessage)
+	switch r.AfterHoursMode {
+	case "":
+		r.AfterHoursMode = AfterHoursBotOnly
+	case AfterHoursBotOnly, AfterHoursAutoReply, AfterHoursSilent:
+	default:
+		return errors.New("Perilaku di luar jam operasional tidak dikenal")
+	}
+	if len([]rune(r.AfterHoursMessage)) > maxAfterHoursMessage {
+		return errors.New("Pesan di luar jam operasional maksimal 1000 karakter")
+	}
+	return nil
+}
+
+// BusinessHoursText describes the opening hours for customers, the legacy free
+// text until the showroom sets structured hours
+func (s *ShowroomSettings) BusinessHoursText() string {
+	if s.Hours.Configured() {
+		return s.Hours.Describe()
+	}
+	if s.LegacyBusinessHours != nil {
+		return strings.TrimSpace(*s.LegacyBusinessHours)
+	}
+	return ""
+}
+
+// ClosedAt reports whether the showroom is known to be closed at t. Without
+// structured hours it is never considered closed.
+func (s *ShowroomSettings) ClosedAt(t time.Time) bool {
+	return s.Hours.Configured() && !s.Hours.IsOpen(t)
+}
+
+// AfterHoursReply is the auto-reply sent to customers at now while the showroom is closed
+func (s *ShowroomSettings) AfterHoursReply(now time.Time) string {
+	message := s.AfterHoursMessage
+	if message == "" {
+		message = DefaultAfterHoursMessage
+	}
+	return strings.ReplaceAll(message, "{buka}", s.NextOpeningText(now))
+}
+
+// NextOpeningText describes when the showroom opens next after now
+func (s *ShowroomSettings) NextOpeningText(now time.Time) string {
+	if opening, ok := s.Hours.NextOpening(now); ok {
+		return s.Hours.FormatOpening(opening, now)
+	}
+	return "pada hari kerja berikutnya"
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func testBusinessHours(t *testing.T) *BusinessHours {
+	hours := &BusinessHours{
+		Weekly: []OpeningPeriod{
+			{Day: time.Friday, Open: "13:00", Close: "17:00"},
+			{Day: time.Friday, Open: "08:00", Close: "11:30"},
+			{Day: time.Monday, Open: "08:00", Close: "17:00"},
+			{Day: time.Saturday, Open: "09:00", Close: "15:00"},
+		},
+		Holidays: []Holiday{{Date: "2026-10-23", Name: "Cuti bersama"}},
+	}
+	if err := hours.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return hours
+}
+
+func TestBusinessHours(t *testing.T) {
+	hours := testBusinessHours(t)
+	wib := hours.Location()
+	at := func(day int, clock string) time.Time {
+		c, _ := time.Parse("15:04", clock)
+		return time.Date(2026, 10, day, c.Hour(), c.Minute(), 0, 0, wib)
+	}
+
+	// 2026-10-16 is a Friday with a prayer break, 2026-10-23 a Friday holiday
+	tests := []struct {
+		name        string
+		now         time.Time
+		open        bool
+		nextOpening time.Time
+		lastClosing time.Time
+	}{
+		{"friday morning", at(16, "10:00"), true, at(16, "13:00"), at(12, "17:00")},
+		{"friday break", at(16, "12:00"), false, at(16, "13:00"), at(16, "11:30")},
+		{"saturday evening", at(17, "20:00"), false, at(19, "08:00"), at(17, "15:00")},
+		{"holiday", at(23, "10:00"), false, at(24, "09:00"), at(19, "17:00")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if open := hours.IsOpen(tt.now); open != tt.open {
+				t.Errorf("IsOpen = %v", open)
+			}
+			if next, ok := hours.NextOpening(tt.now); !ok || !next.Equal(tt.nextOpening) {
+				t.Errorf("NextOpening = %v, %v", next, ok)
+			}
+			if last, ok := hours.LastClosing(tt.now); !ok || !last.Equal(tt.lastClosing) {
+				t.Errorf("LastClosing = %v, %v", last, ok)
+			}
+		})
+	}
+
+	// The same instant in UTC is judged in the showroom's time zone
+	if !hours.IsOpen(at(16, "10:00").UTC()) {
+		t.Error("IsOpen depends on the caller's time zone")
+	}
+	if got := hours.FormatOpening(at(19, "08:00"), at(17, "20:00")); got != "Senin, 19 Oktober pukul 08:00 WIB" {
+		t.Errorf("FormatOpening = %q", got)
+	}
+}
+
+func TestBusinessHoursValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		hours BusinessHours
+	}{
+		{"unknown time zone", BusinessHours{Timezone: "UTC"}},
+		{"closes before opening", BusinessHours{Weekly: []OpeningPeriod{{Day: time.Monday, Open: "17:00", Close: "08:00"}}}},
+		{"overlap", BusinessHours{Weekly: []OpeningPeriod{
+			{Day: time.Monday, Open: "08:00", Close: "12:00"},
+			{Day: time.Monday, Open: "11:00", Close: "17:00"},
+		}}},
+		{"bad holiday", BusinessHours{Holidays: []Holiday{{Date: "17-08-2026"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.hours.Validate(); err == nil {
+				t.Error("invalid hours accepted")
+			}
+		})
+	}
+}
+
+func TestAfterHoursReply(t *testing.T) {
+	showroom := &ShowroomSettings{Hours: testBusinessHours(t)}
+	now := time.Date(2026, 10, 16, 20, 0, 0, 0, showroom.Hours.Location())
+
+	if !showroom.ClosedAt(now) {
+		t.Fatal("showroom open on friday night")
+	}
+	showroom.AfterHoursMessage = "Kami buka {buka}."
+	if got := showroom.AfterHoursReply(now); got != "Kami buka besok pukul 09:00 WIB." {
+		t.Errorf("AfterHoursReply = %q", got)
+	}
+
+	if (&ShowroomSettings{}).ClosedAt(now) {
+		t.Error("showroom without hours considered closed")
+	}
+}
@@ -39,3 +39,62 @@ func GetUserID(ctx context.Context) (int, error) {
 	}
 	return userID, nil
 }
+
+const userKey contextKey = "user"
+
+// WithUser adds the authenticated user (and its ID) to context
+func WithUser(ctx context.Context, user *User) context.Context {
+	ctx = WithUserID(ctx, user.ID)
+	return context.WithValue(ctx, userKey, user)
+}
+
+// GetUser retrieves the authenticated user from context
+func GetUser(ctx context.Context) (*User, error) {
+	user, ok := ctx.Value(userKey).(*User)
+	if !ok {
+		return nil, errors.New("user not found in context")
+	}
+	return user, nil
+}
+
+const systemScopeKey contextKey = "system_scope"
+
+// WithSystemScope marks trusted background work that spans tenants (bot startup,
+// migrations, platform admin). Row-level security is bypassed for its queries.
+func WithSystemScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemScopeKey, true)
+}
+
+// IsSystemScope reports whether the context was marked with WithSystemScope
+func IsSystemScope(ctx context.Context) bool {
+	system, _ := ctx.Value(systemScopeKey).(bool)
+	return system
+}
+
+const systemActorKey contextKey = "system_actor"
+
+// WithSystemActor marks trusted callers acting for the context tenant without a
+// logged-in user (WhatsApp bot, API keys, background jobs, CLI tools). Role
+// permissions only apply to users; without a user or this mark repositories refuse.
+func WithSystemActor(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemActorKey, true)
+}
+
+// IsSystemActor reports whether the context was marked with WithSystemActor
+func IsSystemActor(ctx context.Context) bool {
+	system, _ := ctx.Value(systemActorKey).(bool)
+	return system
+}
+
+const csrfTokenKey contextKey = "csrf_token"
+
+// WithCSRFToken adds the anti-CSRF token of the request's session to context
+func WithCSRFToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, csrfTokenKey, token)
+}
+
+// GetCSRFToken returns the anti-CSRF token, or "" for requests without a session
+func GetCSRFToken(ctx context.Context) string {
+	token, _ := ctx.Value(csrfTokenKey).(string)
+	return token
+}
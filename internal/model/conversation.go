@@ -1,14 +1,19 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+)
 
 type Conversation struct {
-	ID          int       `json:"id"`
-	TenantID    int       `json:"tenant_id"`
-	SenderPhone string    `json:"sender_phone"`
-	IsSales     bool      `json:"is_sales"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              int       `json:"id"`
+	TenantID        int       `json:"tenant_id"`
+	SenderPhone     string    `json:"sender_phone"`
+	IsSales         bool      `json:"is_sales"`
+	AssignedSalesID *int      `json:"assigned_sales_id,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
 }
 
 type Message struct {
@@ -22,13 +27,14 @@ type Message struct {
 
 // ConversationListItem represents conversation with last message info for list view
 type ConversationListItem struct {
-	ID            int       `json:"id"`
-	PhoneNumber   string    `json:"phone_number"`
-	IsSales       bool      `json:"is_sales"`
-	LastMessage   string    `json:"last_message"`
-	LastMessageAt time.Time `json:"last_message_at"`
-	MessageCount  int       `json:"message_count"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID              int       `json:"id"`
+	PhoneNumber     string    `json:"phone_number"`
+	IsSales         bool      `json:"is_sales"`
+	AssignedSalesID *int      `json:"assigned_sales_id,omitempty"`
+	LastMessage     string    `json:"last_message"`
+	LastMessageAt   time.Time `json:"last_message_at"`
+	MessageCount    int       `json:"message_count"`
+	CreatedAt       time.Time `json:"created_at"`
 }
 
 type Lead struct {
@@ -38,7 +44,9 @@ type Lead struct {
 	Name             *string   `json:"name,omitempty"`
 	InterestedCarID  *int      `json:"interested_car_id,omitempty"`
 	ConversationID   *int      `json:"conversation_id,omitempty"`
+	Source           string    `json:"source"` // whatsapp, website, trade_in
 	Status           string    `json:"status"` // new, contacted, converted, lost
+	AssignedSalesID  *int      `json:"assigned_sales_id,omitempty"`
 	CreatedAt        time.Time `json:"created_at"`
 	UpdatedAt        time.Time `json:"updated_at"`
 }
@@ -48,4 +56,31 @@ type CreateLeadRequest struct {
 	Name            *string `json:"name,omitempty"`
 	InterestedCarID *int    `json:"interested_car_id,omitempty"`
 	ConversationID  *int    `json:"conversation_id,omitempty"`
+	Source          string  `json:"source,omitempty"`
+}
+
+// Lead statuses
+const (
+	LeadStatusNew       = "new"
+	LeadStatusContacted = "contacted"
+	LeadStatusConverted = "converted"
+	LeadStatusLost      = "lost"
+)
+
+// IsValidLeadStatus reports whether status is a known lead status
+func IsValidLeadStatus(status string) bool {
+	switch status {
+	case LeadStatusNew, LeadStatusContacted, LeadStatusConverted, LeadStatusLost:
+		return true
+	}
+	return false
+}
+
+// Validate checks the create lead request
+func (r *CreateLeadRequest) Validate() error {
+	r.PhoneNumber = strings.TrimSpace(r.PhoneNumber)
+	if r.PhoneNumber == "" {
+		return errors.New("Nomor telepon wajib diisi")
+	}
+	return nil
 }
@@ -0,0 +1,149 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Customer is a person who contacted the tenant, identified by normalised phone number
+type Customer struct {
+	ID              int       `json:"id"`
+	TenantID        int       `json:"tenant_id"`
+	PhoneNumber     string    `json:"phone_number"` // normalised 62xxx
+	Name            *string   `json:"name,omitempty"`
+	Tags            []string  `json:"tags"`
+	Notes           *string   `json:"notes,omitempty"`
+	BudgetMin       *int64    `json:"budget_min,omitempty"`
+	BudgetMax       *int64    `json:"budget_max,omitempty"`
+	BodyTypes       []string  `json:"body_types"`
+	PreferredBrands []string  `json:"preferred_brands"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CustomerUpdateRequest represents an admin edit of a customer profile
+type CustomerUpdateRequest struct {
+	Name            *string  `json:"name,omitempty"`
+	Tags            []string `json:"tags"`
+	Notes           *string  `json:"notes,omitempty"`
+	BudgetMin       *int64   `json:"budget_min,omitempty"`
+	BudgetMax       *int64   `json:"budget_max,omitempty"`
+	BodyTypes       []string `json:"body_types"`
+	PreferredBrands []string `json:"preferred_brands"`
+}
+
+// Validate checks the customer update request
+func (r *CustomerUpdateRequest) Validate() error {
+	if r.BudgetMin != nil && r.BudgetMax != nil && *r.BudgetMin > *r.BudgetMax {
+		return errors.New("Budget minimal tidak boleh melebihi budget maksimal")
+	}
+	r.Tags = cleanList(r.Tags)
+	r.BodyTypes = cleanList(r.BodyTypes)
+	r.PreferredBrands = cleanList(r.PreferredBrands)
+	return nil
+}
+
+// CustomerPreferences are details the bot extracts from a chat. Empty fields are left unchanged
+// and list fields are merged into what is already known.
+type CustomerPreferences struct {
+	Name      string   `json:"name,omitempty"`
+	BudgetMin int64    `json:"budget_min,omitempty"`
+	BudgetMax int64    `json:"budget_max,omitempty"`
+	BodyTypes []string `json:"body_types,omitempty"`
+	Brands    []string `json:"brands,omitempty"`
+}
+
+// Appointment is a scheduled showroom visit or test drive
+type Appointment struct {
+	ID            int       `json:"id"`
+	TenantID      int       `json:"tenant_id"`
+	CustomerPhone string    `json:"customer_phone"`
+	CarID         *int      `json:"car_id,omitempty"`
+	Type          string    `json:"type"` // visit, test_drive, inspection
+	ScheduledAt   time.Time `json:"scheduled_at"`
+	Status        string    `json:"status"` // scheduled, done, cancelled
+	Notes         *string   `json:"notes,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Validate checks the appointment
+func (a *Appointment) Validate() error {
+	switch a.Type {
+	case "":
+		a.Type = "visit"
+	case "visit", "test_drive", "inspection":
+	default:
+		return errors.New("Jenis janji temu harus visit, test_drive, atau inspection")
+	}
+	if a.ScheduledAt.IsZero() {
+		return errors.New("Waktu janji temu wajib diisi")
+	}
+	return nil
+}
+
+// TimelineEvent is one entry in a customer's activity timeline
+type TimelineEvent struct {
+	Type       string    `json:"type"` // message, lead, appointment, deal, trade_in
+	OccurredAt time.Time `json:"occurred_at"`
+	RefID      int       `json:"ref_id"`
+	Status     string    `json:"status"`
+	Detail     string    `json:"detail"`
+}
+
+// NormalizePhone converts Indonesian phone numbers to the 62xxx form WhatsApp uses.
+// Non-digits are removed and a leading 0 is replaced with 62. A number starting
+// with 8 is a mobile number without its 0 only while it has local length (under
+// 12 digits) and is not 84x or 86x, which no Indonesian operator uses; other
+// numbers are taken as international and kept. migrations/000021 backfills
+// customers with the same rule.
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	cleaned := b.String()
+
+	switch {
+	case strings.HasPrefix(cleaned, "0"):
+		cleaned = "62" + cleaned[1:]
+	case strings.HasPrefix(cleaned, "8") && len(cleaned) < 12 &&
+		!strings.HasPrefix(cleaned, "84") && !strings.HasPrefix(cleaned, "86"):
+		cleaned = "62" + cleaned
+	}
+
+	return cleaned
+}
+
+// PhoneVariants returns the ways a normalised phone number may have been stored
+// (62xxx, +62xxx and local 08xxx), for matching legacy rows.
+func PhoneVariants(phone string) []string {
+	normalized := NormalizePhone(phone)
+	variants := []string{normalized, "+" + normalized}
+	if strings.HasPrefix(normalized, "62") {
+		variants = append(variants, "0"+normalized[2:])
+	}
+	return variants
+}
+
+func cleanList(items []string) []string {
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		key := strings.ToLower(item)
+		if item == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, item)
+	}
+	return result
+}
+
+// MergeList appends items not already present (case-insensitive)
+func MergeList(existing, items []string) []string {
+	return cleanList(append(append([]string{}, existing...), items...))
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		want  string
+	}{
+		{"0812345678", "62812345678"},
+		{"08123456789", "628123456789"},
+		{"081234567890", "6281234567890"},
+		{"0812345678901", "62812345678901"},
+		{"812345678", "62812345678"},
+		{"81234567890", "6281234567890"},
+		{"6281234567890", "6281234567890"},
+		{"+6281234567890", "6281234567890"},
+		{"+62 812-3456-7890", "6281234567890"},
+		{"(0812) 3456 7890", "6281234567890"},
+		{"0812.3456.78901", "62812345678901"},
+		{"", ""},
+
+		// International numbers are kept as they are
+		{"+86 138 1234 5678", "8613812345678"},
+		{"8613812345678", "8613812345678"},
+		{"+81 90-1234-5678", "819012345678"},
+		{"821012345678", "821012345678"},
+		{"+84 912 345 678", "84912345678"},
+		{"+65 9123 4567", "6591234567"},
+		{"+1 (415) 555-0100", "14155550100"},
+		{"+44 7700 900123", "447700900123"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizePhone(tt.phone); got != tt.want {
+			t.Errorf("NormalizePhone(%q) = %q, want %q", tt.phone, got, tt.want)
+		}
+	}
+}
+
+func TestPhoneVariants(t *testing.T) {
+	want := []string{"6281234567890", "+6281234567890", "081234567890"}
+	for _, phone := range []string{"081234567890", "+62 812 3456 7890", "6281234567890"} {
+		if got := PhoneVariants(phone); !reflect.DeepEqual(got, want) {
+			t.Errorf("PhoneVariants(%q) = %v", phone, got)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Data job types
+const (
+	DataJobCustomerExport  = "customer_export"  // one customer's data, by phone number
+	DataJobCustomerErasure = "customer_erasure" // delete or anonymise one customer's data
+	DataJobTenantExport    = "tenant_export"    // archive of all the tenant's data
+)
+
+// Data job statuses
+const (
+	DataJobPending = "pending"
+	DataJobRunning = "running"
+	DataJobDone    = "done"
+	DataJobFailed  = "failed"
+)
+
+// Export formats and erasure modes
+const (
+	ExportFormatJSON = "json"
+	ExportFormatZIP  = "zip"
+
+	ErasureDelete    = "delete"    // remove the customer's rows
+	ErasureAnonymize = "anonymize" // keep the rows for statistics, strip phone, name and text
+)
+
+// DataJob is an export or erasure run in the background. Phone is cleared once
+// an erasure is done and when an export file expires.
+type DataJob struct {
+	ID          int             `json:"id"`
+	TenantID    int             `json:"tenant_id"`
+	Type        string          `json:"type"`
+	Phone       *string         `json:"phone,omitempty"`
+	Format      *string         `json:"format,omitempty"`
+	Mode        *string         `json:"mode,omitempty"`
+	Status      string          `json:"status"` // pending, running, done, failed
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       *string         `json:"error,omitempty"`
+	FilePath    *string         `json:"-"`
+	HasFile     bool            `json:"has_file"`
+	Attempts    int             `json:"attempts"`
+	RequestedBy *int            `json:"requested_by,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	StartedAt   *time.Time      `json:"started_at,omitempty"`
+	FinishedAt  *time.Time      `json:"finished_at,omitempty"`
+	ExpiresAt   *time.Time      `json:"expires_at,omitempty"` // export file deleted after
+}
+
+// DataJobRequest starts a data job
+type DataJobRequest struct {
+	Type   string `json:"type"`
+	Phone  string `json:"phone,omitempty"`
+	Format string `json:"format,omitempty"` // exports: json (default) or zip; tenant exports are always zip
+	Mode   string `json:"mode,omitempty"`   // erasures: delete (default) or anonymize
+}
+
+// Validate checks the data job request and fills in defaults
+func (r *DataJobRequest) Validate() error {
+	switch r.Type {
+	case DataJobCustomerExport, DataJobCustomerErasure:
+		r.Phone = NormalizePhone(r.Phone)
+		if len(r.Phone) < 8 {
+			return errors.New("Nomor telepon customer tidak valid")
+		}
+	case DataJobTenantExport:
+		r.Phone = ""
+	default:
+		return errors.New("Jenis job harus customer_export, customer_erasure, atau tenant_export")
+	}
+
+	switch r.Type {
+	case DataJobCustomerExport:
+		if r.Format == "" {
+			r.Format = ExportFormatJSON
+		}
+		if r.Format != ExportFormatJSON && r.Format != ExportFormatZIP {
+			return errors.New("Format ekspor harus json atau zip")
+		}
+		r.Mode = ""
+	case DataJobCustomerErasure:
+		if r.Mode == "" {
+			r.Mode = ErasureDelete
+		}
+		if r.Mode != ErasureDelete && r.Mode != ErasureAnonymize {
+			return errors.New("Mode penghapusan harus delete atau anonymize")
+		}
+		r.Format = ""
+	case DataJobTenantExport:
+		r.Format, r.Mode = ExportFormatZIP, ""
+	}
+	return nil
+}
+
+// CustomerExport is everything stored about one customer of a tenant
+type CustomerExport struct {
+	PhoneNumber   string                `json:"phone_number"`
+	ExportedAt    time.Time             `json:"exported_at"`
+	Customer      *Customer             `json:"customer,omitempty"`
+	Conversations []*ConversationExport `json:"conversations"`
+	Leads         []*Lead               `json:"leads"`
+	Appointments  []*Appointment        `json:"appointments"`
+}
+
+// ConversationExport is a conversation with all its messages
+type ConversationExport struct {
+	*Conversation
+	Messages []*Message `json:"messages"`
+}
+
+// ErasureResult counts the rows an erasure deleted or anonymised, per table
+type ErasureResult struct {
+	Mode string           `json:"mode"`
+	Rows map[string]int64 `json:"rows"`
+}
@@ -0,0 +1,135 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Deal represents a car sale transaction between a customer and a sales person
+type Deal struct {
+	ID               int        `json:"id"`
+	TenantID         int        `json:"tenant_id"`
+	CarID            *int       `json:"car_id,omitempty"`
+	LeadID           *int       `json:"lead_id,omitempty"`
+	SalesID          *int       `json:"sales_id,omitempty"`
+	CustomerName     string     `json:"customer_name"`
+	CustomerPhone    string     `json:"customer_phone"`
+	ListPrice        int64      `json:"list_price"`
+	AgreedPrice      int64      `json:"agreed_price"`
+	Discount         int64      `json:"discount"`
+	PaymentMethod    string     `json:"payment_method"` // cash, kredit
+	LeasingPartnerID *int       `json:"leasing_partner_id,omitempty"`
+	Status           string     `json:"status"` // open, closed, cancelled
+	ClosingDate      *time.Time `json:"closing_date,omitempty"`
+	CommissionAmount int64      `json:"commission_amount"`
+	Notes            *string    `json:"notes,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// Joined fields for display
+	CarName   string `json:"car_name,omitempty"`
+	SalesName string `json:"sales_name,omitempty"`
+}
+
+// FinalPrice is the amount the customer actually pays
+func (d *Deal) FinalPrice() int64 {
+	return d.AgreedPrice - d.Discount
+}
+
+// DealRequest represents a request to create/update a deal
+type DealRequest struct {
+	CarID            int     `json:"car_id"`
+	LeadID           *int    `json:"lead_id,omitempty"`
+	SalesID          *int    `json:"sales_id,omitempty"`
+	CustomerName     string  `json:"customer_name"`
+	CustomerPhone    string  `json:"customer_phone"`
+	AgreedPrice      int64   `json:"agreed_price"`
+	Discount         int64   `json:"discount"`
+	PaymentMethod    string  `json:"payment_method"`
+	LeasingPartnerID *int    `json:"leasing_partner_id,omitempty"`
+	Notes            *string `json:"notes,omitempty"`
+}
+
+// Validate checks the deal request
+func (r *DealRequest) Validate() error {
+	if r.CarID <= 0 {
+		return errors.New("Mobil wajib dipilih")
+	}
+	if strings.TrimSpace(r.CustomerName) == "" || strings.TrimSpace(r.CustomerPhone) == "" {
+		return errors.New("Nama dan nomor telepon customer wajib diisi")
+	}
+	if r.AgreedPrice <= 0 {
+		return errors.New("Harga deal harus lebih dari 0")
+	}
+	if r.Discount < 0 || r.Discount >= r.AgreedPrice {
+		return errors.New("Diskon tidak valid")
+	}
+	switch r.PaymentMethod {
+	case "":
+		r.PaymentMethod = "cash"
+	case "cash", "kredit":
+	default:
+		return errors.New("Metode pembayaran harus cash atau kredit")
+	}
+	if r.PaymentMethod == "cash" {
+		r.LeasingPartnerID = nil
+	}
+	return nil
+}
+
+// CloseDealRequest represents a request to close a deal
+type CloseDealRequest struct {
+	ClosingDate string `json:"closing_date"` // YYYY-MM-DD, defaults to today
+}
+
+// CommissionRule defines how much commission a sales person earns for a closed deal.
+// A rule applies when the deal's final price falls within [MinPrice, MaxPrice].
+type CommissionRule struct {
+	ID         int       `json:"id"`
+	TenantID   int       `json:"tenant_id"`
+	Name       string    `json:"name"`
+	MinPrice   int64     `json:"min_price"`
+	MaxPrice   *int64    `json:"max_price,omitempty"` // nil = no upper bound
+	Percent    float64   `json:"percent"`             // % of final price
+	FlatAmount int64     `json:"flat_amount"`         // added on top of percent
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Validate checks the commission rule
+func (c *CommissionRule) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return errors.New("Nama aturan komisi tidak boleh kosong")
+	}
+	if c.MinPrice < 0 || (c.MaxPrice != nil && *c.MaxPrice < c.MinPrice) {
+		return errors.New("Rentang harga tidak valid")
+	}
+	if c.Percent < 0 || c.Percent > 100 || c.FlatAmount < 0 {
+		return errors.New("Persentase atau nominal komisi tidak valid")
+	}
+	if c.Percent == 0 && c.FlatAmount == 0 {
+		return errors.New("Isi persentase atau nominal komisi")
+	}
+	return nil
+}
+
+// Matches reports whether the rule applies to the given final price
+func (c *CommissionRule) Matches(price int64) bool {
+	if price < c.MinPrice {
+		return false
+	}
+	return c.MaxPrice == nil || price <= *c.MaxPrice
+}
+
+// CommissionStatement summarises one sales person's closed deals in a month
+type CommissionStatement struct {
+	SalesID         int     `json:"sales_id"`
+	SalesName       string  `json:"sales_name"`
+	Month           string  `json:"month"` // YYYY-MM
+	DealCount       int     `json:"deal_count"`
+	TotalSales      int64   `json:"total_sales"`
+	TotalCommission int64   `json:"total_commission"`
+	Deals           []*Deal `json:"deals"`
+}
@@ -0,0 +1,107 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Custom domain statuses
+const (
+	DomainPending  = "pending"  // added, ownership not proven yet
+	DomainFailed   = "failed"   // the last check found neither proof
+	DomainVerified = "verified" // routes traffic to the tenant
+)
+
+// Ways a tenant proves it owns a custom domain
+const (
+	DomainVerifyDNS  = "dns"
+	DomainVerifyHTTP = "http"
+)
+
+// The DNS TXT record and HTTP path holding a domain's verification token
+const (
+	DomainTXTPrefix = "_autolmk-challenge."
+	DomainTXTValue  = "autolmk-verification="
+	DomainHTTPPath  = "/.well-known/autolmk-verification/"
+)
+
+// TenantDomain is a custom domain of a tenant. It serves the tenant once
+// verified; the canonical one is where the tenant's other domains redirect.
+type TenantDomain struct {
+	ID            int             `json:"id"`
+	TenantID      int             `json:"tenant_id"`
+	Domain        string          `json:"domain"`
+	Status        string          `json:"status"`
+	IsCanonical   bool            `json:"is_canonical"`
+	Challenge     DomainChallenge `json:"challenge"`
+	VerifiedAt    *time.Time      `json:"verified_at,omitempty"`
+	VerifiedBy    *string         `json:"verified_by,omitempty"`
+	LastCheckedAt *time.Time      `json:"last_checked_at,omitempty"`
+	LastError     *string         `json:"last_error,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// DomainChallenge tells the tenant how to prove ownership of a domain: either
+// publish the TXT record or point the domain at the platform so the HTTP URL
+// answers with the token
+type DomainChallenge struct {
+	Token    string `json:"token"`
+	TXTName  string `json:"txt_name"`
+	TXTValue string `json:"txt_value"`
+	HTTPURL  string `json:"http_url"`
+}
+
+// NewDomainChallenge returns the records proving ownership of domain with token
+func NewDomainChallenge(domain, token string) DomainChallenge {
+	return DomainChallenge{
+		Token:    token,
+		TXTName:  DomainTXTPrefix + domain,
+		TXTValue: DomainTXTValue + token,
+		HTTPURL:  "http://" + domain + DomainHTTPPath + token,
+	}
+}
+
+// DomainStatus derives a custom domain's status from its verification fields
+func DomainStatus(verifiedAt *time.Time, lastError *string) string {
+	switch {
+	case verifiedAt != nil:
+		return DomainVerified
+	case lastError != nil:
+		return DomainFailed
+	default:
+		return DomainPending
+	}
+}
+
+// TenantDomains is the status page of a tenant's domains
+type TenantDomains struct {
+	PlatformDomain string          `json:"platform_domain"`
+	Canonical      string          `json:"canonical"`
+	Domains        []*TenantDomain `json:"data"`
+	Count          int             `json:"count"`
+}
+
+// AddDomainRequest adds a custom domain
+type AddDomainRequest struct {
+	Domain string `json:"domain"`
+}
+
+// Validate checks the domain is a usable host name outside platformDomain
+func (r *AddDomainRequest) Validate(platformDomain string) error {
+	domain, err := ValidateDomain(r.Domain)
+	if err != nil {
+		return err
+	}
+	if platformDomain != "" && (domain == platformDomain || strings.HasSuffix(domain, "."+platformDomain)) {
+		return errors.New("Subdomain " + platformDomain + " diatur oleh tim Auto LMK")
+	}
+	r.Domain = domain
+	return nil
+}
+
+// CanonicalDomainRequest picks the domain the others redirect to: the platform
+// domain or a verified custom domain
+type CanonicalDomainRequest struct {
+	Domain string `json:"domain"`
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// FAQ categories, covering what customers usually ask a showroom
+const (
+	FAQCategoryPayment   = "pembayaran"
+	FAQCategoryWarranty  = "garansi"
+	FAQCategoryDocuments = "dokumen"
+	FAQCategoryService   = "layanan"
+	FAQCategoryOther     = "lainnya"
+)
+
+// FAQCategories lists the categories in the order the admin page shows them
+var FAQCategories = []string{
+	FAQCategoryPayment, FAQCategoryWarranty, FAQCategoryDocuments, FAQCategoryService, FAQCategoryOther,
+}
+
+// FAQ limits, keeping search results a reasonable size for the bot
+const (
+	maxFAQQuestion = 300
+	maxFAQAnswer   = 2000
+)
+
+// FAQ is a showroom's answer to a common customer question, which the bot
+// searches before answering questions about payment, warranty or documents
+type FAQ struct {
+	ID        int       `json:"id"`
+	TenantID  int       `json:"tenant_id"`
+	Category  string    `json:"category"`
+	Question  string    `json:"question"`
+	Answer    string    `json:"answer"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FAQRequest creates or updates an FAQ
+type FAQRequest struct {
+	Category string `json:"category"`
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// Validate normalizes the FAQ and checks the category and lengths
+func (r *FAQRequest) Validate() error {
+	r.Category = strings.TrimSpace(r.Category)
+	r.Question = strings.TrimSpace(r.Question)
+	r.Answer = strings.TrimSpace(r.Answer)
+
+	if r.Category == "" {
+		r.Category = FAQCategoryOther
+	}
+	if !IsFAQCategory(r.Category) {
+		return errors.New("Kategori tidak dikenal: " + r.Category)
+	}
+
+	switch {
+	case r.Question == "":
+		return errors.New("Pertanyaan wajib diisi")
+	case r.Answer == "":
+		return errors.New("Jawaban wajib diisi")
+	case len([]rune(r.Question)) > maxFAQQuestion:
+		return errors.New("Pertanyaan maksimal 300 karakter")
+	case len([]rune(r.Answer)) > maxFAQAnswer:
+		return errors.New("Jawaban maksimal 2000 karakter")
+	}
+	return nil
+}
+
+// IsFAQCategory reports whether category is one of FAQCategories
+func IsFAQCategory(category string) bool {
+	for _, c := range FAQCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// LeasingPartner represents a leasing (multifinance) company a tenant works with
+type LeasingPartner struct {
+	ID            int            `json:"id"`
+	TenantID      int            `json:"tenant_id"`
+	Name          string         `json:"name"`
+	AdminFee      int64          `json:"admin_fee"`
+	InsuranceRate float64        `json:"insurance_rate"` // % of OTR price per year
+	IsActive      bool           `json:"is_active"`
+	Rates         []*LeasingRate `json:"rates,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// LeasingRate is a single row of a partner's rate table
+type LeasingRate struct {
+	ID           int     `json:"id"`
+	PartnerID    int     `json:"partner_id"`
+	TenorMonths  int     `json:"tenor_months"`
+	MinDPPercent float64 `json:"min_dp_percent"`
+	MaxCarAge    int     `json:"max_car_age"`   // years, inclusive
+	InterestRate float64 `json:"interest_rate"` // flat % per year
+}
+
+// LeasingPartnerRequest represents a request to create/update a leasing partner
+type LeasingPartnerRequest struct {
+	Name          string         `json:"name"`
+	AdminFee      int64          `json:"admin_fee"`
+	InsuranceRate float64        `json:"insurance_rate"`
+	IsActive      *bool          `json:"is_active,omitempty"`
+	Rates         []*LeasingRate `json:"rates"`
+}
+
+// Validate checks the leasing partner request
+func (r *LeasingPartnerRequest) Validate() error {
+	if r.Name == "" {
+		return errors.New("Nama leasing tidak boleh kosong")
+	}
+	if r.AdminFee < 0 || r.InsuranceRate < 0 {
+		return errors.New("Biaya admin dan asuransi tidak boleh negatif")
+	}
+	for _, rate := range r.Rates {
+		if rate.TenorMonths <= 0 || rate.TenorMonths > 84 {
+			return errors.New("Tenor harus antara 1 dan 84 bulan")
+		}
+		if rate.InterestRate < 0 || rate.MinDPPercent < 0 || rate.MinDPPercent >= 100 {
+			return errors.New("Bunga dan DP minimal tidak valid")
+		}
+	}
+	return nil
+}
+
+// CreditSimulationRequest represents a request to simulate a car loan
+type CreditSimulationRequest struct {
+	CarID       int     `json:"car_id"`
+	Price       int64   `json:"price,omitempty"` // used when car_id is not given
+	CarYear     int     `json:"car_year,omitempty"`
+	DPPercent   float64 `json:"dp_percent,omitempty"`
+	DPAmount    int64   `json:"dp_amount,omitempty"`
+	TenorMonths int     `json:"tenor_months"`
+	PartnerID   int     `json:"partner_id,omitempty"` // 0 = all active partners
+}
+
+// CreditQuote is one partner's quote for a credit simulation
+type CreditQuote struct {
+	PartnerID          int     `json:"partner_id"`
+	PartnerName        string  `json:"partner_name"`
+	TenorMonths        int     `json:"tenor_months"`
+	InterestRate       float64 `json:"interest_rate"`
+	DownPayment        int64   `json:"down_payment"`
+	LoanPrincipal      int64   `json:"loan_principal"`
+	MonthlyInstallment int64   `json:"monthly_installment"`
+	AdminFee           int64   `json:"admin_fee"`
+	InsurancePremium   int64   `json:"insurance_premium"`
+	TotalDownPayment   int64   `json:"total_down_payment"` // TDP: DP + admin + insurance + first installment
+}
+
+// CreditSimulation is the result of a credit simulation
+type CreditSimulation struct {
+	CarID       int            `json:"car_id,omitempty"`
+	Price       int64          `json:"price"`
+	CarAge      int            `json:"car_age"`
+	DPPercent   float64        `json:"dp_percent"`
+	TenorMonths int            `json:"tenor_months"`
+	Quotes      []*CreditQuote `json:"quotes"`
+	Note        string         `json:"note,omitempty"`
+}
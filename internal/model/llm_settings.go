@@ -0,0 +1,82 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// LLM providers a tenant can bring its own key for
+const (
+	LLMProviderOpenAI    = "openai"
+	LLMProviderAnthropic = "anthropic"
+	LLMProviderZAI       = "zai"
+)
+
+// LLMSettings is a tenant's own LLM provider, used instead of the platform's.
+// The API key itself never leaves the server; KeyHint shows its last characters.
+type LLMSettings struct {
+	TenantID    int       `json:"tenant_id"`
+	Provider    string    `json:"provider"`
+	Model       string    `json:"model"`
+	KeyHint     string    `json:"key_hint"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// LLMCredentials is a tenant's provider with its decrypted key, for building
+// the provider
+type LLMCredentials struct {
+	Provider    string
+	Model       string
+	APIKey      string
+	Temperature *float64
+}
+
+// LLMStatus is the AI settings page of a tenant: its own provider (nil when it
+// uses the platform's) and which options the platform offers
+type LLMStatus struct {
+	Settings          *LLMSettings `json:"settings"`
+	PlatformAvailable bool         `json:"platform_available"` // the platform provider is configured
+	OwnKeyAvailable   bool         `json:"own_key_available"`  // keys can be stored encrypted
+}
+
+// SaveLLMSettingsRequest sets a tenant's own provider. An empty APIKey keeps
+// the stored key, which is only possible while the provider stays the same.
+type SaveLLMSettingsRequest struct {
+	Provider    string   `json:"provider"`
+	Model       string   `json:"model"`
+	APIKey      string   `json:"api_key"`
+	Temperature *float64 `json:"temperature"`
+}
+
+// Validate normalizes the request and checks the provider, model and temperature
+func (r *SaveLLMSettingsRequest) Validate() error {
+	r.Provider = strings.ToLower(strings.TrimSpace(r.Provider))
+	r.Model = strings.TrimSpace(r.Model)
+	r.APIKey = strings.TrimSpace(r.APIKey)
+
+	switch r.Provider {
+	case LLMProviderOpenAI, LLMProviderAnthropic, LLMProviderZAI:
+	default:
+		return errors.New("Provider harus openai, anthropic atau zai")
+	}
+	if len(r.Model) > 100 {
+		return errors.New("Nama model maksimal 100 karakter")
+	}
+	if r.APIKey != "" && len(r.APIKey) < 16 {
+		return errors.New("API key tidak valid")
+	}
+	if r.Temperature != nil && (*r.Temperature < 0 || *r.Temperature > 2) {
+		return errors.New("Temperature harus antara 0 dan 2")
+	}
+	return nil
+}
+
+// LLMKeyHint masks an API key down to its last four characters
+func LLMKeyHint(apiKey string) string {
+	if len(apiKey) <= 4 {
+		return "****"
+	}
+	return "…" + apiKey[len(apiKey)-4:]
+}
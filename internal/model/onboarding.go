@@ -0,0 +1,121 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Onboarding wizard steps, in order. The tenant and its owner are created by a
+// platform admin; the owner walks through the rest from the admin panel.
+const (
+	OnboardingTenant   = "tenant"
+	OnboardingOwner    = "owner"
+	OnboardingBranding = "branding"
+	OnboardingShowroom = "showroom"
+	OnboardingWhatsApp = "whatsapp"
+	OnboardingCars     = "cars"
+)
+
+// OnboardingStepInfo describes a wizard step
+type OnboardingStepInfo struct {
+	Key       string
+	Title     string
+	URL       string // admin page where the step is done
+	Skippable bool
+}
+
+// OnboardingSteps lists the wizard steps in order
+var OnboardingSteps = []OnboardingStepInfo{
+	{Key: OnboardingTenant, Title: "Buat tenant"},
+	{Key: OnboardingOwner, Title: "Akun owner pertama"},
+	{Key: OnboardingBranding, Title: "Logo dan tampilan", URL: "/admin/branding", Skippable: true},
+	{Key: OnboardingShowroom, Title: "Alamat dan kontak showroom", URL: "/admin/showroom", Skippable: true},
+	{Key: OnboardingWhatsApp, Title: "Hubungkan WhatsApp bot", URL: "/admin/whatsapp", Skippable: true},
+	{Key: OnboardingCars, Title: "Tambah mobil pertama", URL: "/admin/cars/new", Skippable: true},
+}
+
+// IsSkippableOnboardingStep reports whether step exists and may be skipped
+func IsSkippableOnboardingStep(step string) bool {
+	for _, s := range OnboardingSteps {
+		if s.Key == step {
+			return s.Skippable
+		}
+	}
+	return false
+}
+
+// OnboardingStep is the state of one wizard step for a tenant
+type OnboardingStep struct {
+	Key       string `json:"key"`
+	Title     string `json:"title"`
+	URL       string `json:"url,omitempty"`
+	Skippable bool   `json:"skippable"`
+	Done      bool   `json:"done"`
+	Skipped   bool   `json:"skipped"`
+}
+
+// OnboardingProgress is how far a tenant got through the wizard
+type OnboardingProgress struct {
+	TenantID     int              `json:"tenant_id"`
+	Steps        []OnboardingStep `json:"steps"`
+	Percent      int              `json:"percent"` // steps done or skipped
+	DemoCars     int              `json:"demo_cars"`
+	DemoSeededAt *time.Time       `json:"demo_seeded_at,omitempty"`
+	CompletedAt  *time.Time       `json:"completed_at,omitempty"` // wizard finished or dismissed
+}
+
+// NewOnboardingProgress builds the step list from the steps that are done and skipped
+func NewOnboardingProgress(tenantID int, done map[string]bool, skipped []string) *OnboardingProgress {
+	skip := map[string]bool{}
+	for _, s := range skipped {
+		skip[s] = true
+	}
+
+	progress := &OnboardingProgress{TenantID: tenantID}
+	finished := 0
+	for _, info := range OnboardingSteps {
+		step := OnboardingStep{
+			Key:       info.Key,
+			Title:     info.Title,
+			URL:       info.URL,
+			Skippable: info.Skippable,
+			Done:      done[info.Key],
+		}
+		step.Skipped = !step.Done && skip[info.Key]
+		if step.Done || step.Skipped {
+			finished++
+		}
+		progress.Steps = append(progress.Steps, step)
+	}
+	progress.Percent = finished * 100 / len(OnboardingSteps)
+	return progress
+}
+
+// OnboardTenantRequest creates a tenant with its first owner in one go
+type OnboardTenantRequest struct {
+	Tenant   CreateTenantRequest `json:"tenant"`
+	Owner    CreateUserRequest   `json:"owner"`
+	SeedDemo bool                `json:"seed_demo"` // fill the inventory with the demo catalog
+}
+
+// Validate checks the tenant and owner; the owner's role is always owner
+func (r *OnboardTenantRequest) Validate() error {
+	if err := r.Tenant.Validate(); err != nil {
+		return err
+	}
+	r.Owner.Role = RoleOwner
+	r.Owner.SalesID = nil
+	if err := r.Owner.Validate(); err != nil {
+		return errors.New("Owner: " + err.Error())
+	}
+	return nil
+}
+
+// OnboardTenantResult is the outcome of onboarding a tenant. Created is false
+// when the request was a repeat and the tenant already existed.
+type OnboardTenantResult struct {
+	Tenant   *Tenant             `json:"tenant"`
+	Owner    *User               `json:"owner"`
+	Created  bool                `json:"created"`
+	Progress *OnboardingProgress `json:"onboarding"`
+}
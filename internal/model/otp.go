@@ -0,0 +1,40 @@
+package model
+
+import (
+	"errors"
+	"regexp"
+	"time"
+)
+
+// LoginOTP is a one-time password sent to a sales person over WhatsApp
+type LoginOTP struct {
+	ID          int        `json:"id"`
+	TenantID    int        `json:"tenant_id"`
+	SalesID     int        `json:"sales_id"`
+	PhoneNumber string     `json:"phone_number"`
+	CodeHash    string     `json:"-"`
+	Attempts    int        `json:"attempts"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ConsumedAt  *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+var otpCodeRegex = regexp.MustCompile(`^\d{6}$`)
+
+// OTPVerifyRequest is the second step of WhatsApp login
+type OTPVerifyRequest struct {
+	PhoneNumber string `json:"phone_number"`
+	Code        string `json:"code"`
+}
+
+// Validate normalises the phone number and checks the code format
+func (r *OTPVerifyRequest) Validate() error {
+	r.PhoneNumber = NormalizePhone(r.PhoneNumber)
+	if len(r.PhoneNumber) < 10 {
+		return errors.New("Format nomor telepon tidak valid")
+	}
+	if !otpCodeRegex.MatchString(r.Code) {
+		return errors.New("Kode OTP harus 6 digit")
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package model
+
+// Default and maximum page sizes for cursor pagination
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 200
+)
+
+// PageRequest selects one page of a newest-first list. Cursor is the ID of the
+// last row of the previous page; 0 starts from the newest row.
+type PageRequest struct {
+	Cursor int
+	Limit  int
+}
+
+// PageLimit returns the page size, applying the default and maximum
+func (p PageRequest) PageLimit() int {
+	switch {
+	case p.Limit <= 0:
+		return DefaultPageLimit
+	case p.Limit > MaxPageLimit:
+		return MaxPageLimit
+	default:
+		return p.Limit
+	}
+}
@@ -0,0 +1,183 @@
+package model
+
+import "fmt"
+
+// Plan limits. Cars and sales seats are counted from the data; AI generations
+// and WhatsApp messages are metered per period in tenant_usage.
+const (
+	LimitCars             = "cars"
+	LimitSalesSeats       = "sales_seats"
+	LimitAIGenerations    = "ai_generations"
+	LimitWhatsAppMessages = "whatsapp_messages"
+)
+
+// Plan features: optional modules a plan switches on
+const (
+	FeatureBlogAI    = "blog_ai"
+	FeatureFinancing = "financing"
+	FeatureWebhooks  = "webhooks"
+)
+
+// Usage periods of metered limits
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// LimitInfo describes a plan limit
+type LimitInfo struct {
+	Key    string
+	Label  string // what is counted, e.g. "mobil"
+	Period string // reset period of metered limits; empty for counted ones
+}
+
+// PlanLimits lists the plan limits in display order
+var PlanLimits = []LimitInfo{
+	{Key: LimitCars, Label: "mobil"},
+	{Key: LimitSalesSeats, Label: "sales"},
+	{Key: LimitAIGenerations, Label: "generate AI", Period: PeriodMonth},
+	{Key: LimitWhatsAppMessages, Label: "pesan WhatsApp bot", Period: PeriodDay},
+}
+
+// PlanFeatures lists the plan features with their display names
+var PlanFeatures = []struct {
+	Key   string
+	Label string
+}{
+	{Key: FeatureBlogAI, Label: "Blog dengan AI"},
+	{Key: FeatureFinancing, Label: "Simulasi kredit & partner leasing"},
+	{Key: FeatureWebhooks, Label: "Webhook integrasi"},
+}
+
+// LookupLimit returns the description of a plan limit
+func LookupLimit(key string) (LimitInfo, bool) {
+	for _, info := range PlanLimits {
+		if info.Key == key {
+			return info, true
+		}
+	}
+	return LimitInfo{}, false
+}
+
+// featureLabel returns the display name of a feature
+func featureLabel(key string) string {
+	for _, f := range PlanFeatures {
+		if f.Key == key {
+			return f.Label
+		}
+	}
+	return key
+}
+
+// Plan is a subscription tier. A nil limit means unlimited.
+type Plan struct {
+	Code                string   `json:"code"`
+	Name                string   `json:"name"`
+	PriceMonthly        *int64   `json:"price_monthly,omitempty"` // Rupiah; nil when priced per contract
+	MaxCars             *int     `json:"max_cars"`
+	MaxSalesSeats       *int     `json:"max_sales_seats"`
+	MaxAIGenerations    *int     `json:"max_ai_generations"`    // per month
+	MaxWhatsAppMessages *int     `json:"max_whatsapp_messages"` // bot replies per day
+	Features            []string `json:"features"`
+}
+
+// Limit returns the plan's maximum for a limit; nil means unlimited
+func (p *Plan) Limit(key string) *int {
+	switch key {
+	case LimitCars:
+		return p.MaxCars
+	case LimitSalesSeats:
+		return p.MaxSalesSeats
+	case LimitAIGenerations:
+		return p.MaxAIGenerations
+	case LimitWhatsAppMessages:
+		return p.MaxWhatsAppMessages
+	}
+	return nil
+}
+
+// HasFeature reports whether the plan includes a feature
+func (p *Plan) HasFeature(feature string) bool {
+	for _, f := range p.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// UsageMetric is how much of one limit a tenant has used
+type UsageMetric struct {
+	Key    string `json:"key"`
+	Label  string `json:"label"`
+	Period string `json:"period,omitempty"`
+	Used   int    `json:"used"`
+	Max    *int   `json:"max"` // nil means unlimited
+}
+
+// UsageFeature tells whether a feature is in the tenant's plan
+type UsageFeature struct {
+	Key     string `json:"key"`
+	Label   string `json:"label"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Usage is a tenant's plan with its current consumption
+type Usage struct {
+	Plan     *Plan          `json:"plan"`
+	Metrics  []UsageMetric  `json:"metrics"`
+	Features []UsageFeature `json:"features"`
+}
+
+// NewUsage combines a plan with the used amount of each limit
+func NewUsage(plan *Plan, used map[string]int) *Usage {
+	usage := &Usage{Plan: plan}
+	for _, info := range PlanLimits {
+		usage.Metrics = append(usage.Metrics, UsageMetric{
+			Key:    info.Key,
+			Label:  info.Label,
+			Period: info.Period,
+			Used:   used[info.Key],
+			Max:    plan.Limit(info.Key),
+		})
+	}
+	for _, f := range PlanFeatures {
+		usage.Features = append(usage.Features, UsageFeature{Key: f.Key, Label: f.Label, Enabled: plan.HasFeature(f.Key)})
+	}
+	return usage
+}
+
+// PlanLimitError is returned when an action would go over a plan limit. Its
+// message is shown to the user as is.
+type PlanLimitError struct {
+	Limit string
+	Max   int
+	Plan  string // plan name
+}
+
+func (e *PlanLimitError) Error() string {
+	info, _ := LookupLimit(e.Limit)
+	label := info.Label
+	if label == "" {
+		label = e.Limit
+	}
+	var period string
+	switch info.Period {
+	case PeriodDay:
+		period = " per hari"
+	case PeriodMonth:
+		period = " per bulan"
+	}
+	return fmt.Sprintf("Batas paket %s tercapai: maksimal %d %s%s. Upgrade paket untuk menambah kuota.", e.Plan, e.Max, label, period)
+}
+
+// FeatureUnavailableError is returned when the tenant's plan does not include
+// a feature. Its message is shown to the user as is.
+type FeatureUnavailableError struct {
+	Feature string
+	Plan    string // plan name
+}
+
+func (e *FeatureUnavailableError) Error() string {
+	return fmt.Sprintf("Fitur %s tidak termasuk dalam paket %s. Upgrade paket untuk memakainya.", featureLabel(e.Feature), e.Plan)
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// RateLimit is a token bucket budget: Requests tokens that refill evenly over
+// Per. A zero RateLimit disables limiting.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// Enabled reports whether the limit restricts anything
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+// RefillRate returns the tokens added per second
+func (l RateLimit) RefillRate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
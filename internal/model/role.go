@@ -0,0 +1,73 @@
+package model
+
+// User roles. Super-admins belong to the platform (no tenant); the others to one tenant.
+const (
+	RoleSuperAdmin = "super_admin"
+	RoleOwner      = "owner"
+	RoleAdmin      = "admin"
+	RoleSales      = "sales"
+)
+
+// Permission is an action a role may perform
+type Permission string
+
+const (
+	PermManageTenants   Permission = "tenants:manage"   // platform tenant management
+	PermManageUsers     Permission = "users:manage"     // admin accounts and WhatsApp sales numbers
+	PermManageSettings  Permission = "settings:manage"  // branding, showroom, WhatsApp, financing partners
+	PermManageBilling   Permission = "billing:manage"   // commission rules and plans
+	PermManageInventory Permission = "inventory:manage" // create, edit and delete cars
+	PermManageBlog      Permission = "blog:manage"
+	PermManageCustomers Permission = "customers:manage" // every lead, conversation, customer and deal
+	PermViewAssigned    Permission = "assigned:view"    // leads and conversations assigned to me
+	PermViewAnalytics   Permission = "analytics:view"
+	PermViewAudit       Permission = "audit:view" // audit trail of admin and bot changes
+)
+
+// AllPermissions lists every permission, e.g. to tell templates what a user can do
+var AllPermissions = []Permission{
+	PermManageTenants, PermManageUsers, PermManageSettings, PermManageBilling, PermManageInventory,
+	PermManageBlog, PermManageCustomers, PermViewAssigned, PermViewAnalytics, PermViewAudit,
+}
+
+var rolePermissions = map[string][]Permission{
+	RoleOwner: {
+		PermManageUsers, PermManageSettings, PermManageBilling, PermManageInventory,
+		PermManageBlog, PermManageCustomers, PermViewAssigned, PermViewAnalytics, PermViewAudit,
+	},
+	RoleAdmin: {
+		PermManageInventory, PermManageBlog, PermManageCustomers, PermViewAssigned, PermViewAnalytics,
+	},
+	RoleSales: {
+		PermViewAssigned,
+	},
+}
+
+// RoleHasPermission reports whether a role grants a permission. Super-admins have all.
+func RoleHasPermission(role string, perm Permission) bool {
+	if role == RoleSuperAdmin {
+		return true
+	}
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTenantRole reports whether role can be given to a tenant user
+func IsTenantRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Can reports whether the user's role grants a permission
+func (u *User) Can(perm Permission) bool {
+	return RoleHasPermission(u.Role, perm)
+}
+
+// IsSuperAdmin reports whether the user is a platform super-admin
+func (u *User) IsSuperAdmin() bool {
+	return u.Role == RoleSuperAdmin
+}
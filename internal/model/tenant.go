@@ -1,16 +1,34 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Tenant statuses. Only active tenants are served; a suspended tenant gets a
+// "suspended" page and a deleted one is purged after its grace period.
+const (
+	TenantActive    = "active"
+	TenantSuspended = "suspended"
+	TenantDeleted   = "deleted"
+)
 
 type Tenant struct {
-	ID             int       `json:"id"`
-	Domain         string    `json:"domain"`
-	Name           string    `json:"name"`
-	WhatsAppNumber *string   `json:"whatsapp_number,omitempty"`
-	PairingStatus  string    `json:"pairing_status"`
-	Status         string    `json:"status"`
-	CreatedAt      time.Time `json:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at"`
+	ID              int        `json:"id"`
+	Domain          string     `json:"domain"`
+	Name            string     `json:"name"`
+	WhatsAppNumber  *string    `json:"whatsapp_number,omitempty"`
+	PairingStatus   string     `json:"pairing_status"`
+	Status          string     `json:"status"`
+	Plan            string     `json:"plan"` // code of the subscription plan
+	SuspendedAt     *time.Time `json:"suspended_at,omitempty"`
+	SuspendedReason *string    `json:"suspended_reason,omitempty"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+	PurgeAfter      *time.Time `json:"purge_after,omitempty"` // data is deleted for good after this
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type CreateTenantRequest struct {
@@ -18,3 +36,96 @@ type CreateTenantRequest struct {
 	Name           string  `json:"name"`
 	WhatsAppNumber *string `json:"whatsapp_number,omitempty"`
 }
+
+// Validate checks the tenant and normalizes its domain
+func (r *CreateTenantRequest) Validate() error {
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		return errors.New("Nama tenant wajib diisi")
+	}
+	domain, err := ValidateDomain(r.Domain)
+	if err != nil {
+		return err
+	}
+	r.Domain = domain
+	return nil
+}
+
+// UpdateTenantRequest changes a tenant; nil fields are left as they are.
+// Status moves the tenant through its lifecycle like the dedicated endpoints.
+type UpdateTenantRequest struct {
+	Name   *string `json:"name,omitempty"`
+	Domain *string `json:"domain,omitempty"`
+	Status *string `json:"status,omitempty"` // active, suspended or deleted
+	Reason string  `json:"reason,omitempty"` // shown to the platform admins when suspending
+	Plan   *string `json:"plan,omitempty"`   // code of the subscription plan
+}
+
+// Validate checks the update and normalizes the domain
+func (r *UpdateTenantRequest) Validate() error {
+	if r.Name != nil {
+		name := strings.TrimSpace(*r.Name)
+		if name == "" {
+			return errors.New("Nama tenant wajib diisi")
+		}
+		r.Name = &name
+	}
+	if r.Domain != nil {
+		domain, err := ValidateDomain(*r.Domain)
+		if err != nil {
+			return err
+		}
+		r.Domain = &domain
+	}
+	if r.Status != nil && *r.Status != TenantActive && *r.Status != TenantSuspended && *r.Status != TenantDeleted {
+		return errors.New("Status harus active, suspended, atau deleted")
+	}
+	if r.Plan != nil {
+		plan := strings.TrimSpace(*r.Plan)
+		if plan == "" {
+			return errors.New("Paket wajib diisi")
+		}
+		r.Plan = &plan
+	}
+	r.Reason = strings.TrimSpace(r.Reason)
+	return nil
+}
+
+// reservedDomains are answered by TenantExtractor itself
+var reservedDomains = map[string]bool{
+	"localhost":          true,
+	"admin.localhost":    true,
+	"admin.platform.com": true,
+}
+
+var domainPattern = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z][a-z0-9-]{0,61}[a-z0-9]$`)
+
+// NormalizeDomain reduces what admins paste (a URL, upper case, a port or a
+// trailing dot) to the bare host name tenants are looked up by
+func NormalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(domain, "https://")
+	domain = strings.TrimPrefix(domain, "http://")
+	if i := strings.IndexAny(domain, "/?#"); i != -1 {
+		domain = domain[:i]
+	}
+	if i := strings.LastIndex(domain, ":"); i != -1 {
+		domain = domain[:i]
+	}
+	return strings.TrimSuffix(domain, ".")
+}
+
+// ValidateDomain normalizes a tenant domain and checks it is a usable host name
+func ValidateDomain(domain string) (string, error) {
+	domain = NormalizeDomain(domain)
+	if domain == "" {
+		return "", errors.New("Domain wajib diisi")
+	}
+	if len(domain) > 253 || !domainPattern.MatchString(domain) {
+		return "", errors.New("Domain tidak valid, contoh: showroom.example.com")
+	}
+	if reservedDomains[domain] {
+		return "", errors.New("Domain " + domain + " dipakai oleh platform")
+	}
+	return domain, nil
+}
@@ -0,0 +1,92 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// TradeIn represents a customer's old car offered as part of a purchase (tukar tambah)
+type TradeIn struct {
+	ID              int       `json:"id"`
+	TenantID        int       `json:"tenant_id"`
+	LeadID          *int      `json:"lead_id,omitempty"`
+	PhoneNumber     string    `json:"phone_number"`
+	CustomerName    *string   `json:"customer_name,omitempty"`
+	Brand           string    `json:"brand"`
+	Model           string    `json:"model"`
+	Year            int       `json:"year"`
+	Mileage         *int      `json:"mileage,omitempty"`
+	Transmission    *string   `json:"transmission,omitempty"`
+	EstimateLow     int64     `json:"estimate_low"`
+	EstimateHigh    int64     `json:"estimate_high"`
+	ComparableCount int       `json:"comparable_count"`
+	AppraisedPrice  *int64    `json:"appraised_price,omitempty"`
+	AppraisedBy     *string   `json:"appraised_by,omitempty"`
+	AppraisalNotes  *string   `json:"appraisal_notes,omitempty"`
+	Status          string    `json:"status"` // estimated, appraised, accepted, rejected
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TradeInRequest represents the customer's description of the car to trade in
+type TradeInRequest struct {
+	PhoneNumber  string  `json:"phone_number"`
+	Name         *string `json:"name,omitempty"`
+	Brand        string  `json:"brand"`
+	Model        string  `json:"model"`
+	Year         int     `json:"year"`
+	Mileage      *int    `json:"mileage,omitempty"`
+	Transmission *string `json:"transmission,omitempty"`
+}
+
+// Validate checks the trade-in request
+func (r *TradeInRequest) Validate() error {
+	if strings.TrimSpace(r.PhoneNumber) == "" {
+		return errors.New("Nomor WhatsApp tidak boleh kosong")
+	}
+	if strings.TrimSpace(r.Brand) == "" || strings.TrimSpace(r.Model) == "" {
+		return errors.New("Merek dan model mobil tidak boleh kosong")
+	}
+	if r.Year < 1980 || r.Year > time.Now().Year()+1 {
+		return errors.New("Tahun mobil tidak valid")
+	}
+	if r.Mileage != nil && *r.Mileage < 0 {
+		return errors.New("Kilometer tidak boleh negatif")
+	}
+	return nil
+}
+
+// TradeInEstimate is the computed price range for a trade-in car
+type TradeInEstimate struct {
+	EstimateLow     int64  `json:"estimate_low"`
+	EstimateHigh    int64  `json:"estimate_high"`
+	ComparableCount int    `json:"comparable_count"`
+	Note            string `json:"note"`
+}
+
+// TradeInAppraisalRequest records the sales team's final appraisal
+type TradeInAppraisalRequest struct {
+	AppraisedPrice int64   `json:"appraised_price"`
+	AppraisedBy    string  `json:"appraised_by"`
+	Notes          *string `json:"notes,omitempty"`
+	Status         string  `json:"status"`
+}
+
+// Validate checks the appraisal request
+func (r *TradeInAppraisalRequest) Validate() error {
+	if r.AppraisedPrice <= 0 {
+		return errors.New("Harga appraisal harus lebih dari 0")
+	}
+	if strings.TrimSpace(r.AppraisedBy) == "" {
+		return errors.New("Nama penilai tidak boleh kosong")
+	}
+	switch r.Status {
+	case "":
+		r.Status = "appraised"
+	case "appraised", "accepted", "rejected":
+	default:
+		return errors.New("Status harus appraised, accepted, atau rejected")
+	}
+	return nil
+}
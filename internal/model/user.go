@@ -0,0 +1,96 @@
+package model
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// User is an admin panel account belonging to a tenant, or to the platform for super-admins
+type User struct {
+	ID           int        `json:"id"`
+	TenantID     int        `json:"tenant_id"` // 0 for platform super-admins
+	Email        string     `json:"email"`
+	PasswordHash string     `json:"-"`
+	Name         *string    `json:"name,omitempty"`
+	Role         string     `json:"role"`               // super_admin, owner, admin, sales
+	SalesID      *int       `json:"sales_id,omitempty"` // linked WhatsApp sales record for sales users
+	Status       string     `json:"status"`             // active, disabled
+	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// DisplayName returns the user's name, falling back to the email address
+func (u *User) DisplayName() string {
+	if u.Name != nil && *u.Name != "" {
+		return *u.Name
+	}
+	return u.Email
+}
+
+// CreateUserRequest represents a new admin account
+type CreateUserRequest struct {
+	Email    string  `json:"email"`
+	Name     *string `json:"name,omitempty"`
+	Password string  `json:"password"`
+	Role     string  `json:"role,omitempty"`
+	SalesID  *int    `json:"sales_id,omitempty"`
+}
+
+// Validate checks the create user request
+func (r *CreateUserRequest) Validate() error {
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
+	if _, err := mail.ParseAddress(r.Email); err != nil {
+		return errors.New("Format email tidak valid")
+	}
+	if len(r.Password) < 8 {
+		return errors.New("Password minimal 8 karakter")
+	}
+	if r.Role == "" {
+		r.Role = RoleAdmin
+	}
+	if r.Role != RoleSuperAdmin && !IsTenantRole(r.Role) {
+		return errors.New("Role harus owner, admin, atau sales")
+	}
+	if r.Role == RoleSales && r.SalesID == nil {
+		return errors.New("User sales harus dihubungkan ke data sales")
+	}
+	return nil
+}
+
+// LoginRequest represents email/password credentials
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Validate checks the login request
+func (r *LoginRequest) Validate() error {
+	r.Email = strings.ToLower(strings.TrimSpace(r.Email))
+	if r.Email == "" || r.Password == "" {
+		return errors.New("Email dan password wajib diisi")
+	}
+	return nil
+}
+
+// Session is a login session; the raw token is only known to the client
+type Session struct {
+	ID        int       `json:"id"`
+	TenantID  int       `json:"tenant_id"`
+	UserID    int       `json:"user_id"`
+	UserAgent *string   `json:"user_agent,omitempty"`
+	IPAddress *string   `json:"ip_address,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuthResult is returned after a successful login
+type AuthResult struct {
+	User         *User     `json:"user"`
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	SessionToken string    `json:"-"`
+}
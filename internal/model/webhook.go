@@ -0,0 +1,136 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook event types a tenant can subscribe to
+const (
+	WebhookEventCarCreated          = "car.created"
+	WebhookEventCarSold             = "car.sold"
+	WebhookEventLeadCreated         = "lead.created"
+	WebhookEventConversationHandoff = "conversation.handoff" // bot conversation assigned to a sales person
+	WebhookEventMessageInbound      = "message.inbound"
+	WebhookEventPing                = "ping" // test delivery sent from the admin panel
+)
+
+// AllWebhookEvents lists the events endpoints can subscribe to
+var AllWebhookEvents = []string{
+	WebhookEventCarCreated,
+	WebhookEventCarSold,
+	WebhookEventLeadCreated,
+	WebhookEventConversationHandoff,
+	WebhookEventMessageInbound,
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed" // gave up after the last retry
+)
+
+// WebhookEndpoint is a tenant's URL subscribed to events. Secret signs the
+// payloads and is only shown when the endpoint is created or its secret rotated.
+type WebhookEndpoint struct {
+	ID          int       `json:"id"`
+	TenantID    int       `json:"tenant_id"`
+	URL         string    `json:"url"`
+	Description *string   `json:"description,omitempty"`
+	Secret      string    `json:"-"`
+	Events      []string  `json:"events"`
+	IsActive    bool      `json:"is_active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// WebhookEndpointRequest creates or updates a webhook endpoint
+type WebhookEndpointRequest struct {
+	URL         string   `json:"url"`
+	Description *string  `json:"description,omitempty"`
+	Events      []string `json:"events"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+// Validate checks the webhook endpoint request
+func (r *WebhookEndpointRequest) Validate() error {
+	r.URL = strings.TrimSpace(r.URL)
+	u, err := url.Parse(r.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errors.New("URL webhook harus diawali http:// atau https://")
+	}
+	if len(r.Events) == 0 {
+		return errors.New("Pilih minimal satu event")
+	}
+	for _, event := range r.Events {
+		if !isWebhookEvent(event) {
+			return errors.New("Event tidak dikenal: " + event)
+		}
+	}
+	if r.IsActive == nil {
+		active := true
+		r.IsActive = &active
+	}
+	return nil
+}
+
+func isWebhookEvent(event string) bool {
+	for _, e := range AllWebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEvent is the JSON body POSTed to endpoints
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	TenantID  int         `json:"tenant_id"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookDelivery is one event queued for one endpoint, with the outcome of its
+// latest attempt
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	TenantID       int             `json:"tenant_id"`
+	EndpointID     int             `json:"endpoint_id"`
+	EndpointURL    string          `json:"endpoint_url,omitempty"`
+	EventID        string          `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"` // pending, succeeded, failed
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	RedeliveryOf   *int64          `json:"redelivery_of,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// WebhookDispatch is a claimed delivery with what the dispatcher needs to send it
+type WebhookDispatch struct {
+	DeliveryID int64
+	EventID    string
+	EventType  string
+	Payload    []byte
+	Attempt    int // 1 for the first attempt
+	URL        string
+	Secret     string
+}
+
+// WebhookDeliveryFilter narrows the delivery log. Empty fields match everything.
+type WebhookDeliveryFilter struct {
+	EndpointID int
+	Status     string
+	EventType  string
+	Page       PageRequest
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, tenant_id, name, prefix, key_hash, scopes, created_by, last_used_at, expires_at, revoked_at, created_at`
+
+// apiKeySnapshotQuery loads an API key row for the audit log, without its hash
+const apiKeySnapshotQuery = "SELECT to_jsonb(k) - 'key_hash' FROM api_keys k WHERE k.id = $1 AND k.tenant_id = $2"
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*model.APIKey, error) {
+	key := &model.APIKey{}
+	err := row.Scan(&key.ID, &key.TenantID, &key.Name, &key.Prefix, &key.KeyHash, pq.Array(&key.Scopes),
+		&key.CreatedBy, &key.LastUsedAt, &key.ExpiresAt, &key.RevokedAt, &key.CreatedAt)
+	return key, err
+}
+
+// Create stores a new API key by hash (tenant-scoped)
+func (r *APIKeyRepository) Create(ctx context.Context, req *model.CreateAPIKeyRequest, prefix, keyHash string) (*model.APIKey, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	var createdBy *int
+	if userID, err := model.GetUserID(ctx); err == nil {
+		createdBy = &userID
+	}
+
+	query := `
+		INSERT INTO api_keys (tenant_id, name, prefix, key_hash, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING ` + apiKeyColumns
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	key, err := scanAPIKey(tx.QueryRowContext(ctx, query,
+		tenantID, req.Name, prefix, keyHash, pq.Array(req.Scopes), createdBy, req.ExpiresAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	after, err := snapshotRow(ctx, tx, apiKeySnapshotQuery, key.ID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, tx, "api_key", entityRef(key.ID), "create", nil, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return key, nil
+}
+
+// List returns the tenant's API keys, newest first, including revoked ones (tenant-scoped)
+func (r *APIKeyRepository) List(ctx context.Context) ([]*model.APIKey, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE tenant_id = $1 ORDER BY created_at DESC, id DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*model.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Revoke disables an API key immediately (tenant-scoped)
+func (r *APIKeyRepository) Revoke(ctx context.Context, id int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	result, err := auditedExec(ctx, r.db, auditTarget{
+		EntityType:   "api_key",
+		EntityID:     entityRef(id),
+		Action:       "revoke",
+		Snapshot:     apiKeySnapshotQuery,
+		SnapshotArgs: []interface{}{id, tenantID},
+	}, "UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND tenant_id = $2 AND revoked_at IS NULL", id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key: %w", err)
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return fmt.Errorf("API key not found or no permission")
+	}
+
+	return nil
+}
+
+// GetActiveByHash returns a live key of an active tenant. The tenant is not known
+// before the lookup, so it runs across tenants.
+func (r *APIKeyRepository) GetActiveByHash(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	query := `
+		SELECT k.id, k.tenant_id, k.name, k.prefix, k.key_hash, k.scopes, k.created_by,
+			k.last_used_at, k.expires_at, k.revoked_at, k.created_at
+		FROM api_keys k
+		INNER JOIN tenants t ON t.id = k.tenant_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL
+			AND (k.expires_at IS NULL OR k.expires_at > CURRENT_TIMESTAMP)
+			AND t.status = 'active'
+	`
+
+	key, err := scanAPIKey(r.db.QueryRowContext(model.WithSystemScope(ctx), query, keyHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("API key not found")
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+
+	return key, nil
+}
+
+// TouchLastUsed records that a key was used, at most once a minute to keep
+// busy integrations from writing on every request
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id int) error {
+	query := `
+		UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+	`
+
+	if _, err := r.db.ExecContext(model.WithSystemScope(ctx), query, id); err != nil {
+		return fmt.Errorf("failed to update API key usage: %w", err)
+	}
+
+	return nil
+}
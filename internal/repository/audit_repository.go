@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+// queryRower is implemented by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+const auditColumns = `id, tenant_id, actor_type, actor, entity_type, entity_id, action, before_data, after_data, created_at`
+
+func scanAuditLog(row interface{ Scan(...interface{}) error }) (*model.AuditLog, error) {
+	entry := &model.AuditLog{}
+	var entityID sql.NullInt64
+	var before, after []byte
+	err := row.Scan(&entry.ID, &entry.TenantID, &entry.ActorType, &entry.Actor, &entry.EntityType,
+		&entityID, &entry.Action, &before, &after, &entry.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if entityID.Valid {
+		id := int(entityID.Int64)
+		entry.EntityID = &id
+	}
+	if before != nil {
+		if err := json.Unmarshal(before, &entry.Before); err != nil {
+			return nil, fmt.Errorf("failed to decode audit data: %w", err)
+		}
+	}
+	if after != nil {
+		if err := json.Unmarshal(after, &entry.After); err != nil {
+			return nil, fmt.Errorf("failed to decode audit data: %w", err)
+		}
+	}
+	return entry, nil
+}
+
+// Create stores an audit log entry (tenant-scoped)
+func (r *AuditRepository) Create(ctx context.Context, entry *model.AuditLog) error {
+	return insertAuditLog(ctx, r.db, entry)
+}
+
+// List returns audit entries matching the filter, newest first, with the total match count
+func (r *AuditRepository) List(ctx context.Context, filter *model.AuditLogFilter) ([]*model.AuditLog, int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	where := " WHERE tenant_id = $1"
+	args := []interface{}{tenantID}
+	argCount := 1
+
+	if filter.Search != "" {
+		argCount++
+		where += fmt.Sprintf(" AND (actor ILIKE $%d OR entity_type ILIKE $%d OR action ILIKE $%d)", argCount, argCount, argCount)
+		args = append(args, "%"+filter.Search+"%")
+	}
+	for column, value := range map[string]string{
+		"entity_type": filter.EntityType,
+		"actor_type":  filter.ActorType,
+		"action":      filter.Action,
+	} {
+		if value != "" {
+			argCount++
+			where += fmt.Sprintf(" AND %s = $%d", column, argCount)
+			args = append(args, value)
+		}
+	}
+	if filter.EntityID != nil {
+		argCount++
+		where += fmt.Sprintf(" AND entity_id = $%d", argCount)
+		args = append(args, *filter.EntityID)
+	}
+	if filter.From != nil {
+		argCount++
+		where += fmt.Sprintf(" AND created_at >= $%d", argCount)
+		args = append(args, *filter.From)
+	}
+	if filter.To != nil {
+		argCount++
+		where += fmt.Sprintf(" AND created_at < $%d", argCount)
+		args = append(args, *filter.To)
+	}
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_logs"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	query := "SELECT " + auditColumns + " FROM audit_logs" + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argCount+1, argCount+2)
+	args = append(args, limit, filter.Offset)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*model.AuditLog
+	for rows.Next() {
+		entry, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, total, nil
+}
+
+func insertAuditLog(ctx context.Context, q queryRower, entry *model.AuditLog) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	before, err := marshalAuditData(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalAuditData(entry.After)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO audit_logs (tenant_id, actor_type, actor, entity_type, entity_id, action, before_data, after_data)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	err = q.QueryRowContext(ctx, query,
+		tenantID, entry.ActorType, entry.Actor, entry.EntityType, entry.EntityID, entry.Action, before, after,
+	).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	entry.TenantID = tenantID
+	return nil
+}
+
+// recordAudit appends an audit entry for a change, normally inside the change's
+// transaction so neither is saved without the other. Only changed fields of the
+// snapshots are kept; the actor is taken from ctx.
+func recordAudit(ctx context.Context, q queryRower, entityType string, entityID *int, action string, before, after map[string]interface{}) error {
+	before, after = model.AuditDiff(before, after)
+	if before != nil && after != nil && len(before) == 0 && len(after) == 0 {
+		return nil
+	}
+
+	actorType, actor := model.GetAuditActor(ctx)
+	return insertAuditLog(ctx, q, &model.AuditLog{
+		ActorType:  actorType,
+		Actor:      actor,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Before:     before,
+		After:      after,
+	})
+}
+
+// auditTarget describes the entity a change touches. Snapshot selects
+// to_jsonb of its row using SnapshotArgs. OnChange, when set, runs in the
+// same transaction with both snapshots, e.g. to queue webhook events.
+type auditTarget struct {
+	EntityType   string
+	EntityID     *int
+	Action       string
+	Snapshot     string
+	SnapshotArgs []interface{}
+	OnChange     func(ctx context.Context, tx *sql.Tx, before, after map[string]interface{}) error
+}
+
+// auditedExec runs a single-statement change in a transaction with an audit
+// entry holding the row before and after it
+func auditedExec(ctx context.Context, db *sql.DB, target auditTarget, query string, args ...interface{}) (sql.Result, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, target.Snapshot+" FOR UPDATE", target.SnapshotArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := snapshotRow(ctx, tx, target.Snapshot, target.SnapshotArgs...)
+	if err != nil {
+		return nil, err
+	}
+	if before != nil || after != nil {
+		if err := recordAudit(ctx, tx, target.EntityType, target.EntityID, target.Action, before, after); err != nil {
+			return nil, err
+		}
+	}
+	if target.OnChange != nil {
+		if err := target.OnChange(ctx, tx, before, after); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return result, nil
+}
+
+// snapshotRow runs a query selecting one to_jsonb(row) and decodes it for an
+// audit entry. A missing row yields nil.
+func snapshotRow(ctx context.Context, q queryRower, query string, args ...interface{}) (map[string]interface{}, error) {
+	var data []byte
+	err := q.QueryRowContext(ctx, query, args...).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot row: %w", err)
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode row snapshot: %w", err)
+	}
+	return snapshot, nil
+}
+
+func marshalAuditData(data map[string]interface{}) (interface{}, error) {
+	if data == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit data: %w", err)
+	}
+	return string(b), nil
+}
+
+// entityRef returns a pointer for an audit entry's entity ID
+func entityRef(id int) *int {
+	return &id
+}
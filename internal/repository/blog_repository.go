@@ -17,6 +17,9 @@ func NewBlogRepository(db *sql.DB) *BlogRepository {
 	return &BlogRepository{db: db}
 }
 
+// blogSnapshotQuery loads a blog post row for the audit log
+const blogSnapshotQuery = "SELECT to_jsonb(b) FROM blog_posts b WHERE b.id = $1 AND b.tenant_id = $2"
+
 // Create creates a new blog post
 func (r *BlogRepository) Create(ctx context.Context, post *model.BlogPost) error {
 	tenantID, err := model.GetTenantID(ctx)
@@ -24,6 +27,10 @@ func (r *BlogRepository) Create(ctx context.Context, post *model.BlogPost) error
 		return err
 	}
 
+	if err := authorize(ctx, model.PermManageBlog); err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO blog_posts (tenant_id, title, slug, content, excerpt, status, published_at, created_by)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
@@ -38,7 +45,13 @@ func (r *BlogRepository) Create(ctx context.Context, post *model.BlogPost) error
 		publishedAt = post.PublishedAt
 	}
 
-	err = r.db.QueryRowContext(ctx, query,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, query,
 		tenantID,
 		post.Title,
 		post.Slug,
@@ -53,12 +66,28 @@ func (r *BlogRepository) Create(ctx context.Context, post *model.BlogPost) error
 		return err
 	}
 
+	after, err := snapshotRow(ctx, tx, blogSnapshotQuery, post.ID, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, tx, "blog_post", entityRef(post.ID), "create", nil, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
 	post.TenantID = tenantID
 	return nil
 }
 
-// GetByID gets a blog post by ID
+// GetByID gets a blog post by ID, drafts included
 func (r *BlogRepository) GetByID(ctx context.Context, id int) (*model.BlogPost, error) {
+	if err := authorize(ctx, model.PermManageBlog); err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT id, tenant_id, title, slug, content, excerpt, status, published_at, created_by, created_at, updated_at
 		FROM blog_posts
@@ -129,6 +158,10 @@ func (r *BlogRepository) List(ctx context.Context, statusFilter string) ([]*mode
 		return nil, err
 	}
 
+	if err := authorize(ctx, model.PermManageBlog); err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT id, tenant_id, title, slug, content, excerpt, status, published_at, created_by, created_at, updated_at
 		FROM blog_posts
@@ -214,6 +247,8 @@ func (r *BlogRepository) ListPublished(ctx context.Context, limit int) ([]*model
 			&post.Slug,
 			&post.Content,
 			&post.Excerpt,
+			&post.Status,
+			&post.PublishedAt,
 			&post.CreatedBy,
 			&post.CreatedAt,
 			&post.UpdatedAt,
@@ -233,6 +268,15 @@ func (r *BlogRepository) Update(ctx context.Context, id int, updates map[string]
 		return nil
 	}
 
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := authorize(ctx, model.PermManageBlog); err != nil {
+		return err
+	}
+
 	query := "UPDATE blog_posts SET "
 	args := []interface{}{}
 	i := 1
@@ -257,14 +301,31 @@ func (r *BlogRepository) Update(ctx context.Context, id int, updates map[string]
 	query += ", updated_at = CURRENT_TIMESTAMP WHERE id = $" + string(rune('0'+i))
 	args = append(args, id)
 
-	_, err := r.db.ExecContext(ctx, query, args...)
+	target := auditTarget{
+		EntityType: "blog_post", EntityID: entityRef(id), Action: "update",
+		Snapshot: blogSnapshotQuery, SnapshotArgs: []interface{}{id, tenantID},
+	}
+	_, err = auditedExec(ctx, r.db, target, query, args...)
 	return err
 }
 
 // Delete deletes a blog post
 func (r *BlogRepository) Delete(ctx context.Context, id int) error {
-	query := "DELETE FROM blog_posts WHERE id = $1"
-	_, err := r.db.ExecContext(ctx, query, id)
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := authorize(ctx, model.PermManageBlog); err != nil {
+		return err
+	}
+
+	target := auditTarget{
+		EntityType: "blog_post", EntityID: entityRef(id), Action: "delete",
+		Snapshot: blogSnapshotQuery, SnapshotArgs: []interface{}{id, tenantID},
+	}
+	query := "DELETE FROM blog_posts WHERE id = $1 AND tenant_id = $2"
+	_, err = auditedExec(ctx, r.db, target, query, id, tenantID)
 	return err
 }
 
@@ -303,6 +364,10 @@ func (r *BlogRepository) IsSlugUnique(ctx context.Context, slug string, excludeI
 		return false, err
 	}
 
+	if err := authorize(ctx, model.PermManageBlog); err != nil {
+		return false, err
+	}
+
 	query := "SELECT COUNT(*) FROM blog_posts WHERE tenant_id = $1 AND slug = $2"
 	args := []interface{}{tenantID, slug}
 
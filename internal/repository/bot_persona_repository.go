@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// ErrPersonaVersionNotFound is returned when restoring a persona version the
+// tenant does not have
+var ErrPersonaVersionNotFound = errors.New("bot persona version not found")
+
+// BotPersonaRepository stores the versions of each tenant's bot persona; the
+// latest version is the active one
+type BotPersonaRepository struct {
+	db *sql.DB
+}
+
+func NewBotPersonaRepository(db *sql.DB) *BotPersonaRepository {
+	return &BotPersonaRepository{db: db}
+}
+
+const botPersonaColumns = `p.version, p.name, p.tone, p.greeting, p.rules, p.instructions,
+	p.restored_from, p.created_by, p.created_at`
+
+// botPersonaSnapshotQuery loads the active persona for the audit log
+const botPersonaSnapshotQuery = `
+	SELECT to_jsonb(p) - 'tenant_id' - 'created_by' - 'created_at'
+	FROM bot_persona_versions p
+	WHERE p.tenant_id = $1
+	ORDER BY p.version DESC
+	LIMIT 1
+`
+
+func scanBotPersonaVersion(row interface{ Scan(...interface{}) error }) (*model.BotPersonaVersion, error) {
+	v := &model.BotPersonaVersion{}
+	err := row.Scan(&v.Version, &v.Name, &v.Tone, &v.Greeting, pq.Array(&v.Rules), &v.Instructions,
+		&v.RestoredFrom, &v.CreatedBy, &v.CreatedAt)
+	return v, err
+}
+
+// Active returns the persona the tenant's bot uses, the default one when the
+// tenant never changed it (tenant-scoped)
+func (r *BotPersonaRepository) Active(ctx context.Context) (*model.BotPersona, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		SELECT t.name, p.name, p.tone, p.greeting, p.rules, p.instructions
+		FROM tenants t
+		LEFT JOIN LATERAL (
+			SELECT name, tone, greeting, rules, instructions
+			FROM bot_persona_versions
+			WHERE tenant_id = t.id
+			ORDER BY version DESC
+			LIMIT 1
+		) p ON true
+		WHERE t.id = $1
+	`
+
+	var name, tone, greeting, instructions sql.NullString
+	persona := &model.BotPersona{}
+	err = r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&persona.ShowroomName, &name, &tone, &greeting, pq.Array(&persona.Rules), &instructions,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bot persona: %w", err)
+	}
+
+	persona.Name = name.String
+	persona.Tone = tone.String
+	persona.Greeting = greeting.String
+	persona.Instructions = instructions.String
+	if persona.Tone == "" {
+		persona.Tone = model.BotToneFriendly
+	}
+	return persona, nil
+}
+
+// Versions returns the tenant's persona versions, newest first (tenant-scoped)
+func (r *BotPersonaRepository) Versions(ctx context.Context, limit int) ([]*model.BotPersonaVersion, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `SELECT ` + botPersonaColumns + `
+		FROM bot_persona_versions p
+		WHERE p.tenant_id = $1
+		ORDER BY p.version DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bot persona versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []*model.BotPersonaVersion{}
+	for rows.Next() {
+		v, err := scanBotPersonaVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan bot persona version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+// Save adds persona as the tenant's new active version (tenant-scoped)
+func (r *BotPersonaRepository) Save(ctx context.Context, persona *model.BotPersona) (*model.BotPersonaVersion, error) {
+	return r.addVersion(ctx, "update", func(tx *sql.Tx, tenantID int) (*model.BotPersona, *int, error) {
+		return persona, nil, nil
+	})
+}
+
+// Restore adds a copy of an earlier version as the new active version, so the
+// history keeps the version being rolled back (tenant-scoped)
+func (r *BotPersonaRepository) Restore(ctx context.Context, version int) (*model.BotPersonaVersion, error) {
+	return r.addVersion(ctx, "restore", func(tx *sql.Tx, tenantID int) (*model.BotPersona, *int, error) {
+		query := `SELECT ` + botPersonaColumns + `
+			FROM bot_persona_versions p
+			WHERE p.tenant_id = $1 AND p.version = $2
+		`
+		old, err := scanBotPersonaVersion(tx.QueryRowContext(ctx, query, tenantID, version))
+		if err == sql.ErrNoRows {
+			return nil, nil, ErrPersonaVersionNotFound
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get bot persona version: %w", err)
+		}
+		return &old.BotPersona, &version, nil
+	})
+}
+
+// addVersion inserts the persona source picks as the next version, with an
+// audit entry comparing it to the previous one
+func (r *BotPersonaRepository) addVersion(ctx context.Context, action string, source func(tx *sql.Tx, tenantID int) (*model.BotPersona, *int, error)) (*model.BotPersonaVersion, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the tenant serializes concurrent saves, which would pick the same version
+	if _, err := tx.ExecContext(ctx, "SELECT 1 FROM tenants WHERE id = $1 FOR NO KEY UPDATE", tenantID); err != nil {
+		return nil, fmt.Errorf("failed to lock tenant: %w", err)
+	}
+
+	persona, restoredFrom, err := source(tx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	before, err := snapshotRow(ctx, tx, botPersonaSnapshotQuery, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := persona.Rules
+	if rules == nil {
+		rules = []string{}
+	}
+
+	_, actor := model.GetAuditActor(ctx)
+	query := `
+		INSERT INTO bot_persona_versions (tenant_id, version, name, tone, greeting, rules, instructions, restored_from, created_by)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5, $6, $7, $8
+		FROM bot_persona_versions WHERE tenant_id = $1
+		RETURNING version, name, tone, greeting, rules, instructions, restored_from, created_by, created_at
+	`
+	saved, err := scanBotPersonaVersion(tx.QueryRowContext(ctx, query,
+		tenantID, persona.Name, persona.Tone, persona.Greeting, pq.Array(rules), persona.Instructions,
+		restoredFrom, actor,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save bot persona: %w", err)
+	}
+
+	after, err := snapshotRow(ctx, tx, botPersonaSnapshotQuery, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, tx, "bot_persona", nil, action, before, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return saved, nil
+}
@@ -16,6 +16,15 @@ func NewBrandingRepository(db *sql.DB) *BrandingRepository {
 	return &BrandingRepository{db: db}
 }
 
+// brandingTarget describes a tenant's branding row for the audit log
+func brandingTarget(tenantID int, action string) auditTarget {
+	return auditTarget{
+		EntityType: "branding", Action: action,
+		Snapshot:     "SELECT to_jsonb(b) FROM tenant_branding b WHERE b.tenant_id = $1",
+		SnapshotArgs: []interface{}{tenantID},
+	}
+}
+
 // GetByTenantID retrieves branding settings for a specific tenant
 func (r *BrandingRepository) GetByTenantID(ctx context.Context, tenantID int) (*model.BrandingSettings, error) {
 	query := `
@@ -57,6 +66,10 @@ func (r *BrandingRepository) GetByTenantID(ctx context.Context, tenantID int) (*
 
 // CreateOrUpdate creates or updates branding settings for a tenant
 func (r *BrandingRepository) CreateOrUpdate(ctx context.Context, branding *model.BrandingSettings) error {
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO tenant_branding (
 			tenant_id, logo_path, favicon_path, custom_title, custom_subtitle,
@@ -74,7 +87,7 @@ func (r *BrandingRepository) CreateOrUpdate(ctx context.Context, branding *model
 
 	branding.UpdatedAt = time.Now()
 
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := auditedExec(ctx, r.db, brandingTarget(branding.TenantID, "update"), query,
 		branding.TenantID,
 		branding.LogoPath,
 		branding.FaviconPath,
@@ -90,6 +103,10 @@ func (r *BrandingRepository) CreateOrUpdate(ctx context.Context, branding *model
 
 // UpdateLogo updates only the logo path for a tenant
 func (r *BrandingRepository) UpdateLogo(ctx context.Context, tenantID int, logoPath string) error {
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO tenant_branding (tenant_id, logo_path, header_style, updated_at)
 		VALUES ($1, $2, 'default', $3)
@@ -98,12 +115,16 @@ func (r *BrandingRepository) UpdateLogo(ctx context.Context, tenantID int, logoP
 			updated_at = EXCLUDED.updated_at
 	`
 
-	_, err := r.db.ExecContext(ctx, query, tenantID, logoPath, time.Now())
+	_, err := auditedExec(ctx, r.db, brandingTarget(tenantID, "update_logo"), query, tenantID, logoPath, time.Now())
 	return err
 }
 
 // UpdateFavicon updates only the favicon path for a tenant
 func (r *BrandingRepository) UpdateFavicon(ctx context.Context, tenantID int, faviconPath string) error {
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO tenant_branding (tenant_id, favicon_path, header_style, updated_at)
 		VALUES ($1, $2, 'default', $3)
@@ -112,6 +133,6 @@ func (r *BrandingRepository) UpdateFavicon(ctx context.Context, tenantID int, fa
 			updated_at = EXCLUDED.updated_at
 	`
 
-	_, err := r.db.ExecContext(ctx, query, tenantID, faviconPath, time.Now())
+	_, err := auditedExec(ctx, r.db, brandingTarget(tenantID, "update_favicon"), query, tenantID, faviconPath, time.Now())
 	return err
 }
@@ -17,6 +17,9 @@ func NewCarRepository(db *sql.DB) *CarRepository {
 	return &CarRepository{db: db}
 }
 
+// carSnapshotQuery loads a car row for the audit log
+const carSnapshotQuery = "SELECT to_jsonb(c) FROM cars c WHERE c.id = $1 AND c.tenant_id = $2"
+
 // Create creates a new car (tenant-scoped)
 func (r *CarRepository) Create(ctx context.Context, car *model.Car) error {
 	tenantID, err := model.GetTenantID(ctx)
@@ -24,6 +27,10 @@ func (r *CarRepository) Create(ctx context.Context, car *model.Car) error {
 		return fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	if err := authorize(ctx, model.PermManageInventory); err != nil {
+		return err
+	}
+
 	query := `
 		INSERT INTO cars (
 			tenant_id, brand, model, year, price, mileage, transmission,
@@ -32,7 +39,17 @@ func (r *CarRepository) Create(ctx context.Context, car *model.Car) error {
 		RETURNING id, created_at, updated_at
 	`
 
-	err = r.db.QueryRowContext(ctx, query,
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkPlanLimit(ctx, tx, tenantID, model.LimitCars, 1); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, query,
 		tenantID, car.Brand, car.Model, car.Year, car.Price, car.Mileage,
 		car.Transmission, car.FuelType, car.EngineCC, car.Seats, car.Color,
 		car.Description, car.Status, car.IsFeatured,
@@ -42,6 +59,21 @@ func (r *CarRepository) Create(ctx context.Context, car *model.Car) error {
 		return fmt.Errorf("failed to create car: %w", err)
 	}
 
+	after, err := snapshotRow(ctx, tx, carSnapshotQuery, car.ID, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, tx, "car", entityRef(car.ID), "create", nil, after); err != nil {
+		return err
+	}
+	if err := enqueueWebhook(ctx, tx, model.WebhookEventCarCreated, after); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	car.TenantID = tenantID
 	return nil
 }
@@ -155,6 +187,10 @@ func (r *CarRepository) Update(ctx context.Context, id int, updates map[string]i
 		return fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	if err := authorize(ctx, model.PermManageInventory); err != nil {
+		return err
+	}
+
 	// Build dynamic update query
 	setClauses := []string{}
 	args := []interface{}{}
@@ -199,7 +235,12 @@ func (r *CarRepository) Update(ctx context.Context, id int, updates map[string]i
 		tenantPlaceholder,
 	)
 
-	result, err := r.db.ExecContext(ctx, query, args...)
+	target := auditTarget{
+		EntityType: "car", EntityID: entityRef(id), Action: "update",
+		Snapshot: carSnapshotQuery, SnapshotArgs: []interface{}{id, tenantID},
+		OnChange: queueCarSold,
+	}
+	result, err := auditedExec(ctx, r.db, target, query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update car: %w", err)
 	}
@@ -216,6 +257,14 @@ func (r *CarRepository) Update(ctx context.Context, id int, updates map[string]i
 	return nil
 }
 
+// queueCarSold queues the car.sold webhook when an update marks a car sold
+func queueCarSold(ctx context.Context, tx *sql.Tx, before, after map[string]interface{}) error {
+	if after == nil || after["status"] != "sold" || (before != nil && before["status"] == "sold") {
+		return nil
+	}
+	return enqueueWebhook(ctx, tx, model.WebhookEventCarSold, after)
+}
+
 // Delete soft deletes a car (tenant-scoped)
 func (r *CarRepository) Delete(ctx context.Context, id int) error {
 	tenantID, err := model.GetTenantID(ctx)
@@ -223,8 +272,16 @@ func (r *CarRepository) Delete(ctx context.Context, id int) error {
 		return fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	if err := authorize(ctx, model.PermManageInventory); err != nil {
+		return err
+	}
+
+	target := auditTarget{
+		EntityType: "car", EntityID: entityRef(id), Action: "delete",
+		Snapshot: carSnapshotQuery, SnapshotArgs: []interface{}{id, tenantID},
+	}
 	query := "DELETE FROM cars WHERE id = $1 AND tenant_id = $2"
-	result, err := r.db.ExecContext(ctx, query, id, tenantID)
+	result, err := auditedExec(ctx, r.db, target, query, id, tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to delete car: %w", err)
 	}
@@ -340,6 +397,10 @@ func (r *CarRepository) CreateWithPhotos(ctx context.Context, car *model.Car, ph
 		return 0, fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	if err := authorize(ctx, model.PermManageInventory); err != nil {
+		return 0, err
+	}
+
 	// Start transaction
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -347,6 +408,10 @@ func (r *CarRepository) CreateWithPhotos(ctx context.Context, car *model.Car, ph
 	}
 	defer tx.Rollback()
 
+	if err := checkPlanLimit(ctx, tx, tenantID, model.LimitCars, 1); err != nil {
+		return 0, err
+	}
+
 	// Create the car
 	query := `
 		INSERT INTO cars (
@@ -385,6 +450,20 @@ func (r *CarRepository) CreateWithPhotos(ctx context.Context, car *model.Car, ph
 		}
 	}
 
+	after, err := snapshotRow(ctx, tx, carSnapshotQuery, car.ID, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	if after != nil {
+		after["photos"] = photoURLs
+	}
+	if err := recordAudit(ctx, tx, "car", entityRef(car.ID), "create", nil, after); err != nil {
+		return 0, err
+	}
+	if err := enqueueWebhook(ctx, tx, model.WebhookEventCarCreated, after); err != nil {
+		return 0, err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return 0, fmt.Errorf("failed to commit transaction: %w", err)
@@ -405,6 +484,10 @@ func (r *CarRepository) AddPhotos(ctx context.Context, carID int, photoURLs []st
 		return fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	if err := authorize(ctx, model.PermManageInventory); err != nil {
+		return err
+	}
+
 	// Start transaction
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -448,6 +531,11 @@ func (r *CarRepository) AddPhotos(ctx context.Context, carID int, photoURLs []st
 		}
 	}
 
+	after := map[string]interface{}{"photos": photoURLs}
+	if err := recordAudit(ctx, tx, "car", entityRef(carID), "add_photos", nil, after); err != nil {
+		return err
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
@@ -492,6 +580,28 @@ func (r *CarRepository) DeletePhoto(ctx context.Context, photoID int) error {
 		return fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	if err := authorize(ctx, model.PermManageInventory); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, `
+		SELECT to_jsonb(p) FROM car_photos p
+		JOIN cars c ON c.id = p.car_id
+		WHERE p.id = $1 AND c.tenant_id = $2
+	`, photoID, tenantID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return fmt.Errorf("photo not found or no permission")
+	}
+
 	query := `
 		DELETE FROM car_photos
 		WHERE id = $1 AND EXISTS (
@@ -500,18 +610,17 @@ func (r *CarRepository) DeletePhoto(ctx context.Context, photoID int) error {
 		)
 	`
 
-	result, err := r.db.ExecContext(ctx, query, photoID, tenantID)
-	if err != nil {
+	if _, err := tx.ExecContext(ctx, query, photoID, tenantID); err != nil {
 		return fmt.Errorf("failed to delete photo: %w", err)
 	}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+	carID, _ := before["car_id"].(float64)
+	if err := recordAudit(ctx, tx, "car", entityRef(int(carID)), "delete_photo", before, nil); err != nil {
+		return err
 	}
 
-	if rows == 0 {
-		return fmt.Errorf("photo not found or no permission")
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
@@ -689,4 +798,52 @@ func (r *CarRepository) GetCarSpecsFromDB(ctx context.Context, brand, carModel s
 	}
 
 	return result, nil
-}
\ No newline at end of file
+}
+// ListPage returns one page of the tenant's cars, newest first, optionally
+// filtered by status, and the cursor of the next page (0 on the last page)
+func (r *CarRepository) ListPage(ctx context.Context, page model.PageRequest, status string) ([]*model.Car, int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		SELECT id, tenant_id, brand, model, year, price, mileage, transmission,
+			fuel_type, engine_cc, seats, color, description, status, is_featured,
+			created_at, updated_at
+		FROM cars
+		WHERE tenant_id = $1 AND ($2 = 0 OR id < $2) AND ($3 = '' OR status = $3)
+		ORDER BY id DESC
+		LIMIT $4
+	`
+
+	limit := page.PageLimit()
+	rows, err := r.db.QueryContext(ctx, query, tenantID, page.Cursor, status, limit+1)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list cars: %w", err)
+	}
+	defer rows.Close()
+
+	var cars []*model.Car
+	for rows.Next() {
+		car := &model.Car{}
+		err := rows.Scan(
+			&car.ID, &car.TenantID, &car.Brand, &car.Model, &car.Year, &car.Price,
+			&car.Mileage, &car.Transmission, &car.FuelType, &car.EngineCC, &car.Seats,
+			&car.Color, &car.Description, &car.Status, &car.IsFeatured,
+			&car.CreatedAt, &car.UpdatedAt,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan car: %w", err)
+		}
+		cars = append(cars, car)
+	}
+
+	next := 0
+	if len(cars) > limit {
+		cars = cars[:limit]
+		next = cars[limit-1].ID
+	}
+
+	return cars, next, nil
+}
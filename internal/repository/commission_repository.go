@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type CommissionRepository struct {
+	db *sql.DB
+}
+
+func NewCommissionRepository(db *sql.DB) *CommissionRepository {
+	return &CommissionRepository{db: db}
+}
+
+// ListRules retrieves commission rules for tenant
+func (r *CommissionRepository) ListRules(ctx context.Context, activeOnly bool) ([]*model.CommissionRule, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageBilling, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, tenant_id, name, min_price, max_price, percent, flat_amount, is_active, created_at, updated_at
+		FROM commission_rules
+		WHERE tenant_id = $1
+	`
+	if activeOnly {
+		query += " AND is_active = TRUE"
+	}
+	query += " ORDER BY min_price ASC"
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commission rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*model.CommissionRule
+	for rows.Next() {
+		rule := &model.CommissionRule{}
+		err := rows.Scan(
+			&rule.ID, &rule.TenantID, &rule.Name, &rule.MinPrice, &rule.MaxPrice,
+			&rule.Percent, &rule.FlatAmount, &rule.IsActive, &rule.CreatedAt, &rule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan commission rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// CreateRule creates a commission rule (tenant-scoped)
+func (r *CommissionRepository) CreateRule(ctx context.Context, rule *model.CommissionRule) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageBilling); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO commission_rules (tenant_id, name, min_price, max_price, percent, flat_amount, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		tenantID, rule.Name, rule.MinPrice, rule.MaxPrice, rule.Percent, rule.FlatAmount, rule.IsActive,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create commission rule: %w", err)
+	}
+
+	rule.TenantID = tenantID
+	return nil
+}
+
+// UpdateRule updates a commission rule (tenant-scoped)
+func (r *CommissionRepository) UpdateRule(ctx context.Context, id int, rule *model.CommissionRule) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageBilling); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE commission_rules
+		SET name = $1, min_price = $2, max_price = $3, percent = $4, flat_amount = $5,
+			is_active = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7 AND tenant_id = $8
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		rule.Name, rule.MinPrice, rule.MaxPrice, rule.Percent, rule.FlatAmount, rule.IsActive, id, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update commission rule: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("commission rule not found or no permission")
+	}
+
+	return nil
+}
+
+// DeleteRule deletes a commission rule (tenant-scoped)
+func (r *CommissionRepository) DeleteRule(ctx context.Context, id int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageBilling); err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM commission_rules WHERE id = $1 AND tenant_id = $2", id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete commission rule: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("commission rule not found or no permission")
+	}
+
+	return nil
+}
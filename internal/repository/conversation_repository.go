@@ -6,16 +6,24 @@ import (
 	"fmt"
 
 	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/pkg/security"
 )
 
 type ConversationRepository struct {
-	db *sql.DB
+	db   *sql.DB
+	keys *DataKeyRepository
 }
 
 func NewConversationRepository(db *sql.DB) *ConversationRepository {
 	return &ConversationRepository{db: db}
 }
 
+// SetDataKeys enables encryption at rest of phone numbers and message text.
+// Rows written before stay readable; cmd/encrypt-pii encrypts them.
+func (r *ConversationRepository) SetDataKeys(keys *DataKeyRepository) {
+	r.keys = keys
+}
+
 // GetOrCreate finds existing conversation or creates new one
 func (r *ConversationRepository) GetOrCreate(ctx context.Context, senderPhone string, isSales bool) (*model.Conversation, error) {
 	tenantID, err := model.GetTenantID(ctx)
@@ -23,17 +31,23 @@ func (r *ConversationRepository) GetOrCreate(ctx context.Context, senderPhone st
 		return nil, fmt.Errorf("tenant ID required: %w", err)
 	}
 
-	// Try to get existing conversation
+	bidx, err := phoneIndex(ctx, r.keys, senderPhone)
+	if err != nil {
+		return nil, err
+	}
+
+	// Try to get existing conversation: by blind index once encrypted, by the
+	// plain number for rows not encrypted yet
 	query := `
 		SELECT id, tenant_id, sender_phone, is_sales, created_at, updated_at
 		FROM conversations
-		WHERE tenant_id = $1 AND sender_phone = $2
+		WHERE tenant_id = $1 AND (sender_phone_bidx = $2 OR sender_phone = $3)
 		ORDER BY updated_at DESC
 		LIMIT 1
 	`
 
 	conv := &model.Conversation{}
-	err = r.db.QueryRowContext(ctx, query, tenantID, senderPhone).Scan(
+	err = r.db.QueryRowContext(ctx, query, tenantID, bidx, senderPhone).Scan(
 		&conv.ID, &conv.TenantID, &conv.SenderPhone, &conv.IsSales,
 		&conv.CreatedAt, &conv.UpdatedAt,
 	)
@@ -41,6 +55,9 @@ func (r *ConversationRepository) GetOrCreate(ctx context.Context, senderPhone st
 	if err == nil {
 		// Update timestamp
 		r.db.ExecContext(ctx, "UPDATE conversations SET updated_at = CURRENT_TIMESTAMP WHERE id = $1", conv.ID)
+		if err := r.openConversation(ctx, conv); err != nil {
+			return nil, err
+		}
 		return conv, nil
 	}
 
@@ -48,15 +65,20 @@ func (r *ConversationRepository) GetOrCreate(ctx context.Context, senderPhone st
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
 
+	sealedPhone, err := sealPII(ctx, r.keys, senderPhone)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create new conversation
 	insertQuery := `
-		INSERT INTO conversations (tenant_id, sender_phone, is_sales)
-		VALUES ($1, $2, $3)
-		RETURNING id, tenant_id, sender_phone, is_sales, created_at, updated_at
+		INSERT INTO conversations (tenant_id, sender_phone, sender_phone_bidx, is_sales)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, tenant_id, is_sales, created_at, updated_at
 	`
 
-	err = r.db.QueryRowContext(ctx, insertQuery, tenantID, senderPhone, isSales).Scan(
-		&conv.ID, &conv.TenantID, &conv.SenderPhone, &conv.IsSales,
+	err = r.db.QueryRowContext(ctx, insertQuery, tenantID, sealedPhone, bidx, isSales).Scan(
+		&conv.ID, &conv.TenantID, &conv.IsSales,
 		&conv.CreatedAt, &conv.UpdatedAt,
 	)
 
@@ -64,6 +86,7 @@ func (r *ConversationRepository) GetOrCreate(ctx context.Context, senderPhone st
 		return nil, fmt.Errorf("failed to create conversation: %w", err)
 	}
 
+	conv.SenderPhone = senderPhone
 	return conv, nil
 }
 
@@ -72,13 +95,46 @@ func (r *ConversationRepository) AddMessage(ctx context.Context, conversationID
 	query := `
 		INSERT INTO messages (conversation_id, sender_phone, message_text, direction)
 		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
 	`
 
-	_, err := r.db.ExecContext(ctx, query, conversationID, senderPhone, messageText, direction)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	sealedPhone, err := sealPII(ctx, r.keys, senderPhone)
+	if err != nil {
+		return err
+	}
+	sealedText, err := sealPII(ctx, r.keys, messageText)
+	if err != nil {
+		return err
+	}
+
+	msg := &model.Message{
+		ConversationID: conversationID,
+		SenderPhone:    senderPhone,
+		MessageText:    messageText,
+		Direction:      direction,
+	}
+	err = tx.QueryRowContext(ctx, query, conversationID, sealedPhone, sealedText, direction).Scan(&msg.ID, &msg.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to add message: %w", err)
 	}
 
+	// Webhooks are per tenant; messages stored outside a tenant context have none
+	if _, err := model.GetTenantID(ctx); err == nil && direction == "inbound" {
+		if err := enqueueWebhook(ctx, tx, model.WebhookEventMessageInbound, msg); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
@@ -109,6 +165,9 @@ func (r *ConversationRepository) GetMessages(ctx context.Context, conversationID
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan message: %w", err)
 		}
+		if err := r.openMessage(ctx, msg); err != nil {
+			return nil, err
+		}
 		messages = append(messages, msg)
 	}
 
@@ -120,22 +179,28 @@ func (r *ConversationRepository) GetMessages(ctx context.Context, conversationID
 	return messages, nil
 }
 
-// GetByID retrieves a conversation by ID with tenant isolation
+// GetByID retrieves a conversation by ID with tenant isolation.
+// Sales users only get conversations assigned to them.
 func (r *ConversationRepository) GetByID(ctx context.Context, conversationID int) (*model.Conversation, error) {
 	tenantID, err := model.GetTenantID(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	salesID, scoped, err := assignedSalesScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT id, tenant_id, sender_phone, is_sales, created_at, updated_at
+		SELECT id, tenant_id, sender_phone, is_sales, assigned_sales_id, created_at, updated_at
 		FROM conversations
-		WHERE id = $1 AND tenant_id = $2
+		WHERE id = $1 AND tenant_id = $2 AND (NOT $3 OR assigned_sales_id = $4)
 	`
 
 	conv := &model.Conversation{}
-	err = r.db.QueryRowContext(ctx, query, conversationID, tenantID).Scan(
-		&conv.ID, &conv.TenantID, &conv.SenderPhone, &conv.IsSales,
+	err = r.db.QueryRowContext(ctx, query, conversationID, tenantID, scoped, salesID).Scan(
+		&conv.ID, &conv.TenantID, &conv.SenderPhone, &conv.IsSales, &conv.AssignedSalesID,
 		&conv.CreatedAt, &conv.UpdatedAt,
 	)
 
@@ -146,6 +211,10 @@ func (r *ConversationRepository) GetByID(ctx context.Context, conversationID int
 		return nil, fmt.Errorf("failed to get conversation: %w", err)
 	}
 
+	if err := r.openConversation(ctx, conv); err != nil {
+		return nil, err
+	}
+
 	return conv, nil
 }
 
@@ -162,21 +231,26 @@ func (r *ConversationRepository) GetMessageCount(ctx context.Context, conversati
 	return count, nil
 }
 
-// ListConversations lists all conversations for tenant
+// ListConversations lists all conversations for tenant (assigned ones for sales users)
 func (r *ConversationRepository) ListConversations(ctx context.Context) ([]*model.Conversation, error) {
 	tenantID, err := model.GetTenantID(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	salesID, scoped, err := assignedSalesScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	query := `
-		SELECT id, tenant_id, sender_phone, is_sales, created_at, updated_at
+		SELECT id, tenant_id, sender_phone, is_sales, assigned_sales_id, created_at, updated_at
 		FROM conversations
-		WHERE tenant_id = $1
+		WHERE tenant_id = $1 AND (NOT $2 OR assigned_sales_id = $3)
 		ORDER BY updated_at DESC
 	`
 
-	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	rows, err := r.db.QueryContext(ctx, query, tenantID, scoped, salesID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list conversations: %w", err)
 	}
@@ -185,17 +259,20 @@ func (r *ConversationRepository) ListConversations(ctx context.Context) ([]*mode
 	var conversations []*model.Conversation
 	for rows.Next() {
 		conv := &model.Conversation{}
-		err := rows.Scan(&conv.ID, &conv.TenantID, &conv.SenderPhone, &conv.IsSales, &conv.CreatedAt, &conv.UpdatedAt)
+		err := rows.Scan(&conv.ID, &conv.TenantID, &conv.SenderPhone, &conv.IsSales, &conv.AssignedSalesID, &conv.CreatedAt, &conv.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan conversation: %w", err)
 		}
+		if err := r.openConversation(ctx, conv); err != nil {
+			return nil, err
+		}
 		conversations = append(conversations, conv)
 	}
 
 	return conversations, nil
 }
 
-// List retrieves paginated conversations with last message info
+// List retrieves paginated conversations with last message info (assigned ones for sales users)
 func (r *ConversationRepository) List(ctx context.Context, page, limit int, typeFilter string) ([]*model.ConversationListItem, int, error) {
 	tenantID, err := model.GetTenantID(ctx)
 	if err != nil {
@@ -211,11 +288,22 @@ func (r *ConversationRepository) List(ctx context.Context, page, limit int, type
 	}
 	offset := (page - 1) * limit
 
+	salesID, scoped, err := assignedSalesScope(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// Build WHERE clause
 	whereClause := "WHERE c.tenant_id = $1"
 	args := []interface{}{tenantID}
 	argCount := 1
 
+	if scoped {
+		argCount++
+		whereClause += fmt.Sprintf(" AND c.assigned_sales_id = $%d", argCount)
+		args = append(args, salesID)
+	}
+
 	if typeFilter == "customer" {
 		argCount++
 		whereClause += fmt.Sprintf(" AND c.is_sales = $%d", argCount)
@@ -245,6 +333,7 @@ func (r *ConversationRepository) List(ctx context.Context, page, limit int, type
 			c.id,
 			c.sender_phone,
 			c.is_sales,
+			c.assigned_sales_id,
 			c.created_at,
 			COALESCE(last_msg.message_text, '') as last_message,
 			COALESCE(last_msg.created_at, c.created_at) as last_message_at,
@@ -282,6 +371,7 @@ func (r *ConversationRepository) List(ctx context.Context, page, limit int, type
 			&item.ID,
 			&item.PhoneNumber,
 			&item.IsSales,
+			&item.AssignedSalesID,
 			&item.CreatedAt,
 			&item.LastMessage,
 			&item.LastMessageAt,
@@ -290,8 +380,413 @@ func (r *ConversationRepository) List(ctx context.Context, page, limit int, type
 		if err != nil {
 			return nil, 0, fmt.Errorf("failed to scan conversation: %w", err)
 		}
+		if item.PhoneNumber, err = openPII(ctx, r.keys, item.PhoneNumber); err != nil {
+			return nil, 0, err
+		}
+		if item.LastMessage, err = openPII(ctx, r.keys, item.LastMessage); err != nil {
+			return nil, 0, err
+		}
 		conversations = append(conversations, item)
 	}
 
 	return conversations, total, nil
 }
+
+// AssignSales assigns a conversation to a sales person, or unassigns it when salesID is nil (tenant-scoped)
+func (r *ConversationRepository) AssignSales(ctx context.Context, conversationID int, salesID *int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE conversations SET assigned_sales_id = $1
+		WHERE id = $2 AND tenant_id = $3
+			AND ($1::int IS NULL OR EXISTS (SELECT 1 FROM sales WHERE id = $1 AND tenant_id = $3))
+		RETURNING sender_phone
+	`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var senderPhone string
+	err = tx.QueryRowContext(ctx, query, salesID, conversationID, tenantID).Scan(&senderPhone)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("conversation not found or no permission")
+		}
+		return fmt.Errorf("failed to assign conversation: %w", err)
+	}
+
+	// Assigning a sales person hands the conversation over from the bot
+	if salesID != nil {
+		if senderPhone, err = openPII(ctx, r.keys, senderPhone); err != nil {
+			return err
+		}
+		handoff := map[string]interface{}{
+			"conversation_id":   conversationID,
+			"sender_phone":      senderPhone,
+			"assigned_sales_id": *salesID,
+		}
+		if err := enqueueWebhook(ctx, tx, model.WebhookEventConversationHandoff, handoff); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// ListPage returns one page of conversations, newest first, and the cursor of the
+// next page (0 on the last page). Sales users only get conversations assigned to them.
+func (r *ConversationRepository) ListPage(ctx context.Context, page model.PageRequest) ([]*model.Conversation, int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	salesID, scoped, err := assignedSalesScope(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, tenant_id, sender_phone, is_sales, assigned_sales_id, created_at, updated_at
+		FROM conversations
+		WHERE tenant_id = $1 AND (NOT $2 OR assigned_sales_id = $3) AND ($4 = 0 OR id < $4)
+		ORDER BY id DESC
+		LIMIT $5
+	`
+
+	limit := page.PageLimit()
+	rows, err := r.db.QueryContext(ctx, query, tenantID, scoped, salesID, page.Cursor, limit+1)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []*model.Conversation
+	for rows.Next() {
+		conv := &model.Conversation{}
+		err := rows.Scan(&conv.ID, &conv.TenantID, &conv.SenderPhone, &conv.IsSales, &conv.AssignedSalesID, &conv.CreatedAt, &conv.UpdatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		if err := r.openConversation(ctx, conv); err != nil {
+			return nil, 0, err
+		}
+		conversations = append(conversations, conv)
+	}
+
+	next := 0
+	if len(conversations) > limit {
+		conversations = conversations[:limit]
+		next = conversations[limit-1].ID
+	}
+
+	return conversations, next, nil
+}
+
+// ListMessagesPage returns one page of a conversation's messages, newest first,
+// and the cursor of the next page (0 on the last page) (tenant-scoped)
+func (r *ConversationRepository) ListMessagesPage(ctx context.Context, conversationID int, page model.PageRequest) ([]*model.Message, int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		SELECT m.id, m.conversation_id, m.sender_phone, m.message_text, m.direction, m.created_at
+		FROM messages m
+		INNER JOIN conversations c ON c.id = m.conversation_id
+		WHERE m.conversation_id = $1 AND c.tenant_id = $2 AND ($3 = 0 OR m.id < $3)
+		ORDER BY m.id DESC
+		LIMIT $4
+	`
+
+	limit := page.PageLimit()
+	rows, err := r.db.QueryContext(ctx, query, conversationID, tenantID, page.Cursor, limit+1)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*model.Message
+	for rows.Next() {
+		msg := &model.Message{}
+		err := rows.Scan(&msg.ID, &msg.ConversationID, &msg.SenderPhone, &msg.MessageText, &msg.Direction, &msg.CreatedAt)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if err := r.openMessage(ctx, msg); err != nil {
+			return nil, 0, err
+		}
+		messages = append(messages, msg)
+	}
+
+	next := 0
+	if len(messages) > limit {
+		messages = messages[:limit]
+		next = messages[limit-1].ID
+	}
+
+	return messages, next, nil
+}
+
+// openConversation decrypts the phone number of a conversation read from the database
+func (r *ConversationRepository) openConversation(ctx context.Context, conv *model.Conversation) error {
+	phone, err := openPII(ctx, r.keys, conv.SenderPhone)
+	if err != nil {
+		return err
+	}
+	conv.SenderPhone = phone
+	return nil
+}
+
+// openMessage decrypts the phone number and text of a message read from the database
+func (r *ConversationRepository) openMessage(ctx context.Context, msg *model.Message) error {
+	phone, err := openPII(ctx, r.keys, msg.SenderPhone)
+	if err != nil {
+		return err
+	}
+	text, err := openPII(ctx, r.keys, msg.MessageText)
+	if err != nil {
+		return err
+	}
+	msg.SenderPhone, msg.MessageText = phone, text
+	return nil
+}
+
+// EncryptStored rewrites the tenant's phone numbers and message text that are
+// still plaintext or sealed with an older data key, batchSize rows per
+// transaction. With decrypt set it writes everything back as plaintext instead.
+// Returns the number of rows changed (tenant-scoped).
+func (r *ConversationRepository) EncryptStored(ctx context.Context, decrypt bool, batchSize int) (int, error) {
+	if _, err := model.GetTenantID(ctx); err != nil {
+		return 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+	if r.keys == nil {
+		return 0, fmt.Errorf("encryption is not enabled")
+	}
+
+	active, err := r.keys.ActiveVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+	s := &resealer{keys: r.keys, active: active, decrypt: decrypt}
+
+	conversations, err := r.resealConversations(ctx, s, batchSize)
+	if err != nil {
+		return conversations, fmt.Errorf("failed to encrypt conversations: %w", err)
+	}
+	messages, err := r.resealMessages(ctx, s, batchSize)
+	if err != nil {
+		return conversations + messages, fmt.Errorf("failed to encrypt messages: %w", err)
+	}
+
+	return conversations + messages, nil
+}
+
+// resealer converts stored column values to the target form: sealed with the
+// active data key, or plaintext when decrypting
+type resealer struct {
+	keys    *DataKeyRepository
+	active  int
+	decrypt bool
+}
+
+// value returns the plaintext and the value to store, and whether the latter
+// differs from the stored one
+func (s *resealer) value(ctx context.Context, stored string) (plain, value string, changed bool, err error) {
+	if plain, err = s.keys.Open(ctx, stored); err != nil {
+		return "", "", false, err
+	}
+
+	version, sealed := security.SealedVersion(stored)
+	if s.decrypt {
+		return plain, plain, sealed, nil
+	}
+	if sealed && version == s.active {
+		return plain, stored, false, nil
+	}
+
+	value, err = s.keys.Seal(ctx, plain)
+	return plain, value, true, err
+}
+
+func (r *ConversationRepository) resealConversations(ctx context.Context, s *resealer, batchSize int) (int, error) {
+	changed, lastID := 0, 0
+	for {
+		n, last, err := r.resealConversationBatch(ctx, s, lastID, batchSize)
+		changed += n
+		if err != nil || last == 0 {
+			return changed, err
+		}
+		lastID = last
+	}
+}
+
+// resealConversationBatch rewrites the conversations after afterID in one
+// transaction; last is the ID of the last row seen, 0 when none were left
+func (r *ConversationRepository) resealConversationBatch(ctx context.Context, s *resealer, afterID, batchSize int) (changed, last int, err error) {
+	tenantID, _ := model.GetTenantID(ctx)
+
+	query := `
+		SELECT id, sender_phone, sender_phone_bidx IS NOT NULL
+		FROM conversations
+		WHERE tenant_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+		FOR UPDATE
+	`
+
+	type row struct {
+		id      int
+		phone   string
+		indexed bool
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, tenantID, afterID, batchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	var batch []row
+	for rows.Next() {
+		var rw row
+		if err := rows.Scan(&rw.id, &rw.phone, &rw.indexed); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		batch = append(batch, rw)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, rw := range batch {
+		last = rw.id
+		plain, phone, phoneChanged, err := s.value(ctx, rw.phone)
+		if err != nil {
+			return 0, 0, err
+		}
+		// Decrypted rows drop their blind index, encrypted ones need it
+		if !phoneChanged && rw.indexed != s.decrypt {
+			continue
+		}
+
+		var bidx *string
+		if !s.decrypt {
+			if bidx, err = phoneIndex(ctx, s.keys, plain); err != nil {
+				return 0, 0, err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, "UPDATE conversations SET sender_phone = $1, sender_phone_bidx = $2 WHERE id = $3", phone, bidx, rw.id); err != nil {
+			return 0, 0, err
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return changed, last, nil
+}
+
+func (r *ConversationRepository) resealMessages(ctx context.Context, s *resealer, batchSize int) (int, error) {
+	changed, lastID := 0, 0
+	for {
+		n, last, err := r.resealMessageBatch(ctx, s, lastID, batchSize)
+		changed += n
+		if err != nil || last == 0 {
+			return changed, err
+		}
+		lastID = last
+	}
+}
+
+// resealMessageBatch rewrites the messages after afterID in one transaction;
+// last is the ID of the last row seen, 0 when none were left
+func (r *ConversationRepository) resealMessageBatch(ctx context.Context, s *resealer, afterID, batchSize int) (changed, last int, err error) {
+	tenantID, _ := model.GetTenantID(ctx)
+
+	query := `
+		SELECT m.id, m.sender_phone, m.message_text
+		FROM messages m
+		INNER JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.tenant_id = $1 AND m.id > $2
+		ORDER BY m.id
+		LIMIT $3
+		FOR UPDATE OF m
+	`
+
+	type row struct {
+		id          int
+		phone, text string
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, tenantID, afterID, batchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	var batch []row
+	for rows.Next() {
+		var rw row
+		if err := rows.Scan(&rw.id, &rw.phone, &rw.text); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		batch = append(batch, rw)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, rw := range batch {
+		last = rw.id
+		_, phone, phoneChanged, err := s.value(ctx, rw.phone)
+		if err != nil {
+			return 0, 0, err
+		}
+		_, text, textChanged, err := s.value(ctx, rw.text)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !phoneChanged && !textChanged {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "UPDATE messages SET sender_phone = $1, message_text = $2 WHERE id = $3", phone, text, rw.id); err != nil {
+			return 0, 0, err
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return changed, last, nil
+}
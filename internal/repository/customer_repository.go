@@ -0,0 +1,829 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+type CustomerRepository struct {
+	db   *sql.DB
+	keys *DataKeyRepository
+}
+
+func NewCustomerRepository(db *sql.DB) *CustomerRepository {
+	return &CustomerRepository{db: db}
+}
+
+// SetDataKeys enables encryption at rest of customer names and phone numbers
+// (appointments included) and lets the timeline, exports and erasures find
+// encrypted rows through the blind index. Rows written before stay readable;
+// cmd/encrypt-pii encrypts them.
+func (r *CustomerRepository) SetDataKeys(keys *DataKeyRepository) {
+	r.keys = keys
+}
+
+const customerColumns = `
+	id, tenant_id, phone_number, name, tags, notes, budget_min, budget_max,
+	body_types, preferred_brands, created_at, updated_at
+`
+
+func scanCustomer(row interface{ Scan(...interface{}) error }) (*model.Customer, error) {
+	c := &model.Customer{}
+	err := row.Scan(
+		&c.ID, &c.TenantID, &c.PhoneNumber, &c.Name, pq.Array(&c.Tags), &c.Notes,
+		&c.BudgetMin, &c.BudgetMax, pq.Array(&c.BodyTypes), pq.Array(&c.PreferredBrands),
+		&c.CreatedAt, &c.UpdatedAt,
+	)
+	return c, err
+}
+
+// openCustomer decrypts the phone number and name of a customer read from the database
+func (r *CustomerRepository) openCustomer(ctx context.Context, c *model.Customer) error {
+	phone, err := openPII(ctx, r.keys, c.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	name, err := openOptionalPII(ctx, r.keys, c.Name)
+	if err != nil {
+		return err
+	}
+	c.PhoneNumber, c.Name = phone, name
+	return nil
+}
+
+// GetOrCreate finds the customer by phone (any 08xx/628xx form) or creates one (tenant-scoped)
+func (r *CustomerRepository) GetOrCreate(ctx context.Context, phone string) (*model.Customer, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	normalized := model.NormalizePhone(phone)
+	if normalized == "" {
+		return nil, fmt.Errorf("invalid phone number")
+	}
+
+	phone, bidx, err := sealPhone(ctx, r.keys, normalized)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sealed numbers differ on every write, so look the customer up by blind
+	// index (or the plain number of a row not encrypted yet) first
+	query := "SELECT " + customerColumns + `
+		FROM customers
+		WHERE tenant_id = $1 AND (phone_number_bidx = $2 OR phone_number = $3)
+		LIMIT 1
+	`
+	customer, err := scanCustomer(r.db.QueryRowContext(ctx, query, tenantID, bidx, normalized))
+	if err == sql.ErrNoRows {
+		// The no-op update makes RETURNING yield the existing row on conflict
+		conflict := "(tenant_id, phone_number) DO UPDATE SET phone_number = EXCLUDED.phone_number"
+		if bidx != nil {
+			conflict = "(tenant_id, phone_number_bidx) WHERE phone_number_bidx IS NOT NULL DO UPDATE SET phone_number_bidx = EXCLUDED.phone_number_bidx"
+		}
+		query = `
+			INSERT INTO customers (tenant_id, phone_number, phone_number_bidx)
+			VALUES ($1, $2, $3)
+			ON CONFLICT ` + conflict + `
+			RETURNING ` + customerColumns
+		customer, err = scanCustomer(r.db.QueryRowContext(ctx, query, tenantID, phone, bidx))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get or create customer: %w", err)
+	}
+	if err := r.openCustomer(ctx, customer); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// GetByID retrieves a customer by ID (tenant-scoped)
+func (r *CustomerRepository) GetByID(ctx context.Context, id int) (*model.Customer, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + customerColumns + " FROM customers WHERE id = $1 AND tenant_id = $2"
+
+	customer, err := scanCustomer(r.db.QueryRowContext(ctx, query, id, tenantID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("customer not found")
+		}
+		return nil, fmt.Errorf("failed to get customer: %w", err)
+	}
+	if err := r.openCustomer(ctx, customer); err != nil {
+		return nil, err
+	}
+
+	return customer, nil
+}
+
+// List retrieves customers for tenant, optionally filtered by name/phone search and tag.
+// Encrypted names and numbers cannot be searched in SQL; with encryption enabled
+// the search runs over the tenant's decrypted customers instead.
+func (r *CustomerRepository) List(ctx context.Context, search, tag string) ([]*model.Customer, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + customerColumns + " FROM customers WHERE tenant_id = $1"
+	args := []interface{}{tenantID}
+	argCount := 1
+
+	searchDecrypted := search != "" && r.keys != nil
+	if search != "" && !searchDecrypted {
+		argCount++
+		if phone := model.NormalizePhone(search); phone != "" {
+			query += fmt.Sprintf(" AND (name ILIKE $%d OR phone_number LIKE $%d)", argCount, argCount+1)
+			args = append(args, "%"+search+"%", "%"+phone+"%")
+			argCount++
+		} else {
+			query += fmt.Sprintf(" AND name ILIKE $%d", argCount)
+			args = append(args, "%"+search+"%")
+		}
+	}
+
+	if tag != "" {
+		argCount++
+		query += fmt.Sprintf(" AND $%d = ANY(tags)", argCount)
+		args = append(args, tag)
+	}
+
+	const limit = 200
+	query += " ORDER BY updated_at DESC"
+	if !searchDecrypted {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customers: %w", err)
+	}
+	defer rows.Close()
+
+	var customers []*model.Customer
+	for rows.Next() && len(customers) < limit {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan customer: %w", err)
+		}
+		if err := r.openCustomer(ctx, customer); err != nil {
+			return nil, err
+		}
+		if searchDecrypted && !customerMatches(customer, search) {
+			continue
+		}
+		customers = append(customers, customer)
+	}
+
+	return customers, nil
+}
+
+// customerMatches reports whether a customer's name or phone number contains
+// search, the way List's SQL search matches them
+func customerMatches(c *model.Customer, search string) bool {
+	if c.Name != nil && strings.Contains(strings.ToLower(*c.Name), strings.ToLower(search)) {
+		return true
+	}
+	phone := model.NormalizePhone(search)
+	return phone != "" && strings.Contains(c.PhoneNumber, phone)
+}
+
+// Update replaces the editable profile fields (tenant-scoped)
+func (r *CustomerRepository) Update(ctx context.Context, id int, req *model.CustomerUpdateRequest) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return err
+	}
+
+	name, err := sealOptionalPII(ctx, r.keys, req.Name)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE customers
+		SET name = $1, tags = $2, notes = $3, budget_min = $4, budget_max = $5,
+			body_types = $6, preferred_brands = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8 AND tenant_id = $9
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		name, pq.Array(req.Tags), req.Notes, req.BudgetMin, req.BudgetMax,
+		pq.Array(req.BodyTypes), pq.Array(req.PreferredBrands), id, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update customer: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("customer not found or no permission")
+	}
+
+	return nil
+}
+
+// MergePreferences applies bot-extracted preferences to a customer, creating it if needed
+func (r *CustomerRepository) MergePreferences(ctx context.Context, phone string, prefs *model.CustomerPreferences) (*model.Customer, error) {
+	customer, err := r.GetOrCreate(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &model.CustomerUpdateRequest{
+		Name:            customer.Name,
+		Tags:            customer.Tags,
+		Notes:           customer.Notes,
+		BudgetMin:       customer.BudgetMin,
+		BudgetMax:       customer.BudgetMax,
+		BodyTypes:       model.MergeList(customer.BodyTypes, prefs.BodyTypes),
+		PreferredBrands: model.MergeList(customer.PreferredBrands, prefs.Brands),
+	}
+	if prefs.Name != "" && customer.Name == nil {
+		req.Name = &prefs.Name
+	}
+	if prefs.BudgetMin > 0 {
+		req.BudgetMin = &prefs.BudgetMin
+	}
+	if prefs.BudgetMax > 0 {
+		req.BudgetMax = &prefs.BudgetMax
+	}
+	if err := req.Validate(); err != nil {
+		// Conflicting budget from the chat; keep only the latest upper bound
+		req.BudgetMin = nil
+	}
+
+	if err := r.Update(ctx, customer.ID, req); err != nil {
+		return nil, err
+	}
+
+	return r.GetByID(ctx, customer.ID)
+}
+
+// Timeline returns messages, leads, appointments, deals and trade-ins for a phone number,
+// newest first (tenant-scoped)
+func (r *CustomerRepository) Timeline(ctx context.Context, phone string, limit int) ([]*model.TimelineEvent, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	bidx, err := phoneIndex(ctx, r.keys, phone)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT 'message', m.created_at::timestamptz, m.id, m.direction, m.message_text
+		FROM messages m
+		INNER JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.tenant_id = $1 AND (c.sender_phone_bidx = $4 OR c.sender_phone = ANY($2))
+
+		UNION ALL
+		SELECT 'lead', l.created_at, l.id, l.status, l.source
+		FROM leads l
+		WHERE l.tenant_id = $1 AND (l.phone_number_bidx = $4 OR l.phone_number = ANY($2))
+
+		UNION ALL
+		SELECT 'appointment', a.scheduled_at, a.id, a.status, a.type || COALESCE(': ' || a.notes, '')
+		FROM appointments a
+		WHERE a.tenant_id = $1 AND (a.customer_phone_bidx = $4 OR a.customer_phone = ANY($2))
+
+		UNION ALL
+		SELECT 'deal', COALESCE(d.closing_date::timestamptz, d.created_at), d.id, d.status,
+			COALESCE(cr.brand || ' ' || cr.model || ' ' || cr.year || ' - ', '') || (d.agreed_price - d.discount)
+		FROM deals d
+		LEFT JOIN cars cr ON cr.id = d.car_id
+		WHERE d.tenant_id = $1 AND (d.customer_phone_bidx = $4 OR d.customer_phone = ANY($2))
+
+		UNION ALL
+		SELECT 'trade_in', t.created_at, t.id, t.status, t.brand || ' ' || t.model || ' ' || t.year
+		FROM trade_ins t
+		WHERE t.tenant_id = $1 AND (t.phone_number_bidx = $4 OR t.phone_number = ANY($2))
+
+		ORDER BY 2 DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, pq.Array(model.PhoneVariants(phone)), limit, bidx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load customer timeline: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*model.TimelineEvent{}
+	for rows.Next() {
+		e := &model.TimelineEvent{}
+		if err := rows.Scan(&e.Type, &e.OccurredAt, &e.RefID, &e.Status, &e.Detail); err != nil {
+			return nil, fmt.Errorf("failed to scan timeline event: %w", err)
+		}
+		if e.Type == "message" {
+			if e.Detail, err = openPII(ctx, r.keys, e.Detail); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+// CreateAppointment schedules a visit or test drive for a customer (tenant-scoped)
+func (r *CustomerRepository) CreateAppointment(ctx context.Context, a *model.Appointment) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return err
+	}
+
+	phone, bidx, err := sealPhone(ctx, r.keys, model.NormalizePhone(a.CustomerPhone))
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO appointments (tenant_id, customer_phone, customer_phone_bidx, car_id, type, scheduled_at, notes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, status, created_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		tenantID, phone, bidx, a.CarID, a.Type, a.ScheduledAt, a.Notes,
+	).Scan(&a.ID, &a.Status, &a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create appointment: %w", err)
+	}
+
+	a.TenantID = tenantID
+	return nil
+}
+
+// Export collects everything stored about a phone number: the customer profile,
+// conversations with their messages, leads and appointments (tenant-scoped)
+func (r *CustomerRepository) Export(ctx context.Context, phone string) (*model.CustomerExport, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	bidx, err := phoneIndex(ctx, r.keys, phone)
+	if err != nil {
+		return nil, err
+	}
+	variants := pq.Array(model.PhoneVariants(phone))
+
+	export := &model.CustomerExport{
+		PhoneNumber:   model.NormalizePhone(phone),
+		ExportedAt:    time.Now(),
+		Conversations: []*model.ConversationExport{},
+		Leads:         []*model.Lead{},
+		Appointments:  []*model.Appointment{},
+	}
+
+	customerQuery := `SELECT ` + customerColumns + ` FROM customers
+		WHERE tenant_id = $1 AND (phone_number_bidx = $3 OR phone_number = ANY($2)) LIMIT 1`
+	customer, err := scanCustomer(r.db.QueryRowContext(ctx, customerQuery, tenantID, variants, bidx))
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to export customer: %w", err)
+	}
+	if err == nil {
+		if err := r.openCustomer(ctx, customer); err != nil {
+			return nil, err
+		}
+		export.Customer = customer
+	}
+
+	if export.Conversations, err = r.exportConversations(ctx, tenantID, variants, bidx); err != nil {
+		return nil, err
+	}
+
+	leadQuery := `SELECT ` + leadColumns + ` FROM leads l
+		WHERE l.tenant_id = $1 AND (l.phone_number_bidx = $3 OR l.phone_number = ANY($2)) ORDER BY l.id`
+	rows, err := r.db.QueryContext(ctx, leadQuery, tenantID, variants, bidx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export leads: %w", err)
+	}
+	for rows.Next() {
+		lead, err := scanLead(rows)
+		if err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan lead: %w", err)
+		}
+		if err := openLead(ctx, r.keys, lead); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		export.Leads = append(export.Leads, lead)
+	}
+	rows.Close()
+
+	appointmentQuery := `
+		SELECT id, tenant_id, customer_phone, car_id, type, scheduled_at, status, notes, created_at
+		FROM appointments
+		WHERE tenant_id = $1 AND (customer_phone_bidx = $3 OR customer_phone = ANY($2))
+		ORDER BY id
+	`
+	rows, err = r.db.QueryContext(ctx, appointmentQuery, tenantID, variants, bidx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export appointments: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		a := &model.Appointment{}
+		if err := rows.Scan(&a.ID, &a.TenantID, &a.CustomerPhone, &a.CarID, &a.Type, &a.ScheduledAt, &a.Status, &a.Notes, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan appointment: %w", err)
+		}
+		if a.CustomerPhone, err = openPII(ctx, r.keys, a.CustomerPhone); err != nil {
+			return nil, err
+		}
+		export.Appointments = append(export.Appointments, a)
+	}
+
+	return export, nil
+}
+
+// exportConversations loads the conversations of a phone number with all their
+// messages, decrypted
+func (r *CustomerRepository) exportConversations(ctx context.Context, tenantID int, variants interface{}, bidx *string) ([]*model.ConversationExport, error) {
+	query := `
+		SELECT id, tenant_id, sender_phone, is_sales, assigned_sales_id, created_at, updated_at
+		FROM conversations
+		WHERE tenant_id = $1 AND (sender_phone_bidx = $3 OR sender_phone = ANY($2))
+		ORDER BY id
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, variants, bidx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export conversations: %w", err)
+	}
+	defer rows.Close()
+
+	conversations := []*model.ConversationExport{}
+	byID := map[int]*model.ConversationExport{}
+	var ids []int64
+	for rows.Next() {
+		conv := &model.Conversation{}
+		if err := rows.Scan(&conv.ID, &conv.TenantID, &conv.SenderPhone, &conv.IsSales, &conv.AssignedSalesID, &conv.CreatedAt, &conv.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		if conv.SenderPhone, err = openPII(ctx, r.keys, conv.SenderPhone); err != nil {
+			return nil, err
+		}
+		c := &model.ConversationExport{Conversation: conv, Messages: []*model.Message{}}
+		conversations = append(conversations, c)
+		byID[conv.ID] = c
+		ids = append(ids, int64(conv.ID))
+	}
+	rows.Close()
+	if len(ids) == 0 {
+		return conversations, nil
+	}
+
+	messageQuery := `
+		SELECT id, conversation_id, sender_phone, message_text, direction, created_at
+		FROM messages
+		WHERE conversation_id = ANY($1)
+		ORDER BY id
+	`
+
+	rows, err = r.db.QueryContext(ctx, messageQuery, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to export messages: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		msg := &model.Message{}
+		if err := rows.Scan(&msg.ID, &msg.ConversationID, &msg.SenderPhone, &msg.MessageText, &msg.Direction, &msg.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan message: %w", err)
+		}
+		if msg.SenderPhone, err = openPII(ctx, r.keys, msg.SenderPhone); err != nil {
+			return nil, err
+		}
+		if msg.MessageText, err = openPII(ctx, r.keys, msg.MessageText); err != nil {
+			return nil, err
+		}
+		byID[msg.ConversationID].Messages = append(byID[msg.ConversationID].Messages, msg)
+	}
+
+	return conversations, nil
+}
+
+// erasedMessageText replaces the text of anonymised messages
+const erasedMessageText = "[dihapus atas permintaan customer]"
+
+// erasedPhonePrefix starts the placeholder that replaces anonymised phone numbers
+const erasedPhonePrefix = "anon-"
+
+// Erase deletes or anonymises everything stored about a phone number in one
+// transaction. Deals are always kept, anonymised, for the tenant's bookkeeping.
+// Queued webhook payloads mentioning the number are deleted. Audit log entries
+// are append-only and stay as they are (tenant-scoped).
+func (r *CustomerRepository) Erase(ctx context.Context, phone, mode string) (*model.ErasureResult, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	bidx, err := phoneIndex(ctx, r.keys, phone)
+	if err != nil {
+		return nil, err
+	}
+	phones := model.PhoneVariants(phone)
+
+	// Anonymised rows get a placeholder that matches no real number
+	suffix, err := security.GenerateRandomSecret(6)
+	if err != nil {
+		return nil, err
+	}
+	placeholder := erasedPhonePrefix + suffix
+
+	patterns := make([]string, len(phones))
+	for i, p := range phones {
+		patterns[i] = `%"` + p + `"%`
+	}
+
+	// Rows match by blind index once encrypted, by the plain number otherwise
+	byPhone := []interface{}{tenantID, pq.Array(phones), bidx}
+	match := func(column string) string {
+		return `tenant_id = $1 AND (` + column + `_bidx = $3 OR ` + column + ` = ANY($2))`
+	}
+	conversationMatch := match("sender_phone")
+
+	var steps []erasureStep
+	if mode == model.ErasureDelete {
+		steps = []erasureStep{
+			{"messages", `DELETE FROM messages WHERE conversation_id IN (SELECT id FROM conversations WHERE ` + conversationMatch + `)`, byPhone},
+			{"conversations", `DELETE FROM conversations WHERE ` + conversationMatch, byPhone},
+			{"leads", `DELETE FROM leads WHERE ` + match("phone_number"), byPhone},
+			{"appointments", `DELETE FROM appointments WHERE ` + match("customer_phone"), byPhone},
+			{"trade_ins", `DELETE FROM trade_ins WHERE ` + match("phone_number"), byPhone},
+			{"customers", `DELETE FROM customers WHERE ` + match("phone_number"), byPhone},
+		}
+	} else {
+		steps = []erasureStep{
+			{"messages", `UPDATE messages SET message_text = $5,
+				sender_phone = CASE WHEN direction = 'inbound' THEN $4 ELSE sender_phone END
+				WHERE conversation_id IN (SELECT id FROM conversations WHERE ` + conversationMatch + `)`,
+				append(byPhone, placeholder, erasedMessageText)},
+			{"conversations", `UPDATE conversations SET sender_phone = $4, sender_phone_bidx = NULL WHERE ` + conversationMatch,
+				append(byPhone, placeholder)},
+			{"leads", `UPDATE leads SET phone_number = $4, phone_number_bidx = NULL, name = NULL WHERE ` + match("phone_number"),
+				append(byPhone, placeholder)},
+			{"appointments", `UPDATE appointments SET customer_phone = $4, customer_phone_bidx = NULL, notes = NULL WHERE ` + match("customer_phone"),
+				append(byPhone, placeholder)},
+			{"trade_ins", `UPDATE trade_ins SET phone_number = $4, phone_number_bidx = NULL, customer_name = NULL, appraisal_notes = NULL
+				WHERE ` + match("phone_number"),
+				append(byPhone, placeholder)},
+			{"customers", `UPDATE customers SET phone_number = $4 || '-' || id, phone_number_bidx = NULL, name = NULL, notes = NULL
+				WHERE ` + match("phone_number"),
+				append(byPhone, placeholder)},
+		}
+	}
+	steps = append(steps,
+		erasureStep{"deals", `UPDATE deals SET customer_phone = $4, customer_phone_bidx = NULL, customer_name = 'Anonim', notes = NULL
+			WHERE ` + match("customer_phone"),
+			append(byPhone, placeholder)},
+		erasureStep{"webhook_deliveries", `DELETE FROM webhook_deliveries WHERE tenant_id = $1 AND payload::text LIKE ANY($2)`,
+			[]interface{}{tenantID, pq.Array(patterns)}},
+	)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &model.ErasureResult{Mode: mode, Rows: map[string]int64{}}
+	for _, step := range steps {
+		res, err := tx.ExecContext(ctx, step.query, step.args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to erase %s: %w", step.table, err)
+		}
+		if result.Rows[step.table], err = res.RowsAffected(); err != nil {
+			return nil, fmt.Errorf("failed to erase %s: %w", step.table, err)
+		}
+	}
+
+	if err := recordAudit(ctx, tx, "customer", nil, "erase", nil, map[string]interface{}{
+		"mode": mode,
+		"rows": result.Rows,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// erasureStep is one statement of an erasure and the table it changes
+type erasureStep struct {
+	table string
+	query string
+	args  []interface{}
+}
+
+// customerPIITables are the tables EncryptStored rewrites: the phone number
+// column, indexed in <phone>_bidx, and the other sealed columns
+var customerPIITables = []struct {
+	table  string
+	phone  string
+	others []string
+}{
+	{"customers", "phone_number", []string{"name"}},
+	{"leads", "phone_number", []string{"name"}},
+	{"appointments", "customer_phone", nil},
+	{"trade_ins", "phone_number", []string{"customer_name"}},
+	{"deals", "customer_phone", []string{"customer_name"}},
+}
+
+// EncryptStored rewrites the tenant's customer, lead, appointment, trade-in and
+// deal names and phone numbers that are still plaintext or sealed with an older
+// data key, batchSize rows per transaction. With decrypt set it writes
+// everything back as plaintext instead. Returns the number of rows changed
+// (tenant-scoped).
+func (r *CustomerRepository) EncryptStored(ctx context.Context, decrypt bool, batchSize int) (int, error) {
+	if _, err := model.GetTenantID(ctx); err != nil {
+		return 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+	if r.keys == nil {
+		return 0, fmt.Errorf("encryption is not enabled")
+	}
+
+	active, err := r.keys.ActiveVersion(ctx)
+	if err != nil {
+		return 0, err
+	}
+	s := &resealer{keys: r.keys, active: active, decrypt: decrypt}
+
+	total := 0
+	for i := range customerPIITables {
+		changed, lastID := 0, 0
+		for {
+			n, last, err := r.resealBatch(ctx, s, i, lastID, batchSize)
+			changed += n
+			if err != nil {
+				return total + changed, fmt.Errorf("failed to encrypt %s: %w", customerPIITables[i].table, err)
+			}
+			if last == 0 {
+				break
+			}
+			lastID = last
+		}
+		total += changed
+	}
+
+	return total, nil
+}
+
+// resealBatch rewrites the rows of customerPIITables[table] after afterID in
+// one transaction; last is the ID of the last row seen, 0 when none were left
+func (r *CustomerRepository) resealBatch(ctx context.Context, s *resealer, table, afterID, batchSize int) (changed, last int, err error) {
+	tenantID, _ := model.GetTenantID(ctx)
+	t := customerPIITables[table]
+	columns := append([]string{t.phone}, t.others...)
+
+	query := fmt.Sprintf(`
+		SELECT id, %s_bidx IS NOT NULL, %s
+		FROM %s
+		WHERE tenant_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3
+		FOR UPDATE
+	`, t.phone, strings.Join(columns, ", "), t.table)
+
+	set := make([]string, len(columns))
+	for i, column := range columns {
+		set[i] = fmt.Sprintf("%s = $%d", column, i+1)
+	}
+	update := fmt.Sprintf("UPDATE %s SET %s, %s_bidx = $%d WHERE id = $%d",
+		t.table, strings.Join(set, ", "), t.phone, len(columns)+1, len(columns)+2)
+
+	type row struct {
+		id      int
+		indexed bool
+		values  []sql.NullString
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, tenantID, afterID, batchSize)
+	if err != nil {
+		return 0, 0, err
+	}
+	var batch []row
+	for rows.Next() {
+		rw := row{values: make([]sql.NullString, len(columns))}
+		dest := []interface{}{&rw.id, &rw.indexed}
+		for i := range rw.values {
+			dest = append(dest, &rw.values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		batch = append(batch, rw)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, rw := range batch {
+		last = rw.id
+		args := make([]interface{}, len(columns), len(columns)+2)
+		rowChanged := false
+		phone := ""
+		for i, v := range rw.values {
+			if !v.Valid {
+				continue
+			}
+			plain, value, valueChanged, err := s.value(ctx, v.String)
+			if err != nil {
+				return 0, 0, err
+			}
+			if i == 0 {
+				phone = plain
+			}
+			args[i] = value
+			rowChanged = rowChanged || valueChanged
+		}
+
+		// Decrypted rows drop their blind index, encrypted ones need it, except
+		// for the placeholders of erased numbers
+		var bidx *string
+		if !s.decrypt && rw.values[0].Valid && !strings.HasPrefix(phone, erasedPhonePrefix) {
+			if bidx, err = phoneIndex(ctx, s.keys, phone); err != nil {
+				return 0, 0, err
+			}
+		}
+		if !rowChanged && rw.indexed == (bidx != nil) {
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, update, append(args, bidx, rw.id)...); err != nil {
+			return 0, 0, err
+		}
+		changed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return changed, last, nil
+}
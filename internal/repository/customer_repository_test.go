@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+// Runs against the database of the row-level security suite (see rls_test.go)
+func TestCustomerRecordsEncrypted(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := model.WithSystemActor(model.WithTenantID(context.Background(), f.tenantA))
+
+	master, err := security.NewEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := NewDataKeyRepository(f.db, master)
+	customers := NewCustomerRepository(f.db)
+	customers.SetDataKeys(keys)
+	leads := NewLeadRepository(f.db)
+	leads.SetDataKeys(keys)
+	var convID int
+	mustScan(t, f.db.QueryRowContext(ctx,
+		"INSERT INTO conversations (tenant_id, sender_phone, is_sales) VALUES ($1, '6281234444', false) RETURNING id",
+		f.tenantA), &convID)
+
+	// Every format of the number finds the same customer
+	first, err := customers.GetOrCreate(ctx, "081234444")
+	if err != nil {
+		t.Fatalf("GetOrCreate: %v", err)
+	}
+	again, err := customers.GetOrCreate(ctx, "6281234444")
+	if err != nil {
+		t.Fatalf("GetOrCreate: %v", err)
+	}
+	if again.ID != first.ID || again.PhoneNumber != "6281234444" {
+		t.Errorf("GetOrCreate = %d/%s, want %d/6281234444", again.ID, again.PhoneNumber, first.ID)
+	}
+
+	if created, err := leads.QueueFollowUp(ctx, "6281234444", convID); err != nil || !created {
+		t.Fatalf("QueueFollowUp = %v, %v; want a new lead", created, err)
+	}
+	// Encrypted leads are found through the blind index, plaintext ones as before
+	for _, phone := range []string{"081234444", "628111"} {
+		if created, err := leads.QueueFollowUp(ctx, phone, convID); err != nil || created {
+			t.Errorf("QueueFollowUp(%s) = %v, %v; want no new lead", phone, created, err)
+		}
+	}
+
+	var stored string
+	var indexed bool
+	mustScan(t, f.db.QueryRowContext(ctx,
+		"SELECT phone_number, phone_number_bidx IS NOT NULL FROM customers WHERE id = $1", first.ID), &stored, &indexed)
+	if !strings.HasPrefix(stored, "enc:") || !indexed {
+		t.Errorf("stored customer phone %q (indexed %v), want it sealed and indexed", stored, indexed)
+	}
+
+	timeline, err := customers.Timeline(ctx, "081234444", 10)
+	if err != nil {
+		t.Fatalf("Timeline: %v", err)
+	}
+	if len(timeline) != 1 || timeline[0].Type != "lead" {
+		t.Errorf("timeline = %+v, want the encrypted lead", timeline)
+	}
+
+	result, err := customers.Erase(ctx, "6281234444", model.ErasureAnonymize)
+	if err != nil {
+		t.Fatalf("Erase: %v", err)
+	}
+	if result.Rows["customers"] != 1 || result.Rows["leads"] != 1 {
+		t.Errorf("erased rows = %v, want 1 customer and 1 lead", result.Rows)
+	}
+	var left int
+	mustScan(t, f.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM leads WHERE phone_number_bidx IS NOT NULL"), &left)
+	if left != 0 {
+		t.Errorf("%d leads still indexed after anonymising, want 0", left)
+	}
+}
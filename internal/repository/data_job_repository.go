@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type DataJobRepository struct {
+	db   *sql.DB
+	keys *DataKeyRepository
+}
+
+func NewDataJobRepository(db *sql.DB) *DataJobRepository {
+	return &DataJobRepository{db: db}
+}
+
+// SetDataKeys lets tenant exports decrypt the columns encrypted at rest
+func (r *DataJobRepository) SetDataKeys(keys *DataKeyRepository) {
+	r.keys = keys
+}
+
+const dataJobColumns = `
+	id, tenant_id, type, phone, format, mode, status, result, error, file_path, attempts,
+	requested_by, created_at, started_at, finished_at, expires_at
+`
+
+// dataJobSnapshotQuery loads a job for the audit log, without the customer's
+// phone number: the audit log is permanent and erasures must not leave it behind
+const dataJobSnapshotQuery = `
+	SELECT to_jsonb(j) - 'phone' - 'file_path' - 'locked_until'
+	FROM data_jobs j WHERE j.id = $1 AND j.tenant_id = $2`
+
+func scanDataJob(row interface{ Scan(...interface{}) error }) (*model.DataJob, error) {
+	job := &model.DataJob{}
+	var result []byte
+	err := row.Scan(&job.ID, &job.TenantID, &job.Type, &job.Phone, &job.Format, &job.Mode, &job.Status,
+		&result, &job.Error, &job.FilePath, &job.Attempts, &job.RequestedBy,
+		&job.CreatedAt, &job.StartedAt, &job.FinishedAt, &job.ExpiresAt)
+	job.Result = result
+	job.HasFile = job.FilePath != nil
+	return job, err
+}
+
+// Create queues a data job for the runner (tenant-scoped)
+func (r *DataJobRepository) Create(ctx context.Context, req *model.DataJobRequest) (*model.DataJob, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	// Tenant archives hold all of the tenant's data, settings included
+	perm := model.PermManageCustomers
+	if req.Type == model.DataJobTenantExport {
+		perm = model.PermManageSettings
+	}
+	if err := authorize(ctx, perm); err != nil {
+		return nil, err
+	}
+
+	var requestedBy *int
+	if user, err := model.GetUser(ctx); err == nil {
+		requestedBy = &user.ID
+	}
+
+	query := `
+		INSERT INTO data_jobs (tenant_id, type, phone, format, mode, requested_by)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6)
+		RETURNING ` + dataJobColumns
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	job, err := scanDataJob(tx.QueryRowContext(ctx, query, tenantID, req.Type, req.Phone, req.Format, req.Mode, requestedBy))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data job: %w", err)
+	}
+
+	after, err := snapshotRow(ctx, tx, dataJobSnapshotQuery, job.ID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, tx, "data_job", entityRef(job.ID), "create", nil, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetByID returns a data job (tenant-scoped)
+func (r *DataJobRepository) GetByID(ctx context.Context, id int) (*model.DataJob, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `SELECT ` + dataJobColumns + ` FROM data_jobs WHERE id = $1 AND tenant_id = $2`
+
+	job, err := scanDataJob(r.db.QueryRowContext(ctx, query, id, tenantID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("data job not found")
+		}
+		return nil, fmt.Errorf("failed to get data job: %w", err)
+	}
+
+	return job, nil
+}
+
+// List returns the tenant's latest data jobs, newest first (tenant-scoped)
+func (r *DataJobRepository) List(ctx context.Context, limit int) ([]*model.DataJob, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `SELECT ` + dataJobColumns + ` FROM data_jobs WHERE tenant_id = $1 ORDER BY id DESC LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list data jobs: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []*model.DataJob{}
+	for rows.Next() {
+		job, err := scanDataJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan data job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// ClaimNext takes the oldest pending job of any tenant, or a running one whose
+// lease ran out because its runner died, and counts the attempt. Returns nil
+// when there is nothing to do.
+func (r *DataJobRepository) ClaimNext(ctx context.Context, lease time.Duration) (*model.DataJob, error) {
+	query := `
+		WITH claimed AS (
+			SELECT id AS job_id FROM data_jobs
+			WHERE status = 'pending' OR (status = 'running' AND locked_until < CURRENT_TIMESTAMP)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE data_jobs j
+		SET status = 'running', attempts = j.attempts + 1, error = NULL,
+			started_at = CURRENT_TIMESTAMP, locked_until = CURRENT_TIMESTAMP + make_interval(secs => $1)
+		FROM claimed
+		WHERE j.id = claimed.job_id
+		RETURNING ` + dataJobColumns
+
+	job, err := scanDataJob(r.db.QueryRowContext(model.WithSystemScope(ctx), query, lease.Seconds()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim data job: %w", err)
+	}
+
+	return job, nil
+}
+
+// Complete stores the outcome of a finished job. An erasure forgets the phone
+// number it was run for.
+func (r *DataJobRepository) Complete(ctx context.Context, id int, result interface{}, filePath *string, expiresAt *time.Time) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode data job result: %w", err)
+	}
+
+	query := `
+		UPDATE data_jobs
+		SET status = 'done', result = $1, file_path = $2, expires_at = $3, locked_until = NULL,
+			finished_at = CURRENT_TIMESTAMP,
+			phone = CASE WHEN type = 'customer_erasure' THEN NULL ELSE phone END
+		WHERE id = $4
+	`
+
+	if _, err := r.db.ExecContext(model.WithSystemScope(ctx), query, data, filePath, expiresAt, id); err != nil {
+		return fmt.Errorf("failed to complete data job: %w", err)
+	}
+
+	return nil
+}
+
+// Fail records a failed attempt. The job is retried by the next claim unless
+// final is set.
+func (r *DataJobRepository) Fail(ctx context.Context, id int, errMsg string, final bool) error {
+	query := `
+		UPDATE data_jobs
+		SET status = CASE WHEN $1 THEN 'failed' ELSE 'pending' END, error = $2, locked_until = NULL,
+			finished_at = CASE WHEN $1 THEN CURRENT_TIMESTAMP END
+		WHERE id = $3
+	`
+
+	if _, err := r.db.ExecContext(model.WithSystemScope(ctx), query, final, errMsg, id); err != nil {
+		return fmt.Errorf("failed to record data job failure: %w", err)
+	}
+
+	return nil
+}
+
+// ExpireFiles detaches the export files past their expiry from their jobs, and
+// the phone numbers they were made for, and returns the file paths to delete
+func (r *DataJobRepository) ExpireFiles(ctx context.Context) ([]string, error) {
+	query := `
+		UPDATE data_jobs j
+		SET file_path = NULL, phone = NULL
+		FROM (
+			SELECT id AS job_id, file_path AS old_path FROM data_jobs
+			WHERE file_path IS NOT NULL AND expires_at < CURRENT_TIMESTAMP
+			FOR UPDATE
+		) expired
+		WHERE j.id = expired.job_id
+		RETURNING expired.old_path
+	`
+
+	rows, err := r.db.QueryContext(model.WithSystemScope(ctx), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire export files: %w", err)
+	}
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan export file: %w", err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// tenantExportTables are the tables of a tenant export archive, in order, with
+// the query selecting the tenant's rows as JSON. Secrets (password hashes, API
+// keys, webhook secrets, sessions, OTPs, encryption keys) are left out.
+var tenantExportTables = []struct {
+	name      string
+	query     string
+	encrypted []string // columns sealed at rest, decrypted in the export
+}{
+	{name: "tenant", query: `SELECT to_jsonb(t) FROM tenants t WHERE t.id = $1`},
+	{name: "branding", query: `SELECT to_jsonb(t) FROM tenant_branding t WHERE t.tenant_id = $1`},
+	{name: "showroom_settings", query: `SELECT to_jsonb(t) FROM showroom_settings t WHERE t.tenant_id = $1`},
+	{name: "whatsapp_settings", query: `SELECT to_jsonb(t) FROM whatsapp_settings t WHERE t.tenant_id = $1`},
+	{name: "users", query: `SELECT to_jsonb(t) - 'password_hash' FROM users t WHERE t.tenant_id = $1 ORDER BY t.id`},
+	{name: "sales", query: `SELECT to_jsonb(t) FROM sales t WHERE t.tenant_id = $1 ORDER BY t.id`},
+	{name: "cars", query: `SELECT to_jsonb(t) FROM cars t WHERE t.tenant_id = $1 ORDER BY t.id`},
+	{name: "car_specs", query: `SELECT to_jsonb(t) FROM car_specs t INNER JOIN cars c ON c.id = t.car_id WHERE c.tenant_id = $1 ORDER BY t.id`},
+	{name: "car_photos", query: `SELECT to_jsonb(t) FROM car_photos t INNER JOIN cars c ON c.id = t.car_id WHERE c.tenant_id = $1 ORDER BY t.id`},
+	{name: "blog_posts", query: `SELECT to_jsonb(t) FROM blog_posts t WHERE t.tenant_id = $1 ORDER BY t.id`},
+	{name: "leasing_partners", query: `SELECT to_jsonb(t) FROM leasing_partners t WHERE t.tenant_id = $1 ORDER BY t.id`},
+	{name: "leasing_rates", query: `SELECT to_jsonb(t) FROM leasing_rates t INNER JOIN leasing_partners p ON p.id = t.partner_id WHERE p.tenant_id = $1 ORDER BY t.id`},
+	{name: "commission_rules", query: `SELECT to_jsonb(t) FROM commission_rules t WHERE t.tenant_id = $1 ORDER BY t.id`},
+	{
+		name:      "customers",
+		query:     `SELECT to_jsonb(t) - 'phone_number_bidx' FROM customers t WHERE t.tenant_id = $1 ORDER BY t.id`,
+		encrypted: []string{"phone_number", "name"},
+	},
+	{
+		name:      "leads",
+		query:     `SELECT to_jsonb(t) - 'phone_number_bidx' FROM leads t WHERE t.tenant_id = $1 ORDER BY t.id`,
+		encrypted: []string{"phone_number", "name"},
+	},
+	{
+		name:      "appointments",
+		query:     `SELECT to_jsonb(t) - 'customer_phone_bidx' FROM appointments t WHERE t.tenant_id = $1 ORDER BY t.id`,
+		encrypted: []string{"customer_phone"},
+	},
+	{
+		name:      "trade_ins",
+		query:     `SELECT to_jsonb(t) - 'phone_number_bidx' FROM trade_ins t WHERE t.tenant_id = $1 ORDER BY t.id`,
+		encrypted: []string{"phone_number", "customer_name"},
+	},
+	{
+		name:      "deals",
+		query:     `SELECT to_jsonb(t) - 'customer_phone_bidx' FROM deals t WHERE t.tenant_id = $1 ORDER BY t.id`,
+		encrypted: []string{"customer_phone", "customer_name"},
+	},
+	{
+		name:      "conversations",
+		query:     `SELECT to_jsonb(t) - 'sender_phone_bidx' FROM conversations t WHERE t.tenant_id = $1 ORDER BY t.id`,
+		encrypted: []string{"sender_phone"},
+	},
+	{
+		name:      "messages",
+		query:     `SELECT to_jsonb(t) FROM messages t INNER JOIN conversations c ON c.id = t.conversation_id WHERE c.tenant_id = $1 ORDER BY t.id`,
+		encrypted: []string{"sender_phone", "message_text"},
+	},
+	{name: "webhook_endpoints", query: `SELECT to_jsonb(t) - 'secret' FROM webhook_endpoints t WHERE t.tenant_id = $1 ORDER BY t.id`},
+	{name: "audit_logs", query: `SELECT to_jsonb(t) FROM audit_logs t WHERE t.tenant_id = $1 ORDER BY t.id`},
+}
+
+// TenantExportTables returns the names of the tables in a tenant export archive
+func TenantExportTables() []string {
+	names := make([]string, len(tenantExportTables))
+	for i, t := range tenantExportTables {
+		names[i] = t.name
+	}
+	return names
+}
+
+// WriteTenantTable writes the tenant's rows of an export table to w as a JSON
+// array and returns the number of rows (tenant-scoped)
+func (r *DataJobRepository) WriteTenantTable(ctx context.Context, table string, w io.Writer) (int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	idx := -1
+	for i, t := range tenantExportTables {
+		if t.name == table {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return 0, fmt.Errorf("unknown export table %q", table)
+	}
+	export := tenantExportTables[idx]
+
+	rows, err := r.db.QueryContext(ctx, export.query, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to export %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return 0, err
+	}
+	count := 0
+	for rows.Next() {
+		var row []byte
+		if err := rows.Scan(&row); err != nil {
+			return count, fmt.Errorf("failed to scan %s: %w", table, err)
+		}
+		if len(export.encrypted) > 0 {
+			if row, err = r.openRow(ctx, row, export.encrypted); err != nil {
+				return count, err
+			}
+		}
+
+		sep := ",\n"
+		if count == 0 {
+			sep = "\n"
+		}
+		if _, err := io.WriteString(w, sep); err != nil {
+			return count, err
+		}
+		if _, err := w.Write(row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, fmt.Errorf("failed to export %s: %w", table, err)
+	}
+
+	_, err = io.WriteString(w, "\n]\n")
+	return count, err
+}
+
+// openRow decrypts the sealed string columns of a JSON row
+func (r *DataJobRepository) openRow(ctx context.Context, row []byte, columns []string) ([]byte, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(row, &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode export row: %w", err)
+	}
+	for _, column := range columns {
+		value, ok := fields[column].(string)
+		if !ok {
+			continue
+		}
+		plain, err := openPII(ctx, r.keys, value)
+		if err != nil {
+			return nil, err
+		}
+		fields[column] = plain
+	}
+	return json.Marshal(fields)
+}
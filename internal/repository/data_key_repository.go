@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+// dataKeyCacheTTL bounds how long an instance keeps sealing with a data key
+// after another instance rotated it
+const dataKeyCacheTTL = 5 * time.Minute
+
+// DataKeyRepository holds the per-tenant keys that encrypt customer PII at rest
+// (envelope encryption): each tenant's data keys are stored wrapped with the
+// master key and cached unwrapped in memory.
+type DataKeyRepository struct {
+	db     *sql.DB
+	master []byte
+
+	mu    sync.Mutex
+	cache map[int]*tenantKeys
+}
+
+type tenantKeys struct {
+	active   int
+	data     map[int][]byte
+	index    []byte
+	loadedAt time.Time
+}
+
+func NewDataKeyRepository(db *sql.DB, masterKey []byte) *DataKeyRepository {
+	return &DataKeyRepository{db: db, master: masterKey, cache: make(map[int]*tenantKeys)}
+}
+
+// Seal encrypts a value with the tenant's active data key (tenant-scoped)
+func (r *DataKeyRepository) Seal(ctx context.Context, plaintext string) (string, error) {
+	keys, err := r.keys(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	return security.SealString(keys.data[keys.active], keys.active, plaintext)
+}
+
+// Open decrypts a sealed value with the data key of its version. Plaintext
+// written before encryption was enabled is returned as is (tenant-scoped).
+func (r *DataKeyRepository) Open(ctx context.Context, value string) (string, error) {
+	version, sealed := security.SealedVersion(value)
+	if !sealed {
+		return value, nil
+	}
+
+	keys, err := r.keys(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := keys.data[version]; !ok {
+		// Rotated by another instance since we loaded the keys
+		if keys, err = r.keys(ctx, true); err != nil {
+			return "", err
+		}
+	}
+	key, ok := keys.data[version]
+	if !ok {
+		return "", fmt.Errorf("data key version %d not found", version)
+	}
+
+	plaintext, err := security.OpenString(key, value)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
+
+// PhoneIndex returns the blind index of a phone number; every stored format
+// of the same number gives the same index (tenant-scoped)
+func (r *DataKeyRepository) PhoneIndex(ctx context.Context, phone string) (string, error) {
+	keys, err := r.keys(ctx, false)
+	if err != nil {
+		return "", err
+	}
+	return security.BlindIndex(keys.index, model.NormalizePhone(phone)), nil
+}
+
+// ActiveVersion returns the version of the data key new values are sealed with (tenant-scoped)
+func (r *DataKeyRepository) ActiveVersion(ctx context.Context) (int, error) {
+	keys, err := r.keys(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	return keys.active, nil
+}
+
+// Rotate adds a new data key version for the tenant and returns it. Existing
+// values keep opening with their old version until they are re-encrypted.
+func (r *DataKeyRepository) Rotate(ctx context.Context) (int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if _, err := r.keys(ctx, true); err != nil {
+		return 0, err
+	}
+
+	wrapped, err := r.newWrappedKey()
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO tenant_data_keys (tenant_id, version, wrapped_key)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2 FROM tenant_data_keys WHERE tenant_id = $1
+		RETURNING version
+	`
+
+	var version int
+	if err := r.db.QueryRowContext(ctx, query, tenantID, wrapped).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to rotate data key: %w", err)
+	}
+
+	r.forget(tenantID)
+	return version, nil
+}
+
+// Rewrap re-wraps every tenant's keys from oldMaster to the current master key,
+// for rotating the master key itself. Returns the number of keys re-wrapped.
+func (r *DataKeyRepository) Rewrap(ctx context.Context, oldMaster []byte) (int, error) {
+	ctx = model.WithSystemScope(ctx)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	count := 0
+	for _, table := range []string{"tenant_data_keys", "tenant_index_keys"} {
+		n, err := r.rewrapTable(ctx, tx, table, oldMaster)
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cache = make(map[int]*tenantKeys)
+	r.mu.Unlock()
+	return count, nil
+}
+
+// rewrapTable re-wraps the keys of one key table. Index keys have no version;
+// they are selected as version 0.
+func (r *DataKeyRepository) rewrapTable(ctx context.Context, tx *sql.Tx, table string, oldMaster []byte) (int, error) {
+	type wrappedKey struct {
+		tenantID, version int
+		wrapped           []byte
+	}
+
+	version := "version"
+	if table == "tenant_index_keys" {
+		version = "0"
+	}
+	rows, err := tx.QueryContext(ctx, "SELECT tenant_id, "+version+", wrapped_key FROM "+table+" FOR UPDATE")
+	if err != nil {
+		return 0, fmt.Errorf("failed to load %s: %w", table, err)
+	}
+
+	var keys []wrappedKey
+	for rows.Next() {
+		var k wrappedKey
+		if err := rows.Scan(&k.tenantID, &k.version, &k.wrapped); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan %s: %w", table, err)
+		}
+		keys = append(keys, k)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to load %s: %w", table, err)
+	}
+
+	for _, k := range keys {
+		key, err := security.Decrypt(oldMaster, k.wrapped)
+		if err != nil {
+			return 0, fmt.Errorf("failed to unwrap key of tenant %d with the old master key: %w", k.tenantID, err)
+		}
+		wrapped, err := security.Encrypt(r.master, key)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(ctx,
+			"UPDATE "+table+" SET wrapped_key = $1 WHERE tenant_id = $2 AND "+version+" = $3",
+			wrapped, k.tenantID, k.version,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update %s: %w", table, err)
+		}
+	}
+	return len(keys), nil
+}
+
+// keys returns the tenant's unwrapped keys, creating them on first use
+func (r *DataKeyRepository) keys(ctx context.Context, reload bool) (*tenantKeys, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	r.mu.Lock()
+	cached, ok := r.cache[tenantID]
+	r.mu.Unlock()
+	if ok && !reload && time.Since(cached.loadedAt) < dataKeyCacheTTL {
+		return cached, nil
+	}
+
+	keys, err := r.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		if err := r.create(ctx, tenantID); err != nil {
+			return nil, err
+		}
+		if keys, err = r.load(ctx, tenantID); err != nil {
+			return nil, err
+		}
+		if keys == nil {
+			return nil, fmt.Errorf("data keys not found after creation")
+		}
+	}
+
+	r.mu.Lock()
+	r.cache[tenantID] = keys
+	r.mu.Unlock()
+	return keys, nil
+}
+
+// load reads and unwraps the tenant's keys; nil when it has none yet
+func (r *DataKeyRepository) load(ctx context.Context, tenantID int) (*tenantKeys, error) {
+	var wrappedIndex []byte
+	err := r.db.QueryRowContext(ctx, "SELECT wrapped_key FROM tenant_index_keys WHERE tenant_id = $1", tenantID).Scan(&wrappedIndex)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load index key: %w", err)
+	}
+
+	keys := &tenantKeys{data: make(map[int][]byte), loadedAt: time.Now()}
+	if keys.index, err = r.unwrap(wrappedIndex); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT version, wrapped_key FROM tenant_data_keys WHERE tenant_id = $1", tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load data keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var version int
+		var wrapped []byte
+		if err := rows.Scan(&version, &wrapped); err != nil {
+			return nil, fmt.Errorf("failed to scan data key: %w", err)
+		}
+		if keys.data[version], err = r.unwrap(wrapped); err != nil {
+			return nil, err
+		}
+		if version > keys.active {
+			keys.active = version
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load data keys: %w", err)
+	}
+
+	if keys.active == 0 {
+		return nil, nil
+	}
+	return keys, nil
+}
+
+// create stores the tenant's first data key and its index key. Concurrent
+// creators race on the primary keys; the losers load the winner's keys.
+func (r *DataKeyRepository) create(ctx context.Context, tenantID int) error {
+	wrappedData, err := r.newWrappedKey()
+	if err != nil {
+		return err
+	}
+	wrappedIndex, err := r.newWrappedKey()
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO tenant_index_keys (tenant_id, wrapped_key) VALUES ($1, $2) ON CONFLICT (tenant_id) DO NOTHING",
+		tenantID, wrappedIndex,
+	); err != nil {
+		return fmt.Errorf("failed to create index key: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO tenant_data_keys (tenant_id, version, wrapped_key) VALUES ($1, 1, $2) ON CONFLICT (tenant_id, version) DO NOTHING",
+		tenantID, wrappedData,
+	); err != nil {
+		return fmt.Errorf("failed to create data key: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *DataKeyRepository) newWrappedKey() ([]byte, error) {
+	key, err := security.NewEncryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	return security.Encrypt(r.master, key)
+}
+
+func (r *DataKeyRepository) unwrap(wrapped []byte) ([]byte, error) {
+	key, err := security.Decrypt(r.master, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap key, is PII_MASTER_KEY correct?: %w", err)
+	}
+	return key, nil
+}
+
+func (r *DataKeyRepository) forget(tenantID int) {
+	r.mu.Lock()
+	delete(r.cache, tenantID)
+	r.mu.Unlock()
+}
+
+// sealPII encrypts a value when encryption is enabled (keys set); without
+// keys it is stored as is
+func sealPII(ctx context.Context, keys *DataKeyRepository, value string) (string, error) {
+	if keys == nil {
+		return value, nil
+	}
+	return keys.Seal(ctx, value)
+}
+
+// openPII decrypts a value read from an encrypted column
+func openPII(ctx context.Context, keys *DataKeyRepository, value string) (string, error) {
+	if keys == nil {
+		if _, sealed := security.SealedVersion(value); sealed {
+			return "", fmt.Errorf("value is encrypted but PII_MASTER_KEY is not set")
+		}
+		return value, nil
+	}
+	return keys.Open(ctx, value)
+}
+
+// phoneIndex returns the blind index of a phone number, or nil when
+// encryption is disabled
+func phoneIndex(ctx context.Context, keys *DataKeyRepository, phone string) (*string, error) {
+	if keys == nil {
+		return nil, nil
+	}
+	index, err := keys.PhoneIndex(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+	return &index, nil
+}
+
+// sealPhone encrypts a phone number when encryption is enabled and returns it
+// with its blind index (nil when disabled)
+func sealPhone(ctx context.Context, keys *DataKeyRepository, phone string) (string, *string, error) {
+	bidx, err := phoneIndex(ctx, keys, phone)
+	if err != nil {
+		return "", nil, err
+	}
+	sealed, err := sealPII(ctx, keys, phone)
+	if err != nil {
+		return "", nil, err
+	}
+	return sealed, bidx, nil
+}
+
+// sealOptionalPII is sealPII for nullable columns
+func sealOptionalPII(ctx context.Context, keys *DataKeyRepository, value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	sealed, err := sealPII(ctx, keys, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+// openOptionalPII is openPII for nullable columns
+func openOptionalPII(ctx context.Context, keys *DataKeyRepository, value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	plain, err := openPII(ctx, keys, *value)
+	if err != nil {
+		return nil, err
+	}
+	return &plain, nil
+}
@@ -0,0 +1,372 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type DealRepository struct {
+	db   *sql.DB
+	keys *DataKeyRepository
+}
+
+func NewDealRepository(db *sql.DB) *DealRepository {
+	return &DealRepository{db: db}
+}
+
+// SetDataKeys enables encryption at rest of customer phone numbers and names.
+// Rows written before stay readable; cmd/encrypt-pii encrypts them.
+func (r *DealRepository) SetDataKeys(keys *DataKeyRepository) {
+	r.keys = keys
+}
+
+const dealSnapshotQuery = "SELECT to_jsonb(d) - 'customer_phone_bidx' FROM deals d WHERE d.id = $1 AND d.tenant_id = $2"
+
+const dealSelect = `
+	SELECT d.id, d.tenant_id, d.car_id, d.lead_id, d.sales_id, d.customer_name, d.customer_phone,
+		d.list_price, d.agreed_price, d.discount, d.payment_method, d.leasing_partner_id, d.status,
+		d.closing_date, d.commission_amount, d.notes, d.created_at, d.updated_at,
+		COALESCE(c.brand || ' ' || c.model || ' ' || c.year, ''), COALESCE(s.name, '')
+	FROM deals d
+	LEFT JOIN cars c ON c.id = d.car_id
+	LEFT JOIN sales s ON s.id = d.sales_id
+`
+
+func scanDeal(row interface{ Scan(...interface{}) error }) (*model.Deal, error) {
+	d := &model.Deal{}
+	err := row.Scan(
+		&d.ID, &d.TenantID, &d.CarID, &d.LeadID, &d.SalesID, &d.CustomerName, &d.CustomerPhone,
+		&d.ListPrice, &d.AgreedPrice, &d.Discount, &d.PaymentMethod, &d.LeasingPartnerID, &d.Status,
+		&d.ClosingDate, &d.CommissionAmount, &d.Notes, &d.CreatedAt, &d.UpdatedAt,
+		&d.CarName, &d.SalesName,
+	)
+	return d, err
+}
+
+// openDeal decrypts the customer phone number and name of a deal read from the database
+func (r *DealRepository) openDeal(ctx context.Context, d *model.Deal) error {
+	phone, err := openPII(ctx, r.keys, d.CustomerPhone)
+	if err != nil {
+		return err
+	}
+	name, err := openPII(ctx, r.keys, d.CustomerName)
+	if err != nil {
+		return err
+	}
+	d.CustomerPhone, d.CustomerName = phone, name
+	return nil
+}
+
+// sealCustomer encrypts the customer phone number and name of a deal to store
+func (r *DealRepository) sealCustomer(ctx context.Context, d *model.Deal) (name, phone string, bidx *string, err error) {
+	if phone, bidx, err = sealPhone(ctx, r.keys, d.CustomerPhone); err != nil {
+		return "", "", nil, err
+	}
+	if name, err = sealPII(ctx, r.keys, d.CustomerName); err != nil {
+		return "", "", nil, err
+	}
+	return name, phone, bidx, nil
+}
+
+// Create creates a new open deal (tenant-scoped)
+func (r *DealRepository) Create(ctx context.Context, deal *model.Deal) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return err
+	}
+
+	name, phone, bidx, err := r.sealCustomer(ctx, deal)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO deals (
+			tenant_id, car_id, lead_id, sales_id, customer_name, customer_phone, customer_phone_bidx,
+			list_price, agreed_price, discount, payment_method, leasing_partner_id, notes
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, status, created_at, updated_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		tenantID, deal.CarID, deal.LeadID, deal.SalesID, name, phone, bidx,
+		deal.ListPrice, deal.AgreedPrice, deal.Discount, deal.PaymentMethod, deal.LeasingPartnerID, deal.Notes,
+	).Scan(&deal.ID, &deal.Status, &deal.CreatedAt, &deal.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create deal: %w", err)
+	}
+
+	deal.TenantID = tenantID
+	return nil
+}
+
+// GetByID retrieves a deal by ID (tenant-scoped)
+func (r *DealRepository) GetByID(ctx context.Context, id int) (*model.Deal, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	deal, err := scanDeal(r.db.QueryRowContext(ctx, dealSelect+" WHERE d.id = $1 AND d.tenant_id = $2", id, tenantID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deal not found")
+		}
+		return nil, fmt.Errorf("failed to get deal: %w", err)
+	}
+	if err := r.openDeal(ctx, deal); err != nil {
+		return nil, err
+	}
+
+	return deal, nil
+}
+
+// List retrieves deals for tenant with optional status and sales filters
+func (r *DealRepository) List(ctx context.Context, filters map[string]interface{}) ([]*model.Deal, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	query := dealSelect + " WHERE d.tenant_id = $1"
+	args := []interface{}{tenantID}
+	argCount := 1
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		argCount++
+		query += fmt.Sprintf(" AND d.status = $%d", argCount)
+		args = append(args, status)
+	}
+
+	if salesID, ok := filters["sales_id"].(int); ok && salesID > 0 {
+		argCount++
+		query += fmt.Sprintf(" AND d.sales_id = $%d", argCount)
+		args = append(args, salesID)
+	}
+
+	query += " ORDER BY d.created_at DESC"
+
+	return r.queryDeals(ctx, query, args...)
+}
+
+// ListClosedBetween retrieves closed deals with closing date in [from, to) (tenant-scoped).
+// Only the calendar dates of from and to are used, so the caller picks the time zone.
+func (r *DealRepository) ListClosedBetween(ctx context.Context, from, to time.Time) ([]*model.Deal, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageBilling, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	query := dealSelect + `
+		WHERE d.tenant_id = $1 AND d.status = 'closed'
+			AND d.closing_date >= $2::date AND d.closing_date < $3::date
+		ORDER BY s.name ASC, d.closing_date ASC
+	`
+
+	return r.queryDeals(ctx, query, tenantID, from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+func (r *DealRepository) queryDeals(ctx context.Context, query string, args ...interface{}) ([]*model.Deal, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deals: %w", err)
+	}
+	defer rows.Close()
+
+	var deals []*model.Deal
+	for rows.Next() {
+		deal, err := scanDeal(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan deal: %w", err)
+		}
+		if err := r.openDeal(ctx, deal); err != nil {
+			return nil, err
+		}
+		deals = append(deals, deal)
+	}
+
+	return deals, nil
+}
+
+// Update updates an open deal (tenant-scoped)
+func (r *DealRepository) Update(ctx context.Context, id int, deal *model.Deal) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return err
+	}
+
+	name, phone, bidx, err := r.sealCustomer(ctx, deal)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE deals
+		SET car_id = $1, lead_id = $2, sales_id = $3, customer_name = $4, customer_phone = $5,
+			customer_phone_bidx = $6, list_price = $7, agreed_price = $8, discount = $9,
+			payment_method = $10, leasing_partner_id = $11, notes = $12, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $13 AND tenant_id = $14 AND status = 'open'
+	`
+
+	result, err := r.db.ExecContext(ctx, query,
+		deal.CarID, deal.LeadID, deal.SalesID, name, phone, bidx,
+		deal.ListPrice, deal.AgreedPrice, deal.Discount, deal.PaymentMethod,
+		deal.LeasingPartnerID, deal.Notes, id, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update deal: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("open deal not found or no permission")
+	}
+
+	return nil
+}
+
+// Close marks a deal closed with its commission and sets the car to sold in one transaction.
+// The closing date is stored as closingDate's calendar date in its own location.
+func (r *DealRepository) Close(ctx context.Context, id int, closingDate time.Time, commission int64) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := snapshotRow(ctx, tx, dealSnapshotQuery+" FOR UPDATE", id, tenantID)
+	if err != nil {
+		return err
+	}
+
+	var carID sql.NullInt64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE deals
+		SET status = 'closed', closing_date = $1, commission_amount = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND tenant_id = $4 AND status = 'open'
+		RETURNING car_id
+	`, closingDate.Format("2006-01-02"), commission, id, tenantID).Scan(&carID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("open deal not found or no permission")
+		}
+		return fmt.Errorf("failed to close deal: %w", err)
+	}
+
+	after, err := snapshotRow(ctx, tx, dealSnapshotQuery, id, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, tx, "deal", entityRef(id), "close", before, after); err != nil {
+		return err
+	}
+
+	if carID.Valid {
+		if err := markCarSold(ctx, tx, int(carID.Int64), tenantID); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// markCarSold marks a deal's car sold in the deal's transaction, with an audit
+// entry and a car.sold webhook event. A car already sold is left as is.
+func markCarSold(ctx context.Context, tx *sql.Tx, carID, tenantID int) error {
+	before, err := snapshotRow(ctx, tx, carSnapshotQuery+" FOR UPDATE", carID, tenantID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx,
+		"UPDATE cars SET status = 'sold', updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND tenant_id = $2 AND status <> 'sold'",
+		carID, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark car sold: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil
+	}
+
+	after, err := snapshotRow(ctx, tx, carSnapshotQuery, carID, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, tx, "car", entityRef(carID), "update", before, after); err != nil {
+		return err
+	}
+	return enqueueWebhook(ctx, tx, model.WebhookEventCarSold, after)
+}
+
+// Cancel cancels an open deal (tenant-scoped)
+func (r *DealRepository) Cancel(ctx context.Context, id int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE deals SET status = 'cancelled', updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND tenant_id = $2 AND status = 'open'",
+		id, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to cancel deal: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("open deal not found or no permission")
+	}
+
+	return nil
+}
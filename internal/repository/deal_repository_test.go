@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// Runs against the database of the row-level security suite (see rls_test.go)
+func TestDealCloseAudited(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := model.WithSystemActor(model.WithTenantID(context.Background(), f.tenantA))
+	deals := NewDealRepository(f.db)
+
+	var carID int
+	mustScan(t, f.db.QueryRowContext(ctx,
+		"INSERT INTO cars (tenant_id, brand, model, year, price) VALUES ($1, 'Honda', 'Jazz', 2019, 180000000) RETURNING id",
+		f.tenantA), &carID)
+	deal := &model.Deal{
+		CarID: &carID, CustomerName: "Andi", CustomerPhone: "6281234555",
+		ListPrice: 180000000, AgreedPrice: 175000000, PaymentMethod: "cash",
+	}
+	if err := deals.Create(ctx, deal); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := deals.Close(ctx, deal.ID, time.Now(), 1000000); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	for _, entry := range []struct {
+		entity string
+		id     int
+		action string
+		status string
+	}{
+		{"deal", deal.ID, "close", "closed"},
+		{"car", carID, "update", "sold"},
+	} {
+		var status string
+		mustScan(t, f.db.QueryRowContext(ctx,
+			"SELECT after_data->>'status' FROM audit_logs WHERE entity_type = $1 AND entity_id = $2 AND action = $3",
+			entry.entity, entry.id, entry.action), &status)
+		if status != entry.status {
+			t.Errorf("%s audit status = %q, want %q", entry.entity, status, entry.status)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+var (
+	// ErrDomainNotFound is returned for a custom domain the tenant does not have
+	ErrDomainNotFound = errors.New("domain not found")
+	// ErrDomainNotVerified is returned when making an unverified domain canonical
+	ErrDomainNotVerified = errors.New("domain not verified")
+)
+
+type DomainRepository struct {
+	db *sql.DB
+}
+
+func NewDomainRepository(db *sql.DB) *DomainRepository {
+	return &DomainRepository{db: db}
+}
+
+const tenantDomainColumns = `id, tenant_id, domain, token, is_canonical, verified_at, verified_by,
+	last_checked_at, last_error, created_at`
+
+const tenantDomainSnapshotQuery = "SELECT to_jsonb(d) FROM tenant_domains d WHERE d.id = $1 AND d.tenant_id = $2"
+
+func scanTenantDomain(row interface{ Scan(...interface{}) error }) (*model.TenantDomain, error) {
+	d := &model.TenantDomain{}
+	var token string
+	err := row.Scan(&d.ID, &d.TenantID, &d.Domain, &token, &d.IsCanonical, &d.VerifiedAt, &d.VerifiedBy,
+		&d.LastCheckedAt, &d.LastError, &d.CreatedAt)
+	d.Status = model.DomainStatus(d.VerifiedAt, d.LastError)
+	d.Challenge = model.NewDomainChallenge(d.Domain, token)
+	return d, err
+}
+
+// domainInUse reports whether domain is another tenant's platform domain or
+// verified custom domain. It looks across tenants, bypassing row-level security.
+func domainInUse(ctx context.Context, q queryRower, domain string, tenantID int) (bool, error) {
+	var inUse bool
+	err := q.QueryRowContext(model.WithSystemScope(ctx),
+		"SELECT EXISTS (SELECT 1 FROM tenants WHERE domain = $1 AND id <> $2)",
+		domain, tenantID,
+	).Scan(&inUse)
+	if err != nil {
+		return false, fmt.Errorf("failed to check domain: %w", err)
+	}
+	if inUse {
+		return true, nil
+	}
+	return customDomainTaken(ctx, q, domain, tenantID)
+}
+
+// customDomainTaken reports whether another tenant than tenantID verified
+// domain as its custom domain, looking across tenants
+func customDomainTaken(ctx context.Context, q queryRower, domain string, tenantID int) (bool, error) {
+	var taken bool
+	err := q.QueryRowContext(model.WithSystemScope(ctx),
+		"SELECT EXISTS (SELECT 1 FROM tenant_domains WHERE domain = $1 AND tenant_id <> $2 AND verified_at IS NOT NULL)",
+		domain, tenantID,
+	).Scan(&taken)
+	if err != nil {
+		return false, fmt.Errorf("failed to check custom domain: %w", err)
+	}
+	return taken, nil
+}
+
+// List returns the tenant's platform domain, its custom domains and which of
+// them is canonical (tenant-scoped)
+func (r *DomainRepository) List(ctx context.Context) (*model.TenantDomains, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	result := &model.TenantDomains{Domains: []*model.TenantDomain{}}
+	if err := r.db.QueryRowContext(ctx, "SELECT domain FROM tenants WHERE id = $1", tenantID).Scan(&result.PlatformDomain); err != nil {
+		return nil, fmt.Errorf("failed to get tenant domain: %w", err)
+	}
+	result.Canonical = result.PlatformDomain
+
+	query := "SELECT " + tenantDomainColumns + " FROM tenant_domains WHERE tenant_id = $1 ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list domains: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d, err := scanTenantDomain(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan domain: %w", err)
+		}
+		if d.IsCanonical {
+			result.Canonical = d.Domain
+		}
+		result.Domains = append(result.Domains, d)
+	}
+	result.Count = len(result.Domains)
+
+	return result, rows.Err()
+}
+
+// Get retrieves a custom domain by ID (tenant-scoped)
+func (r *DomainRepository) Get(ctx context.Context, id int) (*model.TenantDomain, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT " + tenantDomainColumns + " FROM tenant_domains WHERE id = $1 AND tenant_id = $2"
+
+	d, err := scanTenantDomain(r.db.QueryRowContext(ctx, query, id, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, ErrDomainNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get domain: %w", err)
+	}
+	return d, nil
+}
+
+// Add stores a pending custom domain with its verification token. A domain
+// another tenant routes already gives ErrDomainTaken (tenant-scoped).
+func (r *DomainRepository) Add(ctx context.Context, domain, token string) (*model.TenantDomain, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	inUse, err := domainInUse(ctx, tx, domain, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, ErrDomainTaken
+	}
+
+	query := `
+		INSERT INTO tenant_domains (tenant_id, domain, token)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (SELECT 1 FROM tenants WHERE id = $1 AND domain = $2)
+		RETURNING ` + tenantDomainColumns
+
+	d, err := scanTenantDomain(tx.QueryRowContext(ctx, query, tenantID, domain, token))
+	if err != nil {
+		var pqErr *pq.Error
+		if err == sql.ErrNoRows || (errors.As(err, &pqErr) && pqErr.Code == "23505") {
+			// Already the platform domain or a custom domain of this tenant
+			return nil, ErrDomainTaken
+		}
+		return nil, fmt.Errorf("failed to add domain: %w", err)
+	}
+
+	after, err := snapshotRow(ctx, tx, tenantDomainSnapshotQuery, d.ID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, tx, "domain", entityRef(d.ID), "create", nil, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return d, nil
+}
+
+// MarkVerified records that the tenant proved ownership with method. It gives
+// ErrDomainTaken when another tenant verified the domain first (tenant-scoped).
+func (r *DomainRepository) MarkVerified(ctx context.Context, id int, method string) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		UPDATE tenant_domains d
+		SET verified_at = COALESCE(verified_at, CURRENT_TIMESTAMP), verified_by = $3,
+			last_checked_at = CURRENT_TIMESTAMP, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND tenant_id = $2
+			AND NOT EXISTS (SELECT 1 FROM tenants t WHERE t.domain = d.domain)
+	`
+
+	target := auditTarget{
+		EntityType: "domain", EntityID: entityRef(id), Action: "verify",
+		Snapshot: tenantDomainSnapshotQuery, SnapshotArgs: []interface{}{id, tenantID},
+	}
+	result, err := auditedExec(ctx, r.db, target, query, id, tenantID, method)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrDomainTaken
+		}
+		return fmt.Errorf("failed to verify domain: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		// Gone, or another tenant got it as its platform domain meanwhile
+		return ErrDomainTaken
+	}
+	return nil
+}
+
+// RecordFailedCheck keeps why the last verification failed. A verified domain
+// stays verified; only the failure is noted (tenant-scoped).
+func (r *DomainRepository) RecordFailedCheck(ctx context.Context, id int, reason string) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		UPDATE tenant_domains
+		SET last_checked_at = CURRENT_TIMESTAMP, last_error = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND tenant_id = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, tenantID, reason); err != nil {
+		return fmt.Errorf("failed to record domain check: %w", err)
+	}
+	return nil
+}
+
+// SetCanonical makes domain the one the tenant's other domains redirect to.
+// The platform domain clears the custom canonical domain (tenant-scoped).
+func (r *DomainRepository) SetCanonical(ctx context.Context, domain string) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Locking the tenant serializes concurrent changes of its canonical domain
+	var platformDomain string
+	err = tx.QueryRowContext(ctx, "SELECT domain FROM tenants WHERE id = $1 FOR NO KEY UPDATE", tenantID).Scan(&platformDomain)
+	if err != nil {
+		return fmt.Errorf("failed to get tenant domain: %w", err)
+	}
+	before, err := snapshotRow(ctx, tx, canonicalSnapshotQuery, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE tenant_domains SET is_canonical = false, updated_at = CURRENT_TIMESTAMP WHERE tenant_id = $1 AND is_canonical",
+		tenantID,
+	); err != nil {
+		return fmt.Errorf("failed to clear canonical domain: %w", err)
+	}
+
+	if domain != platformDomain {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE tenant_domains SET is_canonical = true, updated_at = CURRENT_TIMESTAMP
+			WHERE tenant_id = $1 AND domain = $2 AND verified_at IS NOT NULL
+		`, tenantID, domain)
+		if err != nil {
+			return fmt.Errorf("failed to set canonical domain: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return ErrDomainNotVerified
+		}
+	}
+
+	after, err := snapshotRow(ctx, tx, canonicalSnapshotQuery, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := recordAudit(ctx, tx, "domain", nil, "set_canonical", before, after); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// canonicalSnapshotQuery loads the tenant's canonical domain for the audit log
+const canonicalSnapshotQuery = `
+	SELECT jsonb_build_object('canonical', COALESCE(d.domain, t.domain))
+	FROM tenants t
+	LEFT JOIN tenant_domains d ON d.tenant_id = t.id AND d.is_canonical
+	WHERE t.id = $1`
+
+// Delete removes a custom domain; when it was canonical the platform domain
+// takes over (tenant-scoped)
+func (r *DomainRepository) Delete(ctx context.Context, id int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	target := auditTarget{
+		EntityType: "domain", EntityID: entityRef(id), Action: "delete",
+		Snapshot: tenantDomainSnapshotQuery, SnapshotArgs: []interface{}{id, tenantID},
+	}
+	result, err := auditedExec(ctx, r.db, target, "DELETE FROM tenant_domains WHERE id = $1 AND tenant_id = $2", id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete domain: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrDomainNotFound
+	}
+	return nil
+}
+
+// HasChallenge reports whether a tenant added domain with token, so the
+// platform can answer the HTTP verification request for it. A domain pointing
+// at the platform proves nothing between tenants, so while several tenants
+// claim the domain none is answered and only the TXT record settles it. It runs
+// before any tenant is known and looks across tenants.
+func (r *DomainRepository) HasChallenge(ctx context.Context, domain, token string) (bool, error) {
+	var found bool
+	err := r.db.QueryRowContext(model.WithSystemScope(ctx),
+		"SELECT COALESCE(bool_and(token = $2), false) FROM tenant_domains WHERE domain = $1",
+		domain, token,
+	).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("failed to check domain challenge: %w", err)
+	}
+	return found, nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// ErrFAQNotFound is returned for an FAQ the tenant does not have
+var ErrFAQNotFound = errors.New("faq not found")
+
+type FAQRepository struct {
+	db *sql.DB
+}
+
+func NewFAQRepository(db *sql.DB) *FAQRepository {
+	return &FAQRepository{db: db}
+}
+
+const faqColumns = `id, tenant_id, category, question, answer, created_at, updated_at`
+
+const faqSnapshotQuery = "SELECT to_jsonb(f) - 'search_vector' FROM faqs f WHERE f.id = $1 AND f.tenant_id = $2"
+
+func scanFAQ(row interface{ Scan(...interface{}) error }) (*model.FAQ, error) {
+	f := &model.FAQ{}
+	err := row.Scan(&f.ID, &f.TenantID, &f.Category, &f.Question, &f.Answer, &f.CreatedAt, &f.UpdatedAt)
+	return f, err
+}
+
+// List returns the tenant's FAQs grouped by category (tenant-scoped)
+func (r *FAQRepository) List(ctx context.Context) ([]*model.FAQ, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT " + faqColumns + " FROM faqs WHERE tenant_id = $1 ORDER BY category, id"
+
+	return r.query(ctx, "list", query, tenantID)
+}
+
+// Search returns the tenant's FAQs sharing words with text, best matches
+// first. Any word may match, since customers phrase questions freely
+// (tenant-scoped).
+func (r *FAQRepository) Search(ctx context.Context, text, category string, limit int) ([]*model.FAQ, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		SELECT ` + faqColumns + `
+		FROM faqs, replace(plainto_tsquery('simple', $2)::text, ' & ', ' | ')::tsquery q
+		WHERE tenant_id = $1 AND search_vector @@ q AND ($3 = '' OR category = $3)
+		ORDER BY ts_rank(search_vector, q) DESC, id
+		LIMIT $4
+	`
+
+	return r.query(ctx, "search", query, tenantID, text, category, limit)
+}
+
+func (r *FAQRepository) query(ctx context.Context, action, query string, args ...interface{}) ([]*model.FAQ, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s faqs: %w", action, err)
+	}
+	defer rows.Close()
+
+	faqs := []*model.FAQ{}
+	for rows.Next() {
+		f, err := scanFAQ(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan faq: %w", err)
+		}
+		faqs = append(faqs, f)
+	}
+	return faqs, rows.Err()
+}
+
+// Get retrieves an FAQ by ID (tenant-scoped)
+func (r *FAQRepository) Get(ctx context.Context, id int) (*model.FAQ, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT " + faqColumns + " FROM faqs WHERE id = $1 AND tenant_id = $2"
+
+	f, err := scanFAQ(r.db.QueryRowContext(ctx, query, id, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, ErrFAQNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get faq: %w", err)
+	}
+	return f, nil
+}
+
+// Create stores a new FAQ (tenant-scoped)
+func (r *FAQRepository) Create(ctx context.Context, req *model.FAQRequest) (*model.FAQ, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO faqs (tenant_id, category, question, answer)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + faqColumns
+
+	f, err := scanFAQ(tx.QueryRowContext(ctx, query, tenantID, req.Category, req.Question, req.Answer))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create faq: %w", err)
+	}
+
+	after, err := snapshotRow(ctx, tx, faqSnapshotQuery, f.ID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, tx, "faq", entityRef(f.ID), "create", nil, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return f, nil
+}
+
+// Update changes an FAQ's category, question and answer (tenant-scoped)
+func (r *FAQRepository) Update(ctx context.Context, id int, req *model.FAQRequest) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		UPDATE faqs
+		SET category = $1, question = $2, answer = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND tenant_id = $5
+	`
+
+	return r.execChange(ctx, id, tenantID, "update", query, req.Category, req.Question, req.Answer, id, tenantID)
+}
+
+// Delete removes an FAQ (tenant-scoped)
+func (r *FAQRepository) Delete(ctx context.Context, id int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	return r.execChange(ctx, id, tenantID, "delete", "DELETE FROM faqs WHERE id = $1 AND tenant_id = $2", id, tenantID)
+}
+
+func (r *FAQRepository) execChange(ctx context.Context, id, tenantID int, action, query string, args ...interface{}) error {
+	target := auditTarget{
+		EntityType: "faq", EntityID: entityRef(id), Action: action,
+		Snapshot: faqSnapshotQuery, SnapshotArgs: []interface{}{id, tenantID},
+	}
+	result, err := auditedExec(ctx, r.db, target, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s faq: %w", action, err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrFAQNotFound
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type FinancingRepository struct {
+	db *sql.DB
+}
+
+func NewFinancingRepository(db *sql.DB) *FinancingRepository {
+	return &FinancingRepository{db: db}
+}
+
+// ListPartners retrieves leasing partners with their rate tables. Active ones
+// are public for the credit calculator; inactive ones only show in the settings
+// (tenant-scoped).
+func (r *FinancingRepository) ListPartners(ctx context.Context, activeOnly bool) ([]*model.LeasingPartner, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if !activeOnly {
+		if err := authorize(ctx, model.PermManageSettings); err != nil {
+			return nil, err
+		}
+	}
+
+	query := `
+		SELECT id, tenant_id, name, admin_fee, insurance_rate, is_active, created_at, updated_at
+		FROM leasing_partners
+		WHERE tenant_id = $1
+	`
+	if activeOnly {
+		query += " AND is_active = TRUE"
+	}
+	query += " ORDER BY name ASC"
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leasing partners: %w", err)
+	}
+	defer rows.Close()
+
+	var partners []*model.LeasingPartner
+	byID := make(map[int]*model.LeasingPartner)
+	for rows.Next() {
+		p := &model.LeasingPartner{}
+		err := rows.Scan(&p.ID, &p.TenantID, &p.Name, &p.AdminFee, &p.InsuranceRate, &p.IsActive, &p.CreatedAt, &p.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan leasing partner: %w", err)
+		}
+		partners = append(partners, p)
+		byID[p.ID] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list leasing partners: %w", err)
+	}
+
+	if len(partners) == 0 {
+		return partners, nil
+	}
+
+	rateQuery := `
+		SELECT lr.id, lr.partner_id, lr.tenor_months, lr.min_dp_percent, lr.max_car_age, lr.interest_rate
+		FROM leasing_rates lr
+		INNER JOIN leasing_partners lp ON lp.id = lr.partner_id
+		WHERE lp.tenant_id = $1
+		ORDER BY lr.tenor_months ASC, lr.min_dp_percent ASC
+	`
+
+	rateRows, err := r.db.QueryContext(ctx, rateQuery, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leasing rates: %w", err)
+	}
+	defer rateRows.Close()
+
+	for rateRows.Next() {
+		rate := &model.LeasingRate{}
+		err := rateRows.Scan(&rate.ID, &rate.PartnerID, &rate.TenorMonths, &rate.MinDPPercent, &rate.MaxCarAge, &rate.InterestRate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan leasing rate: %w", err)
+		}
+		if p, ok := byID[rate.PartnerID]; ok {
+			p.Rates = append(p.Rates, rate)
+		}
+	}
+
+	return partners, rateRows.Err()
+}
+
+// GetPartner retrieves a single leasing partner with rates (tenant-scoped)
+func (r *FinancingRepository) GetPartner(ctx context.Context, id int) (*model.LeasingPartner, error) {
+	partners, err := r.ListPartners(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range partners {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("leasing partner not found")
+}
+
+// CreatePartner creates a leasing partner and its rate table in a transaction (tenant-scoped)
+func (r *FinancingRepository) CreatePartner(ctx context.Context, req *model.LeasingPartnerRequest) (*model.LeasingPartner, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	query := `
+		INSERT INTO leasing_partners (tenant_id, name, admin_fee, insurance_rate, is_active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, tenant_id, name, admin_fee, insurance_rate, is_active, created_at, updated_at
+	`
+
+	p := &model.LeasingPartner{}
+	err = tx.QueryRowContext(ctx, query, tenantID, req.Name, req.AdminFee, req.InsuranceRate, isActive).Scan(
+		&p.ID, &p.TenantID, &p.Name, &p.AdminFee, &p.InsuranceRate, &p.IsActive, &p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create leasing partner: %w", err)
+	}
+
+	if err := insertLeasingRates(ctx, tx, p.ID, req.Rates); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	p.Rates = req.Rates
+	return p, nil
+}
+
+// UpdatePartner updates a leasing partner and replaces its rate table (tenant-scoped)
+func (r *FinancingRepository) UpdatePartner(ctx context.Context, id int, req *model.LeasingPartnerRequest) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE leasing_partners
+		SET name = $1, admin_fee = $2, insurance_rate = $3, is_active = COALESCE($4, is_active), updated_at = NOW()
+		WHERE id = $5 AND tenant_id = $6
+	`
+
+	result, err := tx.ExecContext(ctx, query, req.Name, req.AdminFee, req.InsuranceRate, req.IsActive, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update leasing partner: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("leasing partner not found or no permission")
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM leasing_rates WHERE partner_id = $1", id); err != nil {
+		return fmt.Errorf("failed to clear leasing rates: %w", err)
+	}
+
+	if err := insertLeasingRates(ctx, tx, id, req.Rates); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePartner removes a leasing partner and its rates (tenant-scoped)
+func (r *FinancingRepository) DeletePartner(ctx context.Context, id int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM leasing_partners WHERE id = $1 AND tenant_id = $2", id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete leasing partner: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("leasing partner not found or no permission")
+	}
+
+	return nil
+}
+
+// insertLeasingRates inserts rate rows for a partner inside an existing transaction
+func insertLeasingRates(ctx context.Context, tx *sql.Tx, partnerID int, rates []*model.LeasingRate) error {
+	query := `
+		INSERT INTO leasing_rates (partner_id, tenor_months, min_dp_percent, max_car_age, interest_rate)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+
+	for _, rate := range rates {
+		err := tx.QueryRowContext(ctx, query, partnerID, rate.TenorMonths, rate.MinDPPercent, rate.MaxCarAge, rate.InterestRate).Scan(&rate.ID)
+		if err != nil {
+			return fmt.Errorf("failed to create leasing rate: %w", err)
+		}
+		rate.PartnerID = partnerID
+	}
+
+	return nil
+}
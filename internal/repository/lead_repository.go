@@ -0,0 +1,406 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type LeadRepository struct {
+	db   *sql.DB
+	keys *DataKeyRepository
+}
+
+func NewLeadRepository(db *sql.DB) *LeadRepository {
+	return &LeadRepository{db: db}
+}
+
+// SetDataKeys enables encryption at rest of lead phone numbers and names.
+// Rows written before stay readable; cmd/encrypt-pii encrypts them.
+func (r *LeadRepository) SetDataKeys(keys *DataKeyRepository) {
+	r.keys = keys
+}
+
+const leadColumns = `
+	l.id, l.tenant_id, l.phone_number, l.name, l.interested_car_id, l.conversation_id,
+	l.source, l.status, l.assigned_sales_id, l.created_at, l.updated_at
+`
+
+func scanLead(row interface{ Scan(...interface{}) error }) (*model.Lead, error) {
+	lead := &model.Lead{}
+	err := row.Scan(
+		&lead.ID, &lead.TenantID, &lead.PhoneNumber, &lead.Name, &lead.InterestedCarID,
+		&lead.ConversationID, &lead.Source, &lead.Status, &lead.AssignedSalesID,
+		&lead.CreatedAt, &lead.UpdatedAt,
+	)
+	return lead, err
+}
+
+// openLead decrypts the phone number and name of a lead read from the database
+func openLead(ctx context.Context, keys *DataKeyRepository, lead *model.Lead) error {
+	phone, err := openPII(ctx, keys, lead.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	name, err := openOptionalPII(ctx, keys, lead.Name)
+	if err != nil {
+		return err
+	}
+	lead.PhoneNumber, lead.Name = phone, name
+	return nil
+}
+
+// Create creates a new lead (tenant-scoped)
+func (r *LeadRepository) Create(ctx context.Context, req *model.CreateLeadRequest) (*model.Lead, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	source := req.Source
+	if source == "" {
+		source = "whatsapp"
+	}
+
+	phone, bidx, err := sealPhone(ctx, r.keys, req.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	name, err := sealOptionalPII(ctx, r.keys, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO leads AS l (tenant_id, phone_number, phone_number_bidx, name, interested_car_id, conversation_id, source, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, 'new')
+		RETURNING ` + leadColumns
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	lead, err := scanLead(tx.QueryRowContext(ctx, query,
+		tenantID, phone, bidx, name, req.InterestedCarID, req.ConversationID, source,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lead: %w", err)
+	}
+	// Webhook receivers get the plaintext
+	if err := openLead(ctx, r.keys, lead); err != nil {
+		return nil, err
+	}
+
+	if err := enqueueWebhook(ctx, tx, model.WebhookEventLeadCreated, lead); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return lead, nil
+}
+
+// QueueFollowUp opens a WhatsApp lead for sales to pick up, unless the phone
+// number already has an open one. It reports whether a lead was created
+// (tenant-scoped).
+func (r *LeadRepository) QueueFollowUp(ctx context.Context, phone string, conversationID int) (bool, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return false, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	sealed, bidx, err := sealPhone(ctx, r.keys, phone)
+	if err != nil {
+		return false, err
+	}
+
+	// Open leads are found by blind index once encrypted, by the plain number
+	// for rows not encrypted yet
+	query := `
+		INSERT INTO leads AS l (tenant_id, phone_number, phone_number_bidx, conversation_id, source, status)
+		SELECT $1, $2, $3, $4, 'whatsapp', 'new'
+		WHERE NOT EXISTS (
+			SELECT 1 FROM leads
+			WHERE tenant_id = $1 AND (phone_number_bidx = $3 OR phone_number = $5)
+				AND status IN ('new', 'contacted')
+		)
+		RETURNING ` + leadColumns
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	lead, err := scanLead(tx.QueryRowContext(ctx, query, tenantID, sealed, bidx, conversationID, phone))
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to queue lead: %w", err)
+	}
+	if err := openLead(ctx, r.keys, lead); err != nil {
+		return false, err
+	}
+
+	if err := enqueueWebhook(ctx, tx, model.WebhookEventLeadCreated, lead); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return true, nil
+}
+
+// GetByID retrieves a lead by ID; sales users only get leads assigned to them (tenant-scoped)
+func (r *LeadRepository) GetByID(ctx context.Context, id int) (*model.Lead, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	salesID, scoped, err := assignedSalesScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + leadColumns + `
+		FROM leads l
+		WHERE l.id = $1 AND l.tenant_id = $2 AND (NOT $3 OR l.assigned_sales_id = $4)
+	`
+
+	lead, err := scanLead(r.db.QueryRowContext(ctx, query, id, tenantID, scoped, salesID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("lead not found")
+		}
+		return nil, fmt.Errorf("failed to get lead: %w", err)
+	}
+	if err := openLead(ctx, r.keys, lead); err != nil {
+		return nil, err
+	}
+
+	return lead, nil
+}
+
+// List retrieves leads for tenant, optionally filtered by status (assigned ones for sales users)
+func (r *LeadRepository) List(ctx context.Context, statusFilter string) ([]*model.Lead, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	salesID, scoped, err := assignedSalesScope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + leadColumns + " FROM leads l WHERE l.tenant_id = $1"
+	args := []interface{}{tenantID}
+
+	if scoped {
+		args = append(args, salesID)
+		query += fmt.Sprintf(" AND l.assigned_sales_id = $%d", len(args))
+	}
+
+	if statusFilter != "" {
+		args = append(args, statusFilter)
+		query += fmt.Sprintf(" AND l.status = $%d", len(args))
+	}
+
+	query += " ORDER BY l.created_at DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list leads: %w", err)
+	}
+	defer rows.Close()
+
+	var leads []*model.Lead
+	for rows.Next() {
+		lead, err := scanLead(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lead: %w", err)
+		}
+		if err := openLead(ctx, r.keys, lead); err != nil {
+			return nil, err
+		}
+		leads = append(leads, lead)
+	}
+
+	return leads, nil
+}
+
+// ListForSales retrieves open leads a sales person is handling (assigned or linked through a deal),
+// plus new leads nobody has picked up yet when includeUnassigned is set (tenant-scoped)
+func (r *LeadRepository) ListForSales(ctx context.Context, salesPhone string, includeUnassigned bool) ([]*model.Lead, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT " + leadColumns + `
+		FROM leads l
+		WHERE l.tenant_id = $1 AND l.status NOT IN ('converted', 'lost')
+			AND (
+				EXISTS (
+					SELECT 1 FROM sales s
+					WHERE s.id = l.assigned_sales_id AND s.tenant_id = $1 AND s.phone_number = $2
+				)
+				OR EXISTS (
+					SELECT 1 FROM deals d
+					INNER JOIN sales s ON s.id = d.sales_id
+					WHERE d.lead_id = l.id AND s.tenant_id = $1 AND s.phone_number = $2
+				)
+				OR ($3 AND l.status = 'new' AND l.assigned_sales_id IS NULL
+					AND NOT EXISTS (SELECT 1 FROM deals d WHERE d.lead_id = l.id))
+			)
+		ORDER BY l.created_at DESC
+		LIMIT 50
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID, salesPhone, includeUnassigned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sales leads: %w", err)
+	}
+	defer rows.Close()
+
+	var leads []*model.Lead
+	for rows.Next() {
+		lead, err := scanLead(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan lead: %w", err)
+		}
+		if err := openLead(ctx, r.keys, lead); err != nil {
+			return nil, err
+		}
+		leads = append(leads, lead)
+	}
+
+	return leads, nil
+}
+
+// UpdateStatus changes the status of a lead (tenant-scoped)
+func (r *LeadRepository) UpdateStatus(ctx context.Context, id int, status string) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	salesID, scoped, err := assignedSalesScope(ctx)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE leads
+		SET status = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND tenant_id = $3 AND (NOT $4 OR assigned_sales_id = $5)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, status, id, tenantID, scoped, salesID)
+	if err != nil {
+		return fmt.Errorf("failed to update lead: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("lead not found or no permission")
+	}
+
+	return nil
+}
+
+// AssignSales assigns a lead to a sales person, or unassigns it when salesID is nil (tenant-scoped)
+func (r *LeadRepository) AssignSales(ctx context.Context, id int, salesID *int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE leads SET assigned_sales_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND tenant_id = $3
+			AND ($1::int IS NULL OR EXISTS (SELECT 1 FROM sales WHERE id = $1 AND tenant_id = $3))
+	`
+	result, err := r.db.ExecContext(ctx, query, salesID, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to assign lead: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("lead not found or no permission")
+	}
+
+	return nil
+}
+
+// ListPage returns one page of leads, newest first, optionally filtered by status,
+// and the cursor of the next page (0 on the last page). Sales users only get
+// leads assigned to them (tenant-scoped).
+func (r *LeadRepository) ListPage(ctx context.Context, page model.PageRequest, statusFilter string) ([]*model.Lead, int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	salesID, scoped, err := assignedSalesScope(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + leadColumns + `
+		FROM leads l
+		WHERE l.tenant_id = $1 AND (NOT $2 OR l.assigned_sales_id = $3)
+			AND ($4 = 0 OR l.id < $4) AND ($5 = '' OR l.status = $5)
+		ORDER BY l.id DESC
+		LIMIT $6
+	`
+
+	limit := page.PageLimit()
+	rows, err := r.db.QueryContext(ctx, query, tenantID, scoped, salesID, page.Cursor, statusFilter, limit+1)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list leads: %w", err)
+	}
+	defer rows.Close()
+
+	var leads []*model.Lead
+	for rows.Next() {
+		lead, err := scanLead(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan lead: %w", err)
+		}
+		if err := openLead(ctx, r.keys, lead); err != nil {
+			return nil, 0, err
+		}
+		leads = append(leads, lead)
+	}
+
+	next := 0
+	if len(leads) > limit {
+		leads = leads[:limit]
+		next = leads[limit-1].ID
+	}
+
+	return leads, next, nil
+}
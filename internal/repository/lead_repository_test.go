@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// Runs against the database of the row-level security suite (see rls_test.go)
+func TestLeadQueueFollowUp(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := model.WithSystemActor(model.WithTenantID(context.Background(), f.tenantA))
+	system := model.WithSystemScope(context.Background())
+
+	// Tenant A may send webhooks and listens for new leads
+	mustExec(t, f.db, system, "UPDATE tenants SET plan = 'enterprise' WHERE id = $1", f.tenantA)
+	mustExec(t, f.db, ctx,
+		"INSERT INTO webhook_endpoints (tenant_id, url, secret, events) VALUES ($1, 'https://crm.test/hook', 'whsec_test', $2)",
+		f.tenantA, "{"+model.WebhookEventLeadCreated+"}")
+	var convID int
+	mustScan(t, f.db.QueryRowContext(ctx,
+		"INSERT INTO conversations (tenant_id, sender_phone, is_sales) VALUES ($1, '628333', false) RETURNING id",
+		f.tenantA), &convID)
+
+	leads := NewLeadRepository(f.db)
+	created, err := leads.QueueFollowUp(ctx, "628333", convID)
+	if err != nil || !created {
+		t.Fatalf("QueueFollowUp = %v, %v; want a new lead", created, err)
+	}
+
+	var status, source string
+	var leadConv int
+	mustScan(t, f.db.QueryRowContext(ctx,
+		"SELECT status, source, conversation_id FROM leads WHERE phone_number = '628333'"), &status, &source, &leadConv)
+	if status != "new" || source != "whatsapp" || leadConv != convID {
+		t.Errorf("lead = %s/%s/%d, want new/whatsapp/%d", status, source, leadConv, convID)
+	}
+
+	var deliveries int
+	mustScan(t, f.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM webhook_deliveries WHERE event_type = $1", model.WebhookEventLeadCreated), &deliveries)
+	if deliveries != 1 {
+		t.Errorf("queued %d %s deliveries, want 1", deliveries, model.WebhookEventLeadCreated)
+	}
+
+	// An open lead for the phone, queued above or seeded, is not duplicated
+	for _, phone := range []string{"628333", "628111"} {
+		if created, err := leads.QueueFollowUp(ctx, phone, convID); err != nil || created {
+			t.Errorf("QueueFollowUp(%s) = %v, %v; want no new lead", phone, created, err)
+		}
+	}
+}
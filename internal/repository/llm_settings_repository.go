@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+var (
+	// ErrLLMKeyRequired is returned when saving settings without an API key
+	// while the tenant has no stored key for that provider
+	ErrLLMKeyRequired = errors.New("LLM API key required")
+	// ErrLLMKeysUnavailable is returned when storing an API key without data
+	// keys to encrypt it with
+	ErrLLMKeysUnavailable = errors.New("LLM API keys cannot be stored without encryption")
+)
+
+// LLMSettingsRepository stores the LLM providers tenants bring with their own
+// API keys, sealed with the tenant's data key
+type LLMSettingsRepository struct {
+	db   *sql.DB
+	keys *DataKeyRepository
+}
+
+func NewLLMSettingsRepository(db *sql.DB) *LLMSettingsRepository {
+	return &LLMSettingsRepository{db: db}
+}
+
+// SetDataKeys enables storing API keys; without it tenants can only use the
+// platform provider
+func (r *LLMSettingsRepository) SetDataKeys(keys *DataKeyRepository) {
+	r.keys = keys
+}
+
+// KeysAvailable reports whether API keys can be stored
+func (r *LLMSettingsRepository) KeysAvailable() bool {
+	return r.keys != nil
+}
+
+// llmSettingsTarget describes a tenant's LLM settings for the audit log,
+// leaving out the sealed key
+func llmSettingsTarget(tenantID int, action string) auditTarget {
+	return auditTarget{
+		EntityType: "llm_settings", Action: action,
+		Snapshot:     "SELECT to_jsonb(s) - 'api_key' FROM tenant_llm_settings s WHERE s.tenant_id = $1",
+		SnapshotArgs: []interface{}{tenantID},
+	}
+}
+
+// Get returns the tenant's own provider, nil when it uses the platform's (tenant-scoped)
+func (r *LLMSettingsRepository) Get(ctx context.Context) (*model.LLMSettings, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		SELECT tenant_id, provider, model, key_hint, temperature, updated_at
+		FROM tenant_llm_settings
+		WHERE tenant_id = $1
+	`
+
+	s := &model.LLMSettings{}
+	err = r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&s.TenantID, &s.Provider, &s.Model, &s.KeyHint, &s.Temperature, &s.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM settings: %w", err)
+	}
+	return s, nil
+}
+
+// Save sets the tenant's own provider. Without an API key in the request the
+// stored key is kept, as long as the provider stays the same (tenant-scoped).
+func (r *LLMSettingsRepository) Save(ctx context.Context, req *model.SaveLLMSettingsRequest) (*model.LLMSettings, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if req.APIKey == "" {
+		query := `
+			UPDATE tenant_llm_settings
+			SET model = $2, temperature = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE tenant_id = $1 AND provider = $4
+		`
+		result, err := auditedExec(ctx, r.db, llmSettingsTarget(tenantID, "update"), query,
+			tenantID, req.Model, req.Temperature, req.Provider)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save LLM settings: %w", err)
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return nil, ErrLLMKeyRequired
+		}
+		return r.Get(ctx)
+	}
+
+	if r.keys == nil {
+		return nil, ErrLLMKeysUnavailable
+	}
+	sealed, err := r.keys.Seal(ctx, req.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt API key: %w", err)
+	}
+
+	query := `
+		INSERT INTO tenant_llm_settings (tenant_id, provider, model, api_key, key_hint, temperature)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			provider = EXCLUDED.provider,
+			model = EXCLUDED.model,
+			api_key = EXCLUDED.api_key,
+			key_hint = EXCLUDED.key_hint,
+			temperature = EXCLUDED.temperature,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	_, err = auditedExec(ctx, r.db, llmSettingsTarget(tenantID, "update"), query,
+		tenantID, req.Provider, req.Model, sealed, model.LLMKeyHint(req.APIKey), req.Temperature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save LLM settings: %w", err)
+	}
+	return r.Get(ctx)
+}
+
+// Delete removes the tenant's own provider, moving it back to the platform's (tenant-scoped)
+func (r *LLMSettingsRepository) Delete(ctx context.Context) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	_, err = auditedExec(ctx, r.db, llmSettingsTarget(tenantID, "delete"),
+		"DELETE FROM tenant_llm_settings WHERE tenant_id = $1", tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete LLM settings: %w", err)
+	}
+	return nil
+}
+
+// Credentials returns the tenant's provider with its decrypted API key, nil
+// when it uses the platform's (tenant-scoped)
+func (r *LLMSettingsRepository) Credentials(ctx context.Context) (*model.LLMCredentials, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT provider, model, api_key, temperature FROM tenant_llm_settings WHERE tenant_id = $1"
+
+	c := &model.LLMCredentials{}
+	var sealed string
+	err = r.db.QueryRowContext(ctx, query, tenantID).Scan(&c.Provider, &c.Model, &sealed, &c.Temperature)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get LLM credentials: %w", err)
+	}
+
+	if r.keys == nil {
+		return nil, ErrLLMKeysUnavailable
+	}
+	if c.APIKey, err = r.keys.Open(ctx, sealed); err != nil {
+		return nil, fmt.Errorf("failed to decrypt API key: %w", err)
+	}
+	return c, nil
+}
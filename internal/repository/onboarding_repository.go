@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// ErrDemoSeeded is returned when seeding demo inventory a second time
+var ErrDemoSeeded = errors.New("demo inventory already seeded")
+
+type OnboardingRepository struct {
+	db *sql.DB
+}
+
+func NewOnboardingRepository(db *sql.DB) *OnboardingRepository {
+	return &OnboardingRepository{db: db}
+}
+
+const onboardingSnapshotQuery = "SELECT to_jsonb(o) FROM tenant_onboarding o WHERE o.tenant_id = $1"
+
+// Start creates the onboarding record of the context tenant if it has none (tenant-scoped)
+func (r *OnboardingRepository) Start(ctx context.Context) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		"INSERT INTO tenant_onboarding (tenant_id) VALUES ($1) ON CONFLICT (tenant_id) DO NOTHING", tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to start onboarding: %w", err)
+	}
+	return nil
+}
+
+// Progress reads which steps the context tenant has done from its data (tenant-scoped)
+func (r *OnboardingRepository) Progress(ctx context.Context) (*model.OnboardingProgress, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		SELECT
+			EXISTS (SELECT 1 FROM users WHERE tenant_id = t.id AND role = 'owner'),
+			EXISTS (SELECT 1 FROM tenant_branding WHERE tenant_id = t.id),
+			EXISTS (SELECT 1 FROM showroom_settings WHERE tenant_id = t.id),
+			t.pairing_status = 'paired',
+			EXISTS (SELECT 1 FROM cars WHERE tenant_id = t.id),
+			COALESCE(o.skipped_steps, '{}'),
+			COALESCE(cardinality(o.demo_car_ids), 0),
+			o.demo_seeded_at,
+			o.completed_at
+		FROM tenants t
+		LEFT JOIN tenant_onboarding o ON o.tenant_id = t.id
+		WHERE t.id = $1
+	`
+
+	var owner, branding, showroom, paired, cars bool
+	var skipped []string
+	var demoCars int
+	var demoSeededAt, completedAt *time.Time
+	err = r.db.QueryRowContext(ctx, query, tenantID).Scan(
+		&owner, &branding, &showroom, &paired, &cars,
+		pq.Array(&skipped), &demoCars, &demoSeededAt, &completedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get onboarding progress: %w", err)
+	}
+
+	progress := model.NewOnboardingProgress(tenantID, map[string]bool{
+		model.OnboardingTenant:   true,
+		model.OnboardingOwner:    owner,
+		model.OnboardingBranding: branding,
+		model.OnboardingShowroom: showroom,
+		model.OnboardingWhatsApp: paired,
+		model.OnboardingCars:     cars,
+	}, skipped)
+	progress.DemoCars = demoCars
+	progress.DemoSeededAt = demoSeededAt
+	progress.CompletedAt = completedAt
+	return progress, nil
+}
+
+// SetSkipped marks a step of the context tenant as skipped or not (tenant-scoped)
+func (r *OnboardingRepository) SetSkipped(ctx context.Context, step string, skipped bool) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO tenant_onboarding (tenant_id, skipped_steps)
+		VALUES ($1, CASE WHEN $3 THEN ARRAY[$2::text] ELSE '{}' END)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			skipped_steps = CASE WHEN $3
+				THEN array_append(array_remove(tenant_onboarding.skipped_steps, $2::text), $2::text)
+				ELSE array_remove(tenant_onboarding.skipped_steps, $2::text) END,
+			updated_at = NOW()
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, tenantID, step, skipped); err != nil {
+		return fmt.Errorf("failed to update onboarding: %w", err)
+	}
+	return nil
+}
+
+// Finish records that the context tenant finished or dismissed the wizard (tenant-scoped)
+func (r *OnboardingRepository) Finish(ctx context.Context) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO tenant_onboarding (tenant_id, completed_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			completed_at = COALESCE(tenant_onboarding.completed_at, NOW()), updated_at = NOW()
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, tenantID); err != nil {
+		return fmt.Errorf("failed to finish onboarding: %w", err)
+	}
+	return nil
+}
+
+// SeedDemoCars adds the demo catalog to the context tenant's inventory once,
+// up to the car limit of its plan, and remembers the cars so RemoveDemoCars can
+// take them out again. No webhooks are sent for them: integrations should not
+// pick up sample inventory.
+func (r *OnboardingRepository) SeedDemoCars(ctx context.Context, cars []*model.Car) (int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageInventory); err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO tenant_onboarding (tenant_id) VALUES ($1) ON CONFLICT (tenant_id) DO NOTHING", tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to start onboarding: %w", err)
+	}
+
+	var seededAt *time.Time
+	err = tx.QueryRowContext(ctx,
+		"SELECT demo_seeded_at FROM tenant_onboarding WHERE tenant_id = $1 FOR UPDATE", tenantID,
+	).Scan(&seededAt)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock onboarding: %w", err)
+	}
+	if seededAt != nil {
+		return 0, ErrDemoSeeded
+	}
+
+	// Smaller plans get as much of the catalog as fits
+	headroom, plan, err := planHeadroom(ctx, tx, tenantID, model.LimitCars)
+	if err != nil {
+		return 0, err
+	}
+	if headroom == 0 {
+		return 0, &model.PlanLimitError{Limit: model.LimitCars, Max: *plan.MaxCars, Plan: plan.Name}
+	}
+	if headroom > 0 && headroom < len(cars) {
+		cars = cars[:headroom]
+	}
+
+	query := `
+		INSERT INTO cars (
+			tenant_id, brand, model, year, price, mileage, transmission,
+			fuel_type, engine_cc, seats, color, description, status, is_featured
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+
+	ids := make([]int64, 0, len(cars))
+	for _, car := range cars {
+		err := tx.QueryRowContext(ctx, query,
+			tenantID, car.Brand, car.Model, car.Year, car.Price, car.Mileage,
+			car.Transmission, car.FuelType, car.EngineCC, car.Seats, car.Color,
+			car.Description, car.Status, car.IsFeatured,
+		).Scan(&car.ID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create demo car: %w", err)
+		}
+		car.TenantID = tenantID
+		ids = append(ids, int64(car.ID))
+	}
+
+	if err := r.recordDemoCars(ctx, tx, tenantID, "seed_demo", ids, true); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return len(ids), nil
+}
+
+// RemoveDemoCars deletes the seeded demo cars that are still in the context
+// tenant's inventory; demo data can then be seeded again (tenant-scoped)
+func (r *OnboardingRepository) RemoveDemoCars(ctx context.Context) (int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageInventory); err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		DELETE FROM cars
+		WHERE tenant_id = $1
+			AND id = ANY(SELECT unnest(demo_car_ids) FROM tenant_onboarding WHERE tenant_id = $1)
+	`, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete demo cars: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if err := r.recordDemoCars(ctx, tx, tenantID, "remove_demo", []int64{}, false); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return int(removed), nil
+}
+
+// recordDemoCars stores the demo car IDs on the onboarding record with an audit entry
+func (r *OnboardingRepository) recordDemoCars(ctx context.Context, tx *sql.Tx, tenantID int, action string, ids []int64, seeded bool) error {
+	before, err := snapshotRow(ctx, tx, onboardingSnapshotQuery, tenantID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE tenant_onboarding
+		SET demo_car_ids = $2, demo_seeded_at = CASE WHEN $3 THEN NOW() END, updated_at = NOW()
+		WHERE tenant_id = $1
+	`, tenantID, pq.Array(ids), seeded)
+	if err != nil {
+		return fmt.Errorf("failed to record demo cars: %w", err)
+	}
+
+	after, err := snapshotRow(ctx, tx, onboardingSnapshotQuery, tenantID)
+	if err != nil {
+		return err
+	}
+	return recordAudit(ctx, tx, "onboarding", nil, action, before, after)
+}
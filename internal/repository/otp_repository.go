@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type OTPRepository struct {
+	db *sql.DB
+}
+
+func NewOTPRepository(db *sql.DB) *OTPRepository {
+	return &OTPRepository{db: db}
+}
+
+// Create stores a new OTP and invalidates any earlier unused ones for the phone (tenant-scoped)
+func (r *OTPRepository) Create(ctx context.Context, otp *model.LoginOTP, ipAddress string) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE login_otps SET consumed_at = CURRENT_TIMESTAMP
+		WHERE tenant_id = $1 AND phone_number = $2 AND consumed_at IS NULL
+	`, tenantID, otp.PhoneNumber)
+	if err != nil {
+		return fmt.Errorf("failed to invalidate previous OTPs: %w", err)
+	}
+
+	query := `
+		INSERT INTO login_otps (tenant_id, sales_id, phone_number, code_hash, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6)
+		RETURNING id, attempts, created_at
+	`
+	err = tx.QueryRowContext(ctx, query,
+		tenantID, otp.SalesID, otp.PhoneNumber, otp.CodeHash, ipAddress, otp.ExpiresAt,
+	).Scan(&otp.ID, &otp.Attempts, &otp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create OTP: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	otp.TenantID = tenantID
+	return nil
+}
+
+// RecentActivity returns how many OTPs were sent to a phone since a time and when the
+// latest was sent, for rate limiting (tenant-scoped)
+func (r *OTPRepository) RecentActivity(ctx context.Context, phoneNumber string, since time.Time) (int, *time.Time, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_otps
+		WHERE tenant_id = $1 AND phone_number = $2 AND created_at >= $3
+	`
+
+	var count int
+	var latest *time.Time
+	if err := r.db.QueryRowContext(ctx, query, tenantID, phoneNumber, since).Scan(&count, &latest); err != nil {
+		return 0, nil, fmt.Errorf("failed to count OTPs: %w", err)
+	}
+
+	return count, latest, nil
+}
+
+// GetActive retrieves the latest unused OTP for a phone; the caller checks its
+// expiry (tenant-scoped)
+func (r *OTPRepository) GetActive(ctx context.Context, phoneNumber string) (*model.LoginOTP, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		SELECT id, tenant_id, sales_id, phone_number, code_hash, attempts, expires_at, consumed_at, created_at
+		FROM login_otps
+		WHERE tenant_id = $1 AND phone_number = $2 AND consumed_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	otp := &model.LoginOTP{}
+	err = r.db.QueryRowContext(ctx, query, tenantID, phoneNumber).Scan(
+		&otp.ID, &otp.TenantID, &otp.SalesID, &otp.PhoneNumber, &otp.CodeHash,
+		&otp.Attempts, &otp.ExpiresAt, &otp.ConsumedAt, &otp.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("otp not found")
+		}
+		return nil, fmt.Errorf("failed to get OTP: %w", err)
+	}
+
+	return otp, nil
+}
+
+// IncrementAttempts records a wrong code and returns the new attempt count (tenant-scoped)
+func (r *OTPRepository) IncrementAttempts(ctx context.Context, id int) (int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "UPDATE login_otps SET attempts = attempts + 1 WHERE id = $1 AND tenant_id = $2 RETURNING attempts"
+
+	var attempts int
+	if err := r.db.QueryRowContext(ctx, query, id, tenantID).Scan(&attempts); err != nil {
+		return 0, fmt.Errorf("failed to record OTP attempt: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// Consume marks an OTP as used. It fails if the OTP was already used, so a code
+// cannot log in twice even under concurrent requests (tenant-scoped)
+func (r *OTPRepository) Consume(ctx context.Context, id int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		UPDATE login_otps SET consumed_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND tenant_id = $2 AND consumed_at IS NULL
+	`
+	result, err := r.db.ExecContext(ctx, query, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to consume OTP: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("otp not found or already used")
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// ErrUnknownPlan is returned when assigning a plan that does not exist
+var ErrUnknownPlan = errors.New("plan not found")
+
+type PlanRepository struct {
+	db *sql.DB
+}
+
+func NewPlanRepository(db *sql.DB) *PlanRepository {
+	return &PlanRepository{db: db}
+}
+
+const planColumns = `p.code, p.name, p.price_monthly, p.max_cars, p.max_sales_seats,
+	p.max_ai_generations, p.max_whatsapp_messages, p.features`
+
+func scanPlan(row interface{ Scan(...interface{}) error }) (*model.Plan, error) {
+	plan := &model.Plan{}
+	err := row.Scan(
+		&plan.Code,
+		&plan.Name,
+		&plan.PriceMonthly,
+		&plan.MaxCars,
+		&plan.MaxSalesSeats,
+		&plan.MaxAIGenerations,
+		&plan.MaxWhatsAppMessages,
+		pq.Array(&plan.Features),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// countedLimits count the current usage of the limits that are read from the data
+var countedLimits = map[string]string{
+	model.LimitCars:       "SELECT COUNT(*) FROM cars WHERE tenant_id = $1 AND status <> 'sold'",
+	model.LimitSalesSeats: "SELECT COUNT(*) FROM sales WHERE tenant_id = $1",
+}
+
+// usagePeriod returns the SQL for the first day of the current period of a
+// metered limit. Days and months follow Indonesian western time.
+func usagePeriod(period string) string {
+	if period == model.PeriodMonth {
+		return "date_trunc('month', CURRENT_TIMESTAMP AT TIME ZONE 'Asia/Jakarta')::date"
+	}
+	return "(CURRENT_TIMESTAMP AT TIME ZONE 'Asia/Jakarta')::date"
+}
+
+// List returns all plans, cheapest first
+func (r *PlanRepository) List(ctx context.Context) ([]*model.Plan, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+planColumns+" FROM plans p ORDER BY p.sort_order, p.code")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plans: %w", err)
+	}
+	defer rows.Close()
+
+	var plans []*model.Plan
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan plan: %w", err)
+		}
+		plans = append(plans, plan)
+	}
+	return plans, rows.Err()
+}
+
+// Current returns the plan of the context tenant (tenant-scoped)
+func (r *PlanRepository) Current(ctx context.Context) (*model.Plan, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT " + planColumns + " FROM tenants t JOIN plans p ON p.code = t.plan WHERE t.id = $1"
+
+	plan, err := scanPlan(r.db.QueryRowContext(ctx, query, tenantID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("tenant not found")
+		}
+		return nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+	return plan, nil
+}
+
+// CheckFeature fails with *model.FeatureUnavailableError when the context
+// tenant's plan does not include feature (tenant-scoped)
+func (r *PlanRepository) CheckFeature(ctx context.Context, feature string) error {
+	plan, err := r.Current(ctx)
+	if err != nil {
+		return err
+	}
+	if !plan.HasFeature(feature) {
+		return &model.FeatureUnavailableError{Feature: feature, Plan: plan.Name}
+	}
+	return nil
+}
+
+// Consume records one use of a metered limit for the context tenant, or fails
+// with *model.PlanLimitError when the period's quota is used up. Usage is
+// recorded on unlimited plans too, for the usage dashboard (tenant-scoped).
+func (r *PlanRepository) Consume(ctx context.Context, limit string) error {
+	info, ok := model.LookupLimit(limit)
+	if !ok || info.Period == "" {
+		return fmt.Errorf("%s is not a metered limit", limit)
+	}
+
+	plan, err := r.Current(ctx)
+	if err != nil {
+		return err
+	}
+	quota := plan.Limit(limit)
+	if quota != nil && *quota <= 0 {
+		return &model.PlanLimitError{Limit: limit, Max: *quota, Plan: plan.Name}
+	}
+
+	tenantID, _ := model.GetTenantID(ctx)
+	query := fmt.Sprintf(`
+		INSERT INTO tenant_usage (tenant_id, metric, period, count)
+		VALUES ($1, $2, %s, 1)
+		ON CONFLICT (tenant_id, metric, period) DO UPDATE
+		SET count = tenant_usage.count + 1, updated_at = NOW()
+		WHERE $3::int IS NULL OR tenant_usage.count < $3::int
+		RETURNING count
+	`, usagePeriod(info.Period))
+
+	var count int
+	err = r.db.QueryRowContext(ctx, query, tenantID, limit, quota).Scan(&count)
+	if err == sql.ErrNoRows {
+		return &model.PlanLimitError{Limit: limit, Max: *quota, Plan: plan.Name}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record usage: %w", err)
+	}
+	return nil
+}
+
+// Usage returns the context tenant's plan with how much of each limit is used (tenant-scoped)
+func (r *PlanRepository) Usage(ctx context.Context) (*model.Usage, error) {
+	plan, err := r.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tenantID, _ := model.GetTenantID(ctx)
+
+	used := map[string]int{}
+	for _, info := range model.PlanLimits {
+		var n int
+		if query, counted := countedLimits[info.Key]; counted {
+			err = r.db.QueryRowContext(ctx, query, tenantID).Scan(&n)
+		} else {
+			query = fmt.Sprintf(`
+				SELECT COALESCE(SUM(count), 0) FROM tenant_usage
+				WHERE tenant_id = $1 AND metric = $2 AND period = %s
+			`, usagePeriod(info.Period))
+			err = r.db.QueryRowContext(ctx, query, tenantID, info.Key).Scan(&n)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s usage: %w", info.Key, err)
+		}
+		used[info.Key] = n
+	}
+
+	return model.NewUsage(plan, used), nil
+}
+
+// planHeadroom locks the tenant row until tx ends and returns its plan with
+// how many more of a counted limit the plan allows; -1 means unlimited. The
+// lock serializes concurrent inserts, so two of them cannot both take the
+// last slot. FOR NO KEY UPDATE leaves foreign key checks on the tenant free.
+func planHeadroom(ctx context.Context, tx *sql.Tx, tenantID int, limit string) (int, *model.Plan, error) {
+	query := "SELECT " + planColumns + " FROM tenants t JOIN plans p ON p.code = t.plan WHERE t.id = $1 FOR NO KEY UPDATE OF t"
+
+	plan, err := scanPlan(tx.QueryRowContext(ctx, query, tenantID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil, fmt.Errorf("tenant not found")
+		}
+		return 0, nil, fmt.Errorf("failed to get plan: %w", err)
+	}
+
+	quota := plan.Limit(limit)
+	if quota == nil {
+		return -1, plan, nil
+	}
+
+	var used int
+	if err := tx.QueryRowContext(ctx, countedLimits[limit], tenantID).Scan(&used); err != nil {
+		return 0, nil, fmt.Errorf("failed to count %s: %w", limit, err)
+	}
+	return max(0, *quota-used), plan, nil
+}
+
+// checkPlanLimit fails with *model.PlanLimitError when adding n more of a
+// counted limit would go over the tenant's plan. Call it in the transaction
+// that adds them.
+func checkPlanLimit(ctx context.Context, tx *sql.Tx, tenantID int, limit string, n int) error {
+	headroom, plan, err := planHeadroom(ctx, tx, tenantID, limit)
+	if err != nil {
+		return err
+	}
+	if headroom >= 0 && n > headroom {
+		return &model.PlanLimitError{Limit: limit, Max: *plan.Limit(limit), Plan: plan.Name}
+	}
+	return nil
+}
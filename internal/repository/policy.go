@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/pkg/database"
+)
+
+// ErrForbidden is returned when the logged-in user's role does not allow an operation
+var ErrForbidden = errors.New("forbidden")
+
+// authorize checks that the user in context may perform any of perms on the
+// context tenant. Callers without a user (bot, API keys, background jobs, CLI
+// tools) must be marked with model.WithSystemActor; a context with neither is refused.
+func authorize(ctx context.Context, perms ...model.Permission) error {
+	user, err := model.GetUser(ctx)
+	if err != nil {
+		if model.IsSystemActor(ctx) {
+			return nil
+		}
+		return fmt.Errorf("%w: no user or system actor in context", ErrForbidden)
+	}
+
+	if !user.IsSuperAdmin() {
+		tenantID, err := model.GetTenantID(ctx)
+		if err != nil {
+			return fmt.Errorf("tenant ID required: %w", err)
+		}
+		if user.TenantID != tenantID {
+			return ErrForbidden
+		}
+	}
+
+	for _, perm := range perms {
+		if user.Can(perm) {
+			return nil
+		}
+	}
+
+	return ErrForbidden
+}
+
+// assignedSalesScope returns the sales ID a query must be limited to when the
+// user may only see assigned leads and conversations. scoped is false for
+// system callers and users who manage all customers.
+func assignedSalesScope(ctx context.Context) (salesID int, scoped bool, err error) {
+	if err := authorize(ctx, model.PermViewAssigned); err != nil {
+		return 0, false, err
+	}
+
+	user, err := model.GetUser(ctx)
+	if err != nil || user.Can(model.PermManageCustomers) {
+		return 0, false, nil
+	}
+
+	if user.SalesID == nil {
+		// A sales user not linked to a sales record has nothing assigned
+		return 0, true, nil
+	}
+
+	return *user.SalesID, true, nil
+}
+
+// TenantScope ties database sessions to the context tenant so the row-level
+// security policies (migration 000026) isolate tenants even when a query
+// forgets its tenant_id filter
+func TenantScope(role string) database.TenantScope {
+	return database.TenantScope{
+		TenantID: func(ctx context.Context) (int, bool) {
+			tenantID, err := model.GetTenantID(ctx)
+			return tenantID, err == nil
+		},
+		Bypass: model.IsSystemScope,
+		Role:   role,
+	}
+}
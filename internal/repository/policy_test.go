@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+func userContext(tenantID int, user *model.User) context.Context {
+	ctx := model.WithTenantID(context.Background(), tenantID)
+	return model.WithUser(ctx, user)
+}
+
+// The policy check runs before any query, so a repository without a database is enough
+func TestCarRepository_Delete_SalesForbidden(t *testing.T) {
+	repo := &CarRepository{}
+	ctx := userContext(1, &model.User{ID: 3, TenantID: 1, Role: model.RoleSales})
+
+	if err := repo.Delete(ctx, 10); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden for sales deleting a car, got %v", err)
+	}
+}
+
+func TestCarRepository_Writes_SalesForbidden(t *testing.T) {
+	repo := &CarRepository{}
+	ctx := userContext(1, &model.User{ID: 3, TenantID: 1, Role: model.RoleSales})
+
+	if err := repo.Create(ctx, &model.Car{}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden on Create, got %v", err)
+	}
+	if err := repo.Update(ctx, 10, map[string]interface{}{"price": 1}); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden on Update, got %v", err)
+	}
+	if err := repo.DeletePhoto(ctx, 5); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden on DeletePhoto, got %v", err)
+	}
+}
+
+func TestConversationRepository_GetByID_OtherTenantForbidden(t *testing.T) {
+	repo := &ConversationRepository{}
+	ctx := userContext(2, &model.User{ID: 1, TenantID: 1, Role: model.RoleOwner})
+
+	if _, err := repo.GetByID(ctx, 1); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden reading another tenant's conversation, got %v", err)
+	}
+}
+
+func TestLeadRepository_List_OtherTenantForbidden(t *testing.T) {
+	repo := &LeadRepository{}
+	ctx := userContext(2, &model.User{ID: 3, TenantID: 1, Role: model.RoleSales})
+
+	if _, err := repo.List(ctx, ""); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden listing another tenant's leads, got %v", err)
+	}
+}
+
+func TestLeadRepository_AssignSales_SalesForbidden(t *testing.T) {
+	repo := &LeadRepository{}
+	ctx := userContext(1, &model.User{ID: 3, TenantID: 1, Role: model.RoleSales})
+
+	if err := repo.AssignSales(ctx, 1, nil); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden for sales assigning a lead, got %v", err)
+	}
+}
+
+func TestAuthorize_WithoutUserOrSystemActorForbidden(t *testing.T) {
+	ctx := model.WithTenantID(context.Background(), 1)
+
+	if err := authorize(ctx, model.PermViewAssigned); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden without user or system actor, got %v", err)
+	}
+	if err := (&CarRepository{}).Delete(ctx, 10); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden deleting a car without user, got %v", err)
+	}
+	if err := authorize(model.WithSystemActor(ctx), model.PermManageInventory); err != nil {
+		t.Errorf("Expected system actor to be allowed, got %v", err)
+	}
+}
+
+func TestAssignedSalesScope(t *testing.T) {
+	salesID := 7
+
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		wantScoped bool
+		wantID     int
+	}{
+		{"system caller", model.WithSystemActor(model.WithTenantID(context.Background(), 1)), false, 0},
+		{"owner", userContext(1, &model.User{TenantID: 1, Role: model.RoleOwner}), false, 0},
+		{"admin", userContext(1, &model.User{TenantID: 1, Role: model.RoleAdmin}), false, 0},
+		{"sales", userContext(1, &model.User{TenantID: 1, Role: model.RoleSales, SalesID: &salesID}), true, 7},
+		{"unlinked sales", userContext(1, &model.User{TenantID: 1, Role: model.RoleSales}), true, 0},
+		{"super admin", userContext(1, &model.User{Role: model.RoleSuperAdmin}), false, 0},
+	}
+
+	for _, tt := range tests {
+		id, scoped, err := assignedSalesScope(tt.ctx)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		}
+		if scoped != tt.wantScoped || id != tt.wantID {
+			t.Errorf("%s: expected (%d, %v), got (%d, %v)", tt.name, tt.wantID, tt.wantScoped, id, scoped)
+		}
+	}
+}
+
+// forbiddenCall is a repository call the policy must refuse before querying
+type forbiddenCall struct {
+	name string
+	call func(ctx context.Context) error
+}
+
+func expectForbidden(t *testing.T, ctx context.Context, calls []forbiddenCall) {
+	t.Helper()
+	for _, c := range calls {
+		if err := c.call(ctx); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected ErrForbidden on %s, got %v", c.name, err)
+		}
+	}
+}
+
+func TestSalesRepository_Writes_SalesForbidden(t *testing.T) {
+	repo := &SalesRepository{}
+	ctx := userContext(1, &model.User{ID: 3, TenantID: 1, Role: model.RoleSales})
+
+	expectForbidden(t, ctx, []forbiddenCall{
+		{"Create", func(ctx context.Context) error {
+			_, err := repo.Create(ctx, &model.CreateSalesRequest{})
+			return err
+		}},
+		{"Delete", func(ctx context.Context) error { return repo.Delete(ctx, 1) }},
+	})
+}
+
+func TestSalesRepository_List_OtherTenantForbidden(t *testing.T) {
+	repo := &SalesRepository{}
+	ctx := userContext(2, &model.User{ID: 1, TenantID: 1, Role: model.RoleOwner})
+
+	if _, err := repo.List(ctx); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden listing another tenant's sales, got %v", err)
+	}
+	if _, err := repo.IsSales(model.WithTenantID(context.Background(), 1), "628111"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden on IsSales without user, got %v", err)
+	}
+}
+
+func TestDealRepository_SalesForbidden(t *testing.T) {
+	repo := &DealRepository{}
+	ctx := userContext(1, &model.User{ID: 3, TenantID: 1, Role: model.RoleSales})
+
+	expectForbidden(t, ctx, []forbiddenCall{
+		{"Create", func(ctx context.Context) error { return repo.Create(ctx, &model.Deal{}) }},
+		{"GetByID", func(ctx context.Context) error {
+			_, err := repo.GetByID(ctx, 1)
+			return err
+		}},
+		{"List", func(ctx context.Context) error {
+			_, err := repo.List(ctx, nil)
+			return err
+		}},
+		{"ListClosedBetween", func(ctx context.Context) error {
+			_, err := repo.ListClosedBetween(ctx, time.Now(), time.Now())
+			return err
+		}},
+		{"Update", func(ctx context.Context) error { return repo.Update(ctx, 1, &model.Deal{}) }},
+		{"Close", func(ctx context.Context) error { return repo.Close(ctx, 1, time.Now(), 0) }},
+		{"Cancel", func(ctx context.Context) error { return repo.Cancel(ctx, 1) }},
+	})
+}
+
+func TestCommissionRepository_Rules_AdminForbidden(t *testing.T) {
+	repo := &CommissionRepository{}
+	ctx := userContext(1, &model.User{ID: 2, TenantID: 1, Role: model.RoleAdmin})
+
+	expectForbidden(t, ctx, []forbiddenCall{
+		{"CreateRule", func(ctx context.Context) error { return repo.CreateRule(ctx, &model.CommissionRule{}) }},
+		{"UpdateRule", func(ctx context.Context) error { return repo.UpdateRule(ctx, 1, &model.CommissionRule{}) }},
+		{"DeleteRule", func(ctx context.Context) error { return repo.DeleteRule(ctx, 1) }},
+	})
+
+	sales := userContext(1, &model.User{ID: 3, TenantID: 1, Role: model.RoleSales})
+	if _, err := repo.ListRules(sales, true); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden for sales listing commission rules, got %v", err)
+	}
+}
+
+func TestCustomerRepository_SalesForbidden(t *testing.T) {
+	repo := &CustomerRepository{}
+	ctx := userContext(1, &model.User{ID: 3, TenantID: 1, Role: model.RoleSales})
+
+	expectForbidden(t, ctx, []forbiddenCall{
+		{"GetOrCreate", func(ctx context.Context) error {
+			_, err := repo.GetOrCreate(ctx, "628111")
+			return err
+		}},
+		{"GetByID", func(ctx context.Context) error {
+			_, err := repo.GetByID(ctx, 1)
+			return err
+		}},
+		{"List", func(ctx context.Context) error {
+			_, err := repo.List(ctx, "", "")
+			return err
+		}},
+		{"Update", func(ctx context.Context) error { return repo.Update(ctx, 1, &model.CustomerUpdateRequest{}) }},
+		{"Timeline", func(ctx context.Context) error {
+			_, err := repo.Timeline(ctx, "628111", 10)
+			return err
+		}},
+		{"CreateAppointment", func(ctx context.Context) error { return repo.CreateAppointment(ctx, &model.Appointment{}) }},
+		{"Export", func(ctx context.Context) error {
+			_, err := repo.Export(ctx, "628111")
+			return err
+		}},
+		{"Erase", func(ctx context.Context) error {
+			_, err := repo.Erase(ctx, "628111", model.ErasureDelete)
+			return err
+		}},
+	})
+}
+
+func TestBlogRepository_SalesForbidden(t *testing.T) {
+	repo := &BlogRepository{}
+	ctx := userContext(1, &model.User{ID: 3, TenantID: 1, Role: model.RoleSales})
+
+	expectForbidden(t, ctx, []forbiddenCall{
+		{"Create", func(ctx context.Context) error { return repo.Create(ctx, &model.BlogPost{}) }},
+		{"GetByID", func(ctx context.Context) error {
+			_, err := repo.GetByID(ctx, 1)
+			return err
+		}},
+		{"List", func(ctx context.Context) error {
+			_, err := repo.List(ctx, "draft")
+			return err
+		}},
+		{"Update", func(ctx context.Context) error {
+			return repo.Update(ctx, 1, map[string]interface{}{"title": "x"})
+		}},
+		{"Delete", func(ctx context.Context) error { return repo.Delete(ctx, 1) }},
+	})
+}
+
+func TestSettingsRepositories_AdminForbidden(t *testing.T) {
+	branding := &BrandingRepository{}
+	showroom := &ShowroomRepository{}
+	financing := &FinancingRepository{}
+	ctx := userContext(1, &model.User{ID: 2, TenantID: 1, Role: model.RoleAdmin})
+
+	expectForbidden(t, ctx, []forbiddenCall{
+		{"Branding.CreateOrUpdate", func(ctx context.Context) error {
+			return branding.CreateOrUpdate(ctx, &model.BrandingSettings{TenantID: 1})
+		}},
+		{"Branding.UpdateLogo", func(ctx context.Context) error { return branding.UpdateLogo(ctx, 1, "logo.png") }},
+		{"Branding.UpdateFavicon", func(ctx context.Context) error { return branding.UpdateFavicon(ctx, 1, "icon.png") }},
+		{"Showroom.CreateOrUpdate", func(ctx context.Context) error {
+			return showroom.CreateOrUpdate(ctx, &model.ShowroomSettings{TenantID: 1})
+		}},
+		{"Financing.ListPartners", func(ctx context.Context) error {
+			_, err := financing.ListPartners(ctx, false)
+			return err
+		}},
+		{"Financing.CreatePartner", func(ctx context.Context) error {
+			_, err := financing.CreatePartner(ctx, &model.LeasingPartnerRequest{})
+			return err
+		}},
+		{"Financing.UpdatePartner", func(ctx context.Context) error {
+			return financing.UpdatePartner(ctx, 1, &model.LeasingPartnerRequest{})
+		}},
+		{"Financing.DeletePartner", func(ctx context.Context) error { return financing.DeletePartner(ctx, 1) }},
+	})
+}
+
+func TestTradeInRepository_SalesForbidden(t *testing.T) {
+	repo := &TradeInRepository{}
+	ctx := userContext(1, &model.User{ID: 3, TenantID: 1, Role: model.RoleSales})
+
+	expectForbidden(t, ctx, []forbiddenCall{
+		{"GetByID", func(ctx context.Context) error {
+			_, err := repo.GetByID(ctx, 1)
+			return err
+		}},
+		{"List", func(ctx context.Context) error {
+			_, err := repo.List(ctx, "")
+			return err
+		}},
+		{"UpdateAppraisal", func(ctx context.Context) error {
+			return repo.UpdateAppraisal(ctx, 1, &model.TradeInAppraisalRequest{})
+		}},
+	})
+}
+
+func TestWebhookRepository_Endpoints_AdminForbidden(t *testing.T) {
+	repo := &WebhookRepository{}
+	ctx := userContext(1, &model.User{ID: 2, TenantID: 1, Role: model.RoleAdmin})
+
+	expectForbidden(t, ctx, []forbiddenCall{
+		{"CreateEndpoint", func(ctx context.Context) error {
+			_, err := repo.CreateEndpoint(ctx, &model.WebhookEndpointRequest{}, "whsec_test")
+			return err
+		}},
+		{"ListEndpoints", func(ctx context.Context) error {
+			_, err := repo.ListEndpoints(ctx)
+			return err
+		}},
+		{"UpdateEndpoint", func(ctx context.Context) error {
+			return repo.UpdateEndpoint(ctx, 1, &model.WebhookEndpointRequest{})
+		}},
+		{"RotateSecret", func(ctx context.Context) error { return repo.RotateSecret(ctx, 1, "whsec_test") }},
+		{"DeleteEndpoint", func(ctx context.Context) error { return repo.DeleteEndpoint(ctx, 1) }},
+		{"SendPing", func(ctx context.Context) error { return repo.SendPing(ctx, 1) }},
+		{"ListDeliveries", func(ctx context.Context) error {
+			_, _, err := repo.ListDeliveries(ctx, &model.WebhookDeliveryFilter{})
+			return err
+		}},
+		{"Redeliver", func(ctx context.Context) error {
+			_, err := repo.Redeliver(ctx, 1)
+			return err
+		}},
+	})
+}
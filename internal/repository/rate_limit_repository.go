@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// RateLimitRepository keeps token buckets in Postgres so every app instance
+// draws from the same budget
+type RateLimitRepository struct {
+	db *sql.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewRateLimitRepository(db *sql.DB) *RateLimitRepository {
+	return &RateLimitRepository{db: db}
+}
+
+// Allow takes a token from the bucket of key in one statement. The row lock
+// serializes concurrent requests for the same key.
+func (r *RateLimitRepository) Allow(ctx context.Context, key string, limit model.RateLimit) (bool, time.Duration, error) {
+	r.sweep(ctx)
+
+	query := `
+		WITH cur AS (
+			SELECT LEAST($2::float8, COALESCE((
+				SELECT tokens + EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - updated_at)::float8 * $3::float8
+				FROM rate_limit_buckets WHERE key = $1 FOR UPDATE
+			), $2::float8)) AS available
+		), taken AS (
+			SELECT available, CASE WHEN available >= 1 THEN available - 1 ELSE available END AS tokens FROM cur
+		)
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at, full_at)
+		SELECT $1, tokens, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP + make_interval(secs => ($2::float8 - tokens) / $3::float8)
+		FROM taken
+		ON CONFLICT (key) DO UPDATE
+		SET tokens = EXCLUDED.tokens, updated_at = EXCLUDED.updated_at, full_at = EXCLUDED.full_at
+		RETURNING (SELECT available FROM taken)
+	`
+
+	rate := limit.RefillRate()
+	var available float64
+	if err := r.db.QueryRowContext(ctx, query, key, float64(limit.Requests), rate).Scan(&available); err != nil {
+		return false, 0, fmt.Errorf("failed to take rate limit token: %w", err)
+	}
+
+	if available >= 1 {
+		return true, 0, nil
+	}
+	return false, time.Duration((1 - available) / rate * float64(time.Second)), nil
+}
+
+// sweep deletes full buckets at most once a minute; they hold no state
+func (r *RateLimitRepository) sweep(ctx context.Context) {
+	r.mu.Lock()
+	if time.Since(r.lastSweep) < time.Minute {
+		r.mu.Unlock()
+		return
+	}
+	r.lastSweep = time.Now()
+	r.mu.Unlock()
+
+	// A failed sweep only leaves stale rows behind
+	r.db.ExecContext(ctx, "DELETE FROM rate_limit_buckets WHERE full_at < CURRENT_TIMESTAMP")
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/pkg/database"
+)
+
+// The row-level security suite runs against a migrated Postgres database.
+// `make test-rls` creates and migrates autolmk_test on the docker-compose
+// Postgres and runs it; against another database:
+//
+//	TEST_DATABASE_URL="host=localhost user=autolmk password=... dbname=autolmk_test sslmode=disable" go test ./internal/repository -run RLS
+//
+// The suite skips when TEST_DATABASE_URL is unset.
+// TEST_DATABASE_ROLE overrides the role switched to (default autolmk_app).
+// Every query below deliberately omits its tenant_id filter.
+
+type rlsFixture struct {
+	db       *sql.DB
+	tenantA  int
+	tenantB  int
+	carB     int
+	convB    int
+	partnerB int
+}
+
+func newRLSFixture(t *testing.T) *rlsFixture {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	role := os.Getenv("TEST_DATABASE_ROLE")
+	if role == "" {
+		role = "autolmk_app"
+	}
+
+	conn, err := database.ConnectWithTenantScope(dsn, TenantScope(role))
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	f := &rlsFixture{db: conn.DB}
+	system := model.WithSystemScope(context.Background())
+	suffix := time.Now().UnixNano()
+
+	for i, id := range []*int{&f.tenantA, &f.tenantB} {
+		err := f.db.QueryRowContext(system,
+			"INSERT INTO tenants (domain, name) VALUES ($1, $2) RETURNING id",
+			fmt.Sprintf("rls-%d-%d.test", i, suffix), fmt.Sprintf("RLS Tenant %d", i),
+		).Scan(id)
+		if err != nil {
+			t.Fatalf("create tenant: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		f.db.ExecContext(system, "DELETE FROM tenants WHERE id IN ($1, $2)", f.tenantA, f.tenantB)
+	})
+
+	for _, tenantID := range []int{f.tenantA, f.tenantB} {
+		ctx := model.WithTenantID(context.Background(), tenantID)
+		var carID, convID, partnerID int
+		mustScan(t, f.db.QueryRowContext(ctx,
+			"INSERT INTO cars (tenant_id, brand, model, year, price) VALUES ($1, 'Toyota', 'Avanza', 2020, 150000000) RETURNING id",
+			tenantID), &carID)
+		mustExec(t, f.db, ctx, "INSERT INTO car_photos (car_id, file_path, display_order) VALUES ($1, 'a.jpg', 0)", carID)
+		mustScan(t, f.db.QueryRowContext(ctx,
+			"INSERT INTO conversations (tenant_id, sender_phone, is_sales) VALUES ($1, '628111', false) RETURNING id",
+			tenantID), &convID)
+		mustExec(t, f.db, ctx, "INSERT INTO messages (conversation_id, sender_phone, message_text, direction) VALUES ($1, '628111', 'halo', 'inbound')", convID)
+		mustExec(t, f.db, ctx, "INSERT INTO sales (tenant_id, phone_number, name, role) VALUES ($1, '628222', 'Budi', 'sales')", tenantID)
+		mustExec(t, f.db, ctx, "INSERT INTO leads (tenant_id, phone_number, source, status) VALUES ($1, '628111', 'whatsapp', 'new')", tenantID)
+		mustScan(t, f.db.QueryRowContext(ctx,
+			"INSERT INTO leasing_partners (tenant_id, name) VALUES ($1, 'Leasing') RETURNING id",
+			tenantID), &partnerID)
+
+		if tenantID == f.tenantB {
+			f.carB, f.convB, f.partnerB = carID, convID, partnerID
+		}
+	}
+
+	return f
+}
+
+func mustScan(t *testing.T, row *sql.Row, dest ...interface{}) {
+	t.Helper()
+	if err := row.Scan(dest...); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+}
+
+func mustExec(t *testing.T, db *sql.DB, ctx context.Context, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := db.ExecContext(ctx, query, args...); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+}
+
+func TestRLSReadsOnlySeeOwnTenant(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := model.WithTenantID(context.Background(), f.tenantA)
+
+	for _, table := range []string{"cars", "car_photos", "conversations", "messages", "sales", "leads", "leasing_partners"} {
+		t.Run(table, func(t *testing.T) {
+			var total, foreign int
+			query := fmt.Sprintf("SELECT COUNT(*) FROM %s", table)
+			if err := f.db.QueryRowContext(ctx, query).Scan(&total); err != nil {
+				t.Fatalf("count: %v", err)
+			}
+			if total == 0 {
+				t.Fatalf("tenant A sees no rows in %s, want its own rows", table)
+			}
+
+			switch table {
+			case "car_photos":
+				query = "SELECT COUNT(*) FROM car_photos WHERE car_id = $1"
+			case "messages":
+				query = "SELECT COUNT(*) FROM messages WHERE conversation_id = $1"
+			default:
+				query = fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE tenant_id = $1", table)
+			}
+			arg := f.tenantB
+			if table == "car_photos" {
+				arg = f.carB
+			} else if table == "messages" {
+				arg = f.convB
+			}
+			if err := f.db.QueryRowContext(ctx, query, arg).Scan(&foreign); err != nil {
+				t.Fatalf("count foreign: %v", err)
+			}
+			if foreign != 0 {
+				t.Errorf("tenant A sees %d rows of tenant B in %s", foreign, table)
+			}
+		})
+	}
+}
+
+func TestRLSWritesCannotTouchOtherTenant(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := model.WithTenantID(context.Background(), f.tenantA)
+
+	affected := func(query string, args ...interface{}) int64 {
+		t.Helper()
+		result, err := f.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		n, _ := result.RowsAffected()
+		return n
+	}
+
+	if n := affected("UPDATE cars SET price = 1 WHERE id = $1", f.carB); n != 0 {
+		t.Errorf("updated %d cars of tenant B", n)
+	}
+	if n := affected("DELETE FROM messages WHERE conversation_id = $1", f.convB); n != 0 {
+		t.Errorf("deleted %d messages of tenant B", n)
+	}
+	if n := affected("DELETE FROM leasing_partners WHERE id = $1", f.partnerB); n != 0 {
+		t.Errorf("deleted %d leasing partners of tenant B", n)
+	}
+	if n := affected("UPDATE conversations SET is_sales = true"); n != 1 {
+		t.Errorf("unfiltered update touched %d conversations, want only tenant A's 1", n)
+	}
+
+	// Inserting rows for another tenant, or children of its rows, violates the policy
+	for _, insert := range []struct {
+		query string
+		arg   int
+	}{
+		{"INSERT INTO cars (tenant_id, brand, model, year, price) VALUES ($1, 'Honda', 'Jazz', 2019, 1)", f.tenantB},
+		{"INSERT INTO car_photos (car_id, file_path, display_order) VALUES ($1, 'x.jpg', 1)", f.carB},
+		{"INSERT INTO messages (conversation_id, sender_phone, message_text, direction) VALUES ($1, '1', 'x', 'inbound')", f.convB},
+	} {
+		if _, err := f.db.ExecContext(ctx, insert.query, insert.arg); err == nil {
+			t.Errorf("insert into other tenant succeeded: %s", insert.query)
+		}
+	}
+
+	// Moving an own row to another tenant is rejected as well
+	if _, err := f.db.ExecContext(ctx, "UPDATE cars SET tenant_id = $1", f.tenantB); err == nil {
+		t.Error("moving cars to tenant B succeeded")
+	}
+
+	// Tenant B's rows are untouched
+	system := model.WithSystemScope(context.Background())
+	var price int64
+	if err := f.db.QueryRowContext(system, "SELECT price FROM cars WHERE id = $1", f.carB).Scan(&price); err != nil {
+		t.Fatalf("read tenant B car: %v", err)
+	}
+	if price == 1 {
+		t.Error("tenant B car price was changed")
+	}
+}
+
+func TestRLSWithoutTenantSeesNothing(t *testing.T) {
+	f := newRLSFixture(t)
+
+	var count int
+	if err := f.db.QueryRowContext(context.Background(), "SELECT COUNT(*) FROM cars WHERE tenant_id IN ($1, $2)", f.tenantA, f.tenantB).Scan(&count); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("query without tenant saw %d cars", count)
+	}
+}
+
+func TestRLSRepositoryIgnoresForeignIDs(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := model.WithTenantID(context.Background(), f.tenantA)
+
+	if _, err := NewCarRepository(f.db).GetByID(ctx, f.carB); err == nil {
+		t.Error("tenant A loaded a car of tenant B")
+	}
+	if err := NewConversationRepository(f.db).AddMessage(ctx, f.convB, "628111", "x", "inbound"); err == nil {
+		t.Error("tenant A added a message to a conversation of tenant B")
+	}
+}
+
+func TestRLSPlatformRowsReadOnlyForTenants(t *testing.T) {
+	f := newRLSFixture(t)
+	ctx := model.WithTenantID(context.Background(), f.tenantA)
+	system := model.WithSystemScope(context.Background())
+	email := fmt.Sprintf("rls-platform-%d@test", time.Now().UnixNano())
+
+	// Tenant-scoped code cannot create a platform account
+	if _, err := f.db.ExecContext(ctx,
+		"INSERT INTO users (tenant_id, email, password_hash, role) VALUES (NULL, $1, 'x', 'super_admin')", email+".tenant",
+	); err == nil {
+		t.Error("tenant A created a platform user")
+	}
+
+	var userID int
+	mustScan(t, f.db.QueryRowContext(system,
+		"INSERT INTO users (tenant_id, email, password_hash, role) VALUES (NULL, $1, 'x', 'super_admin') RETURNING id", email,
+	), &userID)
+	t.Cleanup(func() { f.db.ExecContext(system, "DELETE FROM users WHERE id = $1", userID) })
+
+	// ...nor change or delete one, though it stays readable for login
+	for _, query := range []string{
+		"UPDATE users SET password_hash = 'y' WHERE id = $1",
+		"DELETE FROM users WHERE id = $1",
+	} {
+		result, err := f.db.ExecContext(ctx, query, userID)
+		if err == nil {
+			if n, _ := result.RowsAffected(); n != 0 {
+				t.Errorf("%s touched %d platform users", query, n)
+			}
+		}
+	}
+	var found int
+	if err := f.db.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1", userID).Scan(&found); err != nil {
+		t.Errorf("platform user not readable: %v", err)
+	}
+
+	if _, err := f.db.ExecContext(ctx,
+		"INSERT INTO user_sessions (tenant_id, user_id, token_hash, expires_at) VALUES (NULL, $1, $2, NOW() + INTERVAL '1 hour')",
+		userID, email,
+	); err == nil {
+		t.Error("tenant A created a platform session")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 
+	"github.com/lib/pq"
 	"github.com/riz/auto-lmk/internal/model"
 )
 
@@ -16,6 +17,9 @@ func NewSalesRepository(db *sql.DB) *SalesRepository {
 	return &SalesRepository{db: db}
 }
 
+// salesSnapshotQuery loads a sales row for the audit log
+const salesSnapshotQuery = "SELECT to_jsonb(s) FROM sales s WHERE s.id = $1 AND s.tenant_id = $2"
+
 // Create registers a new sales person (tenant-scoped)
 func (r *SalesRepository) Create(ctx context.Context, req *model.CreateSalesRequest) (*model.Sales, error) {
 	tenantID, err := model.GetTenantID(ctx)
@@ -23,14 +27,28 @@ func (r *SalesRepository) Create(ctx context.Context, req *model.CreateSalesRequ
 		return nil, fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	if err := authorize(ctx, model.PermManageUsers); err != nil {
+		return nil, err
+	}
+
 	query := `
 		INSERT INTO sales (tenant_id, phone_number, name, role)
 		VALUES ($1, $2, $3, $4)
 		RETURNING id, tenant_id, phone_number, name, role, status, registered_at
 	`
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := checkPlanLimit(ctx, tx, tenantID, model.LimitSalesSeats, 1); err != nil {
+		return nil, err
+	}
+
 	sales := &model.Sales{}
-	err = r.db.QueryRowContext(ctx, query, tenantID, req.PhoneNumber, req.Name, req.Role).Scan(
+	err = tx.QueryRowContext(ctx, query, tenantID, req.PhoneNumber, req.Name, req.Role).Scan(
 		&sales.ID,
 		&sales.TenantID,
 		&sales.PhoneNumber,
@@ -44,6 +62,18 @@ func (r *SalesRepository) Create(ctx context.Context, req *model.CreateSalesRequ
 		return nil, fmt.Errorf("failed to create sales: %w", err)
 	}
 
+	after, err := snapshotRow(ctx, tx, salesSnapshotQuery, sales.ID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, tx, "sales", entityRef(sales.ID), "create", nil, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return sales, nil
 }
 
@@ -54,6 +84,10 @@ func (r *SalesRepository) GetByPhoneNumber(ctx context.Context, phoneNumber stri
 		return nil, fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	if err := authorize(ctx, model.PermViewAssigned); err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT id, tenant_id, phone_number, name, role, status, registered_at
 		FROM sales
@@ -81,8 +115,57 @@ func (r *SalesRepository) GetByPhoneNumber(ctx context.Context, phoneNumber stri
 	return sales, nil
 }
 
-// IsSales checks if a phone number is registered as sales for a tenant
-func (r *SalesRepository) IsSales(tenantID int, phoneNumber string) (bool, error) {
+// GetActiveByPhone retrieves an active sales person by phone number in any stored
+// format (62xxx, +62xxx or 08xxx) (tenant-scoped)
+func (r *SalesRepository) GetActiveByPhone(ctx context.Context, phoneNumber string) (*model.Sales, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermViewAssigned); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, tenant_id, phone_number, name, role, status, registered_at
+		FROM sales
+		WHERE tenant_id = $1 AND phone_number = ANY($2) AND status = 'active'
+		LIMIT 1
+	`
+
+	sales := &model.Sales{}
+	err = r.db.QueryRowContext(ctx, query, tenantID, pq.Array(model.PhoneVariants(phoneNumber))).Scan(
+		&sales.ID,
+		&sales.TenantID,
+		&sales.PhoneNumber,
+		&sales.Name,
+		&sales.Role,
+		&sales.Status,
+		&sales.RegisteredAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("sales not found")
+		}
+		return nil, fmt.Errorf("failed to get sales: %w", err)
+	}
+
+	return sales, nil
+}
+
+// IsSales checks if a phone number is registered as active sales for the tenant
+func (r *SalesRepository) IsSales(ctx context.Context, phoneNumber string) (bool, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return false, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermViewAssigned); err != nil {
+		return false, err
+	}
+
 	query := `
 		SELECT EXISTS(
 			SELECT 1 FROM sales
@@ -91,7 +174,7 @@ func (r *SalesRepository) IsSales(tenantID int, phoneNumber string) (bool, error
 	`
 
 	var exists bool
-	err := r.db.QueryRow(query, tenantID, phoneNumber).Scan(&exists)
+	err = r.db.QueryRowContext(ctx, query, tenantID, phoneNumber).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check sales status: %w", err)
 	}
@@ -106,6 +189,10 @@ func (r *SalesRepository) List(ctx context.Context) ([]*model.Sales, error) {
 		return nil, fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	if err := authorize(ctx, model.PermViewAssigned); err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT id, tenant_id, phone_number, name, role, status, registered_at
 		FROM sales
@@ -147,8 +234,16 @@ func (r *SalesRepository) Delete(ctx context.Context, id int) error {
 		return fmt.Errorf("tenant ID required: %w", err)
 	}
 
+	if err := authorize(ctx, model.PermManageUsers); err != nil {
+		return err
+	}
+
+	target := auditTarget{
+		EntityType: "sales", EntityID: entityRef(id), Action: "delete",
+		Snapshot: salesSnapshotQuery, SnapshotArgs: []interface{}{id, tenantID},
+	}
 	query := "DELETE FROM sales WHERE id = $1 AND tenant_id = $2"
-	result, err := r.db.ExecContext(ctx, query, id, tenantID)
+	result, err := auditedExec(ctx, r.db, target, query, id, tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to delete sales: %w", err)
 	}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// optionalTenantID returns the context tenant, or nil on platform (tenant-less) requests
+func optionalTenantID(ctx context.Context) *int {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil
+	}
+	return &tenantID
+}
+
+// Create stores a new session for a user by token hash, in the user's tenant
+// (none for platform super-admins)
+func (r *SessionRepository) Create(ctx context.Context, user *model.User, tokenHash, userAgent, ipAddress string, expiresAt time.Time) (*model.Session, error) {
+	var tenantID *int
+	if !user.IsSuperAdmin() {
+		tenantID = &user.TenantID
+	} else {
+		// Row-level security only lets trusted work write platform rows (migration 000041)
+		ctx = model.WithSystemScope(ctx)
+	}
+
+	query := `
+		INSERT INTO user_sessions (tenant_id, user_id, token_hash, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, ''), $6)
+		RETURNING id, COALESCE(tenant_id, 0), user_id, user_agent, ip_address, expires_at, created_at
+	`
+
+	s := &model.Session{}
+	err := r.db.QueryRowContext(ctx, query, tenantID, user.ID, tokenHash, userAgent, ipAddress, expiresAt).Scan(
+		&s.ID, &s.TenantID, &s.UserID, &s.UserAgent, &s.IPAddress, &s.ExpiresAt, &s.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return s, nil
+}
+
+// GetActiveUser returns the active user owning a live session. Tenant sessions only
+// count on their own tenant; platform super-admin sessions count everywhere.
+func (r *SessionRepository) GetActiveUser(ctx context.Context, tokenHash string) (*model.User, error) {
+	query := `
+		SELECT u.id, COALESCE(u.tenant_id, 0), u.email, u.password_hash, u.name, u.role, u.sales_id,
+			u.status, u.last_login_at, u.created_at, u.updated_at
+		FROM user_sessions s
+		INNER JOIN users u ON u.id = s.user_id
+		WHERE s.token_hash = $1
+			AND (s.tenant_id = $2 OR (s.tenant_id IS NULL AND u.role = 'super_admin'))
+			AND s.revoked_at IS NULL AND s.expires_at > CURRENT_TIMESTAMP
+			AND u.status = 'active'
+	`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, tokenHash, optionalTenantID(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found")
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return user, nil
+}
+
+// Revoke ends a session of the context tenant, or a platform session. The query
+// runs in system scope since platform sessions are not writable otherwise; the
+// WHERE clause keeps it to the tenant.
+func (r *SessionRepository) Revoke(ctx context.Context, tokenHash string) error {
+	query := `
+		UPDATE user_sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND (tenant_id = $2 OR tenant_id IS NULL) AND revoked_at IS NULL
+	`
+	if _, err := r.db.ExecContext(model.WithSystemScope(ctx), query, tokenHash, optionalTenantID(ctx)); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAllForUser ends every session of a user, e.g. after a password change (tenant-scoped)
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		UPDATE user_sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+	`
+	if _, err := r.db.ExecContext(ctx, query, userID, tenantID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return nil
+}
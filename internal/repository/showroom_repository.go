@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 
 	"github.com/riz/auto-lmk/internal/model"
 )
@@ -19,6 +21,7 @@ func NewShowroomRepository(db *sql.DB) *ShowroomRepository {
 // Returns default empty settings if not found
 func (r *ShowroomRepository) GetByTenantID(ctx context.Context, tenantID int) (*model.ShowroomSettings, error) {
 	var showroom model.ShowroomSettings
+	var hours []byte
 
 	query := `
 		SELECT
@@ -27,6 +30,9 @@ func (r *ShowroomRepository) GetByTenantID(ctx context.Context, tenantID int) (*
 			phone,
 			email,
 			business_hours,
+			opening_hours,
+			after_hours_mode,
+			after_hours_message,
 			latitude,
 			longitude,
 			map_embed,
@@ -41,7 +47,10 @@ func (r *ShowroomRepository) GetByTenantID(ctx context.Context, tenantID int) (*
 		&showroom.Address,
 		&showroom.Phone,
 		&showroom.Email,
-		&showroom.BusinessHours,
+		&showroom.LegacyBusinessHours,
+		&hours,
+		&showroom.AfterHoursMode,
+		&showroom.AfterHoursMessage,
 		&showroom.Latitude,
 		&showroom.Longitude,
 		&showroom.MapEmbed,
@@ -52,53 +61,87 @@ func (r *ShowroomRepository) GetByTenantID(ctx context.Context, tenantID int) (*
 	if err == sql.ErrNoRows {
 		// Return default empty settings
 		return &model.ShowroomSettings{
-			TenantID: tenantID,
+			TenantID:       tenantID,
+			AfterHoursMode: model.AfterHoursBotOnly,
 		}, nil
 	}
 	if err != nil {
 		return nil, err
 	}
+	if hours != nil {
+		if err := json.Unmarshal(hours, &showroom.Hours); err != nil {
+			return nil, fmt.Errorf("failed to decode opening hours: %w", err)
+		}
+	}
 
 	return &showroom, nil
 }
 
-// CreateOrUpdate creates or updates showroom settings using UPSERT pattern
+// CreateOrUpdate creates or updates showroom settings using UPSERT pattern.
+// Setting structured opening hours drops the legacy free-text hours.
 func (r *ShowroomRepository) CreateOrUpdate(ctx context.Context, showroom *model.ShowroomSettings) error {
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
+	var hours []byte
+	if showroom.Hours.Configured() {
+		var err error
+		if hours, err = json.Marshal(showroom.Hours); err != nil {
+			return fmt.Errorf("failed to encode opening hours: %w", err)
+		}
+	}
+
 	query := `
 		INSERT INTO showroom_settings (
 			tenant_id,
 			address,
 			phone,
 			email,
-			business_hours,
+			opening_hours,
+			after_hours_mode,
+			after_hours_message,
 			latitude,
 			longitude,
 			map_embed,
 			created_at,
 			updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW()
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW()
 		)
 		ON CONFLICT (tenant_id)
 		DO UPDATE SET
 			address = EXCLUDED.address,
 			phone = EXCLUDED.phone,
 			email = EXCLUDED.email,
-			business_hours = EXCLUDED.business_hours,
+			business_hours = CASE WHEN EXCLUDED.opening_hours IS NULL THEN showroom_settings.business_hours END,
+			opening_hours = EXCLUDED.opening_hours,
+			after_hours_mode = EXCLUDED.after_hours_mode,
+			after_hours_message = EXCLUDED.after_hours_message,
 			latitude = EXCLUDED.latitude,
 			longitude = EXCLUDED.longitude,
 			map_embed = EXCLUDED.map_embed,
 			updated_at = NOW()
 	`
 
-	_, err := r.db.ExecContext(
+	target := auditTarget{
+		EntityType: "showroom", Action: "update",
+		Snapshot:     "SELECT to_jsonb(s) FROM showroom_settings s WHERE s.tenant_id = $1",
+		SnapshotArgs: []interface{}{showroom.TenantID},
+	}
+
+	_, err := auditedExec(
 		ctx,
+		r.db,
+		target,
 		query,
 		showroom.TenantID,
 		showroom.Address,
 		showroom.Phone,
 		showroom.Email,
-		showroom.BusinessHours,
+		hours,
+		showroom.AfterHoursMode,
+		showroom.AfterHoursMessage,
 		showroom.Latitude,
 		showroom.Longitude,
 		showroom.MapEmbed,
@@ -1,12 +1,25 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/riz/auto-lmk/internal/model"
 )
 
+var (
+	// ErrDomainTaken is returned when another tenant, deleted or not, holds the
+	// domain, or another tenant verified it as a custom domain
+	ErrDomainTaken = errors.New("domain already in use")
+	// ErrTenantState is returned for a lifecycle change the tenant's status does
+	// not allow, e.g. suspending a deleted tenant
+	ErrTenantState = errors.New("tenant status does not allow this change")
+)
+
 type TenantRepository struct {
 	db *sql.DB
 }
@@ -15,51 +28,96 @@ func NewTenantRepository(db *sql.DB) *TenantRepository {
 	return &TenantRepository{db: db}
 }
 
-func (r *TenantRepository) Create(req *model.CreateTenantRequest) (*model.Tenant, error) {
-	query := `
-		INSERT INTO tenants (domain, name, whatsapp_number)
-		VALUES ($1, $2, $3)
-		RETURNING id, domain, name, whatsapp_number, pairing_status, status, created_at, updated_at
-	`
+const tenantColumns = `id, domain, name, whatsapp_number, pairing_status, status, plan,
+	suspended_at, suspended_reason, deleted_at, purge_after, created_at, updated_at`
 
+func scanTenant(row interface{ Scan(...interface{}) error }) (*model.Tenant, error) {
 	tenant := &model.Tenant{}
-	err := r.db.QueryRow(query, req.Domain, req.Name, req.WhatsAppNumber).Scan(
+	err := row.Scan(
 		&tenant.ID,
 		&tenant.Domain,
 		&tenant.Name,
 		&tenant.WhatsAppNumber,
 		&tenant.PairingStatus,
 		&tenant.Status,
+		&tenant.Plan,
+		&tenant.SuspendedAt,
+		&tenant.SuspendedReason,
+		&tenant.DeletedAt,
+		&tenant.PurgeAfter,
 		&tenant.CreatedAt,
 		&tenant.UpdatedAt,
 	)
+	return tenant, err
+}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to create tenant: %w", err)
+// tenantTarget describes a tenant row for the audit log. The entry is written
+// to that tenant's own log.
+func tenantTarget(tenantID int, action string) auditTarget {
+	return auditTarget{
+		EntityType: "tenant", EntityID: entityRef(tenantID), Action: action,
+		Snapshot:     "SELECT to_jsonb(t) FROM tenants t WHERE t.id = $1",
+		SnapshotArgs: []interface{}{tenantID},
 	}
-
-	return tenant, nil
 }
 
-func (r *TenantRepository) GetByID(id int) (*model.Tenant, error) {
+// Create adds a tenant. It is idempotent: creating a tenant that already exists
+// with the same domain and name returns it with created false. Another tenant
+// on the domain gives ErrDomainTaken.
+func (r *TenantRepository) Create(ctx context.Context, req *model.CreateTenantRequest) (*model.Tenant, bool, error) {
 	query := `
-		SELECT id, domain, name, whatsapp_number, pairing_status, status, created_at, updated_at
-		FROM tenants
-		WHERE id = $1
-	`
+		INSERT INTO tenants (domain, name, whatsapp_number)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (domain) DO NOTHING
+		RETURNING ` + tenantColumns
 
-	tenant := &model.Tenant{}
-	err := r.db.QueryRow(query, id).Scan(
-		&tenant.ID,
-		&tenant.Domain,
-		&tenant.Name,
-		&tenant.WhatsAppNumber,
-		&tenant.PairingStatus,
-		&tenant.Status,
-		&tenant.CreatedAt,
-		&tenant.UpdatedAt,
-	)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	taken, err := customDomainTaken(ctx, tx, req.Domain, 0)
+	if err != nil {
+		return nil, false, err
+	}
+	if taken {
+		return nil, false, ErrDomainTaken
+	}
+
+	tenant, err := scanTenant(tx.QueryRowContext(ctx, query, req.Domain, req.Name, req.WhatsAppNumber))
+	if err == sql.ErrNoRows {
+		existing, err := r.GetByDomain(ctx, req.Domain)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing.Name != req.Name || existing.Status == model.TenantDeleted {
+			return nil, false, ErrDomainTaken
+		}
+		return existing, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	after, err := snapshotRow(ctx, tx, "SELECT to_jsonb(t) FROM tenants t WHERE t.id = $1", tenant.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := recordAudit(model.WithTenantID(ctx, tenant.ID), tx, "tenant", entityRef(tenant.ID), "create", nil, after); err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return tenant, true, nil
+}
+
+func (r *TenantRepository) GetByID(ctx context.Context, id int) (*model.Tenant, error) {
+	query := "SELECT " + tenantColumns + " FROM tenants WHERE id = $1"
 
+	tenant, err := scanTenant(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("tenant not found")
@@ -70,25 +128,10 @@ func (r *TenantRepository) GetByID(id int) (*model.Tenant, error) {
 	return tenant, nil
 }
 
-func (r *TenantRepository) GetByDomain(domain string) (*model.Tenant, error) {
-	query := `
-		SELECT id, domain, name, whatsapp_number, pairing_status, status, created_at, updated_at
-		FROM tenants
-		WHERE domain = $1
-	`
-
-	tenant := &model.Tenant{}
-	err := r.db.QueryRow(query, domain).Scan(
-		&tenant.ID,
-		&tenant.Domain,
-		&tenant.Name,
-		&tenant.WhatsAppNumber,
-		&tenant.PairingStatus,
-		&tenant.Status,
-		&tenant.CreatedAt,
-		&tenant.UpdatedAt,
-	)
+func (r *TenantRepository) GetByDomain(ctx context.Context, domain string) (*model.Tenant, error) {
+	query := "SELECT " + tenantColumns + " FROM tenants WHERE domain = $1"
 
+	tenant, err := scanTenant(r.db.QueryRowContext(ctx, query, domain))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("tenant not found")
@@ -99,14 +142,11 @@ func (r *TenantRepository) GetByDomain(domain string) (*model.Tenant, error) {
 	return tenant, nil
 }
 
-func (r *TenantRepository) List() ([]*model.Tenant, error) {
-	query := `
-		SELECT id, domain, name, whatsapp_number, pairing_status, status, created_at, updated_at
-		FROM tenants
-		ORDER BY created_at DESC
-	`
+// List returns all tenants, including suspended and deleted ones
+func (r *TenantRepository) List(ctx context.Context) ([]*model.Tenant, error) {
+	query := "SELECT " + tenantColumns + " FROM tenants ORDER BY created_at DESC"
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tenants: %w", err)
 	}
@@ -114,17 +154,7 @@ func (r *TenantRepository) List() ([]*model.Tenant, error) {
 
 	var tenants []*model.Tenant
 	for rows.Next() {
-		tenant := &model.Tenant{}
-		err := rows.Scan(
-			&tenant.ID,
-			&tenant.Domain,
-			&tenant.Name,
-			&tenant.WhatsAppNumber,
-			&tenant.PairingStatus,
-			&tenant.Status,
-			&tenant.CreatedAt,
-			&tenant.UpdatedAt,
-		)
+		tenant, err := scanTenant(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan tenant: %w", err)
 		}
@@ -134,16 +164,138 @@ func (r *TenantRepository) List() ([]*model.Tenant, error) {
 	return tenants, nil
 }
 
+// Update changes the name, domain and/or plan of a tenant; nil leaves a field as it is
+func (r *TenantRepository) Update(ctx context.Context, id int, name, domain, plan *string) error {
+	if domain != nil {
+		taken, err := customDomainTaken(ctx, r.db, *domain, id)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrDomainTaken
+		}
+	}
+
+	query := `
+		UPDATE tenants
+		SET name = COALESCE($2, name), domain = COALESCE($3, domain), plan = COALESCE($4, plan), updated_at = NOW()
+		WHERE id = $1
+	`
+
+	result, err := auditedExec(model.WithTenantID(ctx, id), r.db, tenantTarget(id, "update"), query, id, name, domain, plan)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrDomainTaken
+		}
+		if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+			return ErrUnknownPlan
+		}
+		return fmt.Errorf("failed to update tenant: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("tenant not found")
+	}
+	return nil
+}
+
+// Suspend stops serving an active tenant
+func (r *TenantRepository) Suspend(ctx context.Context, id int, reason string) error {
+	query := `
+		UPDATE tenants
+		SET status = 'suspended', suspended_at = NOW(), suspended_reason = NULLIF($2, ''), updated_at = NOW()
+		WHERE id = $1 AND status = 'active'
+	`
+
+	result, err := auditedExec(model.WithTenantID(ctx, id), r.db, tenantTarget(id, "suspend"), query, id, reason)
+	if err != nil {
+		return fmt.Errorf("failed to suspend tenant: %w", err)
+	}
+	return requireTenantRow(result)
+}
+
+// Reactivate serves a suspended tenant again, or restores a deleted one that
+// has not been purged yet
+func (r *TenantRepository) Reactivate(ctx context.Context, id int) error {
+	query := `
+		UPDATE tenants
+		SET status = 'active', suspended_at = NULL, suspended_reason = NULL,
+			deleted_at = NULL, purge_after = NULL, updated_at = NOW()
+		WHERE id = $1 AND status IN ('suspended', 'deleted')
+	`
+
+	result, err := auditedExec(model.WithTenantID(ctx, id), r.db, tenantTarget(id, "reactivate"), query, id)
+	if err != nil {
+		return fmt.Errorf("failed to reactivate tenant: %w", err)
+	}
+	return requireTenantRow(result)
+}
+
+// SoftDelete stops serving a tenant and schedules its data to be purged after
+// the grace period. Until then Reactivate brings it back.
+func (r *TenantRepository) SoftDelete(ctx context.Context, id int, grace time.Duration) error {
+	query := `
+		UPDATE tenants
+		SET status = 'deleted', deleted_at = NOW(), purge_after = NOW() + make_interval(secs => $2), updated_at = NOW()
+		WHERE id = $1 AND status <> 'deleted'
+	`
+
+	result, err := auditedExec(model.WithTenantID(ctx, id), r.db, tenantTarget(id, "delete"), query, id, int64(grace.Seconds()))
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+	return requireTenantRow(result)
+}
+
+// PurgeDeleted deletes the tenants whose grace period is over, with all their
+// data, and returns their IDs
+func (r *TenantRepository) PurgeDeleted(ctx context.Context) ([]int, error) {
+	query := `
+		DELETE FROM tenants
+		WHERE status = 'deleted' AND purge_after < NOW()
+		RETURNING id
+	`
+
+	rows, err := r.db.QueryContext(model.WithSystemScope(ctx), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// requireTenantRow reports a lifecycle change that matched no tenant in the
+// expected status as ErrTenantState
+func requireTenantRow(result sql.Result) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTenantState
+	}
+	return nil
+}
+
 // UpdatePairingStatus updates the pairing_status field for a tenant
 // Valid status values: "unpaired", "pairing_pending", "paired", "disconnected", "failed"
-func (r *TenantRepository) UpdatePairingStatus(tenantID int, status string) error {
+func (r *TenantRepository) UpdatePairingStatus(ctx context.Context, tenantID int, status string) error {
 	query := `
 		UPDATE tenants
 		SET pairing_status = $1, updated_at = NOW()
 		WHERE id = $2
 	`
 
-	result, err := r.db.Exec(query, status, tenantID)
+	result, err := r.db.ExecContext(ctx, query, status, tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to update pairing status: %w", err)
 	}
@@ -161,14 +313,14 @@ func (r *TenantRepository) UpdatePairingStatus(tenantID int, status string) erro
 }
 
 // UpdateWhatsAppNumber updates the whatsapp_number field for a tenant
-func (r *TenantRepository) UpdateWhatsAppNumber(tenantID int, phoneNumber string) error {
+func (r *TenantRepository) UpdateWhatsAppNumber(ctx context.Context, tenantID int, phoneNumber string) error {
 	query := `
 		UPDATE tenants
 		SET whatsapp_number = $1, updated_at = NOW()
 		WHERE id = $2
 	`
 
-	result, err := r.db.Exec(query, phoneNumber, tenantID)
+	result, err := r.db.ExecContext(ctx, query, phoneNumber, tenantID)
 	if err != nil {
 		return fmt.Errorf("failed to update whatsapp number: %w", err)
 	}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type TradeInRepository struct {
+	db   *sql.DB
+	keys *DataKeyRepository
+}
+
+func NewTradeInRepository(db *sql.DB) *TradeInRepository {
+	return &TradeInRepository{db: db}
+}
+
+// SetDataKeys enables encryption at rest of customer phone numbers and names.
+// Rows written before stay readable; cmd/encrypt-pii encrypts them.
+func (r *TradeInRepository) SetDataKeys(keys *DataKeyRepository) {
+	r.keys = keys
+}
+
+const tradeInColumns = `
+	id, tenant_id, lead_id, phone_number, customer_name, brand, model, year, mileage, transmission,
+	estimate_low, estimate_high, comparable_count, appraised_price, appraised_by, appraisal_notes,
+	status, created_at, updated_at
+`
+
+func scanTradeIn(row interface{ Scan(...interface{}) error }) (*model.TradeIn, error) {
+	t := &model.TradeIn{}
+	err := row.Scan(
+		&t.ID, &t.TenantID, &t.LeadID, &t.PhoneNumber, &t.CustomerName, &t.Brand, &t.Model,
+		&t.Year, &t.Mileage, &t.Transmission, &t.EstimateLow, &t.EstimateHigh, &t.ComparableCount,
+		&t.AppraisedPrice, &t.AppraisedBy, &t.AppraisalNotes, &t.Status, &t.CreatedAt, &t.UpdatedAt,
+	)
+	return t, err
+}
+
+// openTradeIn decrypts the customer phone number and name of a trade-in read from the database
+func (r *TradeInRepository) openTradeIn(ctx context.Context, t *model.TradeIn) error {
+	phone, err := openPII(ctx, r.keys, t.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	name, err := openOptionalPII(ctx, r.keys, t.CustomerName)
+	if err != nil {
+		return err
+	}
+	t.PhoneNumber, t.CustomerName = phone, name
+	return nil
+}
+
+// Create stores a trade-in estimate (tenant-scoped)
+func (r *TradeInRepository) Create(ctx context.Context, t *model.TradeIn) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	phone, bidx, err := sealPhone(ctx, r.keys, t.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	name, err := sealOptionalPII(ctx, r.keys, t.CustomerName)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO trade_ins (
+			tenant_id, lead_id, phone_number, phone_number_bidx, customer_name, brand, model, year, mileage,
+			transmission, estimate_low, estimate_high, comparable_count, status
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, 'estimated')
+		RETURNING id, status, created_at, updated_at
+	`
+
+	err = r.db.QueryRowContext(ctx, query,
+		tenantID, t.LeadID, phone, bidx, name, t.Brand, t.Model, t.Year, t.Mileage,
+		t.Transmission, t.EstimateLow, t.EstimateHigh, t.ComparableCount,
+	).Scan(&t.ID, &t.Status, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create trade-in: %w", err)
+	}
+
+	t.TenantID = tenantID
+	return nil
+}
+
+// GetByID retrieves a trade-in by ID (tenant-scoped)
+func (r *TradeInRepository) GetByID(ctx context.Context, id int) (*model.TradeIn, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + tradeInColumns + " FROM trade_ins WHERE id = $1 AND tenant_id = $2"
+
+	t, err := scanTradeIn(r.db.QueryRowContext(ctx, query, id, tenantID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("trade-in not found")
+		}
+		return nil, fmt.Errorf("failed to get trade-in: %w", err)
+	}
+	if err := r.openTradeIn(ctx, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// List retrieves trade-ins for tenant, optionally filtered by status
+func (r *TradeInRepository) List(ctx context.Context, statusFilter string) ([]*model.TradeIn, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + tradeInColumns + " FROM trade_ins WHERE tenant_id = $1"
+	args := []interface{}{tenantID}
+
+	if statusFilter != "" {
+		query += " AND status = $2"
+		args = append(args, statusFilter)
+	}
+
+	query += " ORDER BY created_at DESC"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trade-ins: %w", err)
+	}
+	defer rows.Close()
+
+	var tradeIns []*model.TradeIn
+	for rows.Next() {
+		t, err := scanTradeIn(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trade-in: %w", err)
+		}
+		if err := r.openTradeIn(ctx, t); err != nil {
+			return nil, err
+		}
+		tradeIns = append(tradeIns, t)
+	}
+
+	return tradeIns, nil
+}
+
+// UpdateAppraisal records the final appraisal made by sales (tenant-scoped)
+func (r *TradeInRepository) UpdateAppraisal(ctx context.Context, id int, req *model.TradeInAppraisalRequest) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageCustomers); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE trade_ins
+		SET appraised_price = $1, appraised_by = $2, appraisal_notes = $3, status = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5 AND tenant_id = $6
+	`
+
+	result, err := r.db.ExecContext(ctx, query, req.AppraisedPrice, req.AppraisedBy, req.Notes, req.Status, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update trade-in appraisal: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("trade-in not found or no permission")
+	}
+
+	return nil
+}
+
+// FindComparableCars returns the tenant's cars (in stock and sold) of the same brand,
+// optionally narrowed to the same model. Matching is case-insensitive.
+func (r *TradeInRepository) FindComparableCars(ctx context.Context, brand, carModel string) ([]*model.Car, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := `
+		SELECT id, tenant_id, brand, model, year, price, mileage, transmission,
+			fuel_type, engine_cc, seats, color, description, status, is_featured,
+			created_at, updated_at
+		FROM cars
+		WHERE tenant_id = $1 AND LOWER(brand) = LOWER($2) AND status IN ('available', 'sold')
+	`
+	args := []interface{}{tenantID, brand}
+
+	if carModel != "" {
+		query += " AND LOWER(model) = LOWER($3)"
+		args = append(args, carModel)
+	}
+
+	query += " ORDER BY updated_at DESC LIMIT 50"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find comparable cars: %w", err)
+	}
+	defer rows.Close()
+
+	var cars []*model.Car
+	for rows.Next() {
+		car := &model.Car{}
+		err := rows.Scan(
+			&car.ID, &car.TenantID, &car.Brand, &car.Model, &car.Year, &car.Price,
+			&car.Mileage, &car.Transmission, &car.FuelType, &car.EngineCC, &car.Seats,
+			&car.Color, &car.Description, &car.Status, &car.IsFeatured,
+			&car.CreatedAt, &car.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan car: %w", err)
+		}
+		cars = append(cars, car)
+	}
+
+	return cars, nil
+}
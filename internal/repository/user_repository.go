@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Platform super-admins have no tenant and scan as TenantID 0
+const userColumns = `
+	id, COALESCE(tenant_id, 0), email, password_hash, name, role, sales_id, status,
+	last_login_at, created_at, updated_at
+`
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*model.User, error) {
+	u := &model.User{}
+	err := row.Scan(
+		&u.ID, &u.TenantID, &u.Email, &u.PasswordHash, &u.Name, &u.Role, &u.SalesID, &u.Status,
+		&u.LastLoginAt, &u.CreatedAt, &u.UpdatedAt,
+	)
+	return u, err
+}
+
+// Create creates a user with an already hashed password (tenant-scoped).
+// Super-admins are created without a tenant and only by system callers.
+func (r *UserRepository) Create(ctx context.Context, req *model.CreateUserRequest, passwordHash string) (*model.User, error) {
+	var tenantID *int
+	if req.Role == model.RoleSuperAdmin {
+		if _, err := model.GetUser(ctx); err == nil {
+			return nil, ErrForbidden
+		}
+		// Row-level security only lets trusted work write platform rows (migration 000041)
+		ctx = model.WithSystemScope(ctx)
+	} else {
+		id, err := model.GetTenantID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("tenant ID required: %w", err)
+		}
+		if err := authorize(ctx, model.PermManageUsers); err != nil {
+			return nil, err
+		}
+		tenantID = &id
+	}
+
+	query := `
+		INSERT INTO users (tenant_id, email, password_hash, name, role, sales_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, tenantID, req.Email, passwordHash, req.Name, req.Role, req.SalesID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByID retrieves a user by ID (tenant-scoped)
+func (r *UserRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT " + userColumns + " FROM users WHERE id = $1 AND tenant_id = $2"
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id, tenantID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByEmail retrieves a user by email, case-insensitive (tenant-scoped)
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT " + userColumns + " FROM users WHERE tenant_id = $1 AND LOWER(email) = $2"
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, tenantID, strings.ToLower(email)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetBySalesID retrieves the user account linked to a sales record (tenant-scoped)
+func (r *UserRepository) GetBySalesID(ctx context.Context, salesID int) (*model.User, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT " + userColumns + " FROM users WHERE tenant_id = $1 AND sales_id = $2 ORDER BY id LIMIT 1"
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, tenantID, salesID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetPlatformByEmail retrieves a platform super-admin by email, case-insensitive
+func (r *UserRepository) GetPlatformByEmail(ctx context.Context, email string) (*model.User, error) {
+	query := "SELECT " + userColumns + " FROM users WHERE tenant_id IS NULL AND LOWER(email) = $1"
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, strings.ToLower(email)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// List retrieves all users for tenant
+func (r *UserRepository) List(ctx context.Context) ([]*model.User, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	query := "SELECT " + userColumns + " FROM users WHERE tenant_id = $1 ORDER BY created_at"
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*model.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// UpdatePassword replaces a user's password hash; users may change their own (tenant-scoped)
+func (r *UserRepository) UpdatePassword(ctx context.Context, id int, passwordHash string) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if current, err := model.GetUser(ctx); err != nil || current.ID != id {
+		if err := authorize(ctx, model.PermManageUsers); err != nil {
+			return err
+		}
+	}
+
+	query := "UPDATE users SET password_hash = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND tenant_id = $3"
+	result, err := r.db.ExecContext(ctx, query, passwordHash, id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("user not found or no permission")
+	}
+
+	return nil
+}
+
+// UpdateLastLogin records a successful login (tenant-scoped, or platform without a tenant)
+func (r *UserRepository) UpdateLastLogin(ctx context.Context, id int) error {
+	query := "UPDATE users SET last_login_at = CURRENT_TIMESTAMP WHERE id = $1 AND tenant_id IS NOT DISTINCT FROM $2"
+	tenantID := optionalTenantID(ctx)
+	if tenantID == nil {
+		// Row-level security only lets trusted work write platform rows (migration 000041)
+		ctx = model.WithSystemScope(ctx)
+	}
+	if _, err := r.db.ExecContext(ctx, query, id, tenantID); err != nil {
+		return fmt.Errorf("failed to update last login: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a user; their sessions are removed by cascade (tenant-scoped)
+func (r *UserRepository) Delete(ctx context.Context, id int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageUsers); err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM users WHERE id = $1 AND tenant_id = $2", id, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("user not found or no permission")
+	}
+
+	return nil
+}
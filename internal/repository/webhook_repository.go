@@ -0,0 +1,449 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+const webhookEndpointColumns = `id, tenant_id, url, description, secret, events, is_active, created_at, updated_at`
+
+// webhookSnapshotQuery loads an endpoint for the audit log. The secret is left
+// out; its last characters show that it was rotated.
+const webhookSnapshotQuery = `
+	SELECT (to_jsonb(w) - 'secret') || jsonb_build_object('secret_hint', right(w.secret, 4))
+	FROM webhook_endpoints w WHERE w.id = $1 AND w.tenant_id = $2`
+
+const webhookDeliveryColumns = `
+	d.id, d.tenant_id, d.endpoint_id, e.url, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_status_code, d.last_error, d.redelivery_of, d.delivered_at, d.created_at
+`
+
+func scanWebhookEndpoint(row interface{ Scan(...interface{}) error }) (*model.WebhookEndpoint, error) {
+	endpoint := &model.WebhookEndpoint{}
+	err := row.Scan(&endpoint.ID, &endpoint.TenantID, &endpoint.URL, &endpoint.Description, &endpoint.Secret,
+		pq.Array(&endpoint.Events), &endpoint.IsActive, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+	return endpoint, err
+}
+
+func scanWebhookDelivery(row interface{ Scan(...interface{}) error }) (*model.WebhookDelivery, error) {
+	d := &model.WebhookDelivery{}
+	var payload []byte
+	err := row.Scan(&d.ID, &d.TenantID, &d.EndpointID, &d.EndpointURL, &d.EventID, &d.EventType, &payload,
+		&d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.RedeliveryOf,
+		&d.DeliveredAt, &d.CreatedAt)
+	d.Payload = payload
+	return d, err
+}
+
+// enqueueWebhook queues an event for every active endpoint of the context tenant
+// subscribed to it. Call it inside the transaction of the change, so the event is
+// only sent if the change is saved.
+func enqueueWebhook(ctx context.Context, q execer, eventType string, data interface{}) error {
+	return enqueueWebhookFor(ctx, q, eventType, data, 0)
+}
+
+// enqueueWebhookFor queues an event for one endpoint (regardless of its
+// subscriptions), or for all subscribed endpoints when endpointID is 0. Nothing
+// is queued when the tenant's plan does not include webhooks.
+func enqueueWebhookFor(ctx context.Context, q execer, eventType string, data interface{}, endpointID int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	eventID, err := security.GenerateRandomSecret(12)
+	if err != nil {
+		return err
+	}
+	eventID = "evt_" + eventID
+
+	payload, err := json.Marshal(model.WebhookEvent{
+		ID:        eventID,
+		Type:      eventType,
+		TenantID:  tenantID,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (tenant_id, endpoint_id, event_id, event_type, payload)
+		SELECT tenant_id, id, $2, $3, $4
+		FROM webhook_endpoints
+		WHERE tenant_id = $1 AND is_active AND (id = $5 OR ($5 = 0 AND $3 = ANY(events)))
+			AND EXISTS (
+				SELECT 1 FROM tenants t JOIN plans p ON p.code = t.plan
+				WHERE t.id = $1 AND 'webhooks' = ANY(p.features)
+			)
+	`
+
+	if _, err := q.ExecContext(ctx, query, tenantID, eventID, eventType, string(payload), endpointID); err != nil {
+		return fmt.Errorf("failed to queue webhook: %w", err)
+	}
+
+	return nil
+}
+
+// CreateEndpoint stores a new webhook endpoint with its signing secret (tenant-scoped)
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, req *model.WebhookEndpointRequest, secret string) (*model.WebhookEndpoint, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO webhook_endpoints (tenant_id, url, description, secret, events, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + webhookEndpointColumns
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	endpoint, err := scanWebhookEndpoint(tx.QueryRowContext(ctx, query,
+		tenantID, req.URL, req.Description, secret, pq.Array(req.Events), *req.IsActive,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	after, err := snapshotRow(ctx, tx, webhookSnapshotQuery, endpoint.ID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordAudit(ctx, tx, "webhook", entityRef(endpoint.ID), "create", nil, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// ListEndpoints returns the tenant's webhook endpoints (tenant-scoped)
+func (r *WebhookRepository) ListEndpoints(ctx context.Context) ([]*model.WebhookEndpoint, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + webhookEndpointColumns + " FROM webhook_endpoints WHERE tenant_id = $1 ORDER BY id"
+
+	rows, err := r.db.QueryContext(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var endpoints []*model.WebhookEndpoint
+	for rows.Next() {
+		endpoint, err := scanWebhookEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+
+	return endpoints, nil
+}
+
+// GetEndpoint retrieves a webhook endpoint by ID (tenant-scoped)
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id int) (*model.WebhookEndpoint, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return nil, err
+	}
+
+	query := "SELECT " + webhookEndpointColumns + " FROM webhook_endpoints WHERE id = $1 AND tenant_id = $2"
+
+	endpoint, err := scanWebhookEndpoint(r.db.QueryRowContext(ctx, query, id, tenantID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("webhook endpoint not found")
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+
+	return endpoint, nil
+}
+
+// UpdateEndpoint changes the URL, description, events and active flag of an endpoint (tenant-scoped)
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, id int, req *model.WebhookEndpointRequest) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE webhook_endpoints
+		SET url = $1, description = $2, events = $3, is_active = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5 AND tenant_id = $6
+	`
+
+	return r.execEndpointChange(ctx, id, tenantID, "update", query,
+		req.URL, req.Description, pq.Array(req.Events), *req.IsActive, id, tenantID)
+}
+
+// RotateSecret replaces the signing secret of an endpoint (tenant-scoped)
+func (r *WebhookRepository) RotateSecret(ctx context.Context, id int, secret string) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
+	query := "UPDATE webhook_endpoints SET secret = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND tenant_id = $3"
+
+	return r.execEndpointChange(ctx, id, tenantID, "rotate_secret", query, secret, id, tenantID)
+}
+
+// DeleteEndpoint removes an endpoint with its delivery log (tenant-scoped)
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id int) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return err
+	}
+
+	query := "DELETE FROM webhook_endpoints WHERE id = $1 AND tenant_id = $2"
+
+	return r.execEndpointChange(ctx, id, tenantID, "delete", query, id, tenantID)
+}
+
+func (r *WebhookRepository) execEndpointChange(ctx context.Context, id, tenantID int, action, query string, args ...interface{}) error {
+	target := auditTarget{
+		EntityType: "webhook", EntityID: entityRef(id), Action: action,
+		Snapshot: webhookSnapshotQuery, SnapshotArgs: []interface{}{id, tenantID},
+	}
+	result, err := auditedExec(ctx, r.db, target, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to %s webhook endpoint: %w", action, err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("webhook endpoint not found or no permission")
+	}
+
+	return nil
+}
+
+// SendPing queues a ping event for one active endpoint (tenant-scoped)
+func (r *WebhookRepository) SendPing(ctx context.Context, endpointID int) error {
+	endpoint, err := r.GetEndpoint(ctx, endpointID)
+	if err != nil {
+		return err
+	}
+	if !endpoint.IsActive {
+		return fmt.Errorf("webhook endpoint is not active")
+	}
+
+	return enqueueWebhookFor(ctx, r.db, model.WebhookEventPing, map[string]interface{}{
+		"endpoint_id": endpoint.ID,
+		"message":     "Webhook test dari Auto LMK",
+	}, endpoint.ID)
+}
+
+// ListDeliveries returns one page of the delivery log, newest first, and the
+// cursor of the next page (0 on the last page) (tenant-scoped)
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, filter *model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, int, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + webhookDeliveryColumns + `
+		FROM webhook_deliveries d
+		INNER JOIN webhook_endpoints e ON e.id = d.endpoint_id
+		WHERE d.tenant_id = $1 AND ($2 = 0 OR d.endpoint_id = $2) AND ($3 = '' OR d.status = $3)
+			AND ($4 = '' OR d.event_type = $4) AND ($5 = 0 OR d.id < $5)
+		ORDER BY d.id DESC
+		LIMIT $6
+	`
+
+	limit := filter.Page.PageLimit()
+	rows, err := r.db.QueryContext(ctx, query,
+		tenantID, filter.EndpointID, filter.Status, filter.EventType, filter.Page.Cursor, limit+1)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*model.WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	next := 0
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+		next = int(deliveries[limit-1].ID)
+	}
+
+	return deliveries, next, nil
+}
+
+// Redeliver queues a copy of a delivery to be sent again as a fresh delivery,
+// keeping the original in the log (tenant-scoped)
+func (r *WebhookRepository) Redeliver(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	if err := authorize(ctx, model.PermManageSettings); err != nil {
+		return nil, err
+	}
+
+	query := `
+		WITH d AS (
+			INSERT INTO webhook_deliveries (tenant_id, endpoint_id, event_id, event_type, payload, redelivery_of)
+			SELECT tenant_id, endpoint_id, event_id, event_type, payload, id
+			FROM webhook_deliveries
+			WHERE id = $1 AND tenant_id = $2
+			RETURNING *
+		)
+		SELECT ` + webhookDeliveryColumns + `
+		FROM d INNER JOIN webhook_endpoints e ON e.id = d.endpoint_id
+	`
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, id, tenantID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("webhook delivery not found")
+		}
+		return nil, fmt.Errorf("failed to redeliver webhook: %w", err)
+	}
+
+	return delivery, nil
+}
+
+// ClaimDue takes up to limit deliveries of any tenant that are due and counts
+// the attempt. A claimed delivery is leased: if the dispatcher dies before
+// recording the outcome, it becomes due again after lease.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDispatch, error) {
+	query := `
+		WITH due AS (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET attempts = d.attempts + 1, next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM due, webhook_endpoints e
+		WHERE d.id = due.id AND e.id = d.endpoint_id AND e.is_active
+		RETURNING d.id, d.event_id, d.event_type, d.payload, d.attempts, e.url, e.secret
+	`
+
+	rows, err := r.db.QueryContext(model.WithSystemScope(ctx), query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var dispatches []*model.WebhookDispatch
+	for rows.Next() {
+		d := &model.WebhookDispatch{}
+		if err := rows.Scan(&d.DeliveryID, &d.EventID, &d.EventType, &d.Payload, &d.Attempt, &d.URL, &d.Secret); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		dispatches = append(dispatches, d)
+	}
+
+	return dispatches, nil
+}
+
+// RecordSuccess marks a delivery as received by the endpoint
+func (r *WebhookRepository) RecordSuccess(ctx context.Context, deliveryID int64, statusCode int) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'succeeded', last_status_code = $1, last_error = NULL,
+			next_attempt_at = NULL, delivered_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`
+
+	if _, err := r.db.ExecContext(model.WithSystemScope(ctx), query, statusCode, deliveryID); err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+// RecordFailure stores a failed attempt. The delivery is retried at retryAt, or
+// marked failed when retryAt is nil. statusCode is nil when no response arrived.
+func (r *WebhookRepository) RecordFailure(ctx context.Context, deliveryID int64, statusCode *int, errMsg string, retryAt *time.Time) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = CASE WHEN $3::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			last_status_code = $1, last_error = $2, next_attempt_at = $3
+		WHERE id = $4
+	`
+
+	if _, err := r.db.ExecContext(model.WithSystemScope(ctx), query, statusCode, errMsg, retryAt, deliveryID); err != nil {
+		return fmt.Errorf("failed to record webhook delivery: %w", err)
+	}
+
+	return nil
+}
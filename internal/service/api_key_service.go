@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+// ErrInvalidAPIKey is returned for a malformed, unknown, expired or revoked API key
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKeyService issues and checks the tenant API keys used by /api/v1
+// integrations. Keys are stored hashed and shown to the admin only once.
+type APIKeyService struct {
+	repo *repository.APIKeyRepository
+}
+
+func NewAPIKeyService(repo *repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Create issues a key for the tenant in ctx and returns it with its plaintext value
+func (s *APIKeyService) Create(ctx context.Context, req *model.CreateAPIKeyRequest) (*model.APIKey, string, error) {
+	key, prefix, err := security.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+
+	apiKey, err := s.repo.Create(ctx, req, prefix, security.HashToken(key))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+// List returns the tenant's keys
+func (s *APIKeyService) List(ctx context.Context) ([]*model.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+// Revoke disables a key of the tenant in ctx
+func (s *APIKeyService) Revoke(ctx context.Context, id int) error {
+	return s.repo.Revoke(ctx, id)
+}
+
+// AuthenticateAPIKey returns the live key matching a plaintext key and records its use
+func (s *APIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*model.APIKey, error) {
+	if !security.IsAPIKey(key) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.repo.GetActiveByHash(ctx, security.HashToken(key))
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, apiKey.ID); err != nil {
+		slog.Warn("failed to record API key usage", "error", err, "api_key_id", apiKey.ID)
+	}
+
+	return apiKey, nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+// ErrInvalidCredentials is returned for an unknown email, wrong password or disabled account
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// AuthService handles admin logins. Every login creates a server-side session; the admin
+// panel carries it in a signed cookie and API clients in the sid claim of a JWT, so
+// revoking the session logs out both.
+type AuthService struct {
+	userRepo    *repository.UserRepository
+	sessionRepo *repository.SessionRepository
+	secret      string
+	sessionTTL  time.Duration
+}
+
+func NewAuthService(userRepo *repository.UserRepository, sessionRepo *repository.SessionRepository, secret string, sessionTTL time.Duration) *AuthService {
+	return &AuthService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		secret:      secret,
+		sessionTTL:  sessionTTL,
+	}
+}
+
+// findByEmail looks up a tenant user, or a platform super-admin when ctx has no tenant
+func (s *AuthService) findByEmail(ctx context.Context, email string) (*model.User, error) {
+	if _, err := model.GetTenantID(ctx); err != nil {
+		return s.userRepo.GetPlatformByEmail(ctx, email)
+	}
+	return s.userRepo.GetByEmail(ctx, email)
+}
+
+// Login checks credentials for the tenant in ctx and starts a session.
+// Without a tenant in ctx it logs in platform super-admins.
+func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest, userAgent, ipAddress string) (*model.AuthResult, error) {
+	user, err := s.findByEmail(ctx, req.Email)
+	if err != nil || user.Status != "active" || !security.CheckPassword(req.Password, user.PasswordHash) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.startSession(ctx, user, userAgent, ipAddress)
+}
+
+// startSession creates a session for an authenticated user and issues its tokens
+func (s *AuthService) startSession(ctx context.Context, user *model.User, userAgent, ipAddress string) (*model.AuthResult, error) {
+	sessionToken, err := security.GenerateRandomSecret(32)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(s.sessionTTL)
+	if _, err := s.sessionRepo.Create(ctx, user, security.HashToken(sessionToken), userAgent, ipAddress, expiresAt); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := security.GenerateJWT(&security.Claims{
+		UserID:    user.ID,
+		TenantID:  user.TenantID,
+		Role:      user.Role,
+		SessionID: sessionToken,
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}, s.secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
+		slog.Warn("failed to record last login", "error", err, "user_id", user.ID)
+	}
+
+	return &model.AuthResult{
+		User:         user,
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresAt:    expiresAt,
+		SessionToken: sessionToken,
+	}, nil
+}
+
+// CreateUser creates an account with a bcrypt-hashed password
+func (s *AuthService) CreateUser(ctx context.Context, req *model.CreateUserRequest) (*model.User, error) {
+	lookup := s.userRepo.GetByEmail
+	if req.Role == model.RoleSuperAdmin {
+		lookup = s.userRepo.GetPlatformByEmail
+	}
+	if existing, err := lookup(ctx, req.Email); err == nil && existing != nil {
+		return nil, fmt.Errorf("user already exists")
+	}
+
+	hash, err := security.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.Create(ctx, req, hash)
+}
+
+// ChangePassword sets a new password and ends all of the user's sessions
+func (s *AuthService) ChangePassword(ctx context.Context, userID int, password string) error {
+	if len(password) < 8 {
+		return fmt.Errorf("Password minimal 8 karakter")
+	}
+
+	hash, err := security.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, hash); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeAllForUser(ctx, userID)
+}
+
+// SessionCookieValue signs a session token for the admin session cookie
+func (s *AuthService) SessionCookieValue(sessionToken string) string {
+	return security.SignValue(sessionToken, s.secret)
+}
+
+// SessionFromCookie verifies a session cookie and returns its session token
+func (s *AuthService) SessionFromCookie(value string) (string, error) {
+	token, ok := security.VerifySignedValue(value, s.secret)
+	if !ok {
+		return "", security.ErrInvalidToken
+	}
+	return token, nil
+}
+
+// SessionFromBearer verifies a JWT access token and returns its session token
+func (s *AuthService) SessionFromBearer(accessToken string) (string, error) {
+	claims, err := security.ParseJWT(accessToken, s.secret)
+	if err != nil {
+		return "", err
+	}
+	return claims.SessionID, nil
+}
+
+// ValidateSession returns the user of a live session for the tenant in ctx,
+// or of a live platform session
+func (s *AuthService) ValidateSession(ctx context.Context, sessionToken string) (*model.User, error) {
+	return s.sessionRepo.GetActiveUser(ctx, security.HashToken(sessionToken))
+}
+
+// Logout revokes a session
+func (s *AuthService) Logout(ctx context.Context, sessionToken string) error {
+	return s.sessionRepo.Revoke(ctx, security.HashToken(sessionToken))
+}
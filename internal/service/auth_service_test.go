@@ -0,0 +1,60 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+func TestSessionCookie(t *testing.T) {
+	auth := NewAuthService(nil, nil, "cookie-secret", time.Hour)
+
+	cookie := auth.SessionCookieValue("session-token")
+	token, err := auth.SessionFromCookie(cookie)
+	if err != nil || token != "session-token" {
+		t.Fatalf("SessionFromCookie = %q, %v", token, err)
+	}
+
+	other := NewAuthService(nil, nil, "another-secret", time.Hour)
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"unsigned token", "session-token"},
+		{"other token, same signature", "session-tokem" + cookie[len("session-token"):]},
+		{"signed with another secret", other.SessionCookieValue("session-token")},
+		{"truncated signature", cookie[:len(cookie)-1]},
+		{"empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := auth.SessionFromCookie(tt.value); !errors.Is(err, security.ErrInvalidToken) {
+				t.Errorf("SessionFromCookie(%q) error = %v, want %v", tt.value, err, security.ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func TestSessionFromBearer(t *testing.T) {
+	auth := NewAuthService(nil, nil, "jwt-secret", time.Hour)
+	claims := &security.Claims{UserID: 1, TenantID: 2, Role: "sales", SessionID: "session-token", ExpiresAt: time.Now().Add(time.Hour).Unix()}
+
+	token, _ := security.GenerateJWT(claims, "jwt-secret")
+	if sid, err := auth.SessionFromBearer(token); err != nil || sid != "session-token" {
+		t.Fatalf("SessionFromBearer = %q, %v", sid, err)
+	}
+
+	forged, _ := security.GenerateJWT(claims, "another-secret")
+	if _, err := auth.SessionFromBearer(forged); !errors.Is(err, security.ErrInvalidToken) {
+		t.Errorf("forged token error = %v, want %v", err, security.ErrInvalidToken)
+	}
+
+	claims.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	expired, _ := security.GenerateJWT(claims, "jwt-secret")
+	if _, err := auth.SessionFromBearer(expired); !errors.Is(err, security.ErrExpiredToken) {
+		t.Errorf("expired token error = %v, want %v", err, security.ErrExpiredToken)
+	}
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+const (
+	dataJobPollInterval = 10 * time.Second
+	dataJobLease        = time.Hour // longer than the biggest tenant export takes
+	dataJobMaxAttempts  = 3
+	dataJobListLimit    = 50
+	dataExportTTL       = 7 * 24 * time.Hour // export files hold PII unencrypted
+	dataExpireInterval  = time.Hour
+)
+
+// ErrExportNotReady is returned when downloading a job without a (live) file
+var ErrExportNotReady = errors.New("export file not available")
+
+// DataJobService starts customer exports, customer erasures and tenant export
+// archives, and serves their status and files. DataJobRunner runs them.
+type DataJobService struct {
+	jobs *repository.DataJobRepository
+}
+
+func NewDataJobService(jobs *repository.DataJobRepository) *DataJobService {
+	return &DataJobService{jobs: jobs}
+}
+
+// Request queues a data job for the context tenant
+func (s *DataJobService) Request(ctx context.Context, req *model.DataJobRequest) (*model.DataJob, error) {
+	return s.jobs.Create(ctx, req)
+}
+
+// Get returns a job of the context tenant
+func (s *DataJobService) Get(ctx context.Context, id int) (*model.DataJob, error) {
+	return s.jobs.GetByID(ctx, id)
+}
+
+// List returns the latest jobs of the context tenant
+func (s *DataJobService) List(ctx context.Context) ([]*model.DataJob, error) {
+	return s.jobs.List(ctx, dataJobListLimit)
+}
+
+// File opens the export file of a finished job and returns it with the name
+// to download it as. The caller closes the file.
+func (s *DataJobService) File(ctx context.Context, id int) (*os.File, string, error) {
+	job, err := s.jobs.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if job.Status != model.DataJobDone || job.FilePath == nil {
+		return nil, "", ErrExportNotReady
+	}
+
+	f, err := os.Open(*job.FilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", ErrExportNotReady
+		}
+		return nil, "", fmt.Errorf("failed to open export file: %w", err)
+	}
+	return f, filepath.Base(*job.FilePath), nil
+}
+
+// DataJobRunner runs queued data jobs one at a time and deletes expired export
+// files. A job that fails is retried up to dataJobMaxAttempts times; one whose
+// runner died is picked up again when its lease runs out. Erasures are safe to
+// run twice.
+type DataJobRunner struct {
+	jobs      *repository.DataJobRepository
+	customers *repository.CustomerRepository
+	dir       string
+}
+
+func NewDataJobRunner(jobs *repository.DataJobRepository, customers *repository.CustomerRepository, dir string) *DataJobRunner {
+	return &DataJobRunner{jobs: jobs, customers: customers, dir: dir}
+}
+
+// Run processes data jobs until ctx is cancelled
+func (d *DataJobRunner) Run(ctx context.Context) {
+	ticker := time.NewTicker(dataJobPollInterval)
+	defer ticker.Stop()
+
+	slog.Info("data job runner started")
+	var lastExpire time.Time
+	for {
+		for ctx.Err() == nil {
+			processed, err := d.ProcessNext(ctx)
+			if err != nil && ctx.Err() == nil {
+				slog.Error("failed to process data jobs", "error", err)
+			}
+			if !processed || err != nil {
+				break
+			}
+		}
+
+		if time.Since(lastExpire) > dataExpireInterval {
+			lastExpire = time.Now()
+			d.expireFiles(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("data job runner stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessNext runs the next due job; false when there was none
+func (d *DataJobRunner) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := d.jobs.ClaimNext(ctx, dataJobLease)
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	jobCtx := model.WithSystemActor(model.WithTenantID(ctx, job.TenantID))
+	jobCtx = model.WithAuditActor(jobCtx, model.AuditActorSystem, fmt.Sprintf("data_job:%d", job.ID))
+
+	if job.Attempts > dataJobMaxAttempts {
+		return true, d.jobs.Fail(ctx, job.ID, "job did not finish after repeated attempts", true)
+	}
+
+	start := time.Now()
+	result, path, err := d.run(jobCtx, job)
+	if err != nil {
+		final := job.Attempts >= dataJobMaxAttempts
+		slog.Error("data job failed", "job_id", job.ID, "type", job.Type, "attempt", job.Attempts, "error", err, "will_retry", !final)
+		return true, d.jobs.Fail(ctx, job.ID, err.Error(), final)
+	}
+
+	var filePath *string
+	var expiresAt *time.Time
+	if path != "" {
+		expires := time.Now().Add(dataExportTTL)
+		filePath, expiresAt = &path, &expires
+	}
+
+	slog.Info("data job done", "job_id", job.ID, "type", job.Type, "tenant_id", job.TenantID, "duration", time.Since(start))
+	return true, d.jobs.Complete(ctx, job.ID, result, filePath, expiresAt)
+}
+
+// run does the work of a job and returns its result and export file, if any
+func (d *DataJobRunner) run(ctx context.Context, job *model.DataJob) (interface{}, string, error) {
+	switch job.Type {
+	case model.DataJobCustomerErasure:
+		if job.Phone == nil || job.Mode == nil {
+			return nil, "", errors.New("erasure job without phone or mode")
+		}
+		result, err := d.customers.Erase(ctx, *job.Phone, *job.Mode)
+		return result, "", err
+
+	case model.DataJobCustomerExport:
+		if job.Phone == nil || job.Format == nil {
+			return nil, "", errors.New("export job without phone or format")
+		}
+		export, err := d.customers.Export(ctx, *job.Phone)
+		if err != nil {
+			return nil, "", err
+		}
+		result := map[string]int{
+			"conversations": len(export.Conversations),
+			"leads":         len(export.Leads),
+			"appointments":  len(export.Appointments),
+		}
+		name := fmt.Sprintf("customer-%s-%d.%s", export.PhoneNumber, job.ID, *job.Format)
+		path, err := d.writeFile(job, name, func(w io.Writer) error {
+			if *job.Format == model.ExportFormatZIP {
+				return writeCustomerZip(w, export)
+			}
+			return writeJSON(w, export)
+		})
+		return result, path, err
+
+	case model.DataJobTenantExport:
+		result := map[string]int{}
+		name := fmt.Sprintf("tenant-%d-export-%d.zip", job.TenantID, job.ID)
+		path, err := d.writeFile(job, name, func(w io.Writer) error {
+			return d.writeTenantZip(ctx, w, result)
+		})
+		return result, path, err
+	}
+
+	return nil, "", fmt.Errorf("unknown data job type %q", job.Type)
+}
+
+// writeFile writes an export file under the tenant's directory. It is written
+// to a temporary name first so a crash never leaves a truncated export behind.
+func (d *DataJobRunner) writeFile(job *model.DataJob, name string, write func(w io.Writer) error) (string, error) {
+	dir := filepath.Join(d.dir, fmt.Sprintf("tenant-%d", job.TenantID))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".export-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write export file: %w", err)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to save export file: %w", err)
+	}
+	return path, nil
+}
+
+// writeTenantZip writes one JSON file per table and a manifest with the row counts
+func (d *DataJobRunner) writeTenantZip(ctx context.Context, w io.Writer, counts map[string]int) error {
+	zw := zip.NewWriter(w)
+	for _, table := range repository.TenantExportTables() {
+		f, err := zw.Create(table + ".json")
+		if err != nil {
+			return err
+		}
+		if counts[table], err = d.jobs.WriteTenantTable(ctx, table, f); err != nil {
+			return err
+		}
+	}
+
+	tenantID, _ := model.GetTenantID(ctx)
+	manifest := map[string]interface{}{
+		"tenant_id":   tenantID,
+		"exported_at": time.Now(),
+		"tables":      counts,
+	}
+	if err := writeZipJSON(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeCustomerZip splits a customer export into one JSON file per section
+func writeCustomerZip(w io.Writer, export *model.CustomerExport) error {
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"customer.json", map[string]interface{}{
+			"phone_number": export.PhoneNumber,
+			"exported_at":  export.ExportedAt,
+			"profile":      export.Customer,
+		}},
+		{"conversations.json", export.Conversations},
+		{"leads.json", export.Leads},
+		{"appointments.json", export.Appointments},
+	}
+	for _, file := range files {
+		if err := writeZipJSON(zw, file.name, file.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeZipJSON(zw *zip.Writer, name string, data interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	return writeJSON(f, data)
+}
+
+func writeJSON(w io.Writer, data interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// expireFiles deletes export files past their expiry
+func (d *DataJobRunner) expireFiles(ctx context.Context) {
+	paths, err := d.jobs.ExpireFiles(ctx)
+	if err != nil {
+		slog.Error("failed to expire export files", "error", err)
+		return
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			slog.Error("failed to delete export file", "error", err, "path", path)
+		}
+	}
+	if len(paths) > 0 {
+		slog.Info("deleted expired export files", "count", len(paths))
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+func TestWriteCustomerZip(t *testing.T) {
+	export := &model.CustomerExport{
+		PhoneNumber: "6281234567890",
+		ExportedAt:  time.Now(),
+		Conversations: []*model.ConversationExport{{
+			Conversation: &model.Conversation{ID: 7, SenderPhone: "6281234567890"},
+			Messages:     []*model.Message{{ID: 1, ConversationID: 7, MessageText: "Halo"}},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeCustomerZip(&buf, export); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	for _, name := range []string{"customer.json", "conversations.json", "leads.json", "appointments.json"} {
+		if files[name] == nil {
+			t.Errorf("archive is missing %s", name)
+		}
+	}
+
+	rc, err := files["conversations.json"].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+
+	var conversations []struct {
+		ID       int `json:"id"`
+		Messages []struct {
+			MessageText string `json:"message_text"`
+		} `json:"messages"`
+	}
+	if err := json.NewDecoder(rc).Decode(&conversations); err != nil {
+		t.Fatal(err)
+	}
+	if len(conversations) != 1 || conversations[0].ID != 7 || len(conversations[0].Messages) != 1 || conversations[0].Messages[0].MessageText != "Halo" {
+		t.Errorf("unexpected conversations.json: %+v", conversations)
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+// DealService manages deals and sales commissions
+type DealService struct {
+	dealRepo       *repository.DealRepository
+	commissionRepo *repository.CommissionRepository
+	carRepo        *repository.CarRepository
+	showrooms      *repository.ShowroomRepository
+}
+
+func NewDealService(dealRepo *repository.DealRepository, commissionRepo *repository.CommissionRepository, carRepo *repository.CarRepository) *DealService {
+	return &DealService{
+		dealRepo:       dealRepo,
+		commissionRepo: commissionRepo,
+		carRepo:        carRepo,
+	}
+}
+
+// SetShowrooms makes closing dates and statement months follow each tenant's
+// business time zone; without it they are in WIB
+func (s *DealService) SetShowrooms(showrooms *repository.ShowroomRepository) {
+	s.showrooms = showrooms
+}
+
+// Location returns the tenant's business time zone, WIB when it has not set one
+func (s *DealService) Location(ctx context.Context) *time.Location {
+	var hours *model.BusinessHours
+	if s.showrooms != nil {
+		if tenantID, err := model.GetTenantID(ctx); err == nil {
+			showroom, err := s.showrooms.GetByTenantID(ctx, tenantID)
+			if err != nil {
+				slog.Warn("failed to load showroom time zone, using default", "tenant_id", tenantID, "error", err)
+			} else {
+				hours = showroom.Hours
+			}
+		}
+	}
+	return hours.Location()
+}
+
+// CreateDeal opens a new deal for an available car
+func (s *DealService) CreateDeal(ctx context.Context, req *model.DealRequest) (*model.Deal, error) {
+	car, err := s.carRepo.GetByID(ctx, req.CarID)
+	if err != nil {
+		return nil, err
+	}
+	if car.Status == "sold" {
+		return nil, fmt.Errorf("mobil sudah terjual")
+	}
+
+	deal := dealFromRequest(req, car.Price)
+	if err := s.dealRepo.Create(ctx, deal); err != nil {
+		return nil, err
+	}
+
+	return s.dealRepo.GetByID(ctx, deal.ID)
+}
+
+// UpdateDeal updates an open deal
+func (s *DealService) UpdateDeal(ctx context.Context, id int, req *model.DealRequest) (*model.Deal, error) {
+	car, err := s.carRepo.GetByID(ctx, req.CarID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.dealRepo.Update(ctx, id, dealFromRequest(req, car.Price)); err != nil {
+		return nil, err
+	}
+
+	return s.dealRepo.GetByID(ctx, id)
+}
+
+// CloseDeal closes a deal, snapshots the sales commission and marks the car sold.
+// The deal is recorded as closed on closingDate's calendar date in its location.
+func (s *DealService) CloseDeal(ctx context.Context, id int, closingDate time.Time) (*model.Deal, error) {
+	deal, err := s.dealRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deal.Status != "open" {
+		return nil, fmt.Errorf("deal sudah %s", deal.Status)
+	}
+
+	var commission int64
+	if deal.SalesID != nil {
+		rules, err := s.commissionRepo.ListRules(ctx, true)
+		if err != nil {
+			return nil, err
+		}
+		commission = CalculateCommission(rules, deal.FinalPrice())
+	}
+
+	if err := s.dealRepo.Close(ctx, id, closingDate, commission); err != nil {
+		return nil, err
+	}
+
+	return s.dealRepo.GetByID(ctx, id)
+}
+
+// MonthlyStatements builds one commission statement per sales person for the
+// calendar month month falls in, in month's location
+func (s *DealService) MonthlyStatements(ctx context.Context, month time.Time) ([]*model.CommissionStatement, error) {
+	from, to := statementPeriod(month)
+
+	deals, err := s.dealRepo.ListClosedBetween(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []*model.CommissionStatement{}
+	bySales := make(map[int]*model.CommissionStatement)
+	for _, deal := range deals {
+		salesID := 0
+		if deal.SalesID != nil {
+			salesID = *deal.SalesID
+		}
+
+		st, ok := bySales[salesID]
+		if !ok {
+			name := deal.SalesName
+			if salesID == 0 {
+				name = "Tanpa Sales"
+			}
+			st = &model.CommissionStatement{
+				SalesID:   salesID,
+				SalesName: name,
+				Month:     from.Format("2006-01"),
+				Deals:     []*model.Deal{},
+			}
+			bySales[salesID] = st
+			statements = append(statements, st)
+		}
+
+		st.DealCount++
+		st.TotalSales += deal.FinalPrice()
+		st.TotalCommission += deal.CommissionAmount
+		st.Deals = append(st.Deals, deal)
+	}
+
+	return statements, nil
+}
+
+// statementPeriod returns the first day of month's calendar month and of the
+// month after, both at midnight in month's location
+func statementPeriod(month time.Time) (from, to time.Time) {
+	from = time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	return from, from.AddDate(0, 1, 0)
+}
+
+// CalculateCommission applies the most specific matching rule (highest minimum price) to a final price
+func CalculateCommission(rules []*model.CommissionRule, price int64) int64 {
+	var best *model.CommissionRule
+	for _, rule := range rules {
+		if !rule.Matches(price) {
+			continue
+		}
+		if best == nil || rule.MinPrice > best.MinPrice {
+			best = rule
+		}
+	}
+	if best == nil {
+		return 0
+	}
+
+	commission := int64(math.Round(float64(price)*best.Percent/100)) + best.FlatAmount
+	return roundUp(commission, quoteRoundingUnit)
+}
+
+func dealFromRequest(req *model.DealRequest, listPrice int64) *model.Deal {
+	carID := req.CarID
+	return &model.Deal{
+		CarID:            &carID,
+		LeadID:           req.LeadID,
+		SalesID:          req.SalesID,
+		CustomerName:     req.CustomerName,
+		CustomerPhone:    req.CustomerPhone,
+		ListPrice:        listPrice,
+		AgreedPrice:      req.AgreedPrice,
+		Discount:         req.Discount,
+		PaymentMethod:    req.PaymentMethod,
+		LeasingPartnerID: req.LeasingPartnerID,
+		Notes:            req.Notes,
+	}
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+func TestCalculateCommission(t *testing.T) {
+	upTo := func(v int64) *int64 { return &v }
+	rules := []*model.CommissionRule{
+		{Name: "Dasar", MinPrice: 0, Percent: 1},
+		{Name: "Menengah", MinPrice: 150000000, MaxPrice: upTo(300000000), Percent: 1.5},
+		{Name: "Premium", MinPrice: 300000001, Percent: 1, FlatAmount: 2000000},
+	}
+
+	tests := []struct {
+		name  string
+		rules []*model.CommissionRule
+		price int64
+		want  int64
+	}{
+		{"no rules", nil, 200000000, 0},
+		{"below every rule", rules[1:], 100000000, 0},
+		{"base tier", rules, 100000000, 1000000},
+		{"tier lower bound", rules, 150000000, 2250000},
+		{"tier upper bound", rules, 300000000, 4500000},
+		{"percent plus flat", rules, 400000000, 6000000},
+		{"rounded up to thousand", rules, 123456789, 1235000},
+		{"flat only", []*model.CommissionRule{{Name: "Flat", FlatAmount: 750000}}, 90000000, 750000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CalculateCommission(tt.rules, tt.price); got != tt.want {
+				t.Errorf("CalculateCommission(%d) = %d, want %d", tt.price, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatementPeriod(t *testing.T) {
+	wib := (*model.BusinessHours)(nil).Location()
+	wit := (&model.BusinessHours{Timezone: "WIT"}).Location()
+
+	tests := []struct {
+		name     string
+		month    time.Time
+		from, to string
+	}{
+		{"mid month", time.Date(2026, 10, 15, 12, 0, 0, 0, wib), "2026-10-01", "2026-11-01"},
+		{"first day after midnight WIB", time.Date(2026, 9, 30, 17, 30, 0, 0, time.UTC).In(wib), "2026-10-01", "2026-11-01"},
+		{"last day before midnight WIB", time.Date(2026, 10, 31, 16, 59, 0, 0, time.UTC).In(wib), "2026-10-01", "2026-11-01"},
+		{"tenant in WIT", time.Date(2026, 10, 31, 15, 30, 0, 0, time.UTC).In(wit), "2026-11-01", "2026-12-01"},
+		{"december", time.Date(2026, 12, 31, 23, 0, 0, 0, wib), "2026-12-01", "2027-01-01"},
+		{"parsed month", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), "2026-02-01", "2026-03-01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to := statementPeriod(tt.month)
+			if got := from.Format("2006-01-02"); got != tt.from {
+				t.Errorf("from = %s, want %s", got, tt.from)
+			}
+			if got := to.Format("2006-01-02"); got != tt.to {
+				t.Errorf("to = %s, want %s", got, tt.to)
+			}
+			if from.Location() != tt.month.Location() {
+				t.Errorf("period in %s, want %s", from.Location(), tt.month.Location())
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+const (
+	domainCheckTimeout = 10 * time.Second
+	domainTokenBytes   = 18 // 24 characters, no base64 padding
+	domainMaxRedirects = 3
+)
+
+// sharedPrefixes are not private by RFC 1918 but still not reachable on the
+// internet; 100.64.0.0/10 hosts cloud metadata services on some providers
+var sharedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// TXTResolver looks up DNS TXT records; *net.Resolver implements it
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// DomainService lets a tenant bring its own domains. A domain is verified by
+// a TXT record holding its token, or by pointing it at the platform so the
+// token is served over HTTP; then it routes to the tenant. One domain is
+// canonical and the others redirect to it.
+type DomainService struct {
+	repo           *repository.DomainRepository
+	resolver       TXTResolver
+	client         *http.Client
+	platformDomain string
+	cache          TenantCache
+}
+
+// NewDomainService creates the service. Custom domains under platformDomain
+// are refused: those subdomains are assigned by platform admins.
+func NewDomainService(repo *repository.DomainRepository, platformDomain string) *DomainService {
+	return &DomainService{
+		repo:           repo,
+		resolver:       net.DefaultResolver,
+		client:         newDomainCheckClient(),
+		platformDomain: model.NormalizeDomain(platformDomain),
+	}
+}
+
+// newDomainCheckClient returns the client for HTTP challenges. The URL is
+// built from a tenant-supplied domain, so the client only connects to public
+// addresses, checked after DNS resolution, and only follows redirects that keep
+// the host and path (e.g. to HTTPS).
+func newDomainCheckClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: domainCheckTimeout,
+		Control: refuseInternalAddress,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // a proxy would connect on our behalf, past the dialer check
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   domainCheckTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= domainMaxRedirects {
+				return errors.New("too many redirects")
+			}
+			first := via[0].URL
+			if (req.URL.Scheme != "http" && req.URL.Scheme != "https") ||
+				!strings.EqualFold(req.URL.Hostname(), first.Hostname()) || req.URL.Path != first.Path {
+				return fmt.Errorf("redirect to %s leaves the challenge URL", req.URL.Redacted())
+			}
+			return nil
+		},
+	}
+}
+
+// refuseInternalAddress is a net.Dialer Control hook refusing connections to
+// loopback, private, link-local and other non-public addresses
+func refuseInternalAddress(network, address string, c syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("domain check: invalid address %q: %w", address, err)
+	}
+	ip := addrPort.Addr().Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return fmt.Errorf("domain check: address %s is not public", ip)
+	}
+	for _, prefix := range sharedPrefixes {
+		if prefix.Contains(ip) {
+			return fmt.Errorf("domain check: address %s is not public", ip)
+		}
+	}
+	return nil
+}
+
+// SetCache sets the domain cache to invalidate when the tenant's domains change
+func (s *DomainService) SetCache(cache TenantCache) {
+	s.cache = cache
+}
+
+// Validate checks a domain to add, refusing subdomains of the platform domain
+func (s *DomainService) Validate(req *model.AddDomainRequest) error {
+	return req.Validate(s.platformDomain)
+}
+
+// List returns the tenant's domains for the status page
+func (s *DomainService) List(ctx context.Context) (*model.TenantDomains, error) {
+	return s.repo.List(ctx)
+}
+
+// Add registers a custom domain as pending with a new verification token
+func (s *DomainService) Add(ctx context.Context, req *model.AddDomainRequest) (*model.TenantDomain, error) {
+	token, err := security.GenerateRandomSecret(domainTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Add(ctx, req.Domain, token)
+}
+
+// Verify checks the domain's TXT record, then its HTTP token. A failed check
+// is kept on the domain (status failed) rather than returned as an error.
+func (s *DomainService) Verify(ctx context.Context, id int) (*model.TenantDomain, error) {
+	domain, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	method, checkErr := s.check(ctx, domain.Domain, domain.Challenge.Token)
+	if checkErr != nil {
+		if err := s.repo.RecordFailedCheck(ctx, id, checkErr.Error()); err != nil {
+			return nil, err
+		}
+		return s.repo.Get(ctx, id)
+	}
+
+	if err := s.repo.MarkVerified(ctx, id, method); err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	slog.Info("custom domain verified", "domain", domain.Domain, "method", method)
+	return s.repo.Get(ctx, id)
+}
+
+// SetCanonical makes the platform domain or a verified custom domain canonical
+func (s *DomainService) SetCanonical(ctx context.Context, req *model.CanonicalDomainRequest) error {
+	if err := s.repo.SetCanonical(ctx, model.NormalizeDomain(req.Domain)); err != nil {
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+// Delete removes a custom domain, which stops routing at once
+func (s *DomainService) Delete(ctx context.Context, id int) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+// HasChallenge reports whether token is the verification token of a domain
+// added for host; the platform then answers the HTTP check with it
+func (s *DomainService) HasChallenge(ctx context.Context, host, token string) (bool, error) {
+	return s.repo.HasChallenge(ctx, model.NormalizeDomain(host), token)
+}
+
+// check proves ownership of domain by DNS first, then HTTP, and returns the
+// method that succeeded. The error explains to the tenant what is missing.
+func (s *DomainService) check(ctx context.Context, domain, token string) (string, error) {
+	challenge := model.NewDomainChallenge(domain, token)
+
+	records, err := s.resolver.LookupTXT(ctx, challenge.TXTName)
+	if err != nil {
+		slog.Debug("domain TXT lookup failed", "domain", domain, "error", err)
+	}
+	if slices.Contains(records, challenge.TXTValue) {
+		return model.DomainVerifyDNS, nil
+	}
+
+	err = s.fetchToken(ctx, challenge.HTTPURL, token)
+	if err == nil {
+		return model.DomainVerifyHTTP, nil
+	}
+	slog.Debug("domain HTTP check failed", "domain", domain, "error", err)
+
+	return "", fmt.Errorf("Record TXT %s belum ditemukan dan %s belum mengarah ke server kami", challenge.TXTName, domain)
+}
+
+// fetchToken requests the HTTP challenge URL and expects the token as body
+func (s *DomainService) fetchToken(ctx context.Context, url, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(string(body)) != token {
+		return errors.New("token mismatch")
+	}
+	return nil
+}
+
+// invalidate drops the context tenant from the domain cache
+func (s *DomainService) invalidate(ctx context.Context) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil || s.cache == nil {
+		return
+	}
+	s.cache.Invalidate(tenantID)
+}
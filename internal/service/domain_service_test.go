@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// fakeTXT answers TXT lookups from a map, failing like a resolver on NXDOMAIN
+type fakeTXT map[string][]string
+
+func (f fakeTXT) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	records, ok := f[name]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return records, nil
+}
+
+// toServer sends every request to the test server, whatever its host
+type toServer struct{ server *url.URL }
+
+func (t toServer) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Host == "" {
+		req.Host = req.URL.Host // redirected requests leave Host to the URL
+	}
+	req.URL.Scheme = t.server.Scheme
+	req.URL.Host = t.server.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestDomainCheck(t *testing.T) {
+	const token = "tok123"
+
+	// The platform answering the HTTP challenge, for one domain only
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host == "pointed.com" && r.URL.Path == model.DomainHTTPPath+token {
+			w.Write([]byte(token + "\n"))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	s := &DomainService{
+		resolver: fakeTXT{
+			"_autolmk-challenge.txt.com":   {"v=spf1 -all", "autolmk-verification=" + token},
+			"_autolmk-challenge.wrong.com": {"autolmk-verification=other"},
+		},
+		client: newDomainCheckClient(),
+	}
+	s.client.Transport = toServer{serverURL}
+
+	tests := []struct {
+		domain     string
+		wantMethod string
+	}{
+		{"txt.com", model.DomainVerifyDNS},
+		{"pointed.com", model.DomainVerifyHTTP},
+		{"wrong.com", ""},
+		{"nothing.com", ""},
+	}
+
+	for _, tt := range tests {
+		method, err := s.check(context.Background(), tt.domain, token)
+		if method != tt.wantMethod {
+			t.Errorf("%s: method = %q, want %q (err %v)", tt.domain, method, tt.wantMethod, err)
+		}
+		if tt.wantMethod == "" && (err == nil || !strings.Contains(err.Error(), "_autolmk-challenge."+tt.domain)) {
+			t.Errorf("%s: error = %v, want the missing TXT record named", tt.domain, err)
+		}
+	}
+}
+
+func TestAddDomainRequestValidate(t *testing.T) {
+	tests := []struct {
+		domain  string
+		want    string
+		wantErr bool
+	}{
+		{"https://WWW.ShowroomAnda.com/", "www.showroomanda.com", false},
+		{"showroomanda.co.id", "showroomanda.co.id", false},
+		{"jaya.autolmk.id", "", true}, // platform subdomains are assigned by admins
+		{"autolmk.id", "", true},
+		{"localhost", "", true},
+		{"not a domain", "", true},
+	}
+
+	for _, tt := range tests {
+		req := model.AddDomainRequest{Domain: tt.domain}
+		err := req.Validate("autolmk.id")
+		if (err != nil) != tt.wantErr || (err == nil && req.Domain != tt.want) {
+			t.Errorf("%q: got %q, %v", tt.domain, req.Domain, err)
+		}
+	}
+}
+
+func TestRefuseInternalAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.216.34:80", true},
+		{"[2606:2800:220:1:248:1893:25c8:1946]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"10.1.2.3:80", false},
+		{"172.16.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"100.100.100.200:80", false},
+		{"0.0.0.0:80", false},
+		{"[fd00::1]:80", false},
+		{"[fe80::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"[::ffff:169.254.169.254]:80", false},
+	}
+
+	for _, tt := range tests {
+		err := refuseInternalAddress("tcp", tt.address, nil)
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("%s: allowed = %v, want %v (err %v)", tt.address, allowed, tt.allowed, err)
+		}
+	}
+}
+
+func TestDomainCheckClientRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the loopback server")
+	}))
+	defer server.Close()
+
+	s := &DomainService{client: newDomainCheckClient()}
+	if err := s.fetchToken(context.Background(), server.URL+"/token", "token"); err == nil || !strings.Contains(err.Error(), "not public") {
+		t.Errorf("fetchToken error = %v, want the address refused", err)
+	}
+}
+
+func TestDomainCheckRedirects(t *testing.T) {
+	const token = "tok123"
+	path := model.DomainHTTPPath + token
+
+	// Every host answers from the test server; pointed.com redirects per query
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Host != "pointed.com":
+			w.Write([]byte(token))
+		case r.URL.Query().Get("to") == "self":
+			http.Redirect(w, r, r.URL.String(), http.StatusFound)
+		case r.URL.Query().Get("to") != "":
+			http.Redirect(w, r, r.URL.Query().Get("to"), http.StatusFound)
+		case r.URL.Path == path:
+			w.Write([]byte(token))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	s := &DomainService{client: newDomainCheckClient()}
+	s.client.Transport = toServer{serverURL}
+
+	tests := []struct {
+		name string
+		to   string
+		ok   bool
+	}{
+		{"same host and path over https", "https://pointed.com" + path, true},
+		{"other host", "http://169.254.169.254" + path, false},
+		{"other path", "http://pointed.com/admin", false},
+		{"other scheme", "ftp://pointed.com" + path, false},
+		{"redirect loop", "http://pointed.com" + path + "?to=self", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.fetchToken(context.Background(), "http://pointed.com"+path+"?to="+url.QueryEscape(tt.to), token)
+			if (err == nil) != tt.ok {
+				t.Errorf("fetchToken error = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+const (
+	defaultDPPercent   = 20.0
+	defaultTenorMonths = 36
+
+	// Leasing quotes in Indonesia are presented rounded up: DP to the nearest
+	// Rp 100.000 and installments/fees to the nearest Rp 1.000.
+	dpRoundingUnit    = 100000
+	quoteRoundingUnit = 1000
+)
+
+// FinancingService simulates car loans (kredit) from tenant leasing rate tables
+type FinancingService struct {
+	financingRepo *repository.FinancingRepository
+	carRepo       *repository.CarRepository
+	plans         *repository.PlanRepository
+}
+
+func NewFinancingService(financingRepo *repository.FinancingRepository, carRepo *repository.CarRepository) *FinancingService {
+	return &FinancingService{
+		financingRepo: financingRepo,
+		carRepo:       carRepo,
+	}
+}
+
+// SetPlans limits credit simulations to tenants whose plan includes financing.
+// The HTTP routes check the plan themselves; this covers the WhatsApp bot.
+func (s *FinancingService) SetPlans(plans *repository.PlanRepository) {
+	s.plans = plans
+}
+
+// SimulateCredit returns one quote per matching leasing partner
+func (s *FinancingService) SimulateCredit(ctx context.Context, req *model.CreditSimulationRequest) (*model.CreditSimulation, error) {
+	if s.plans != nil {
+		if err := s.plans.CheckFeature(ctx, model.FeatureFinancing); err != nil {
+			return nil, err
+		}
+	}
+
+	price := req.Price
+	carYear := req.CarYear
+
+	if req.CarID > 0 {
+		car, err := s.carRepo.GetByID(ctx, req.CarID)
+		if err != nil {
+			return nil, err
+		}
+		price = car.Price
+		carYear = car.Year
+	}
+
+	if price <= 0 {
+		return nil, fmt.Errorf("harga mobil wajib diisi")
+	}
+
+	tenor := req.TenorMonths
+	if tenor <= 0 {
+		tenor = defaultTenorMonths
+	}
+
+	dp, dpPercent, err := resolveDownPayment(price, req.DPAmount, req.DPPercent)
+	if err != nil {
+		return nil, err
+	}
+
+	carAge := 0
+	if carYear > 0 {
+		carAge = time.Now().Year() - carYear
+	}
+
+	partners, err := s.financingRepo.ListPartners(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &model.CreditSimulation{
+		CarID:       req.CarID,
+		Price:       price,
+		CarAge:      carAge,
+		DPPercent:   dpPercent,
+		TenorMonths: tenor,
+		Quotes:      []*model.CreditQuote{},
+	}
+
+	for _, partner := range partners {
+		if req.PartnerID > 0 && partner.ID != req.PartnerID {
+			continue
+		}
+		rate := selectLeasingRate(partner.Rates, tenor, dpPercent, carAge)
+		if rate == nil {
+			continue
+		}
+		sim.Quotes = append(sim.Quotes, calculateCreditQuote(partner, rate, price, dp, tenor))
+	}
+
+	if len(sim.Quotes) == 0 {
+		sim.Note = "Belum ada skema leasing yang cocok untuk DP, tenor, dan usia mobil ini. Silakan hubungi sales kami."
+	} else {
+		sim.Note = "Simulasi bersifat estimasi, angka final mengikuti persetujuan leasing."
+	}
+
+	return sim, nil
+}
+
+// resolveDownPayment returns the DP from a nominal amount, else a percentage
+// of the price (default 20%) rounded up, and the DP as a percentage of the price
+func resolveDownPayment(price, amount int64, percent float64) (int64, float64, error) {
+	var dp int64
+	switch {
+	case amount > 0:
+		dp = amount
+	case percent > 0:
+		dp = roundUp(int64(math.Ceil(float64(price)*percent/100)), dpRoundingUnit)
+	default:
+		dp = roundUp(int64(math.Ceil(float64(price)*defaultDPPercent/100)), dpRoundingUnit)
+	}
+	if dp >= price {
+		return 0, 0, fmt.Errorf("DP tidak boleh melebihi harga mobil")
+	}
+	return dp, math.Round(float64(dp)/float64(price)*10000) / 100, nil
+}
+
+// selectLeasingRate picks the most specific rate row for the given tenor, DP and car age
+func selectLeasingRate(rates []*model.LeasingRate, tenor int, dpPercent float64, carAge int) *model.LeasingRate {
+	var best *model.LeasingRate
+	for _, rate := range rates {
+		if rate.TenorMonths != tenor || rate.MinDPPercent > dpPercent || carAge > rate.MaxCarAge {
+			continue
+		}
+		if best == nil ||
+			rate.MinDPPercent > best.MinDPPercent ||
+			(rate.MinDPPercent == best.MinDPPercent && rate.MaxCarAge < best.MaxCarAge) {
+			best = rate
+		}
+	}
+	return best
+}
+
+// calculateCreditQuote computes a flat-rate installment quote with the first installment paid upfront (ADDM)
+func calculateCreditQuote(partner *model.LeasingPartner, rate *model.LeasingRate, price, dp int64, tenor int) *model.CreditQuote {
+	principal := price - dp
+	years := float64(tenor) / 12
+	interest := float64(principal) * rate.InterestRate / 100 * years
+	installment := roundUp(int64(math.Ceil((float64(principal)+interest)/float64(tenor))), quoteRoundingUnit)
+
+	insuranceYears := int64(math.Ceil(years))
+	insurance := roundUp(int64(math.Ceil(float64(price)*partner.InsuranceRate/100))*insuranceYears, quoteRoundingUnit)
+
+	return &model.CreditQuote{
+		PartnerID:          partner.ID,
+		PartnerName:        partner.Name,
+		TenorMonths:        tenor,
+		InterestRate:       rate.InterestRate,
+		DownPayment:        dp,
+		LoanPrincipal:      principal,
+		MonthlyInstallment: installment,
+		AdminFee:           partner.AdminFee,
+		InsurancePremium:   insurance,
+		TotalDownPayment:   dp + partner.AdminFee + insurance + installment,
+	}
+}
+
+// roundUp rounds value up to the next multiple of unit
+func roundUp(value, unit int64) int64 {
+	if unit <= 0 || value%unit == 0 {
+		return value
+	}
+	return (value/unit + 1) * unit
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+func TestResolveDownPayment(t *testing.T) {
+	tests := []struct {
+		name        string
+		price       int64
+		amount      int64
+		percent     float64
+		wantDP      int64
+		wantPercent float64
+		wantErr     bool
+	}{
+		{"default 20%", 187500000, 0, 0, 37500000, 20, false},
+		{"percent rounded up to Rp 100.000", 187654321, 0, 15, 28200000, 15.03, false},
+		{"amount taken as is", 200000000, 50000000, 30, 50000000, 25, false},
+		{"odd amount not rounded", 200000000, 50060000, 0, 50060000, 25.03, false},
+		{"amount equal to price", 200000000, 200000000, 0, 0, 0, true},
+		{"amount over price", 200000000, 250000000, 0, 0, 0, true},
+		{"100%", 200000000, 0, 100, 0, 0, true},
+		{"rounding reaches the price", 100000000, 0, 99.99, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp, percent, err := resolveDownPayment(tt.price, tt.amount, tt.percent)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if dp != tt.wantDP || percent != tt.wantPercent {
+				t.Errorf("got %d (%.2f%%), want %d (%.2f%%)", dp, percent, tt.wantDP, tt.wantPercent)
+			}
+		})
+	}
+}
+
+func TestSelectLeasingRate(t *testing.T) {
+	rates := []*model.LeasingRate{
+		{ID: 1, TenorMonths: 36, MinDPPercent: 20, MaxCarAge: 5, InterestRate: 5},
+		{ID: 2, TenorMonths: 36, MinDPPercent: 30, MaxCarAge: 5, InterestRate: 4.5},
+		{ID: 3, TenorMonths: 36, MinDPPercent: 20, MaxCarAge: 10, InterestRate: 6},
+		{ID: 4, TenorMonths: 48, MinDPPercent: 20, MaxCarAge: 5, InterestRate: 5.5},
+	}
+
+	tests := []struct {
+		name      string
+		tenor     int
+		dpPercent float64
+		carAge    int
+		wantID    int // 0 = no rate
+	}{
+		{"exact minimum DP and maximum age", 36, 20, 5, 1},
+		{"higher DP tier", 36, 30, 5, 2},
+		{"just under the higher tier", 36, 29.99, 5, 1},
+		{"older car", 36, 30, 6, 3},
+		{"oldest car", 36, 20, 10, 3},
+		{"car too old", 36, 50, 11, 0},
+		{"DP under every minimum", 36, 19.99, 0, 0},
+		{"other tenor", 48, 25, 3, 4},
+		{"tenor without rates", 24, 50, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate := selectLeasingRate(rates, tt.tenor, tt.dpPercent, tt.carAge)
+			gotID := 0
+			if rate != nil {
+				gotID = rate.ID
+			}
+			if gotID != tt.wantID {
+				t.Errorf("rate = %d, want %d", gotID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestCalculateCreditQuote(t *testing.T) {
+	partner := &model.LeasingPartner{ID: 1, Name: "Leasing", AdminFee: 2500000, InsuranceRate: 2.5}
+
+	tests := []struct {
+		name  string
+		rate  float64
+		price int64
+		dp    int64
+		tenor int
+		want  model.CreditQuote
+	}{
+		{
+			// 184.000.000 / 36 = 5.111.111,11 is rounded up to 5.112.000
+			name: "36 months", rate: 5, price: 200000000, dp: 40000000, tenor: 36,
+			want: model.CreditQuote{
+				LoanPrincipal: 160000000, MonthlyInstallment: 5112000,
+				InsurancePremium: 15000000, TotalDownPayment: 62612000,
+			},
+		},
+		{
+			// A started year of insurance is paid in full
+			name: "18 months", rate: 6, price: 150000000, dp: 30000000, tenor: 18,
+			want: model.CreditQuote{
+				LoanPrincipal: 120000000, MonthlyInstallment: 7267000,
+				InsurancePremium: 7500000, TotalDownPayment: 47267000,
+			},
+		},
+		{
+			name: "12 months exact", rate: 0, price: 120000000, dp: 12000000, tenor: 12,
+			want: model.CreditQuote{
+				LoanPrincipal: 108000000, MonthlyInstallment: 9000000,
+				InsurancePremium: 3000000, TotalDownPayment: 26500000,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote := calculateCreditQuote(partner, &model.LeasingRate{InterestRate: tt.rate}, tt.price, tt.dp, tt.tenor)
+
+			want := tt.want
+			want.PartnerID, want.PartnerName, want.TenorMonths = 1, "Leasing", tt.tenor
+			want.InterestRate, want.DownPayment, want.AdminFee = tt.rate, tt.dp, partner.AdminFee
+			if !reflect.DeepEqual(*quote, want) {
+				t.Errorf("quote = %+v\nwant    %+v", *quote, want)
+			}
+		})
+	}
+}
+
+func TestRoundUp(t *testing.T) {
+	tests := []struct {
+		value, unit, want int64
+	}{
+		{0, 1000, 0},
+		{1, 1000, 1000},
+		{1000, 1000, 1000},
+		{1001, 1000, 2000},
+		{28148149, 100000, 28200000},
+		{5, 0, 5},
+	}
+
+	for _, tt := range tests {
+		if got := roundUp(tt.value, tt.unit); got != tt.want {
+			t.Errorf("roundUp(%d, %d) = %d, want %d", tt.value, tt.unit, got, tt.want)
+		}
+	}
+}
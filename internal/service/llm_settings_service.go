@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/llm"
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+// llmTestTimeout bounds the test chat, so a wrong key or model fails fast
+const llmTestTimeout = 30 * time.Second
+
+// ErrPlatformLLMFailed is returned when a test chat through the platform
+// provider fails; the details, which may describe the platform's key, are
+// only logged
+var ErrPlatformLLMFailed = errors.New("platform LLM provider failed")
+
+// LLMSettingsService lets a tenant bring its own LLM provider and key instead
+// of using the platform's
+type LLMSettingsService struct {
+	repo     *repository.LLMSettingsRepository
+	registry *llm.Registry
+}
+
+// NewLLMSettingsService creates the service; registry is nil when neither the
+// platform nor tenants can have a provider
+func NewLLMSettingsService(repo *repository.LLMSettingsRepository, registry *llm.Registry) *LLMSettingsService {
+	return &LLMSettingsService{repo: repo, registry: registry}
+}
+
+// Status returns the tenant's own provider and the options the platform offers
+func (s *LLMSettingsService) Status(ctx context.Context) (*model.LLMStatus, error) {
+	settings, err := s.repo.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LLMStatus{
+		Settings:          settings,
+		PlatformAvailable: s.registry != nil && s.registry.HasPlatform(),
+		OwnKeyAvailable:   s.repo.KeysAvailable(),
+	}, nil
+}
+
+// Save sets the tenant's own provider; its next chat uses it
+func (s *LLMSettingsService) Save(ctx context.Context, req *model.SaveLLMSettingsRequest) (*model.LLMSettings, error) {
+	settings, err := s.repo.Save(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.invalidate(ctx)
+	return settings, nil
+}
+
+// Delete moves the tenant back to the platform provider
+func (s *LLMSettingsService) Delete(ctx context.Context) error {
+	if err := s.repo.Delete(ctx); err != nil {
+		return err
+	}
+	s.invalidate(ctx)
+	return nil
+}
+
+// Test sends a short chat through the provider the tenant's bot uses and
+// returns the reply, to check a key and model before customers rely on them
+func (s *LLMSettingsService) Test(ctx context.Context) (string, error) {
+	if s.registry == nil {
+		return "", llm.ErrNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, llmTestTimeout)
+	defer cancel()
+
+	response, err := s.registry.Chat(ctx, []llm.Message{
+		{Role: "user", Content: "Balas hanya dengan kata: OK"},
+	}, nil)
+	if err != nil {
+		if settings, getErr := s.repo.Get(ctx); getErr == nil && settings == nil && !errors.Is(err, llm.ErrNotConfigured) {
+			slog.Error("platform LLM test failed", "error", err)
+			return "", ErrPlatformLLMFailed
+		}
+		return "", err
+	}
+	return strings.TrimSpace(response.Content), nil
+}
+
+func (s *LLMSettingsService) invalidate(ctx context.Context) {
+	if s.registry == nil {
+		return
+	}
+	if tenantID, err := model.GetTenantID(ctx); err == nil {
+		s.registry.Invalidate(tenantID)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+//go:embed demo/cars.json
+var demoCatalog []byte
+
+// demoFeatured is how many demo cars are featured on the homepage
+const demoFeatured = 6
+
+// OnboardingService runs the onboarding wizard of new dealerships: a platform
+// admin creates the tenant and its owner, the owner then sets up branding,
+// showroom, WhatsApp and inventory, optionally starting from demo data.
+type OnboardingService struct {
+	tenants    *TenantService
+	auth       *AuthService
+	users      *repository.UserRepository
+	onboarding *repository.OnboardingRepository
+}
+
+func NewOnboardingService(tenants *TenantService, auth *AuthService, users *repository.UserRepository, onboarding *repository.OnboardingRepository) *OnboardingService {
+	return &OnboardingService{tenants: tenants, auth: auth, users: users, onboarding: onboarding}
+}
+
+// Onboard creates a tenant with its first owner and starts its wizard.
+// Repeating the request returns the same tenant and owner.
+func (s *OnboardingService) Onboard(ctx context.Context, req *model.OnboardTenantRequest) (*model.OnboardTenantResult, error) {
+	tenant, created, err := s.tenants.Create(ctx, &req.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantCtx := model.WithTenantID(ctx, tenant.ID)
+	if err := s.onboarding.Start(tenantCtx); err != nil {
+		return nil, err
+	}
+
+	owner, err := s.auth.CreateUser(tenantCtx, &req.Owner)
+	if err != nil && strings.Contains(err.Error(), "already exists") {
+		owner, err = s.users.GetByEmail(tenantCtx, req.Owner.Email)
+		if err == nil && owner.Role != model.RoleOwner {
+			return nil, fmt.Errorf("user %s already exists with role %s", owner.Email, owner.Role)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SeedDemo {
+		var limitErr *model.PlanLimitError
+		if _, err := s.SeedDemo(tenantCtx); err != nil && !errors.Is(err, repository.ErrDemoSeeded) && !errors.As(err, &limitErr) {
+			return nil, err
+		}
+	}
+
+	progress, err := s.onboarding.Progress(tenantCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.OnboardTenantResult{Tenant: tenant, Owner: owner, Created: created, Progress: progress}, nil
+}
+
+// Progress returns the wizard state of the context tenant
+func (s *OnboardingService) Progress(ctx context.Context) (*model.OnboardingProgress, error) {
+	return s.onboarding.Progress(ctx)
+}
+
+// Skip marks an optional step as skipped, or brings it back
+func (s *OnboardingService) Skip(ctx context.Context, step string, skipped bool) (*model.OnboardingProgress, error) {
+	if !model.IsSkippableOnboardingStep(step) {
+		return nil, fmt.Errorf("step %q cannot be skipped", step)
+	}
+	if err := s.onboarding.SetSkipped(ctx, step, skipped); err != nil {
+		return nil, err
+	}
+	return s.onboarding.Progress(ctx)
+}
+
+// Finish closes the wizard for the context tenant
+func (s *OnboardingService) Finish(ctx context.Context) (*model.OnboardingProgress, error) {
+	if err := s.onboarding.Finish(ctx); err != nil {
+		return nil, err
+	}
+	return s.onboarding.Progress(ctx)
+}
+
+// SeedDemo copies the demo catalog into the context tenant's inventory
+func (s *OnboardingService) SeedDemo(ctx context.Context) (int, error) {
+	cars, err := DemoCars()
+	if err != nil {
+		return 0, err
+	}
+	return s.onboarding.SeedDemoCars(ctx, cars)
+}
+
+// RemoveDemo deletes the demo cars still in the context tenant's inventory
+func (s *OnboardingService) RemoveDemo(ctx context.Context) (int, error) {
+	return s.onboarding.RemoveDemoCars(ctx)
+}
+
+// DemoCars returns a fresh copy of the demo catalog, the same cars
+// feed_100_cars.sh posts to a local instance
+func DemoCars() ([]*model.Car, error) {
+	var cars []*model.Car
+	if err := json.Unmarshal(demoCatalog, &cars); err != nil {
+		return nil, fmt.Errorf("failed to read demo catalog: %w", err)
+	}
+	for i, car := range cars {
+		car.Status = "available"
+		car.IsFeatured = i < demoFeatured
+	}
+	return cars, nil
+}
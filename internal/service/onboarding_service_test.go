@@ -0,0 +1,35 @@
+package service
+
+import "testing"
+
+func TestDemoCars(t *testing.T) {
+	cars, err := DemoCars()
+	if err != nil {
+		t.Fatalf("DemoCars: %v", err)
+	}
+	if len(cars) < 100 {
+		t.Fatalf("got %d demo cars, want at least 100", len(cars))
+	}
+
+	featured := 0
+	for i, car := range cars {
+		if car.Brand == "" || car.Model == "" || car.Year == 0 || car.Price <= 0 {
+			t.Errorf("car %d is incomplete: %+v", i, car)
+		}
+		if car.Status != "available" {
+			t.Errorf("car %d has status %q", i, car.Status)
+		}
+		if car.IsFeatured {
+			featured++
+		}
+	}
+	if featured != demoFeatured {
+		t.Errorf("got %d featured cars, want %d", featured, demoFeatured)
+	}
+
+	again, _ := DemoCars()
+	again[0].Brand = "changed"
+	if cars[0].Brand == "changed" {
+		t.Error("DemoCars shares cars between calls")
+	}
+}
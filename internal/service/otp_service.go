@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+const (
+	otpDigits         = 6
+	otpTTL            = 5 * time.Minute
+	otpMaxAttempts    = 5
+	otpResendInterval = time.Minute
+	otpWindow         = time.Hour
+	otpMaxPerWindow   = 5
+)
+
+var (
+	// ErrOTPInvalid is returned for a wrong, expired or already used code
+	ErrOTPInvalid = errors.New("invalid or expired OTP")
+	// ErrOTPLocked is returned once a code has had too many wrong attempts
+	ErrOTPLocked = errors.New("too many wrong OTP attempts")
+)
+
+// OTPRateLimitError is returned when a phone asks for codes too often
+type OTPRateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *OTPRateLimitError) Error() string {
+	return fmt.Sprintf("too many OTP requests, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// OTPSender delivers a code to a phone through the tenant's paired WhatsApp bot
+type OTPSender interface {
+	SendMessage(tenantID int, recipientPhone, message string) error
+}
+
+// OTPStore keeps the login codes that were sent, tenant-scoped
+type OTPStore interface {
+	Create(ctx context.Context, otp *model.LoginOTP, ipAddress string) error
+	RecentActivity(ctx context.Context, phoneNumber string, since time.Time) (int, *time.Time, error)
+	GetActive(ctx context.Context, phoneNumber string) (*model.LoginOTP, error)
+	IncrementAttempts(ctx context.Context, id int) (int, error)
+	Consume(ctx context.Context, id int) error
+}
+
+// ActiveSalesLookup finds the active sales person registered with a phone number
+type ActiveSalesLookup interface {
+	GetActiveByPhone(ctx context.Context, phoneNumber string) (*model.Sales, error)
+}
+
+// AuditWriter records audit log entries
+type AuditWriter interface {
+	Create(ctx context.Context, entry *model.AuditLog) error
+}
+
+// OTPService handles passwordless admin login for sales staff: a 6-digit code is sent
+// over WhatsApp to a phone registered as an active sales person
+type OTPService struct {
+	otpRepo     OTPStore
+	salesRepo   ActiveSalesLookup
+	userRepo    *repository.UserRepository
+	auditRepo   AuditWriter
+	authService *AuthService
+	sender      OTPSender
+	now         func() time.Time
+}
+
+func NewOTPService(otpRepo OTPStore, salesRepo ActiveSalesLookup, userRepo *repository.UserRepository, auditRepo AuditWriter, authService *AuthService, sender OTPSender) *OTPService {
+	return &OTPService{
+		otpRepo:     otpRepo,
+		salesRepo:   salesRepo,
+		userRepo:    userRepo,
+		auditRepo:   auditRepo,
+		authService: authService,
+		sender:      sender,
+		now:         time.Now,
+	}
+}
+
+// RequestOTP sends a login code to phoneNumber if it belongs to an active sales person.
+// Unknown numbers get no code but no error either, so the form cannot be used to
+// find out who works at the showroom.
+func (s *OTPService) RequestOTP(ctx context.Context, phoneNumber, ipAddress string) error {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	// Nobody is logged in yet, so the sales team is looked up as the system
+	phone := model.NormalizePhone(phoneNumber)
+	sales, err := s.salesRepo.GetActiveByPhone(model.WithSystemActor(ctx), phone)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			slog.Info("OTP requested for unknown phone", "tenant_id", tenantID, "phone", phone)
+			return nil
+		}
+		return err
+	}
+
+	now := s.now()
+	count, latest, err := s.otpRepo.RecentActivity(ctx, phone, now.Add(-otpWindow))
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		if wait := otpResendInterval - now.Sub(*latest); wait > 0 {
+			return &OTPRateLimitError{RetryAfter: wait}
+		}
+	}
+	if count >= otpMaxPerWindow {
+		return &OTPRateLimitError{RetryAfter: otpWindow}
+	}
+
+	code, err := security.GenerateOTP(otpDigits)
+	if err != nil {
+		return err
+	}
+
+	otp := &model.LoginOTP{
+		SalesID:     sales.ID,
+		PhoneNumber: phone,
+		CodeHash:    s.hashCode(tenantID, phone, code),
+		ExpiresAt:   now.Add(otpTTL),
+	}
+	if err := s.otpRepo.Create(ctx, otp, ipAddress); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf(
+		"Kode login panel admin Anda: *%s*\n\nBerlaku %d menit. Jangan bagikan kode ini kepada siapa pun, termasuk yang mengaku dari showroom.",
+		code, int(otpTTL.Minutes()),
+	)
+	if err := s.sender.SendMessage(tenantID, phone, message); err != nil {
+		return fmt.Errorf("failed to send OTP: %w", err)
+	}
+
+	slog.Info("login OTP sent", "tenant_id", tenantID, "sales_id", sales.ID)
+	return nil
+}
+
+// VerifyOTP checks a code and starts a session for the sales person's user account,
+// creating the account on first login
+func (s *OTPService) VerifyOTP(ctx context.Context, req *model.OTPVerifyRequest, userAgent, ipAddress string) (*model.AuthResult, error) {
+	tenantID, err := model.GetTenantID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("tenant ID required: %w", err)
+	}
+
+	// Codes are stored and sales looked up by the normalised number
+	req.PhoneNumber = model.NormalizePhone(req.PhoneNumber)
+	otp, err := s.consumeCode(ctx, tenantID, req.PhoneNumber, req.Code, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	// The sales person may have been deactivated since the code was sent
+	sales, err := s.salesRepo.GetActiveByPhone(model.WithSystemActor(ctx), req.PhoneNumber)
+	if err != nil || sales.ID != otp.SalesID {
+		return nil, ErrOTPInvalid
+	}
+
+	user, err := s.salesUser(ctx, sales)
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != "active" {
+		return nil, ErrOTPInvalid
+	}
+
+	result, err := s.authService.startSession(ctx, user, userAgent, ipAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit(ctx, req.PhoneNumber, &user.ID, "login.otp", map[string]interface{}{
+		"user_id":    user.ID,
+		"sales_id":   sales.ID,
+		"ip_address": ipAddress,
+		"user_agent": userAgent,
+	})
+
+	return result, nil
+}
+
+// consumeCode checks a code against the phone's active OTP and uses the OTP up,
+// so each code logs in at most once
+func (s *OTPService) consumeCode(ctx context.Context, tenantID int, phone, code, ipAddress string) (*model.LoginOTP, error) {
+	otp, err := s.otpRepo.GetActive(ctx, phone)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil, ErrOTPInvalid
+		}
+		return nil, err
+	}
+
+	if !s.now().Before(otp.ExpiresAt) {
+		return nil, ErrOTPInvalid
+	}
+
+	if otp.Attempts >= otpMaxAttempts {
+		return nil, ErrOTPLocked
+	}
+
+	if !security.CheckOTP(code, otpRecipient(tenantID, phone), s.authService.secret, otp.CodeHash) {
+		attempts, err := s.otpRepo.IncrementAttempts(ctx, otp.ID)
+		if err != nil {
+			return nil, err
+		}
+		if attempts >= otpMaxAttempts {
+			// Burn the code so the next try needs a fresh one
+			if err := s.otpRepo.Consume(ctx, otp.ID); err != nil {
+				slog.Warn("failed to burn locked OTP", "error", err, "otp_id", otp.ID)
+			}
+			s.audit(ctx, phone, nil, "login.otp_locked", map[string]interface{}{"ip_address": ipAddress})
+			return nil, ErrOTPLocked
+		}
+		return nil, ErrOTPInvalid
+	}
+
+	if err := s.otpRepo.Consume(ctx, otp.ID); err != nil {
+		return nil, ErrOTPInvalid
+	}
+
+	return otp, nil
+}
+
+// salesUser returns the user account linked to a sales record, creating a sales-role
+// account without a usable password on first login
+func (s *OTPService) salesUser(ctx context.Context, sales *model.Sales) (*model.User, error) {
+	user, err := s.userRepo.GetBySalesID(ctx, sales.ID)
+	if err == nil {
+		return user, nil
+	}
+	if !strings.Contains(err.Error(), "not found") {
+		return nil, err
+	}
+
+	password, err := security.GenerateRandomSecret(32)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := security.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	name := sales.Name
+	req := &model.CreateUserRequest{
+		Email:   model.NormalizePhone(sales.PhoneNumber) + "@whatsapp.local",
+		Name:    &name,
+		Role:    model.RoleSales,
+		SalesID: &sales.ID,
+	}
+	// Nobody is logged in yet: the verified code vouches for the sales person
+	return s.userRepo.Create(model.WithSystemActor(ctx), req, hash)
+}
+
+func (s *OTPService) hashCode(tenantID int, phone, code string) string {
+	return security.HashOTP(code, otpRecipient(tenantID, phone), s.authService.secret)
+}
+
+func (s *OTPService) audit(ctx context.Context, phone string, userID *int, action string, after map[string]interface{}) {
+	entry := &model.AuditLog{
+		ActorType:  model.AuditActorSales,
+		Actor:      phone,
+		EntityType: "user",
+		EntityID:   userID,
+		Action:     action,
+		After:      after,
+	}
+	if err := s.auditRepo.Create(ctx, entry); err != nil {
+		slog.Warn("failed to write audit log", "error", err, "action", action)
+	}
+}
+
+// otpRecipient binds a code hash to the tenant and phone it was sent to
+func otpRecipient(tenantID int, phone string) string {
+	return fmt.Sprintf("%d:%s", tenantID, phone)
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+// testClock is a settable clock for time-dependent services
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time          { return c.now }
+func (c *testClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// fakeOTPStore keeps OTPs in memory with the same rules as the repository:
+// a new code invalidates earlier unused ones and a code is consumed only once
+type fakeOTPStore struct {
+	clock *testClock
+	otps  []*model.LoginOTP
+}
+
+func (f *fakeOTPStore) Create(ctx context.Context, otp *model.LoginOTP, ipAddress string) error {
+	now := f.clock.Now()
+	for _, o := range f.otps {
+		if o.PhoneNumber == otp.PhoneNumber && o.ConsumedAt == nil {
+			o.ConsumedAt = &now
+		}
+	}
+	otp.ID = len(f.otps) + 1
+	otp.CreatedAt = now
+	f.otps = append(f.otps, otp)
+	return nil
+}
+
+func (f *fakeOTPStore) RecentActivity(ctx context.Context, phoneNumber string, since time.Time) (int, *time.Time, error) {
+	var count int
+	var latest *time.Time
+	for _, o := range f.otps {
+		if o.PhoneNumber == phoneNumber && !o.CreatedAt.Before(since) {
+			count++
+			latest = &o.CreatedAt
+		}
+	}
+	return count, latest, nil
+}
+
+func (f *fakeOTPStore) GetActive(ctx context.Context, phoneNumber string) (*model.LoginOTP, error) {
+	for i := len(f.otps) - 1; i >= 0; i-- {
+		if o := f.otps[i]; o.PhoneNumber == phoneNumber && o.ConsumedAt == nil {
+			copied := *o
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("otp not found")
+}
+
+func (f *fakeOTPStore) IncrementAttempts(ctx context.Context, id int) (int, error) {
+	f.otps[id-1].Attempts++
+	return f.otps[id-1].Attempts, nil
+}
+
+func (f *fakeOTPStore) Consume(ctx context.Context, id int) error {
+	if f.otps[id-1].ConsumedAt != nil {
+		return fmt.Errorf("otp not found or already used")
+	}
+	now := f.clock.Now()
+	f.otps[id-1].ConsumedAt = &now
+	return nil
+}
+
+type fakeSalesLookup map[string]*model.Sales
+
+func (f fakeSalesLookup) GetActiveByPhone(ctx context.Context, phoneNumber string) (*model.Sales, error) {
+	if sales, ok := f[phoneNumber]; ok {
+		return sales, nil
+	}
+	return nil, fmt.Errorf("sales not found")
+}
+
+type fakeAuditWriter struct {
+	actions []string
+}
+
+func (f *fakeAuditWriter) Create(ctx context.Context, entry *model.AuditLog) error {
+	f.actions = append(f.actions, entry.Action)
+	return nil
+}
+
+// fakeOTPSender remembers the last code sent to each phone
+type fakeOTPSender map[string]string
+
+func (f fakeOTPSender) SendMessage(tenantID int, recipientPhone, message string) error {
+	parts := strings.Split(message, "*")
+	f[recipientPhone] = parts[1]
+	return nil
+}
+
+const otpTestPhone = "6281234567890"
+
+func newTestOTPService() (*OTPService, *testClock, *fakeOTPStore, fakeOTPSender, *fakeAuditWriter) {
+	clock := &testClock{now: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC)}
+	store := &fakeOTPStore{clock: clock}
+	sender := fakeOTPSender{}
+	audit := &fakeAuditWriter{}
+	sales := fakeSalesLookup{otpTestPhone: {ID: 4, Name: "Budi", PhoneNumber: otpTestPhone}}
+	auth := NewAuthService(nil, nil, "otp-secret", time.Hour)
+
+	svc := NewOTPService(store, sales, nil, audit, auth, sender)
+	svc.now = clock.Now
+	return svc, clock, store, sender, audit
+}
+
+func TestRequestOTPRateLimit(t *testing.T) {
+	svc, clock, store, sender, _ := newTestOTPService()
+	ctx := model.WithTenantID(context.Background(), 1)
+
+	if err := svc.RequestOTP(ctx, "081234567890", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if sender[otpTestPhone] == "" {
+		t.Fatal("no code sent")
+	}
+
+	// A resend within a minute is refused with the remaining wait
+	clock.Advance(20 * time.Second)
+	var limited *OTPRateLimitError
+	if err := svc.RequestOTP(ctx, otpTestPhone, "10.0.0.1"); !errors.As(err, &limited) || limited.RetryAfter != 40*time.Second {
+		t.Fatalf("resend error = %v, want retry after 40s", err)
+	}
+
+	// Four more codes fit in the hour, the sixth does not
+	for i := 0; i < 4; i++ {
+		clock.Advance(otpResendInterval)
+		if err := svc.RequestOTP(ctx, otpTestPhone, "10.0.0.1"); err != nil {
+			t.Fatalf("request %d: %v", i+2, err)
+		}
+	}
+	clock.Advance(otpResendInterval)
+	if err := svc.RequestOTP(ctx, otpTestPhone, "10.0.0.1"); !errors.As(err, &limited) || limited.RetryAfter != otpWindow {
+		t.Fatalf("sixth request error = %v, want retry after %s", err, otpWindow)
+	}
+	if len(store.otps) != otpMaxPerWindow {
+		t.Errorf("stored %d codes, want %d", len(store.otps), otpMaxPerWindow)
+	}
+
+	// The window slides, so an hour after the first code one more is allowed
+	clock.Advance(otpWindow - 4*otpResendInterval - 20*time.Second)
+	if err := svc.RequestOTP(ctx, otpTestPhone, "10.0.0.1"); err != nil {
+		t.Fatalf("request after the window: %v", err)
+	}
+
+	// Unknown numbers get no code and no error
+	if err := svc.RequestOTP(ctx, "089999999999", "10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := sender["6289999999999"]; ok {
+		t.Error("code sent to an unknown phone")
+	}
+}
+
+func TestConsumeCode(t *testing.T) {
+	ctx := model.WithTenantID(context.Background(), 1)
+
+	// request starts a fresh service with one code sent to the test phone
+	request := func(t *testing.T) (*OTPService, *testClock, string, *fakeAuditWriter) {
+		svc, clock, _, sender, audit := newTestOTPService()
+		if err := svc.RequestOTP(ctx, otpTestPhone, ""); err != nil {
+			t.Fatal(err)
+		}
+		return svc, clock, sender[otpTestPhone], audit
+	}
+	wrong := func(code string) string {
+		if code == "000000" {
+			return "000001"
+		}
+		return "000000"
+	}
+
+	t.Run("valid until expiry", func(t *testing.T) {
+		svc, clock, code, _ := request(t)
+		clock.Advance(otpTTL - time.Second)
+		otp, err := svc.consumeCode(ctx, 1, otpTestPhone, code, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if otp.SalesID != 4 {
+			t.Errorf("SalesID = %d, want 4", otp.SalesID)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		svc, clock, code, _ := request(t)
+		clock.Advance(otpTTL)
+		if _, err := svc.consumeCode(ctx, 1, otpTestPhone, code, ""); !errors.Is(err, ErrOTPInvalid) {
+			t.Errorf("error = %v, want %v", err, ErrOTPInvalid)
+		}
+	})
+
+	t.Run("single use", func(t *testing.T) {
+		svc, _, code, _ := request(t)
+		if _, err := svc.consumeCode(ctx, 1, otpTestPhone, code, ""); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.consumeCode(ctx, 1, otpTestPhone, code, ""); !errors.Is(err, ErrOTPInvalid) {
+			t.Errorf("second use error = %v, want %v", err, ErrOTPInvalid)
+		}
+	})
+
+	t.Run("bound to tenant", func(t *testing.T) {
+		svc, _, code, _ := request(t)
+		if _, err := svc.consumeCode(ctx, 2, otpTestPhone, code, ""); !errors.Is(err, ErrOTPInvalid) {
+			t.Errorf("error = %v, want %v", err, ErrOTPInvalid)
+		}
+	})
+
+	t.Run("attempt limit", func(t *testing.T) {
+		svc, _, code, audit := request(t)
+		for i := 1; i < otpMaxAttempts; i++ {
+			if _, err := svc.consumeCode(ctx, 1, otpTestPhone, wrong(code), ""); !errors.Is(err, ErrOTPInvalid) {
+				t.Fatalf("attempt %d error = %v, want %v", i, err, ErrOTPInvalid)
+			}
+		}
+		if _, err := svc.consumeCode(ctx, 1, otpTestPhone, wrong(code), ""); !errors.Is(err, ErrOTPLocked) {
+			t.Fatalf("last attempt error = %v, want %v", err, ErrOTPLocked)
+		}
+		if len(audit.actions) != 1 || audit.actions[0] != "login.otp_locked" {
+			t.Errorf("audit = %v, want one login.otp_locked", audit.actions)
+		}
+
+		// The locked code is burnt, even the right code no longer works
+		if _, err := svc.consumeCode(ctx, 1, otpTestPhone, code, ""); !errors.Is(err, ErrOTPInvalid) {
+			t.Errorf("right code after lock error = %v, want %v", err, ErrOTPInvalid)
+		}
+	})
+
+	t.Run("new code replaces old", func(t *testing.T) {
+		svc, clock, first, _ := request(t)
+		clock.Advance(otpResendInterval)
+		if err := svc.RequestOTP(ctx, otpTestPhone, ""); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.consumeCode(ctx, 1, otpTestPhone, first, ""); err == nil {
+			t.Error("the replaced code still logs in")
+		}
+	})
+}
+
+// recordingSalesLookup remembers the numbers it was asked for
+type recordingSalesLookup struct {
+	fakeSalesLookup
+	phones []string
+}
+
+func (f *recordingSalesLookup) GetActiveByPhone(ctx context.Context, phoneNumber string) (*model.Sales, error) {
+	f.phones = append(f.phones, phoneNumber)
+	return f.fakeSalesLookup.GetActiveByPhone(ctx, phoneNumber)
+}
+
+func TestVerifyOTPNormalisesPhone(t *testing.T) {
+	ctx := model.WithTenantID(context.Background(), 1)
+	svc, _, store, sender, _ := newTestOTPService()
+	if err := svc.RequestOTP(ctx, otpTestPhone, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// The sales person is deactivated after the code was sent, so the login
+	// stops once the code is used and the sales team looked up
+	sales := &recordingSalesLookup{fakeSalesLookup: fakeSalesLookup{}}
+	svc.salesRepo = sales
+	req := &model.OTPVerifyRequest{PhoneNumber: "0812-3456-7890", Code: sender[otpTestPhone]}
+	if _, err := svc.VerifyOTP(ctx, req, "", ""); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("error = %v, want %v", err, ErrOTPInvalid)
+	}
+
+	if store.otps[0].ConsumedAt == nil {
+		t.Error("the code sent to the local number format was not used up")
+	}
+	if len(sales.phones) != 1 || sales.phones[0] != otpTestPhone {
+		t.Errorf("sales looked up by %v, want [%s]", sales.phones, otpTestPhone)
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+const (
+	// TenantDeleteGrace is how long a deleted tenant can be restored before its
+	// data is purged
+	TenantDeleteGrace   = 30 * 24 * time.Hour
+	tenantPurgeInterval = time.Hour
+)
+
+// TenantBot stops a tenant's WhatsApp bot
+type TenantBot interface {
+	Disconnect(tenantID int) error
+}
+
+// TenantCache forgets what it knows about a tenant once the tenant changes
+type TenantCache interface {
+	Invalidate(tenantID int)
+}
+
+// TenantService manages the tenant lifecycle for platform admins: a tenant is
+// active, suspended (not served, bot stopped) or deleted (restorable during
+// TenantDeleteGrace, then purged with all its data).
+type TenantService struct {
+	repo  *repository.TenantRepository
+	bot   TenantBot
+	cache TenantCache
+}
+
+func NewTenantService(repo *repository.TenantRepository) *TenantService {
+	return &TenantService{repo: repo}
+}
+
+// SetBot sets the WhatsApp client whose bots are stopped on suspension and deletion
+func (s *TenantService) SetBot(bot TenantBot) {
+	s.bot = bot
+}
+
+// SetCache sets the domain cache to invalidate when a tenant's domain or status changes
+func (s *TenantService) SetCache(cache TenantCache) {
+	s.cache = cache
+}
+
+// Create adds a tenant, or returns the existing one when the same request is
+// repeated; created reports which
+func (s *TenantService) Create(ctx context.Context, req *model.CreateTenantRequest) (*model.Tenant, bool, error) {
+	tenant, created, err := s.repo.Create(ctx, req)
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		s.invalidate(tenant.ID)
+	}
+	return tenant, created, nil
+}
+
+func (s *TenantService) Get(ctx context.Context, id int) (*model.Tenant, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+func (s *TenantService) List(ctx context.Context) ([]*model.Tenant, error) {
+	return s.repo.List(ctx)
+}
+
+// Update changes name and domain, then moves the tenant to the requested status
+func (s *TenantService) Update(ctx context.Context, id int, req *model.UpdateTenantRequest) (*model.Tenant, error) {
+	tenant, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil || req.Domain != nil || req.Plan != nil {
+		if err := s.repo.Update(ctx, id, req.Name, req.Domain, req.Plan); err != nil {
+			return nil, err
+		}
+		s.invalidate(id)
+	}
+
+	if req.Status != nil && *req.Status != tenant.Status {
+		switch *req.Status {
+		case model.TenantActive:
+			return s.Reactivate(ctx, id)
+		case model.TenantSuspended:
+			return s.Suspend(ctx, id, req.Reason)
+		case model.TenantDeleted:
+			return s.Delete(ctx, id)
+		}
+	}
+
+	return s.repo.GetByID(ctx, id)
+}
+
+// Suspend stops serving an active tenant and its bot
+func (s *TenantService) Suspend(ctx context.Context, id int, reason string) (*model.Tenant, error) {
+	if err := s.repo.Suspend(ctx, id, reason); err != nil {
+		return nil, err
+	}
+	s.invalidate(id)
+	s.stopBot(ctx, id)
+	slog.Info("tenant suspended", "tenant_id", id, "reason", reason)
+	return s.repo.GetByID(ctx, id)
+}
+
+// Reactivate serves a suspended tenant again or restores a deleted one. The
+// bot is not reconnected; the tenant pairs it again from the admin panel.
+func (s *TenantService) Reactivate(ctx context.Context, id int) (*model.Tenant, error) {
+	if err := s.repo.Reactivate(ctx, id); err != nil {
+		return nil, err
+	}
+	s.invalidate(id)
+	slog.Info("tenant reactivated", "tenant_id", id)
+	return s.repo.GetByID(ctx, id)
+}
+
+// Delete soft-deletes a tenant; its data is purged after TenantDeleteGrace
+func (s *TenantService) Delete(ctx context.Context, id int) (*model.Tenant, error) {
+	if err := s.repo.SoftDelete(ctx, id, TenantDeleteGrace); err != nil {
+		return nil, err
+	}
+	s.invalidate(id)
+	s.stopBot(ctx, id)
+	slog.Info("tenant deleted", "tenant_id", id, "grace", TenantDeleteGrace)
+	return s.repo.GetByID(ctx, id)
+}
+
+// invalidate drops the tenant from the domain cache, if there is one
+func (s *TenantService) invalidate(tenantID int) {
+	if s.cache != nil {
+		s.cache.Invalidate(tenantID)
+	}
+}
+
+// stopBot disconnects the tenant's WhatsApp bot, if it is running
+func (s *TenantService) stopBot(ctx context.Context, tenantID int) {
+	if s.bot == nil {
+		return
+	}
+	if err := s.bot.Disconnect(tenantID); err != nil {
+		slog.Debug("no WhatsApp bot to stop", "tenant_id", tenantID, "error", err)
+		return
+	}
+	if err := s.repo.UpdatePairingStatus(ctx, tenantID, "disconnected"); err != nil {
+		slog.Error("failed to update pairing status after stopping bot", "error", err, "tenant_id", tenantID)
+	}
+	slog.Info("WhatsApp bot stopped", "tenant_id", tenantID)
+}
+
+// RunPurger deletes tenants whose grace period is over until ctx is cancelled
+func (s *TenantService) RunPurger(ctx context.Context) {
+	ticker := time.NewTicker(tenantPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		ids, err := s.repo.PurgeDeleted(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("failed to purge deleted tenants", "error", err)
+		}
+		for _, id := range ids {
+			slog.Info("tenant purged", "tenant_id", id)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+)
+
+const (
+	// Typical used-car depreciation per model year in the Indonesian market
+	tradeInYearDepreciation = 0.08
+	// Expected kilometers driven per year and the price impact per 10.000 km above/below it
+	tradeInKmPerYear      = 15000
+	tradeInMileageImpact  = 0.015
+	tradeInMileageCap     = 0.20
+	tradeInTransmissionAT = 0.05
+
+	// Dealers buy below retail to cover reconditioning and margin
+	tradeInBuyLowRatio  = 0.80
+	tradeInBuyHighRatio = 0.90
+
+	tradeInRoundingUnit = 500000
+)
+
+// TradeInService estimates trade-in (tukar tambah) values from the tenant's own inventory
+type TradeInService struct {
+	tradeInRepo *repository.TradeInRepository
+	leadRepo    *repository.LeadRepository
+}
+
+func NewTradeInService(tradeInRepo *repository.TradeInRepository, leadRepo *repository.LeadRepository) *TradeInService {
+	return &TradeInService{
+		tradeInRepo: tradeInRepo,
+		leadRepo:    leadRepo,
+	}
+}
+
+// Estimate computes a buy price range for the described car without storing anything
+func (s *TradeInService) Estimate(ctx context.Context, req *model.TradeInRequest) (*model.TradeInEstimate, error) {
+	comparables, err := s.tradeInRepo.FindComparableCars(ctx, req.Brand, req.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	basis := fmt.Sprintf("%s %s", req.Brand, req.Model)
+	if len(comparables) == 0 {
+		// Fall back to the same brand; less precise but better than nothing
+		comparables, err = s.tradeInRepo.FindComparableCars(ctx, req.Brand, "")
+		if err != nil {
+			return nil, err
+		}
+		basis = req.Brand
+	}
+
+	if len(comparables) == 0 {
+		return &model.TradeInEstimate{
+			Note: "Belum ada data pembanding untuk mobil ini. Tim sales kami akan menghubungi Anda untuk penilaian langsung.",
+		}, nil
+	}
+
+	now := time.Now().Year()
+	values := make([]float64, 0, len(comparables))
+	for _, car := range comparables {
+		values = append(values, adjustComparablePrice(car, req, now))
+	}
+	sort.Float64s(values)
+
+	retail := median(values)
+	estimate := &model.TradeInEstimate{
+		EstimateLow:     roundDown(int64(retail*tradeInBuyLowRatio), tradeInRoundingUnit),
+		EstimateHigh:    roundDown(int64(retail*tradeInBuyHighRatio), tradeInRoundingUnit),
+		ComparableCount: len(comparables),
+		Note: fmt.Sprintf("Estimasi berdasarkan %d data %s di showroom kami. Harga final ditentukan setelah inspeksi fisik.",
+			len(comparables), basis),
+	}
+
+	return estimate, nil
+}
+
+// Submit estimates the trade-in and records it as a lead for the sales team to follow up
+func (s *TradeInService) Submit(ctx context.Context, req *model.TradeInRequest, source string) (*model.TradeIn, *model.TradeInEstimate, error) {
+	if err := req.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	estimate, err := s.Estimate(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lead, err := s.leadRepo.Create(ctx, &model.CreateLeadRequest{
+		PhoneNumber: req.PhoneNumber,
+		Name:        req.Name,
+		Source:      source,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tradeIn := &model.TradeIn{
+		LeadID:          &lead.ID,
+		PhoneNumber:     req.PhoneNumber,
+		CustomerName:    req.Name,
+		Brand:           req.Brand,
+		Model:           req.Model,
+		Year:            req.Year,
+		Mileage:         req.Mileage,
+		Transmission:    req.Transmission,
+		EstimateLow:     estimate.EstimateLow,
+		EstimateHigh:    estimate.EstimateHigh,
+		ComparableCount: estimate.ComparableCount,
+	}
+	if err := s.tradeInRepo.Create(ctx, tradeIn); err != nil {
+		return nil, nil, err
+	}
+
+	return tradeIn, estimate, nil
+}
+
+// adjustComparablePrice normalises a comparable car's price to the trade-in car's year, mileage and transmission
+func adjustComparablePrice(car *model.Car, req *model.TradeInRequest, currentYear int) float64 {
+	price := float64(car.Price)
+
+	// Year: each model year older loses a fixed share of value
+	price *= math.Pow(1-tradeInYearDepreciation, float64(car.Year-req.Year))
+
+	// Mileage: compare against what each car would be expected to have driven
+	carKm := expectedMileage(car.Mileage, car.Year, currentYear)
+	reqKm := expectedMileage(req.Mileage, req.Year, currentYear)
+	mileageAdj := float64(carKm-reqKm) / 10000 * tradeInMileageImpact
+	mileageAdj = math.Max(-tradeInMileageCap, math.Min(tradeInMileageCap, mileageAdj))
+	price *= 1 + mileageAdj
+
+	// Transmission: automatics sell at a premium over manuals
+	carAT := isAutomatic(car.Transmission)
+	reqAT := isAutomatic(req.Transmission)
+	if car.Transmission != nil && req.Transmission != nil && carAT != reqAT {
+		if reqAT {
+			price *= 1 + tradeInTransmissionAT
+		} else {
+			price *= 1 - tradeInTransmissionAT
+		}
+	}
+
+	return price
+}
+
+func expectedMileage(mileage *int, year, currentYear int) int {
+	if mileage != nil && *mileage > 0 {
+		return *mileage
+	}
+	age := currentYear - year
+	if age < 0 {
+		age = 0
+	}
+	return age * tradeInKmPerYear
+}
+
+func isAutomatic(transmission *string) bool {
+	if transmission == nil {
+		return false
+	}
+	t := strings.ToLower(*transmission)
+	return strings.Contains(t, "auto") || t == "at" || strings.Contains(t, "cvt") || strings.Contains(t, "matic")
+}
+
+// median of sorted values, 0 for none
+func median(sorted []float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// roundDown rounds value down to the previous multiple of unit
+func roundDown(value, unit int64) int64 {
+	if unit <= 0 {
+		return value
+	}
+	return value / unit * unit
+}
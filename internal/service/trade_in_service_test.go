@@ -0,0 +1,106 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		sorted []float64
+		want   float64
+	}{
+		{nil, 0},
+		{[]float64{150}, 150},
+		{[]float64{100, 200, 900}, 200},
+		{[]float64{100, 300}, 200},
+		{[]float64{100, 200, 400, 1000}, 300},
+	}
+
+	for _, tt := range tests {
+		if got := median(tt.sorted); got != tt.want {
+			t.Errorf("median(%v) = %v, want %v", tt.sorted, got, tt.want)
+		}
+	}
+}
+
+func TestAdjustComparablePrice(t *testing.T) {
+	const currentYear = 2026
+	km := func(v int) *int { return &v }
+	str := func(v string) *string { return &v }
+
+	tests := []struct {
+		name string
+		car  model.Car
+		req  model.TradeInRequest
+		want float64
+	}{
+		{
+			name: "same car",
+			car:  model.Car{Price: 200000000, Year: 2020},
+			req:  model.TradeInRequest{Year: 2020},
+			want: 200000000,
+		},
+		{
+			// Two years older loses 8% twice, and the 30.000 km more it is
+			// expected to have driven another 4,5%
+			name: "older trade-in",
+			car:  model.Car{Price: 200000000, Year: 2020},
+			req:  model.TradeInRequest{Year: 2018},
+			want: 200000000 * 0.92 * 0.92 * 0.955,
+		},
+		{
+			name: "newer trade-in",
+			car:  model.Car{Price: 200000000, Year: 2018, Mileage: km(60000)},
+			req:  model.TradeInRequest{Year: 2020, Mileage: km(60000)},
+			want: 200000000 / (0.92 * 0.92),
+		},
+		{
+			name: "lower mileage",
+			car:  model.Car{Price: 200000000, Year: 2020, Mileage: km(90000)},
+			req:  model.TradeInRequest{Year: 2020, Mileage: km(50000)},
+			want: 200000000 * 1.06,
+		},
+		{
+			name: "mileage bonus clamped",
+			car:  model.Car{Price: 200000000, Year: 2020, Mileage: km(200000)},
+			req:  model.TradeInRequest{Year: 2020, Mileage: km(10000)},
+			want: 200000000 * 1.20,
+		},
+		{
+			name: "mileage penalty clamped",
+			car:  model.Car{Price: 200000000, Year: 2020, Mileage: km(10000)},
+			req:  model.TradeInRequest{Year: 2020, Mileage: km(200000)},
+			want: 200000000 * 0.80,
+		},
+		{
+			name: "automatic trade-in for a manual comparable",
+			car:  model.Car{Price: 200000000, Year: 2020, Transmission: str("MT")},
+			req:  model.TradeInRequest{Year: 2020, Transmission: str("matic")},
+			want: 200000000 * 1.05,
+		},
+		{
+			name: "manual trade-in for a CVT comparable",
+			car:  model.Car{Price: 200000000, Year: 2020, Transmission: str("CVT")},
+			req:  model.TradeInRequest{Year: 2020, Transmission: str("manual")},
+			want: 200000000 * 0.95,
+		},
+		{
+			name: "unknown transmission",
+			car:  model.Car{Price: 200000000, Year: 2020, Transmission: str("AT")},
+			req:  model.TradeInRequest{Year: 2020},
+			want: 200000000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := adjustComparablePrice(&tt.car, &tt.req, currentYear)
+			if math.Abs(got-tt.want) > 1 {
+				t.Errorf("price = %.0f, want %.0f", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/internal/repository"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+const (
+	webhookMaxAttempts  = 8
+	webhookBaseBackoff  = 30 * time.Second
+	webhookMaxBackoff   = 6 * time.Hour
+	webhookTimeout      = 10 * time.Second
+	webhookPollInterval = 5 * time.Second
+	webhookBatchSize    = 20
+	webhookMaxErrorLen  = 500
+)
+
+// WebhookService manages the tenant's webhook endpoints and delivery log
+type WebhookService struct {
+	repo *repository.WebhookRepository
+}
+
+func NewWebhookService(repo *repository.WebhookRepository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// CreateEndpoint adds an endpoint with a new signing secret, returned only here
+func (s *WebhookService) CreateEndpoint(ctx context.Context, req *model.WebhookEndpointRequest) (*model.WebhookEndpoint, string, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	endpoint, err := s.repo.CreateEndpoint(ctx, req, secret)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return endpoint, secret, nil
+}
+
+// ListEndpoints returns the tenant's endpoints
+func (s *WebhookService) ListEndpoints(ctx context.Context) ([]*model.WebhookEndpoint, error) {
+	return s.repo.ListEndpoints(ctx)
+}
+
+// UpdateEndpoint changes an endpoint's URL, events or active flag
+func (s *WebhookService) UpdateEndpoint(ctx context.Context, id int, req *model.WebhookEndpointRequest) error {
+	return s.repo.UpdateEndpoint(ctx, id, req)
+}
+
+// DeleteEndpoint removes an endpoint and its delivery log
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, id int) error {
+	return s.repo.DeleteEndpoint(ctx, id)
+}
+
+// RotateSecret gives an endpoint a new signing secret and returns it
+func (s *WebhookService) RotateSecret(ctx context.Context, id int) (string, error) {
+	secret, err := newWebhookSecret()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.RotateSecret(ctx, id, secret); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// SendTest queues a ping event for an endpoint
+func (s *WebhookService) SendTest(ctx context.Context, id int) error {
+	return s.repo.SendPing(ctx, id)
+}
+
+// ListDeliveries returns one page of the delivery log
+func (s *WebhookService) ListDeliveries(ctx context.Context, filter *model.WebhookDeliveryFilter) ([]*model.WebhookDelivery, int, error) {
+	return s.repo.ListDeliveries(ctx, filter)
+}
+
+// Redeliver queues a delivery to be sent again
+func (s *WebhookService) Redeliver(ctx context.Context, id int64) (*model.WebhookDelivery, error) {
+	return s.repo.Redeliver(ctx, id)
+}
+
+func newWebhookSecret() (string, error) {
+	secret, err := security.GenerateRandomSecret(24)
+	if err != nil {
+		return "", err
+	}
+	return "whsec_" + secret, nil
+}
+
+// WebhookQueue is the durable queue the dispatcher works from
+type WebhookQueue interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDispatch, error)
+	RecordSuccess(ctx context.Context, deliveryID int64, statusCode int) error
+	RecordFailure(ctx context.Context, deliveryID int64, statusCode *int, errMsg string, retryAt *time.Time) error
+}
+
+// WebhookDispatcher sends queued webhook deliveries. A delivery that does not
+// get a 2xx response is retried with exponential backoff and marked failed
+// after the last attempt. An event can arrive more than once (after a timeout
+// or a redelivery), so receivers should deduplicate on the event id.
+type WebhookDispatcher struct {
+	queue  WebhookQueue
+	client *http.Client
+}
+
+func NewWebhookDispatcher(queue WebhookQueue) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		queue:  queue,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Run delivers due webhooks until ctx is cancelled
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookPollInterval)
+	defer ticker.Stop()
+
+	slog.Info("webhook dispatcher started")
+	for {
+		if err := d.ProcessDue(ctx); err != nil && ctx.Err() == nil {
+			slog.Error("failed to process webhooks", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("webhook dispatcher stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue sends every delivery that is due, one batch at a time
+func (d *WebhookDispatcher) ProcessDue(ctx context.Context) error {
+	for ctx.Err() == nil {
+		// The lease outlasts a batch of timed-out requests
+		dispatches, err := d.queue.ClaimDue(ctx, webhookBatchSize, webhookBatchSize*webhookTimeout)
+		if err != nil {
+			return err
+		}
+
+		for _, dispatch := range dispatches {
+			if err := d.deliver(ctx, dispatch); err != nil {
+				return err
+			}
+		}
+
+		if len(dispatches) < webhookBatchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// deliver sends one delivery and records the outcome
+func (d *WebhookDispatcher) deliver(ctx context.Context, dispatch *model.WebhookDispatch) error {
+	statusCode, sendErr := d.send(ctx, dispatch)
+	if sendErr == nil {
+		return d.queue.RecordSuccess(ctx, dispatch.DeliveryID, statusCode)
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	var retryAt *time.Time
+	if dispatch.Attempt < webhookMaxAttempts {
+		next := time.Now().Add(webhookBackoff(dispatch.Attempt))
+		retryAt = &next
+	}
+
+	slog.Warn("webhook delivery failed",
+		"delivery_id", dispatch.DeliveryID, "event", dispatch.EventType, "attempt", dispatch.Attempt,
+		"error", sendErr, "will_retry", retryAt != nil)
+
+	return d.queue.RecordFailure(ctx, dispatch.DeliveryID, code, truncateError(sendErr.Error()), retryAt)
+}
+
+// send POSTs the signed payload and returns the response status
+func (d *WebhookDispatcher) send(ctx context.Context, dispatch *model.WebhookDispatch) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, dispatch.URL, bytes.NewReader(dispatch.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "AutoLMK-Webhooks/1.0")
+	req.Header.Set("X-AutoLMK-Event", dispatch.EventType)
+	req.Header.Set("X-AutoLMK-Delivery", strconv.FormatInt(dispatch.DeliveryID, 10))
+	req.Header.Set(security.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(security.WebhookSignatureHeader, security.SignWebhook(dispatch.Secret, timestamp, dispatch.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, webhookMaxErrorLen))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	return resp.StatusCode, nil
+}
+
+// webhookBackoff is the wait after a failed attempt: 30s, 1m, 2m, ... up to 6h
+func webhookBackoff(attempt int) time.Duration {
+	backoff := webhookBaseBackoff
+	for i := 1; i < attempt && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookMaxBackoff {
+		backoff = webhookMaxBackoff
+	}
+	return backoff
+}
+
+func truncateError(msg string) string {
+	if len(msg) > webhookMaxErrorLen {
+		return msg[:webhookMaxErrorLen]
+	}
+	return msg
+}
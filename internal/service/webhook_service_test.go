@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+	"github.com/riz/auto-lmk/pkg/security"
+)
+
+type webhookOutcome struct {
+	success    bool
+	statusCode *int
+	retryAt    *time.Time
+}
+
+// fakeWebhookQueue hands out its pending dispatches once and records outcomes
+type fakeWebhookQueue struct {
+	pending  []*model.WebhookDispatch
+	outcomes map[int64]webhookOutcome
+}
+
+func (q *fakeWebhookQueue) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*model.WebhookDispatch, error) {
+	n := min(limit, len(q.pending))
+	claimed := q.pending[:n]
+	q.pending = q.pending[n:]
+	return claimed, nil
+}
+
+func (q *fakeWebhookQueue) RecordSuccess(ctx context.Context, deliveryID int64, statusCode int) error {
+	q.outcomes[deliveryID] = webhookOutcome{success: true, statusCode: &statusCode}
+	return nil
+}
+
+func (q *fakeWebhookQueue) RecordFailure(ctx context.Context, deliveryID int64, statusCode *int, errMsg string, retryAt *time.Time) error {
+	q.outcomes[deliveryID] = webhookOutcome{statusCode: statusCode, retryAt: retryAt}
+	return nil
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	const secret = "whsec_test"
+
+	// Local receiver that verifies signatures and fails deliveries for /down
+	var received []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := security.VerifyWebhook(secret, r.Header.Get(security.WebhookSignatureHeader),
+			r.Header.Get(security.WebhookTimestampHeader), body, 5*time.Minute)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/down" {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		received = append(received, r.Header.Get("X-AutoLMK-Event")+" "+string(body))
+	}))
+	defer receiver.Close()
+
+	dispatch := func(id int64, path, key string, attempt int) *model.WebhookDispatch {
+		return &model.WebhookDispatch{
+			DeliveryID: id, EventID: "evt_1", EventType: model.WebhookEventLeadCreated,
+			Payload: []byte(`{"id":"evt_1"}`), Attempt: attempt, URL: receiver.URL + path, Secret: key,
+		}
+	}
+	queue := &fakeWebhookQueue{
+		pending: []*model.WebhookDispatch{
+			dispatch(1, "/ok", secret, 1),
+			dispatch(2, "/down", secret, 3),
+			dispatch(3, "/down", secret, webhookMaxAttempts),
+			dispatch(4, "/ok", "whsec_wrong", 1),
+		},
+		outcomes: map[int64]webhookOutcome{},
+	}
+
+	start := time.Now()
+	if err := NewWebhookDispatcher(queue).ProcessDue(context.Background()); err != nil {
+		t.Fatalf("ProcessDue: %v", err)
+	}
+
+	if len(received) != 1 || received[0] != `lead.created {"id":"evt_1"}` {
+		t.Errorf("receiver got %q", received)
+	}
+
+	if got := queue.outcomes[1]; !got.success || *got.statusCode != http.StatusOK {
+		t.Errorf("delivery 1 = %+v, want success", got)
+	}
+
+	got := queue.outcomes[2]
+	if got.success || got.retryAt == nil || *got.statusCode != http.StatusServiceUnavailable {
+		t.Fatalf("delivery 2 = %+v, want retry after 503", got)
+	}
+	if wait := got.retryAt.Sub(start); wait < 2*time.Minute || wait > 2*time.Minute+10*time.Second {
+		t.Errorf("retry after attempt 3 in %v, want 2m", wait)
+	}
+
+	if got := queue.outcomes[3]; got.success || got.retryAt != nil {
+		t.Errorf("delivery 3 = %+v, want given up after the last attempt", got)
+	}
+
+	if got := queue.outcomes[4]; got.success || *got.statusCode != http.StatusUnauthorized {
+		t.Errorf("delivery 4 = %+v, want rejected signature", got)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{10, 4*time.Hour + 16*time.Minute},
+		{11, 6 * time.Hour},
+		{50, 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempt); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
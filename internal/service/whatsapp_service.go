@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -9,17 +10,21 @@ import (
 	"time"
 
 	"github.com/riz/auto-lmk/internal/llm"
+	"github.com/riz/auto-lmk/internal/model"
 	"github.com/riz/auto-lmk/internal/repository"
 	"github.com/riz/auto-lmk/internal/whatsapp"
 )
 
 // WhatsAppService orchestrates WhatsApp bot functionality
 type WhatsAppService struct {
-	waClient   *whatsapp.Client
-	bot        *llm.Bot
-	salesRepo  *repository.SalesRepository
-	convRepo   *repository.ConversationRepository
-	carService *CarService
+	waClient     *whatsapp.Client
+	bot          *llm.Bot
+	salesRepo    *repository.SalesRepository
+	convRepo     *repository.ConversationRepository
+	carService   *CarService
+	customerRepo *repository.CustomerRepository
+	plans        *repository.PlanRepository
+	afterHours   *afterHoursResponder
 }
 
 func NewWhatsAppService(
@@ -38,12 +43,38 @@ func NewWhatsAppService(
 	}
 }
 
+// SetCustomerRepository enables customer profile creation for inbound customer messages
+func (s *WhatsAppService) SetCustomerRepository(customerRepo *repository.CustomerRepository) {
+	s.customerRepo = customerRepo
+}
+
+// SetPlans meters bot replies against the daily WhatsApp message quota of the tenant's plan
+func (s *WhatsAppService) SetPlans(plans *repository.PlanRepository) {
+	s.plans = plans
+}
+
+// SetAfterHours applies each showroom's business hours and after-hours
+// behaviour to customer messages, queueing leads for sales when it auto-replies
+func (s *WhatsAppService) SetAfterHours(showrooms *repository.ShowroomRepository, leads *repository.LeadRepository) {
+	s.afterHours = &afterHoursResponder{
+		showrooms: showrooms,
+		leads:     leads,
+		messages:  s.convRepo,
+		sender:    s.waClient,
+		now:       time.Now,
+	}
+}
+
 // ProcessIncomingMessage handles incoming WhatsApp message
 func (s *WhatsAppService) ProcessIncomingMessage(ctx context.Context, tenantID int, senderPhone, messageText, messageType, mediaURL string) error {
 	slog.Info("processing WhatsApp message", "tenant_id", tenantID, "sender", senderPhone, "type", messageType)
 
+	// Messages arrive outside any HTTP request, so scope everything below to the
+	// receiving tenant; the bot acts for it without a logged-in user
+	ctx = model.WithSystemActor(model.WithTenantID(ctx, tenantID))
+
 	// 1. Check if sender is sales
-	isSales, err := s.salesRepo.IsSales(tenantID, senderPhone)
+	isSales, err := s.salesRepo.IsSales(ctx, senderPhone)
 	if err != nil {
 		slog.Error("failed to check sales status", "error", err)
 		isSales = false // Default to customer
@@ -72,7 +103,7 @@ func (s *WhatsAppService) ProcessIncomingMessage(ctx context.Context, tenantID i
 		}
 
 		// Add to pending photos
-		count := s.bot.AddPendingPhoto(senderPhone, photoPath)
+		count := s.bot.AddPendingPhoto(tenantID, senderPhone, photoPath)
 
 		// Send confirmation
 		var response string
@@ -97,11 +128,44 @@ func (s *WhatsAppService) ProcessIncomingMessage(ctx context.Context, tenantID i
 		return fmt.Errorf("failed to get conversation: %w", err)
 	}
 
+	// Make sure every customer who chats has a profile
+	if !isSales && s.customerRepo != nil {
+		if _, err := s.customerRepo.GetOrCreate(ctx, senderPhone); err != nil {
+			slog.Error("failed to get or create customer", "error", err)
+		}
+	}
+
 	// 4. Store incoming message
 	if err := s.convRepo.AddMessage(ctx, conversation.ID, senderPhone, messageText, "inbound"); err != nil {
 		slog.Error("failed to store message", "error", err)
 	}
 
+	// While the showroom is closed the tenant may not want the bot to answer customers
+	if !isSales && s.afterHours != nil {
+		handled, err := s.afterHours.handle(ctx, tenantID, conversation.ID, senderPhone)
+		if err != nil {
+			return err
+		}
+		if handled {
+			return nil
+		}
+	}
+
+	// Over the daily quota the bot stays quiet: customer messages are stored
+	// above for the sales team to answer, sales staff are told why
+	if s.plans != nil {
+		var limitErr *model.PlanLimitError
+		if err := s.plans.Consume(ctx, model.LimitWhatsAppMessages); errors.As(err, &limitErr) {
+			slog.Warn("WhatsApp message quota used up", "tenant_id", tenantID, "max", limitErr.Max)
+			if isSales {
+				return s.waClient.SendMessage(tenantID, senderPhone, limitErr.Error())
+			}
+			return nil
+		} else if err != nil {
+			slog.Error("failed to record WhatsApp usage", "error", err)
+		}
+	}
+
 	// 5. Process with LLM bot
 	response, err := s.bot.ProcessMessage(ctx, tenantID, senderPhone, messageText, isSales)
 	if err != nil {
@@ -120,7 +184,7 @@ func (s *WhatsAppService) ProcessIncomingMessage(ctx context.Context, tenantID i
 	}
 
 	// 8. Send pending images if any
-	pendingImages := s.bot.GetPendingImages(senderPhone)
+	pendingImages := s.bot.GetPendingImages(tenantID, senderPhone)
 	if len(pendingImages) > 0 {
 		slog.Info("sending car images", "tenant_id", tenantID, "sender", senderPhone, "count", len(pendingImages))
 
@@ -139,12 +203,114 @@ func (s *WhatsAppService) ProcessIncomingMessage(ctx context.Context, tenantID i
 		}
 
 		// Clear pending images after sending
-		s.bot.ClearPendingImages(senderPhone)
+		s.bot.ClearPendingImages(tenantID, senderPhone)
+	}
+
+	// 9. Send the showroom location if the bot queued it
+	if location := s.bot.GetPendingLocation(tenantID, senderPhone); location != nil {
+		err := s.waClient.SendLocation(tenantID, senderPhone, location.Latitude, location.Longitude, location.Name, location.Address)
+		if err != nil {
+			slog.Error("failed to send location", "error", err)
+		}
+		s.bot.ClearPendingLocation(tenantID, senderPhone)
 	}
 
 	return nil
 }
 
+// afterHoursResponder applies each showroom's business hours and after-hours
+// mode to customer messages; its dependencies are the few repository and
+// client methods it needs
+type afterHoursResponder struct {
+	showrooms interface {
+		GetByTenantID(ctx context.Context, tenantID int) (*model.ShowroomSettings, error)
+	}
+	leads interface {
+		QueueFollowUp(ctx context.Context, phone string, conversationID int) (bool, error)
+	}
+	messages interface {
+		AddMessage(ctx context.Context, conversationID int, senderPhone, messageText, direction string) error
+		GetMessages(ctx context.Context, conversationID int, limit int) ([]*model.Message, error)
+	}
+	sender interface {
+		SendMessage(tenantID int, recipientPhone, message string) error
+	}
+	now func() time.Time
+}
+
+// handle applies the showroom's after-hours behaviour to a stored customer
+// message and reports whether it did, so the bot does not answer. An
+// auto-reply is sent once per closed period, however many messages arrive.
+func (s *afterHoursResponder) handle(ctx context.Context, tenantID, conversationID int, senderPhone string) (bool, error) {
+	showroom, err := s.showrooms.GetByTenantID(ctx, tenantID)
+	if err != nil {
+		slog.Error("failed to load showroom settings", "error", err)
+		return false, nil
+	}
+
+	now := s.now()
+	switch afterHoursMode(showroom, now) {
+	case model.AfterHoursSilent:
+		slog.Info("customer message stored after hours", "tenant_id", tenantID, "sender", senderPhone)
+		return true, nil
+
+	case model.AfterHoursAutoReply:
+		if _, err := s.leads.QueueFollowUp(ctx, senderPhone, conversationID); err != nil {
+			slog.Error("failed to queue lead after hours", "error", err)
+		}
+
+		messages, err := s.messages.GetMessages(ctx, conversationID, 20)
+		if err != nil {
+			slog.Error("failed to check after-hours reply", "error", err)
+		}
+		if repliedSince(showroom, now, messages) {
+			return true, nil
+		}
+
+		response := showroom.AfterHoursReply(now)
+		if err := s.messages.AddMessage(ctx, conversationID, "BOT", response, "outbound"); err != nil {
+			slog.Error("failed to store after-hours reply", "error", err)
+		}
+		if err := s.sender.SendMessage(tenantID, senderPhone, response); err != nil {
+			return true, fmt.Errorf("failed to send after-hours reply: %w", err)
+		}
+		return true, nil
+	}
+
+	// Open, or bot only: the bot answers, knowing from the business hours
+	// whether a sales person is around
+	return false, nil
+}
+
+// afterHoursMode returns the after-hours mode that applies to a customer
+// message at now, or "" while the showroom is open
+func afterHoursMode(showroom *model.ShowroomSettings, now time.Time) string {
+	if !showroom.ClosedAt(now) {
+		return ""
+	}
+	switch showroom.AfterHoursMode {
+	case model.AfterHoursSilent, model.AfterHoursAutoReply:
+		return showroom.AfterHoursMode
+	}
+	return model.AfterHoursBotOnly
+}
+
+// repliedSince reports whether the conversation's recent messages hold a reply
+// sent since the showroom last closed
+func repliedSince(showroom *model.ShowroomSettings, now time.Time, messages []*model.Message) bool {
+	closed, ok := showroom.Hours.LastClosing(now)
+	if !ok {
+		closed = now.Add(-24 * time.Hour)
+	}
+
+	for _, msg := range messages {
+		if msg.Direction == "outbound" && msg.CreatedAt.After(closed) {
+			return true
+		}
+	}
+	return false
+}
+
 // saveCarPhoto saves an uploaded car photo to disk
 func (s *WhatsAppService) saveCarPhoto(ctx context.Context, tenantID int, mediaURL string) (string, error) {
 	// For simplified implementation, create a placeholder photo
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/riz/auto-lmk/internal/model"
+)
+
+func testShowroom(t *testing.T, mode string) *model.ShowroomSettings {
+	t.Helper()
+	hours := &model.BusinessHours{
+		Weekly: []model.OpeningPeriod{
+			{Day: time.Friday, Open: "08:00", Close: "17:00"},
+			{Day: time.Saturday, Open: "09:00", Close: "15:00"},
+		},
+		Holidays: []model.Holiday{{Date: "2026-10-24", Name: "Libur"}},
+	}
+	if err := hours.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return &model.ShowroomSettings{Hours: hours, AfterHoursMode: mode}
+}
+
+func TestAfterHoursMode(t *testing.T) {
+	wib := (*model.BusinessHours)(nil).Location()
+	open := time.Date(2026, 10, 16, 10, 0, 0, 0, wib)            // Friday
+	closed := time.Date(2026, 10, 16, 20, 0, 0, 0, wib)          // Friday night
+	holiday := time.Date(2026, 10, 24, 10, 0, 0, 0, wib)         // Saturday holiday
+	closedUTC := time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC) // 17:30 WIB
+
+	tests := []struct {
+		name string
+		mode string
+		now  time.Time
+		want string
+	}{
+		{"open, silent", model.AfterHoursSilent, open, ""},
+		{"open, auto reply", model.AfterHoursAutoReply, open, ""},
+		{"closed, bot only", model.AfterHoursBotOnly, closed, model.AfterHoursBotOnly},
+		{"closed, silent", model.AfterHoursSilent, closed, model.AfterHoursSilent},
+		{"closed, auto reply", model.AfterHoursAutoReply, closed, model.AfterHoursAutoReply},
+		{"closed, mode unset", "", closed, model.AfterHoursBotOnly},
+		{"holiday, auto reply", model.AfterHoursAutoReply, holiday, model.AfterHoursAutoReply},
+		{"server clock in UTC", model.AfterHoursSilent, closedUTC, model.AfterHoursSilent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := afterHoursMode(testShowroom(t, tt.mode), tt.now); got != tt.want {
+				t.Errorf("afterHoursMode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if got := afterHoursMode(&model.ShowroomSettings{AfterHoursMode: model.AfterHoursSilent}, closed); got != "" {
+		t.Errorf("showroom without hours: afterHoursMode = %q, want open", got)
+	}
+}
+
+func TestRepliedSince(t *testing.T) {
+	wib := (*model.BusinessHours)(nil).Location()
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 10, day, hour, minute, 0, 0, wib)
+	}
+	msg := func(direction string, sent time.Time) *model.Message {
+		return &model.Message{Direction: direction, CreatedAt: sent}
+	}
+	showroom := testShowroom(t, model.AfterHoursAutoReply)
+
+	// Closed from Friday 17:00 until Saturday 09:00, and again from Saturday 15:00
+	tests := []struct {
+		name     string
+		now      time.Time
+		messages []*model.Message
+		want     bool
+	}{
+		{"first message of the night", at(16, 20, 0), []*model.Message{msg("inbound", at(16, 20, 0))}, false},
+		{"bot answered during opening hours", at(16, 20, 0), []*model.Message{msg("outbound", at(16, 16, 0))}, false},
+		{"replied earlier tonight", at(16, 23, 0), []*model.Message{msg("outbound", at(16, 20, 0)), msg("inbound", at(16, 23, 0))}, true},
+		{"still the same closed period after midnight", at(17, 7, 0), []*model.Message{msg("outbound", at(16, 20, 0))}, true},
+		{"next closed period", at(17, 18, 0), []*model.Message{msg("outbound", at(16, 20, 0))}, false},
+		{"reply at the moment of closing", at(16, 20, 0), []*model.Message{msg("outbound", at(16, 17, 0))}, false},
+		{"no history", at(16, 20, 0), nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repliedSince(showroom, tt.now, tt.messages); got != tt.want {
+				t.Errorf("repliedSince = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type fakeShowrooms map[int]*model.ShowroomSettings
+
+func (f fakeShowrooms) GetByTenantID(ctx context.Context, tenantID int) (*model.ShowroomSettings, error) {
+	if showroom, ok := f[tenantID]; ok {
+		return showroom, nil
+	}
+	return nil, errors.New("failed to get showroom settings")
+}
+
+// fakeFollowUps records the phones queued for sales
+type fakeFollowUps struct {
+	phones []string
+}
+
+func (f *fakeFollowUps) QueueFollowUp(ctx context.Context, phone string, conversationID int) (bool, error) {
+	f.phones = append(f.phones, phone)
+	return len(f.phones) == 1, nil
+}
+
+// fakeMessages is a conversation history stamped with the test clock
+type fakeMessages struct {
+	clock    *testClock
+	messages []*model.Message
+}
+
+func (f *fakeMessages) AddMessage(ctx context.Context, conversationID int, senderPhone, messageText, direction string) error {
+	f.messages = append(f.messages, &model.Message{
+		ConversationID: conversationID, SenderPhone: senderPhone, MessageText: messageText,
+		Direction: direction, CreatedAt: f.clock.Now(),
+	})
+	return nil
+}
+
+func (f *fakeMessages) GetMessages(ctx context.Context, conversationID int, limit int) ([]*model.Message, error) {
+	return f.messages[max(0, len(f.messages)-limit):], nil
+}
+
+type fakeWhatsAppSender struct {
+	sent []string
+	err  error
+}
+
+func (f *fakeWhatsAppSender) SendMessage(tenantID int, recipientPhone, message string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, message)
+	return nil
+}
+
+func newTestAfterHours(t *testing.T, mode string) (*afterHoursResponder, *testClock, *fakeFollowUps, *fakeMessages, *fakeWhatsAppSender) {
+	showroom := testShowroom(t, mode)
+	showroom.AfterHoursMessage = "Kami buka {buka}."
+
+	clock := &testClock{}
+	leads := &fakeFollowUps{}
+	messages := &fakeMessages{clock: clock}
+	sender := &fakeWhatsAppSender{}
+	return &afterHoursResponder{
+		showrooms: fakeShowrooms{1: showroom},
+		leads:     leads,
+		messages:  messages,
+		sender:    sender,
+		now:       clock.Now,
+	}, clock, leads, messages, sender
+}
+
+func TestAfterHoursResponder(t *testing.T) {
+	wib := (*model.BusinessHours)(nil).Location()
+	ctx := context.Background()
+	const phone = "6281234567890"
+
+	// receive stores an inbound message at the given time, as ProcessIncomingMessage
+	// does, and lets the responder handle it
+	receive := func(t *testing.T, r *afterHoursResponder, clock *testClock, messages *fakeMessages, at time.Time) bool {
+		t.Helper()
+		clock.now = at
+		messages.AddMessage(ctx, 7, phone, "halo", "inbound")
+		handled, err := r.handle(ctx, 1, 7, phone)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return handled
+	}
+
+	t.Run("auto reply once per closed period", func(t *testing.T) {
+		r, clock, leads, messages, sender := newTestAfterHours(t, model.AfterHoursAutoReply)
+
+		// Friday night: one reply, then quiet until the showroom has opened and closed again
+		steps := []struct {
+			at      time.Time
+			handled bool
+			sent    int
+		}{
+			{time.Date(2026, 10, 16, 20, 0, 0, 0, wib), true, 1},
+			{time.Date(2026, 10, 16, 23, 0, 0, 0, wib), true, 1},
+			{time.Date(2026, 10, 17, 7, 0, 0, 0, wib), true, 1},
+			{time.Date(2026, 10, 17, 10, 0, 0, 0, wib), false, 1},
+			{time.Date(2026, 10, 17, 18, 0, 0, 0, wib), true, 2},
+		}
+		for _, step := range steps {
+			if handled := receive(t, r, clock, messages, step.at); handled != step.handled {
+				t.Errorf("%s: handled = %v, want %v", step.at.Format("Mon 15:04"), handled, step.handled)
+			}
+			if len(sender.sent) != step.sent {
+				t.Errorf("%s: sent %d replies, want %d", step.at.Format("Mon 15:04"), len(sender.sent), step.sent)
+			}
+		}
+
+		if want := "Kami buka besok pukul 09:00 WIB."; sender.sent[0] != want {
+			t.Errorf("reply = %q, want %q", sender.sent[0], want)
+		}
+		if len(leads.phones) != 4 || leads.phones[0] != phone {
+			t.Errorf("queued %v, want the phone once per closed message", leads.phones)
+		}
+		var stored int
+		for _, msg := range messages.messages {
+			if msg.Direction == "outbound" {
+				stored++
+			}
+		}
+		if stored != len(sender.sent) {
+			t.Errorf("stored %d replies, sent %d", stored, len(sender.sent))
+		}
+	})
+
+	t.Run("silent", func(t *testing.T) {
+		r, clock, leads, messages, sender := newTestAfterHours(t, model.AfterHoursSilent)
+		if !receive(t, r, clock, messages, time.Date(2026, 10, 16, 20, 0, 0, 0, wib)) {
+			t.Error("closed message not handled")
+		}
+		if receive(t, r, clock, messages, time.Date(2026, 10, 16, 10, 0, 0, 0, wib)) {
+			t.Error("message during opening hours handled")
+		}
+		if len(sender.sent) != 0 || len(leads.phones) != 0 {
+			t.Errorf("sent %v, queued %v; want nothing", sender.sent, leads.phones)
+		}
+	})
+
+	t.Run("bot only", func(t *testing.T) {
+		r, clock, leads, messages, sender := newTestAfterHours(t, model.AfterHoursBotOnly)
+		if receive(t, r, clock, messages, time.Date(2026, 10, 16, 20, 0, 0, 0, wib)) {
+			t.Error("closed message handled, want the bot to answer")
+		}
+		if len(sender.sent) != 0 || len(leads.phones) != 0 {
+			t.Errorf("sent %v, queued %v; want nothing", sender.sent, leads.phones)
+		}
+	})
+
+	t.Run("send failure", func(t *testing.T) {
+		r, clock, _, _, sender := newTestAfterHours(t, model.AfterHoursAutoReply)
+		sender.err = errors.New("not connected")
+		clock.now = time.Date(2026, 10, 16, 20, 0, 0, 0, wib)
+		if handled, err := r.handle(ctx, 1, 7, phone); !handled || err == nil {
+			t.Errorf("handle = %v, %v; want handled with the send error", handled, err)
+		}
+	})
+
+	t.Run("showroom not loaded", func(t *testing.T) {
+		r, _, _, _, _ := newTestAfterHours(t, model.AfterHoursSilent)
+		if handled, err := r.handle(ctx, 2, 7, phone); handled || err != nil {
+			t.Errorf("handle = %v, %v; want the bot to answer", handled, err)
+		}
+	})
+}
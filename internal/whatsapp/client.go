@@ -6,11 +6,11 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
-	"strings"
 	"sync"
 	"time"
 
 	_ "github.com/lib/pq"
+	"github.com/riz/auto-lmk/internal/model"
 	"github.com/riz/auto-lmk/internal/repository"
 	"github.com/skip2/go-qrcode"
 	"go.mau.fi/whatsmeow"
@@ -312,6 +312,41 @@ func (c *Client) SendImage(tenantID int, recipientPhone, imagePath, caption stri
 	return nil
 }
 
+// SendLocation sends a WhatsApp location pin, which opens in the recipient's maps app
+func (c *Client) SendLocation(tenantID int, recipientPhone string, latitude, longitude float64, name, address string) error {
+	slog.Info("sending WhatsApp location", "tenant_id", tenantID, "recipient", recipientPhone)
+
+	c.mu.RLock()
+	client, exists := c.clients[tenantID]
+	c.mu.RUnlock()
+
+	if !exists || !client.IsConnected() {
+		return fmt.Errorf("tenant not connected to WhatsApp")
+	}
+
+	// Parse phone number to JID
+	jid, err := parsePhoneNumber(recipientPhone)
+	if err != nil {
+		return fmt.Errorf("invalid phone number: %w", err)
+	}
+
+	_, err = client.SendMessage(context.Background(), jid, &waE2E.Message{
+		LocationMessage: &waE2E.LocationMessage{
+			DegreesLatitude:  proto.Float64(latitude),
+			DegreesLongitude: proto.Float64(longitude),
+			Name:             proto.String(name),
+			Address:          proto.String(address),
+		},
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to send location: %w", err)
+	}
+
+	slog.Info("location sent successfully", "tenant_id", tenantID, "recipient", recipientPhone)
+	return nil
+}
+
 // DownloadMedia downloads media from WhatsApp (simplified placeholder for now)
 func (c *Client) DownloadMedia(tenantID int, mediaURL string) ([]byte, error) {
 	slog.Info("downloading media", "tenant_id", tenantID, "url", mediaURL)
@@ -449,26 +484,12 @@ func (c *Client) createEventHandler(tenantID int) func(interface{}) {
 
 // parsePhoneNumber converts phone number to WhatsApp JID
 func parsePhoneNumber(phone string) (types.JID, error) {
-	// Remove any non-numeric characters
-	cleaned := ""
-	for _, r := range phone {
-		if r >= '0' && r <= '9' {
-			cleaned += string(r)
-		}
-	}
+	// Same normalisation as customer profiles: digits only, 08xx -> 628xx
+	cleaned := model.NormalizePhone(phone)
 
 	if len(cleaned) == 0 {
 		return types.JID{}, fmt.Errorf("invalid phone number")
 	}
 
-	// If number doesn't start with country code, assume Indonesia (+62)
-	if !strings.HasPrefix(cleaned, "62") && len(cleaned) < 12 {
-		// Remove leading 0 if present
-		if strings.HasPrefix(cleaned, "0") {
-			cleaned = cleaned[1:]
-		}
-		cleaned = "62" + cleaned
-	}
-
 	return types.NewJID(cleaned, types.DefaultUserServer), nil
 }
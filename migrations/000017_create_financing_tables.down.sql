@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE leasing_rates;
+DROP TABLE leasing_partners;
\ No newline at end of file
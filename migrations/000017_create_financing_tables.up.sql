@@ -0,0 +1,24 @@
+CREATE TABLE leasing_partners (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    admin_fee BIGINT NOT NULL DEFAULT 0,
+    insurance_rate DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, name)
+);
+
+CREATE TABLE leasing_rates (
+    id SERIAL PRIMARY KEY,
+    partner_id INTEGER NOT NULL REFERENCES leasing_partners(id) ON DELETE CASCADE,
+    tenor_months INTEGER NOT NULL CHECK (tenor_months > 0),
+    min_dp_percent DECIMAL(5, 2) NOT NULL DEFAULT 20,
+    max_car_age INTEGER NOT NULL DEFAULT 10,
+    interest_rate DECIMAL(5, 2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_leasing_partners_tenant_id ON leasing_partners(tenant_id);
+CREATE INDEX idx_leasing_rates_partner_tenor ON leasing_rates(partner_id, tenor_months);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS leads (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    phone_number VARCHAR(50) NOT NULL,
+    name VARCHAR(255),
+    interested_car_id INTEGER REFERENCES cars(id) ON DELETE SET NULL,
+    conversation_id INTEGER REFERENCES conversations(id) ON DELETE SET NULL,
+    source VARCHAR(50) NOT NULL DEFAULT 'whatsapp',
+    status VARCHAR(50) NOT NULL DEFAULT 'new',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_leads_tenant_id ON leads(tenant_id);
+CREATE INDEX idx_leads_phone_number ON leads(tenant_id, phone_number);
+CREATE INDEX idx_leads_status ON leads(tenant_id, status);
@@ -0,0 +1,24 @@
+CREATE TABLE trade_ins (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    lead_id INTEGER REFERENCES leads(id) ON DELETE SET NULL,
+    phone_number VARCHAR(50) NOT NULL,
+    customer_name VARCHAR(255),
+    brand VARCHAR(100) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    year INTEGER NOT NULL,
+    mileage INTEGER,
+    transmission VARCHAR(50),
+    estimate_low BIGINT NOT NULL DEFAULT 0,
+    estimate_high BIGINT NOT NULL DEFAULT 0,
+    comparable_count INTEGER NOT NULL DEFAULT 0,
+    appraised_price BIGINT,
+    appraised_by VARCHAR(255),
+    appraisal_notes TEXT,
+    status VARCHAR(50) NOT NULL DEFAULT 'estimated',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_trade_ins_tenant_id ON trade_ins(tenant_id);
+CREATE INDEX idx_trade_ins_status ON trade_ins(tenant_id, status);
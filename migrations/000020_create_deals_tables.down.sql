@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE deals;
+DROP TABLE commission_rules;
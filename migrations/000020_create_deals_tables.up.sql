@@ -0,0 +1,40 @@
+CREATE TABLE commission_rules (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    min_price BIGINT NOT NULL DEFAULT 0,
+    max_price BIGINT,
+    percent DECIMAL(5, 2) NOT NULL DEFAULT 0,
+    flat_amount BIGINT NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE deals (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    car_id INTEGER REFERENCES cars(id) ON DELETE SET NULL,
+    lead_id INTEGER REFERENCES leads(id) ON DELETE SET NULL,
+    sales_id INTEGER REFERENCES sales(id) ON DELETE SET NULL,
+    customer_name VARCHAR(255) NOT NULL,
+    customer_phone VARCHAR(50) NOT NULL,
+    list_price BIGINT NOT NULL DEFAULT 0,
+    agreed_price BIGINT NOT NULL,
+    discount BIGINT NOT NULL DEFAULT 0,
+    payment_method VARCHAR(20) NOT NULL DEFAULT 'cash' CHECK (payment_method IN ('cash', 'kredit')),
+    leasing_partner_id INTEGER REFERENCES leasing_partners(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed', 'cancelled')),
+    closing_date DATE,
+    commission_amount BIGINT NOT NULL DEFAULT 0,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_commission_rules_tenant_id ON commission_rules(tenant_id);
+CREATE INDEX idx_deals_tenant_id ON deals(tenant_id);
+CREATE INDEX idx_deals_status ON deals(tenant_id, status);
+CREATE INDEX idx_deals_sales_closing ON deals(tenant_id, sales_id, closing_date);
+-- A car can only be in one active deal at a time
+CREATE UNIQUE INDEX idx_deals_active_car ON deals(car_id) WHERE status IN ('open', 'closed');
@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE appointments;
+DROP TABLE customers;
@@ -0,0 +1,55 @@
+CREATE TABLE customers (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    phone_number VARCHAR(50) NOT NULL,
+    name VARCHAR(255),
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    notes TEXT,
+    budget_min BIGINT,
+    budget_max BIGINT,
+    body_types TEXT[] NOT NULL DEFAULT '{}',
+    preferred_brands TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(tenant_id, phone_number)
+);
+
+CREATE TABLE appointments (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    customer_phone VARCHAR(50) NOT NULL,
+    car_id INTEGER REFERENCES cars(id) ON DELETE SET NULL,
+    type VARCHAR(20) NOT NULL DEFAULT 'visit' CHECK (type IN ('visit', 'test_drive', 'inspection')),
+    scheduled_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'scheduled' CHECK (status IN ('scheduled', 'done', 'cancelled')),
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_customers_tenant_id ON customers(tenant_id);
+CREATE INDEX idx_customers_tags ON customers USING GIN (tags);
+CREATE INDEX idx_appointments_tenant_phone ON appointments(tenant_id, customer_phone);
+
+-- Backfill customers from existing customer conversations and leads,
+-- normalising 08xx numbers to 628xx like the WhatsApp client does
+INSERT INTO customers (tenant_id, phone_number, name, created_at)
+SELECT tenant_id, phone, MAX(name), MIN(created_at)
+FROM (
+    SELECT tenant_id, regexp_replace(sender_phone, '\D', '', 'g') AS raw, NULL::VARCHAR AS name, created_at
+    FROM conversations
+    WHERE is_sales = FALSE
+    UNION ALL
+    SELECT tenant_id, regexp_replace(phone_number, '\D', '', 'g'), name, created_at
+    FROM leads
+) src
+CROSS JOIN LATERAL (
+    -- Same rule as model.NormalizePhone
+    SELECT CASE
+        WHEN raw LIKE '0%' THEN '62' || substr(raw, 2)
+        WHEN raw LIKE '8%' AND length(raw) < 12 AND raw NOT LIKE '84%' AND raw NOT LIKE '86%' THEN '62' || raw
+        ELSE raw
+    END AS phone
+) normalized
+WHERE raw <> ''
+GROUP BY tenant_id, phone
+ON CONFLICT (tenant_id, phone_number) DO NOTHING;
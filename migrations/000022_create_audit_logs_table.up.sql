@@ -0,0 +1,15 @@
+CREATE TABLE audit_logs (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    actor_type VARCHAR(20) NOT NULL CHECK (actor_type IN ('user', 'sales', 'system')),
+    actor VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id INTEGER,
+    action VARCHAR(50) NOT NULL,
+    before_data JSONB,
+    after_data JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_logs_tenant_created ON audit_logs(tenant_id, created_at DESC);
+CREATE INDEX idx_audit_logs_entity ON audit_logs(tenant_id, entity_type, entity_id);
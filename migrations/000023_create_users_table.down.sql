@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE user_sessions;
+DROP TABLE users;
@@ -0,0 +1,13 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_leads_assigned_sales;
+DROP INDEX IF EXISTS idx_conversations_assigned_sales;
+ALTER TABLE leads DROP COLUMN assigned_sales_id;
+ALTER TABLE conversations DROP COLUMN assigned_sales_id;
+DELETE FROM user_sessions WHERE tenant_id IS NULL;
+ALTER TABLE user_sessions ALTER COLUMN tenant_id SET NOT NULL;
+DROP INDEX IF EXISTS idx_users_platform_email;
+ALTER TABLE users DROP CONSTRAINT chk_users_platform;
+ALTER TABLE users DROP CONSTRAINT chk_users_role;
+ALTER TABLE users DROP COLUMN sales_id;
+DELETE FROM users WHERE tenant_id IS NULL;
+ALTER TABLE users ALTER COLUMN tenant_id SET NOT NULL;
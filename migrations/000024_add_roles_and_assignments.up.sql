@@ -0,0 +1,15 @@
+-- Platform super-admins are users without a tenant
+ALTER TABLE users ALTER COLUMN tenant_id DROP NOT NULL;
+ALTER TABLE users ADD COLUMN sales_id INTEGER REFERENCES sales(id) ON DELETE SET NULL;
+ALTER TABLE users ADD CONSTRAINT chk_users_role CHECK (role IN ('super_admin', 'owner', 'admin', 'sales'));
+ALTER TABLE users ADD CONSTRAINT chk_users_platform CHECK ((role = 'super_admin') = (tenant_id IS NULL));
+CREATE UNIQUE INDEX idx_users_platform_email ON users(LOWER(email)) WHERE tenant_id IS NULL;
+
+ALTER TABLE user_sessions ALTER COLUMN tenant_id DROP NOT NULL;
+
+-- Sales users only see the conversations and leads assigned to them
+ALTER TABLE conversations ADD COLUMN assigned_sales_id INTEGER REFERENCES sales(id) ON DELETE SET NULL;
+ALTER TABLE leads ADD COLUMN assigned_sales_id INTEGER REFERENCES sales(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_conversations_assigned_sales ON conversations(tenant_id, assigned_sales_id);
+CREATE INDEX idx_leads_assigned_sales ON leads(tenant_id, assigned_sales_id);
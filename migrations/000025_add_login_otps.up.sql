@@ -0,0 +1,15 @@
+-- One-time passwords sent over WhatsApp for passwordless sales login
+CREATE TABLE login_otps (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    sales_id INTEGER NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+    phone_number VARCHAR(20) NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    ip_address VARCHAR(64),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    consumed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_otps_phone ON login_otps(tenant_id, phone_number, created_at DESC);
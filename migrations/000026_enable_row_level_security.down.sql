@@ -0,0 +1,101 @@
+-- +migrate Down
+DROP POLICY IF EXISTS tenant_isolation ON sales;
+ALTER TABLE sales NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE sales DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON cars;
+ALTER TABLE cars NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE cars DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON conversations;
+ALTER TABLE conversations NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE conversations DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON search_analytics;
+ALTER TABLE search_analytics NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE search_analytics DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON car_views;
+ALTER TABLE car_views NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE car_views DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON whatsapp_settings;
+ALTER TABLE whatsapp_settings NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE whatsapp_settings DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON blog_posts;
+ALTER TABLE blog_posts NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE blog_posts DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON tenant_branding;
+ALTER TABLE tenant_branding NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE tenant_branding DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON showroom_settings;
+ALTER TABLE showroom_settings NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE showroom_settings DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON leasing_partners;
+ALTER TABLE leasing_partners NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE leasing_partners DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON leads;
+ALTER TABLE leads NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE leads DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON trade_ins;
+ALTER TABLE trade_ins NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE trade_ins DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON deals;
+ALTER TABLE deals NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE deals DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON commission_rules;
+ALTER TABLE commission_rules NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE commission_rules DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON customers;
+ALTER TABLE customers NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE customers DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON appointments;
+ALTER TABLE appointments NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE appointments DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON audit_logs;
+ALTER TABLE audit_logs NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE audit_logs DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON login_otps;
+ALTER TABLE login_otps NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE login_otps DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON users;
+ALTER TABLE users NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE users DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON user_sessions;
+ALTER TABLE user_sessions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE user_sessions DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON car_photos;
+ALTER TABLE car_photos NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE car_photos DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON car_specs;
+ALTER TABLE car_specs NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE car_specs DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON messages;
+ALTER TABLE messages NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE messages DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON leasing_rates;
+ALTER TABLE leasing_rates NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE leasing_rates DISABLE ROW LEVEL SECURITY;
+
+DROP FUNCTION IF EXISTS app_rls_bypass();
+DROP FUNCTION IF EXISTS app_current_tenant();
+
+-- The autolmk_app role is kept: other databases on the server may use it
@@ -0,0 +1,176 @@
+-- Row-level security: every tenant table only exposes rows of the tenant set on
+-- the session (app.tenant_id, set per statement from the request context).
+-- Trusted cross-tenant work sets app.rls_bypass = 'on'; later data migrations
+-- must run SELECT set_config('app.rls_bypass', 'on', false) first.
+
+CREATE OR REPLACE FUNCTION app_current_tenant() RETURNS INTEGER AS $$
+    SELECT NULLIF(current_setting('app.tenant_id', true), '')::INTEGER
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION app_rls_bypass() RETURNS BOOLEAN AS $$
+    SELECT COALESCE(current_setting('app.rls_bypass', true), '') = 'on'
+$$ LANGUAGE SQL STABLE;
+
+ALTER TABLE sales ENABLE ROW LEVEL SECURITY;
+ALTER TABLE sales FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON sales
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE cars ENABLE ROW LEVEL SECURITY;
+ALTER TABLE cars FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON cars
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE conversations ENABLE ROW LEVEL SECURITY;
+ALTER TABLE conversations FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON conversations
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE search_analytics ENABLE ROW LEVEL SECURITY;
+ALTER TABLE search_analytics FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON search_analytics
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE car_views ENABLE ROW LEVEL SECURITY;
+ALTER TABLE car_views FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON car_views
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE whatsapp_settings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE whatsapp_settings FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON whatsapp_settings
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE blog_posts ENABLE ROW LEVEL SECURITY;
+ALTER TABLE blog_posts FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON blog_posts
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE tenant_branding ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_branding FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant_branding
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE showroom_settings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE showroom_settings FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON showroom_settings
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE leasing_partners ENABLE ROW LEVEL SECURITY;
+ALTER TABLE leasing_partners FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON leasing_partners
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE leads ENABLE ROW LEVEL SECURITY;
+ALTER TABLE leads FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON leads
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE trade_ins ENABLE ROW LEVEL SECURITY;
+ALTER TABLE trade_ins FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON trade_ins
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE deals ENABLE ROW LEVEL SECURITY;
+ALTER TABLE deals FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON deals
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE commission_rules ENABLE ROW LEVEL SECURITY;
+ALTER TABLE commission_rules FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON commission_rules
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE customers ENABLE ROW LEVEL SECURITY;
+ALTER TABLE customers FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON customers
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE appointments ENABLE ROW LEVEL SECURITY;
+ALTER TABLE appointments FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON appointments
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE audit_logs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE audit_logs FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON audit_logs
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE login_otps ENABLE ROW LEVEL SECURITY;
+ALTER TABLE login_otps FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON login_otps
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+-- Platform accounts (super admins) have no tenant and stay visible for login
+ALTER TABLE users ENABLE ROW LEVEL SECURITY;
+ALTER TABLE users FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON users
+    USING (app_rls_bypass() OR tenant_id IS NULL OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id IS NULL OR tenant_id = app_current_tenant());
+
+ALTER TABLE user_sessions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE user_sessions FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON user_sessions
+    USING (app_rls_bypass() OR tenant_id IS NULL OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id IS NULL OR tenant_id = app_current_tenant());
+
+-- Child tables inherit the tenant of their parent row
+ALTER TABLE car_photos ENABLE ROW LEVEL SECURITY;
+ALTER TABLE car_photos FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON car_photos
+    USING (app_rls_bypass() OR EXISTS (SELECT 1 FROM cars WHERE cars.id = car_photos.car_id))
+    WITH CHECK (app_rls_bypass() OR EXISTS (SELECT 1 FROM cars WHERE cars.id = car_photos.car_id));
+
+ALTER TABLE car_specs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE car_specs FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON car_specs
+    USING (app_rls_bypass() OR EXISTS (SELECT 1 FROM cars WHERE cars.id = car_specs.car_id))
+    WITH CHECK (app_rls_bypass() OR EXISTS (SELECT 1 FROM cars WHERE cars.id = car_specs.car_id));
+
+ALTER TABLE messages ENABLE ROW LEVEL SECURITY;
+ALTER TABLE messages FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON messages
+    USING (app_rls_bypass() OR EXISTS (SELECT 1 FROM conversations WHERE conversations.id = messages.conversation_id))
+    WITH CHECK (app_rls_bypass() OR EXISTS (SELECT 1 FROM conversations WHERE conversations.id = messages.conversation_id));
+
+ALTER TABLE leasing_rates ENABLE ROW LEVEL SECURITY;
+ALTER TABLE leasing_rates FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON leasing_rates
+    USING (app_rls_bypass() OR EXISTS (SELECT 1 FROM leasing_partners WHERE leasing_partners.id = leasing_rates.partner_id))
+    WITH CHECK (app_rls_bypass() OR EXISTS (SELECT 1 FROM leasing_partners WHERE leasing_partners.id = leasing_rates.partner_id));
+
+-- Application role without BYPASSRLS (see DB_APP_ROLE). Creating roles needs
+-- CREATEROLE; without it the DBA creates the role and this block is skipped.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_roles WHERE rolname = 'autolmk_app') THEN
+        CREATE ROLE autolmk_app NOLOGIN;
+    END IF;
+    EXECUTE format('GRANT autolmk_app TO %I', current_user);
+    GRANT USAGE ON SCHEMA public TO autolmk_app;
+    GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA public TO autolmk_app;
+    GRANT USAGE, SELECT ON ALL SEQUENCES IN SCHEMA public TO autolmk_app;
+    ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT SELECT, INSERT, UPDATE, DELETE ON TABLES TO autolmk_app;
+    ALTER DEFAULT PRIVILEGES IN SCHEMA public GRANT USAGE, SELECT ON SEQUENCES TO autolmk_app;
+EXCEPTION WHEN insufficient_privilege THEN
+    RAISE NOTICE 'skipping autolmk_app role setup: %', SQLERRM;
+END
+$$;
@@ -0,0 +1,17 @@
+-- Audit entries are never edited or removed by the application. Deletes are only
+-- allowed as part of a cascade (deleting the tenant removes its trail).
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND pg_trigger_depth() > 1 THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+CREATE INDEX idx_audit_logs_actor ON audit_logs(tenant_id, actor);
+CREATE INDEX idx_audit_logs_action ON audit_logs(tenant_id, action);
@@ -0,0 +1,11 @@
+-- Token buckets shared by all app instances when RATE_LIMIT_STORE=postgres.
+-- Keys embed the tenant or API key they limit, so the table is not tenant-scoped.
+CREATE TABLE rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    full_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Full buckets carry no state and are swept
+CREATE INDEX idx_rate_limit_buckets_full_at ON rate_limit_buckets(full_at);
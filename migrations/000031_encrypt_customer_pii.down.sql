@@ -0,0 +1,6 @@
+-- +migrate Down
+-- Run "go run ./cmd/encrypt-pii -decrypt" first: encrypted values cannot be read without the keys
+DROP INDEX IF EXISTS idx_conversations_sender_phone_bidx;
+ALTER TABLE conversations DROP COLUMN IF EXISTS sender_phone_bidx;
+DROP TABLE IF EXISTS tenant_index_keys;
+DROP TABLE IF EXISTS tenant_data_keys;
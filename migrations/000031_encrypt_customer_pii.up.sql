@@ -0,0 +1,37 @@
+-- Per-tenant data keys, wrapped (AES-GCM) with the PII_MASTER_KEY. The newest
+-- version encrypts new values; older versions stay to open existing ones.
+CREATE TABLE tenant_data_keys (
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    wrapped_key BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, version)
+);
+
+-- Blind index key per tenant. It never rotates with the data keys, so the
+-- phone lookups keep matching rows written under any data key version.
+CREATE TABLE tenant_index_keys (
+    tenant_id INTEGER PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    wrapped_key BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE tenant_data_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_data_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant_data_keys
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+ALTER TABLE tenant_index_keys ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_index_keys FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant_index_keys
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+
+-- Encrypted values ("enc:<version>:<base64>") outgrow VARCHAR(50)
+ALTER TABLE conversations ALTER COLUMN sender_phone TYPE TEXT;
+ALTER TABLE messages ALTER COLUMN sender_phone TYPE TEXT;
+
+-- Blind index of the normalised phone number, for lookups on the encrypted column
+ALTER TABLE conversations ADD COLUMN sender_phone_bidx VARCHAR(64);
+CREATE INDEX idx_conversations_sender_phone_bidx ON conversations(tenant_id, sender_phone_bidx);
@@ -0,0 +1,32 @@
+-- Background data jobs: customer exports, customer erasures (right to be
+-- forgotten) and full tenant export archives. The runner claims pending jobs
+-- of all tenants under app.rls_bypass.
+CREATE TABLE data_jobs (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL CHECK (type IN ('customer_export', 'customer_erasure', 'tenant_export')),
+    phone VARCHAR(50),
+    format VARCHAR(10) CHECK (format IN ('json', 'zip')),
+    mode VARCHAR(20) CHECK (mode IN ('delete', 'anonymize')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'failed')),
+    result JSONB,
+    error TEXT,
+    file_path TEXT,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    requested_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP WITH TIME ZONE,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_data_jobs_tenant ON data_jobs(tenant_id, id DESC);
+CREATE INDEX idx_data_jobs_queue ON data_jobs(created_at) WHERE status IN ('pending', 'running');
+CREATE INDEX idx_data_jobs_expiry ON data_jobs(expires_at) WHERE file_path IS NOT NULL;
+
+ALTER TABLE data_jobs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE data_jobs FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON data_jobs
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
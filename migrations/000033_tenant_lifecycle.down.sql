@@ -0,0 +1,10 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tenants_purge_after;
+
+ALTER TABLE tenants
+    DROP CONSTRAINT IF EXISTS tenants_status_check,
+    DROP COLUMN IF EXISTS purge_after,
+    DROP COLUMN IF EXISTS deleted_at,
+    DROP COLUMN IF EXISTS suspended_reason,
+    DROP COLUMN IF EXISTS suspended_at,
+    ALTER COLUMN status DROP NOT NULL;
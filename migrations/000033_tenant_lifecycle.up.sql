@@ -0,0 +1,14 @@
+-- Tenant lifecycle: active -> suspended -> active, and soft delete with a grace
+-- period during which the tenant can still be restored. Deleted tenants are
+-- purged (with all their data, via ON DELETE CASCADE) once purge_after passes.
+UPDATE tenants SET status = 'active' WHERE status IS NULL OR status NOT IN ('active', 'suspended', 'deleted');
+
+ALTER TABLE tenants
+    ALTER COLUMN status SET NOT NULL,
+    ADD COLUMN suspended_at TIMESTAMP,
+    ADD COLUMN suspended_reason TEXT,
+    ADD COLUMN deleted_at TIMESTAMP,
+    ADD COLUMN purge_after TIMESTAMP,
+    ADD CONSTRAINT tenants_status_check CHECK (status IN ('active', 'suspended', 'deleted'));
+
+CREATE INDEX idx_tenants_purge_after ON tenants(purge_after) WHERE status = 'deleted';
@@ -0,0 +1,23 @@
+-- Onboarding wizard progress per tenant. Whether a step is done is read from
+-- the data itself (owner user, branding, showroom settings, paired WhatsApp,
+-- cars); this table keeps what the data cannot tell: skipped steps, the demo
+-- inventory that was seeded and when the wizard was finished.
+CREATE TABLE tenant_onboarding (
+    tenant_id INTEGER PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    skipped_steps TEXT[] NOT NULL DEFAULT '{}',
+    demo_car_ids INTEGER[] NOT NULL DEFAULT '{}',
+    demo_seeded_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tenants that existed before the wizard are already set up
+INSERT INTO tenant_onboarding (tenant_id, completed_at)
+SELECT id, CURRENT_TIMESTAMP FROM tenants;
+
+ALTER TABLE tenant_onboarding ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_onboarding FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant_onboarding
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
@@ -0,0 +1,4 @@
+-- +migrate Down
+DROP TABLE IF EXISTS tenant_usage;
+ALTER TABLE tenants DROP COLUMN IF EXISTS plan;
+DROP TABLE IF EXISTS plans;
@@ -0,0 +1,41 @@
+-- Subscription plans. A NULL limit means unlimited; features switch optional
+-- modules on (blog_ai, financing, webhooks). Plans are platform-wide, so the
+-- table is not tenant-scoped.
+CREATE TABLE plans (
+    code VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    price_monthly BIGINT, -- Rupiah; NULL for plans priced per contract
+    max_cars INTEGER,                  -- cars in inventory that are not sold
+    max_sales_seats INTEGER,           -- WhatsApp sales numbers
+    max_ai_generations INTEGER,        -- per calendar month
+    max_whatsapp_messages INTEGER,     -- bot replies per day
+    features TEXT[] NOT NULL DEFAULT '{}',
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO plans (code, name, price_monthly, max_cars, max_sales_seats, max_ai_generations, max_whatsapp_messages, features, sort_order) VALUES
+    ('starter', 'Starter', 499000, 30, 2, 50, 300, '{}', 1),
+    ('growth', 'Growth', 1499000, 150, 10, 500, 2000, '{blog_ai,financing}', 2),
+    ('enterprise', 'Enterprise', NULL, NULL, NULL, NULL, NULL, '{blog_ai,financing,webhooks}', 3);
+
+-- Existing dealers keep everything they had; new tenants start on Starter
+ALTER TABLE tenants ADD COLUMN plan VARCHAR(50) NOT NULL DEFAULT 'enterprise' REFERENCES plans(code);
+ALTER TABLE tenants ALTER COLUMN plan SET DEFAULT 'starter';
+
+-- Metered usage per tenant and period: the first day of the month for monthly
+-- limits, the day itself for daily ones (both in Asia/Jakarta)
+CREATE TABLE tenant_usage (
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    metric VARCHAR(50) NOT NULL,
+    period DATE NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, metric, period)
+);
+
+ALTER TABLE tenant_usage ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_usage FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant_usage
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
@@ -0,0 +1,31 @@
+-- Custom domains a tenant brings in addition to its platform domain
+-- (tenants.domain), e.g. the apex and www of its own domain. A domain only
+-- routes traffic once the tenant proved ownership with a DNS TXT record or an
+-- HTTP token. Until then any tenant may claim it, so a domain is only unique
+-- among verified ones.
+CREATE TABLE tenant_domains (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    domain VARCHAR(255) NOT NULL,
+    token VARCHAR(64) NOT NULL,
+    -- Redirect target for the tenant's other domains; the platform domain is
+    -- canonical when no custom domain is
+    is_canonical BOOLEAN NOT NULL DEFAULT false,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    verified_by VARCHAR(10) CHECK (verified_by IN ('dns', 'http')),
+    last_checked_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, domain),
+    CHECK (NOT is_canonical OR verified_at IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX idx_tenant_domains_verified ON tenant_domains(domain) WHERE verified_at IS NOT NULL;
+CREATE UNIQUE INDEX idx_tenant_domains_canonical ON tenant_domains(tenant_id) WHERE is_canonical;
+
+ALTER TABLE tenant_domains ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_domains FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant_domains
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
@@ -0,0 +1,19 @@
+-- A tenant's own LLM provider, used instead of the platform's for its bot and
+-- AI features. The API key is sealed with the tenant's data key (see
+-- tenant_data_keys); key_hint keeps its last characters for display.
+CREATE TABLE tenant_llm_settings (
+    tenant_id INTEGER PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    provider VARCHAR(20) NOT NULL CHECK (provider IN ('openai', 'anthropic', 'zai')),
+    model VARCHAR(100) NOT NULL DEFAULT '',
+    api_key TEXT NOT NULL,
+    key_hint VARCHAR(10) NOT NULL,
+    temperature NUMERIC(3, 2) CHECK (temperature >= 0 AND temperature <= 2),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE tenant_llm_settings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE tenant_llm_settings FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON tenant_llm_settings
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
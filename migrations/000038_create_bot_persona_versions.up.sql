@@ -0,0 +1,22 @@
+-- How each tenant's WhatsApp bot presents itself: name, tone, greeting and the
+-- showroom's rules. Every change adds a version and the latest one is active,
+-- so a bad prompt can be rolled back by restoring an earlier version.
+CREATE TABLE bot_persona_versions (
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    name VARCHAR(50) NOT NULL DEFAULT '',
+    tone VARCHAR(20) NOT NULL DEFAULT 'ramah' CHECK (tone IN ('ramah', 'formal', 'santai')),
+    greeting TEXT NOT NULL DEFAULT '',
+    rules TEXT[] NOT NULL DEFAULT '{}',
+    instructions TEXT NOT NULL DEFAULT '',
+    restored_from INTEGER,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant_id, version)
+);
+
+ALTER TABLE bot_persona_versions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bot_persona_versions FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON bot_persona_versions
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
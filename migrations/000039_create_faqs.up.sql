@@ -0,0 +1,25 @@
+-- Answers to questions customers often ask a showroom (payment methods,
+-- warranty, documents), searched by the WhatsApp bot. The search vector uses
+-- the 'simple' configuration since Postgres ships no Indonesian stemmer.
+CREATE TABLE faqs (
+    id SERIAL PRIMARY KEY,
+    tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    category VARCHAR(20) NOT NULL DEFAULT 'lainnya'
+        CHECK (category IN ('pembayaran', 'garansi', 'dokumen', 'layanan', 'lainnya')),
+    question TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', question), 'A') || setweight(to_tsvector('simple', answer), 'B')
+    ) STORED,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_faqs_tenant ON faqs(tenant_id, category);
+CREATE INDEX idx_faqs_search ON faqs USING GIN (search_vector);
+
+ALTER TABLE faqs ENABLE ROW LEVEL SECURITY;
+ALTER TABLE faqs FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON faqs
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
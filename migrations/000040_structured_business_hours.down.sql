@@ -0,0 +1,5 @@
+-- +migrate Down
+ALTER TABLE showroom_settings
+    DROP COLUMN IF EXISTS opening_hours,
+    DROP COLUMN IF EXISTS after_hours_mode,
+    DROP COLUMN IF EXISTS after_hours_message;
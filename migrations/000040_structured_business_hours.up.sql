@@ -0,0 +1,9 @@
+-- Structured opening hours with holidays, so the WhatsApp bot knows whether a
+-- sales person is around, and what the bot does with customer messages while
+-- the showroom is closed. The free-text business_hours is kept for display
+-- until the showroom sets opening_hours, which clears it.
+ALTER TABLE showroom_settings
+    ADD COLUMN opening_hours JSONB,
+    ADD COLUMN after_hours_mode VARCHAR(20) NOT NULL DEFAULT 'bot_only'
+        CHECK (after_hours_mode IN ('bot_only', 'auto_reply', 'silent')),
+    ADD COLUMN after_hours_message TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,12 @@
+-- +migrate Down
+DROP POLICY IF EXISTS platform_read ON users;
+DROP POLICY IF EXISTS tenant_isolation ON users;
+CREATE POLICY tenant_isolation ON users
+    USING (app_rls_bypass() OR tenant_id IS NULL OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id IS NULL OR tenant_id = app_current_tenant());
+
+DROP POLICY IF EXISTS platform_read ON user_sessions;
+DROP POLICY IF EXISTS tenant_isolation ON user_sessions;
+CREATE POLICY tenant_isolation ON user_sessions
+    USING (app_rls_bypass() OR tenant_id IS NULL OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id IS NULL OR tenant_id = app_current_tenant());
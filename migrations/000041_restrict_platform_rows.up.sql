@@ -0,0 +1,17 @@
+-- Platform accounts and their sessions (tenant_id NULL) stay readable for login
+-- and session checks on any domain, but only trusted work (app.rls_bypass) may
+-- create, change or delete them: tenant-scoped code must not be able to add a
+-- platform admin. Migration 000026 allowed NULL in USING and WITH CHECK alike.
+DROP POLICY IF EXISTS tenant_isolation ON users;
+CREATE POLICY tenant_isolation ON users
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+CREATE POLICY platform_read ON users FOR SELECT
+    USING (tenant_id IS NULL);
+
+DROP POLICY IF EXISTS tenant_isolation ON user_sessions;
+CREATE POLICY tenant_isolation ON user_sessions
+    USING (app_rls_bypass() OR tenant_id = app_current_tenant())
+    WITH CHECK (app_rls_bypass() OR tenant_id = app_current_tenant());
+CREATE POLICY platform_read ON user_sessions FOR SELECT
+    USING (tenant_id IS NULL);
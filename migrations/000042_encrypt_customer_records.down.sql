@@ -0,0 +1,12 @@
+-- +migrate Down
+-- Run "go run ./cmd/encrypt-pii -decrypt" first: encrypted values cannot be read without the keys
+DROP INDEX IF EXISTS idx_deals_customer_phone_bidx;
+DROP INDEX IF EXISTS idx_trade_ins_phone_number_bidx;
+DROP INDEX IF EXISTS idx_appointments_customer_phone_bidx;
+DROP INDEX IF EXISTS idx_leads_phone_number_bidx;
+DROP INDEX IF EXISTS idx_customers_phone_number_bidx;
+ALTER TABLE deals DROP COLUMN IF EXISTS customer_phone_bidx;
+ALTER TABLE trade_ins DROP COLUMN IF EXISTS phone_number_bidx;
+ALTER TABLE appointments DROP COLUMN IF EXISTS customer_phone_bidx;
+ALTER TABLE leads DROP COLUMN IF EXISTS phone_number_bidx;
+ALTER TABLE customers DROP COLUMN IF EXISTS phone_number_bidx;
@@ -0,0 +1,24 @@
+-- Customer names and phone numbers on customers, leads, appointments, trade-ins
+-- and deals are encrypted like conversations (000031); sealed values outgrow
+-- their VARCHAR columns
+ALTER TABLE customers ALTER COLUMN phone_number TYPE TEXT, ALTER COLUMN name TYPE TEXT;
+ALTER TABLE leads ALTER COLUMN phone_number TYPE TEXT, ALTER COLUMN name TYPE TEXT;
+ALTER TABLE appointments ALTER COLUMN customer_phone TYPE TEXT;
+ALTER TABLE trade_ins ALTER COLUMN phone_number TYPE TEXT, ALTER COLUMN customer_name TYPE TEXT;
+ALTER TABLE deals ALTER COLUMN customer_phone TYPE TEXT, ALTER COLUMN customer_name TYPE TEXT;
+
+-- Blind index of the normalised phone number, for lookups on the encrypted columns
+ALTER TABLE customers ADD COLUMN phone_number_bidx VARCHAR(64);
+ALTER TABLE leads ADD COLUMN phone_number_bidx VARCHAR(64);
+ALTER TABLE appointments ADD COLUMN customer_phone_bidx VARCHAR(64);
+ALTER TABLE trade_ins ADD COLUMN phone_number_bidx VARCHAR(64);
+ALTER TABLE deals ADD COLUMN customer_phone_bidx VARCHAR(64);
+
+-- Sealed values differ on every write, so one customer per number is enforced
+-- on the index instead of UNIQUE (tenant_id, phone_number)
+CREATE UNIQUE INDEX idx_customers_phone_number_bidx ON customers(tenant_id, phone_number_bidx)
+    WHERE phone_number_bidx IS NOT NULL;
+CREATE INDEX idx_leads_phone_number_bidx ON leads(tenant_id, phone_number_bidx);
+CREATE INDEX idx_appointments_customer_phone_bidx ON appointments(tenant_id, customer_phone_bidx);
+CREATE INDEX idx_trade_ins_phone_number_bidx ON trade_ins(tenant_id, phone_number_bidx);
+CREATE INDEX idx_deals_customer_phone_bidx ON deals(tenant_id, customer_phone_bidx);
@@ -4,23 +4,31 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	LLM      LLMConfig
-	WhatsApp WhatsAppConfig
-	Security SecurityConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	LLM       LLMConfig
+	WhatsApp  WhatsAppConfig
+	Security  SecurityConfig
+	RateLimit RateLimitConfig
 }
 
 type ServerConfig struct {
-	Port string
-	Env  string
+	Port           string
+	Env            string
+	ExportPath     string        // data export files; must not be publicly served
+	TenantCacheTTL time.Duration // how long each instance caches a domain's tenant; 0 disables
+	PlatformDomain string        // tenants' subdomains live under it, e.g. "autolmk.id"
+	TrustedProxies []*net.IPNet  // reverse proxies whose X-Forwarded-For names the client
 }
 
 type DatabaseConfig struct {
@@ -30,6 +38,7 @@ type DatabaseConfig struct {
 	Password string
 	Name     string
 	SSLMode  string
+	AppRole  string // role switched to per connection so row-level security applies
 }
 
 type LLMConfig struct {
@@ -44,7 +53,23 @@ type WhatsAppConfig struct {
 }
 
 type SecurityConfig struct {
-	JWTSecret string
+	JWTSecret    string
+	SessionTTL   time.Duration
+	PIIMasterKey []byte // wraps the per-tenant keys encrypting customer data; nil leaves it unencrypted
+}
+
+// RateLimitConfig sets the token bucket budgets. A zero Rate disables that limit.
+type RateLimitConfig struct {
+	Store  string // "memory" (per instance) or "postgres" (shared)
+	Public Rate   // per client IP on the public storefront and API
+	API    Rate   // per API key on /api/v1
+	AI     Rate   // per tenant on AI generation endpoints
+}
+
+// Rate allows Requests per Per, e.g. "60/1m"
+type Rate struct {
+	Requests int
+	Per      time.Duration
 }
 
 // Load reads configuration from environment variables
@@ -57,10 +82,48 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("invalid DB_PORT: %w", err)
 	}
 
+	sessionTTL, err := time.ParseDuration(getEnv("SESSION_TTL", "168h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SESSION_TTL: %w", err)
+	}
+
+	tenantCacheTTL, err := time.ParseDuration(getEnv("TENANT_CACHE_TTL", "30s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TENANT_CACHE_TTL: %w", err)
+	}
+
+	piiMasterKey, err := getEnvKey("PII_MASTER_KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	trustedProxies, err := getEnvNetworks("TRUSTED_PROXIES")
+	if err != nil {
+		return nil, err
+	}
+
+	rateLimit := RateLimitConfig{Store: getEnv("RATE_LIMIT_STORE", "memory")}
+	if rateLimit.Store != "memory" && rateLimit.Store != "postgres" {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_STORE: %q", rateLimit.Store)
+	}
+	if rateLimit.Public, err = getEnvRate("RATE_LIMIT_PUBLIC", "120/1m"); err != nil {
+		return nil, err
+	}
+	if rateLimit.API, err = getEnvRate("RATE_LIMIT_API", "600/1m"); err != nil {
+		return nil, err
+	}
+	if rateLimit.AI, err = getEnvRate("RATE_LIMIT_AI", "60/1h"); err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Port:           getEnv("PORT", "8080"),
+			Env:            getEnv("ENV", "development"),
+			ExportPath:     getEnv("EXPORT_PATH", "./exports"),
+			TenantCacheTTL: tenantCacheTTL,
+			PlatformDomain: getEnv("PLATFORM_DOMAIN", ""),
+			TrustedProxies: trustedProxies,
 		},
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -69,6 +132,7 @@ func Load() (*Config, error) {
 			Password: getEnv("DB_PASSWORD", "autolmk_dev_password"),
 			Name:     getEnv("DB_NAME", "autolmk"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			AppRole:  getEnv("DB_APP_ROLE", ""),
 		},
 		LLM: LLMConfig{
 			Provider:    getEnv("LLM_PROVIDER", ""),
@@ -80,8 +144,11 @@ func Load() (*Config, error) {
 			SessionPath: getEnv("WHATSAPP_SESSION_PATH", "./whatsapp_sessions"),
 		},
 		Security: SecurityConfig{
-			JWTSecret: getSecureJWTSecret(),
+			JWTSecret:    getSecureJWTSecret(),
+			SessionTTL:   sessionTTL,
+			PIIMasterKey: piiMasterKey,
 		},
+		RateLimit: rateLimit,
 	}
 
 	return cfg, nil
@@ -107,6 +174,65 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvRate parses a rate such as "60/1m"; "0" or "off" disables it
+func getEnvRate(key, fallback string) (Rate, error) {
+	value := getEnv(key, fallback)
+	if value == "0" || value == "off" {
+		return Rate{}, nil
+	}
+
+	requests, per, ok := strings.Cut(value, "/")
+	n, err := strconv.Atoi(requests)
+	if !ok || err != nil || n < 0 {
+		return Rate{}, fmt.Errorf("invalid %s: %q (want e.g. 60/1m)", key, value)
+	}
+	d, err := time.ParseDuration(per)
+	if err != nil || d <= 0 {
+		return Rate{}, fmt.Errorf("invalid %s: %q (want e.g. 60/1m)", key, value)
+	}
+
+	return Rate{Requests: n, Per: d}, nil
+}
+
+// getEnvNetworks parses a comma separated list of IPs and CIDRs such as
+// "127.0.0.1,10.0.0.0/8"; unset returns nil
+func getEnvNetworks(key string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %q (want IPs or CIDRs, e.g. 127.0.0.1,10.0.0.0/8)", key, value)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// getEnvKey decodes a base64 encoded 32-byte key; unset returns nil
+func getEnvKey(key string) ([]byte, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(decoded) != 32 {
+		return nil, fmt.Errorf("invalid %s: want 32 random bytes, base64 encoded (openssl rand -base64 32)", key)
+	}
+	return decoded, nil
+}
+
 // getSecureJWTSecret generates or retrieves a secure JWT secret
 func getSecureJWTSecret() string {
 	if value := os.Getenv("JWT_SECRET"); value != "" {
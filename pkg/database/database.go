@@ -22,10 +22,7 @@ func Connect(dsn string) (*DB, error) {
 	}
 
 	// Set connection pool settings
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
-	db.SetConnMaxIdleTime(10 * time.Minute)
+	configurePool(db)
 
 	// Verify connection
 	if err := db.Ping(); err != nil {
@@ -0,0 +1,301 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// TenantScope tells the connection which tenant a statement runs for, so Postgres
+// row-level security policies can enforce isolation even when a query forgets
+// its tenant_id filter.
+type TenantScope struct {
+	// TenantID returns the tenant of the request context, if any
+	TenantID func(ctx context.Context) (int, bool)
+	// Bypass reports trusted system work that spans tenants
+	Bypass func(ctx context.Context) bool
+	// Role is switched to on every new connection when set. Superusers and roles
+	// with BYPASSRLS ignore policies, so connect as (or SET ROLE to) a plain role.
+	Role string
+}
+
+// ConnectWithTenantScope connects like Connect, but sets the app.tenant_id and
+// app.rls_bypass settings read by the RLS policies from each statement's context
+func ConnectWithTenantScope(dsn string, scope TenantScope) (*DB, error) {
+	base, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	db := sql.OpenDB(NewTenantConnector(base, scope))
+	configurePool(db)
+
+	if err := db.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	warnIfRLSBypassed(db)
+	slog.Info("database connection established", "rls_role", scope.Role)
+
+	return &DB{db}, nil
+}
+
+// warnIfRLSBypassed logs when the connected role ignores row-level security
+func warnIfRLSBypassed(db *sql.DB) {
+	var bypass bool
+	err := db.QueryRow("SELECT rolsuper OR rolbypassrls FROM pg_roles WHERE rolname = current_user").Scan(&bypass)
+	if err != nil {
+		slog.Warn("failed to check database role", "error", err)
+		return
+	}
+	if bypass {
+		slog.Warn("database role bypasses row-level security; set DB_APP_ROLE or connect as a non-superuser role")
+	}
+}
+
+// NewTenantConnector wraps a driver connector with tenant scoping
+func NewTenantConnector(base driver.Connector, scope TenantScope) driver.Connector {
+	return &tenantConnector{base: base, scope: scope}
+}
+
+type tenantConnector struct {
+	base  driver.Connector
+	scope TenantScope
+}
+
+func (c *tenantConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tc := &tenantConn{Conn: conn, scope: c.scope}
+	if c.scope.Role != "" {
+		if _, err := tc.execBase(ctx, "SET ROLE "+pq.QuoteIdentifier(c.scope.Role), nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to set database role: %w", err)
+		}
+	}
+
+	return tc, nil
+}
+
+func (c *tenantConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// tenantConn applies the context's tenant to the session before statements and
+// transactions. The applied value is cached so unchanged scopes cost nothing.
+type tenantConn struct {
+	driver.Conn
+	scope   TenantScope
+	applied string // "tenant|bypass" currently set on the session; "" when unknown
+	inTx    bool
+	txDirty bool // scope changed inside a transaction, which a rollback would undo
+}
+
+func (c *tenantConn) scopeFor(ctx context.Context) (tenant, bypass string) {
+	if c.scope.TenantID != nil {
+		if id, ok := c.scope.TenantID(ctx); ok {
+			tenant = strconv.Itoa(id)
+		}
+	}
+	bypass = "off"
+	if c.scope.Bypass != nil && c.scope.Bypass(ctx) {
+		bypass = "on"
+	}
+	return tenant, bypass
+}
+
+func (c *tenantConn) apply(ctx context.Context) error {
+	tenant, bypass := c.scopeFor(ctx)
+	key := tenant + "|" + bypass
+	if key == c.applied {
+		return nil
+	}
+
+	_, err := c.execBase(ctx,
+		"SELECT set_config('app.tenant_id', $1, false), set_config('app.rls_bypass', $2, false)",
+		[]driver.NamedValue{{Ordinal: 1, Value: tenant}, {Ordinal: 2, Value: bypass}},
+	)
+	if err != nil {
+		c.applied = ""
+		return fmt.Errorf("failed to set tenant scope: %w", err)
+	}
+
+	c.applied = key
+	if c.inTx {
+		c.txDirty = true
+	}
+	return nil
+}
+
+func (c *tenantConn) execBase(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, errors.New("driver does not support ExecContext")
+	}
+	return execer.ExecContext(ctx, query, args)
+}
+
+func (c *tenantConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.apply(ctx); err != nil {
+		return nil, err
+	}
+	return queryer.QueryContext(ctx, query, args)
+}
+
+func (c *tenantConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if _, ok := c.Conn.(driver.ExecerContext); !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.apply(ctx); err != nil {
+		return nil, err
+	}
+	return c.execBase(ctx, query, args)
+}
+
+func (c *tenantConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tenantStmt{Stmt: stmt, conn: c}, nil
+}
+
+// BeginTx sets the scope before BEGIN, so the whole transaction runs for one tenant
+func (c *tenantConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.apply(ctx); err != nil {
+		return nil, err
+	}
+
+	var tx driver.Tx
+	var err error
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		tx, err = c.Conn.Begin() //nolint:staticcheck // fallback for drivers without BeginTx
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.inTx = true
+	c.txDirty = false
+	return &tenantTx{Tx: tx, conn: c}, nil
+}
+
+func (c *tenantConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tenantConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tenantConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+type tenantTx struct {
+	driver.Tx
+	conn *tenantConn
+}
+
+func (t *tenantTx) Commit() error {
+	defer t.end()
+	return t.Tx.Commit()
+}
+
+func (t *tenantTx) Rollback() error {
+	defer t.end()
+	return t.Tx.Rollback()
+}
+
+// end forgets the cached scope if it changed inside the transaction, since a
+// rollback restores the previous session settings
+func (t *tenantTx) end() {
+	if t.conn.txDirty {
+		t.conn.applied = ""
+	}
+	t.conn.inTx = false
+	t.conn.txDirty = false
+}
+
+type tenantStmt struct {
+	driver.Stmt
+	conn *tenantConn
+}
+
+func (s *tenantStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.conn.apply(ctx); err != nil {
+		return nil, err
+	}
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, args)
+	}
+	values, err := namedToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Query(values) //nolint:staticcheck // fallback for drivers without StmtQueryContext
+}
+
+func (s *tenantStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.conn.apply(ctx); err != nil {
+		return nil, err
+	}
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, args)
+	}
+	values, err := namedToValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.Exec(values) //nolint:staticcheck // fallback for drivers without StmtExecContext
+}
+
+func namedToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("named parameters are not supported")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// configurePool applies the connection pool settings shared by all connections
+func configurePool(db *sql.DB) {
+	db.SetMaxOpenConns(25)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(5 * time.Minute)
+	db.SetConnMaxIdleTime(10 * time.Minute)
+}
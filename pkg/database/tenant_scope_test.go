@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+)
+
+type tenantKey struct{}
+
+// recordingConn is a minimal driver connection that records every statement
+type recordingConn struct {
+	log *[]string
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recordingConn) Close() error                              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error)                 { return c, nil }
+func (c *recordingConn) Commit() error                             { *c.log = append(*c.log, "COMMIT"); return nil }
+func (c *recordingConn) Rollback() error                           { *c.log = append(*c.log, "ROLLBACK"); return nil }
+
+func (c *recordingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	*c.log = append(*c.log, "BEGIN")
+	return c, nil
+}
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	entry := query
+	for _, arg := range args {
+		entry += " " + arg.Value.(string)
+	}
+	*c.log = append(*c.log, entry)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	*c.log = append(*c.log, query)
+	return emptyRows{}, nil
+}
+
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{"x"} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+type recordingConnector struct {
+	log []string
+}
+
+func (c *recordingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &recordingConn{log: &c.log}, nil
+}
+
+func (c *recordingConnector) Driver() driver.Driver { return nil }
+
+func newScopedDB(t *testing.T, role string) (*sql.DB, *recordingConnector) {
+	t.Helper()
+
+	base := &recordingConnector{}
+	db := sql.OpenDB(NewTenantConnector(base, TenantScope{
+		TenantID: func(ctx context.Context) (int, bool) {
+			id, ok := ctx.Value(tenantKey{}).(int)
+			return id, ok
+		},
+		Bypass: func(ctx context.Context) bool {
+			return ctx.Value(tenantKey{}) == "system"
+		},
+		Role: role,
+	}))
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	return db, base
+}
+
+func setConfigs(log []string) []string {
+	var sets []string
+	for _, entry := range log {
+		if strings.HasPrefix(entry, "SELECT set_config") {
+			sets = append(sets, entry[strings.LastIndex(entry, ")")+1:])
+		}
+	}
+	return sets
+}
+
+func TestTenantConnSetsScopeFromContext(t *testing.T) {
+	db, base := newScopedDB(t, "autolmk_app")
+	tenantA := context.WithValue(context.Background(), tenantKey{}, 1)
+	tenantB := context.WithValue(context.Background(), tenantKey{}, 2)
+	system := context.WithValue(context.Background(), tenantKey{}, "system")
+
+	for _, ctx := range []context.Context{tenantA, tenantA, tenantB, context.Background(), system} {
+		if _, err := db.ExecContext(ctx, "UPDATE cars SET status = 'sold'"); err != nil {
+			t.Fatalf("exec: %v", err)
+		}
+	}
+
+	if base.log[0] != `SET ROLE "autolmk_app"` {
+		t.Errorf("first statement = %q, want SET ROLE", base.log[0])
+	}
+
+	// The repeated tenant A statement must not set the scope again
+	want := []string{" 1 off", " 2 off", "  off", "  on"}
+	got := setConfigs(base.log)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("set_config calls = %q, want %q", got, want)
+	}
+}
+
+func TestTenantConnScopesTransactionsBeforeBegin(t *testing.T) {
+	db, base := newScopedDB(t, "")
+	ctx := context.WithValue(context.Background(), tenantKey{}, 7)
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM leads"); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	want := []string{"SELECT set_config('app.tenant_id', $1, false), set_config('app.rls_bypass', $2, false) 7 off", "BEGIN", "DELETE FROM leads", "COMMIT"}
+	if strings.Join(base.log, "\n") != strings.Join(want, "\n") {
+		t.Errorf("statements = %q, want %q", base.log, want)
+	}
+}
+
+func TestTenantConnReappliesScopeAfterRollback(t *testing.T) {
+	db, base := newScopedDB(t, "")
+	tenantA := context.WithValue(context.Background(), tenantKey{}, 1)
+	tenantB := context.WithValue(context.Background(), tenantKey{}, 2)
+
+	tx, err := db.BeginTx(tenantA, nil)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	// Switching tenant inside the transaction is undone by the rollback
+	if _, err := tx.ExecContext(tenantB, "SELECT 1"); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if _, err := db.ExecContext(tenantB, "SELECT 2"); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+
+	want := []string{" 1 off", " 2 off", " 2 off"}
+	got := setConfigs(base.log)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("set_config calls = %q, want %q", got, want)
+	}
+}
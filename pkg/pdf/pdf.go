@@ -0,0 +1,152 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// A4 portrait in PDF points
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	margin     = 40.0
+)
+
+// Document is a minimal text-only PDF writer for tabular reports.
+// It uses the built-in Helvetica font so no font files are embedded.
+type Document struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+// NewDocument creates an empty document with one page
+func NewDocument() *Document {
+	d := &Document{}
+	d.addPage()
+	return d
+}
+
+func (d *Document) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - margin
+}
+
+func (d *Document) current() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// ensureSpace starts a new page if the next line of the given height would not fit
+func (d *Document) ensureSpace(height float64) {
+	if d.y-height < margin {
+		d.addPage()
+	}
+}
+
+func (d *Document) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.current(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, escape(s))
+}
+
+// Heading writes a bold title line
+func (d *Document) Heading(s string) {
+	d.ensureSpace(22)
+	d.y -= 18
+	d.text(margin, d.y, 14, true, s)
+	d.y -= 4
+}
+
+// Line writes a plain text line
+func (d *Document) Line(s string) {
+	d.ensureSpace(14)
+	d.y -= 14
+	d.text(margin, d.y, 10, false, s)
+}
+
+// Space adds vertical whitespace
+func (d *Document) Space() {
+	d.y -= 8
+}
+
+// Row writes a table row. Widths are fractions of the printable width and
+// should add up to 1. Cells that do not fit are truncated.
+func (d *Document) Row(cells []string, widths []float64, bold bool) {
+	d.ensureSpace(14)
+	d.y -= 14
+	usable := pageWidth - 2*margin
+	x := margin
+	for i, cell := range cells {
+		w := usable / float64(len(cells))
+		if i < len(widths) {
+			w = usable * widths[i]
+		}
+		// Helvetica at 9pt averages ~4.5pt per character
+		maxChars := int(w / 4.5)
+		if len(cell) > maxChars && maxChars > 1 {
+			cell = cell[:maxChars-1] + "."
+		}
+		d.text(x, d.y, 9, bold, cell)
+		x += w
+	}
+}
+
+// WriteTo serialises the document as a PDF file
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// 1: catalog, 2: page tree, 3-4: fonts, then page/content pairs
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+i*2))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// escape makes s safe inside a PDF literal string; non-ASCII runes are replaced
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 32 || r > 126:
+			b.WriteByte('?')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
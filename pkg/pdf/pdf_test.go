@@ -0,0 +1,92 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Rp 150.000.000", "Rp 150.000.000"},
+		{"Avanza (2020)", `Avanza \(2020\)`},
+		{`C:\komisi`, `C:\\komisi`},
+		{"baris\nbaru", "baris?baru"},
+		{"Café", "Caf?"},
+	}
+
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	doc := NewDocument()
+	doc.Heading("Laporan Komisi Sales - 10/2026")
+	doc.Space()
+	for i := 0; i < 80; i++ {
+		doc.Row([]string{fmt.Sprintf("Sales %d", i), "Toyota Avanza (2020)", "Rp 1.500.000"}, []float64{0.3, 0.4, 0.3}, i == 0)
+	}
+
+	var buf bytes.Buffer
+	n, err := doc.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if n != int64(len(out)) {
+		t.Errorf("WriteTo returned %d, wrote %d bytes", n, len(out))
+	}
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatalf("missing PDF header or trailer")
+	}
+
+	// 80 rows of 14pt do not fit on one A4 page
+	if len(doc.pages) < 2 {
+		t.Errorf("got %d pages, want at least 2", len(doc.pages))
+	}
+	if want := fmt.Sprintf("/Count %d", len(doc.pages)); !strings.Contains(out, want) {
+		t.Errorf("page tree does not contain %q", want)
+	}
+	if !strings.Contains(out, `(Toyota Avanza \(2020\)) Tj`) {
+		t.Errorf("cell text not escaped")
+	}
+
+	// startxref points at the xref table, and every entry at its object
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(m[1])
+	if !strings.HasPrefix(out[xref:], "xref\n") {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(out[xref:], -1)
+	if want := 4 + 2*len(doc.pages); len(entries) != want {
+		t.Fatalf("got %d xref entries, want %d", len(entries), want)
+	}
+	for i, e := range entries {
+		off, _ := strconv.Atoi(e[1])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(out[off:], want) {
+			t.Errorf("xref entry %d points at %q, want %q", i+1, out[off:off+len(want)], want)
+		}
+	}
+}
+
+func TestRowTruncatesLongCells(t *testing.T) {
+	doc := NewDocument()
+	doc.Row([]string{strings.Repeat("x", 200)}, []float64{0.1}, false)
+
+	// a tenth of the printable width holds 11 characters of 9pt Helvetica
+	if want := "(" + strings.Repeat("x", 10) + ".) Tj"; !strings.Contains(doc.current().String(), want) {
+		t.Errorf("row = %q, want it to contain %q", doc.current().String(), want)
+	}
+}
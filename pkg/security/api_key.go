@@ -0,0 +1,30 @@
+package security
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix marks tenant API keys, so they are recognisable in headers and secret scanners
+const APIKeyPrefix = "lmk_"
+
+// apiKeyDisplayLen is how much of a key is kept in clear to identify it in the admin panel
+const apiKeyDisplayLen = len(APIKeyPrefix) + 8
+
+// GenerateAPIKey returns a new random API key and its display prefix. Store the
+// key with HashToken; the key itself is only shown once.
+func GenerateAPIKey() (key, prefix string, err error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(bytes)
+	return key, key[:apiKeyDisplayLen], nil
+}
+
+// IsAPIKey reports whether token looks like a key made by GenerateAPIKey
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix) && len(token) > apiKeyDisplayLen
+}
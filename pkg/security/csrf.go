@@ -0,0 +1,14 @@
+package security
+
+import "crypto/hmac"
+
+// CSRFToken derives the anti-CSRF token of a session. It is bound to the
+// session, so nothing is stored and every login gets a new token.
+func CSRFToken(sessionToken, secret string) string {
+	return sign("csrf:"+sessionToken, secret)
+}
+
+// CheckCSRFToken reports whether token belongs to the session
+func CheckCSRFToken(token, sessionToken, secret string) bool {
+	return token != "" && hmac.Equal([]byte(token), []byte(CSRFToken(sessionToken, secret)))
+}
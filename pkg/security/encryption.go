@@ -0,0 +1,127 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EncryptionKeySize is the size of master and data keys (AES-256)
+const EncryptionKeySize = 32
+
+// sealedPrefix marks an encrypted column value: "enc:<key version>:<base64>".
+// Values without it are legacy plaintext.
+const sealedPrefix = "enc:"
+
+// ErrDecrypt is returned for a ciphertext that does not open with the given key
+var ErrDecrypt = errors.New("failed to decrypt value")
+
+// NewEncryptionKey returns a random AES-256 key
+func NewEncryptionKey() ([]byte, error) {
+	key := make([]byte, EncryptionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// Encrypt seals plaintext with AES-256-GCM; the random nonce is prepended
+func Encrypt(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// Decrypt opens a ciphertext produced by Encrypt
+func Decrypt(key, ciphertext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// SealString encrypts a column value with the data key of the given version.
+// The version is kept in the value so rotated keys can still open it.
+func SealString(key []byte, version int, plaintext string) (string, error) {
+	ciphertext, err := Encrypt(key, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	return sealedPrefix + strconv.Itoa(version) + ":" + base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+// SealedVersion returns the key version of a sealed value; ok is false for plaintext
+func SealedVersion(value string) (version int, ok bool) {
+	rest, found := strings.CutPrefix(value, sealedPrefix)
+	if !found {
+		return 0, false
+	}
+	v, _, found := strings.Cut(rest, ":")
+	if !found {
+		return 0, false
+	}
+	version, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// OpenString decrypts a value sealed by SealString with the key of its version
+func OpenString(key []byte, value string) (string, error) {
+	if _, ok := SealedVersion(value); !ok {
+		return "", ErrDecrypt
+	}
+	_, encoded, _ := strings.Cut(strings.TrimPrefix(value, sealedPrefix), ":")
+	ciphertext, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	plaintext, err := Decrypt(key, ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex returns a keyed hash of value for equality lookups on an
+// encrypted column. Callers normalise value first so variants match.
+func BlindIndex(key []byte, value string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("encryption key must be %d bytes", EncryptionKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,59 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSealString(t *testing.T) {
+	key, err := NewEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := SealString(key, 3, "6281234567890")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, "6281234567890") {
+		t.Fatalf("sealed value leaks the plaintext: %s", sealed)
+	}
+	if version, ok := SealedVersion(sealed); !ok || version != 3 {
+		t.Errorf("SealedVersion = %d, %v; want 3, true", version, ok)
+	}
+
+	plain, err := OpenString(key, sealed)
+	if err != nil || plain != "6281234567890" {
+		t.Fatalf("OpenString = %q, %v", plain, err)
+	}
+
+	other, _ := NewEncryptionKey()
+	if _, err := OpenString(other, sealed); err == nil {
+		t.Error("opened with the wrong key")
+	}
+	if _, err := OpenString(key, sealed[:len(sealed)-2]+"xx"); err == nil {
+		t.Error("opened a tampered value")
+	}
+
+	if _, ok := SealedVersion("6281234567890"); ok {
+		t.Error("plaintext reported as sealed")
+	}
+	if _, ok := SealedVersion("enc: not a version"); ok {
+		t.Error("malformed value reported as sealed")
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	a, _ := NewEncryptionKey()
+	b, _ := NewEncryptionKey()
+
+	if BlindIndex(a, "628123") != BlindIndex(a, "628123") {
+		t.Error("blind index is not deterministic")
+	}
+	if BlindIndex(a, "628123") == BlindIndex(a, "628124") {
+		t.Error("different values share an index")
+	}
+	if BlindIndex(a, "628123") == BlindIndex(b, "628123") {
+		t.Error("index does not depend on the key")
+	}
+}
@@ -1,11 +1,35 @@
 package security
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token expired")
+)
+
+// jwtHeader is the only header we issue and accept (HS256)
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// Claims are the JWT claims for API access tokens
+type Claims struct {
+	UserID    int    `json:"uid"`
+	TenantID  int    `json:"tid"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"` // session token, so logout revokes the JWT as well
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
 // GenerateRandomSecret generates a random secret for JWT
 func GenerateRandomSecret(length int) (string, error) {
 	bytes := make([]byte, length)
@@ -15,5 +39,72 @@ func GenerateRandomSecret(length int) (string, error) {
 	return base64.URLEncoding.EncodeToString(bytes), nil
 }
 
-// TODO: Implement JWT token generation and validation
-// This will be needed for authentication in Week 2
+// GenerateJWT signs claims as an HS256 JWT
+func GenerateJWT(claims *Claims, secret string) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode claims: %w", err)
+	}
+
+	unsigned := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + sign(unsigned, secret), nil
+}
+
+// ParseJWT verifies an HS256 JWT and returns its claims
+func ParseJWT(token, secret string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != jwtHeader {
+		return nil, ErrInvalidToken
+	}
+
+	if !hmac.Equal([]byte(parts[2]), []byte(sign(parts[0]+"."+parts[1], secret))) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrExpiredToken
+	}
+
+	return &claims, nil
+}
+
+// SignValue appends an HMAC signature to value, for tamper-proof cookies
+func SignValue(value, secret string) string {
+	return value + "." + sign(value, secret)
+}
+
+// VerifySignedValue checks a value produced by SignValue and returns the original value
+func VerifySignedValue(signed, secret string) (string, bool) {
+	idx := strings.LastIndex(signed, ".")
+	if idx <= 0 {
+		return "", false
+	}
+
+	value := signed[:idx]
+	if !hmac.Equal([]byte(signed[idx+1:]), []byte(sign(value, secret))) {
+		return "", false
+	}
+	return value, true
+}
+
+// HashToken returns the hex SHA-256 of a token, for storing session tokens at rest
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("%x", sum)
+}
+
+func sign(value, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
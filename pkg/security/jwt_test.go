@@ -0,0 +1,114 @@
+package security
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "test-secret-with-enough-entropy"
+
+func testToken(t *testing.T, expiresAt time.Time) string {
+	t.Helper()
+	token, err := GenerateJWT(&Claims{
+		UserID:    7,
+		TenantID:  3,
+		Role:      "admin",
+		SessionID: "session-token",
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}, testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestParseJWT(t *testing.T) {
+	token := testToken(t, time.Now().Add(time.Hour))
+	claims, err := ParseJWT(token, testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 7 || claims.TenantID != 3 || claims.Role != "admin" || claims.SessionID != "session-token" {
+		t.Errorf("claims = %+v", claims)
+	}
+}
+
+func TestParseJWTRejects(t *testing.T) {
+	valid := testToken(t, time.Now().Add(time.Hour))
+	parts := strings.Split(valid, ".")
+	enc := base64.RawURLEncoding.EncodeToString
+
+	// resign re-signs header.payload with the real secret, so only the
+	// header or payload change is under test
+	resign := func(header, payload string) string {
+		unsigned := header + "." + payload
+		return unsigned + "." + sign(unsigned, testSecret)
+	}
+	adminPayload := enc([]byte(`{"uid":7,"tid":3,"role":"super_admin","sid":"session-token","exp":4102444800}`))
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"alg none", enc([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + ".", ErrInvalidToken},
+		{"alg none without signature", enc([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1], ErrInvalidToken},
+		{"alg HS512", resign(enc([]byte(`{"alg":"HS512","typ":"JWT"}`)), parts[1]), ErrInvalidToken},
+		{"alg lowercase", resign(enc([]byte(`{"alg":"hs256","typ":"JWT"}`)), parts[1]), ErrInvalidToken},
+		{"tampered payload", parts[0] + "." + adminPayload + "." + parts[2], ErrInvalidToken},
+		{"tampered signature", parts[0] + "." + parts[1] + "." + strings.Repeat("A", len(parts[2])), ErrInvalidToken},
+		{"empty signature", parts[0] + "." + parts[1] + ".", ErrInvalidToken},
+		{"extra segment", valid + ".x", ErrInvalidToken},
+		{"garbage", "not-a-jwt", ErrInvalidToken},
+		{"expired", testToken(t, time.Now().Add(-time.Second)), ErrExpiredToken},
+		{"expires now", testToken(t, time.Now()), ErrExpiredToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := ParseJWT(tt.token, testSecret)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ParseJWT error = %v, want %v (claims %+v)", err, tt.err, claims)
+			}
+		})
+	}
+
+	t.Run("wrong secret", func(t *testing.T) {
+		if _, err := ParseJWT(valid, "another-secret"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("ParseJWT error = %v, want %v", err, ErrInvalidToken)
+		}
+	})
+}
+
+func TestVerifySignedValue(t *testing.T) {
+	signed := SignValue("session.token", testSecret)
+	if value, ok := VerifySignedValue(signed, testSecret); !ok || value != "session.token" {
+		t.Fatalf("VerifySignedValue = %q, %v", value, ok)
+	}
+
+	idx := strings.LastIndex(signed, ".")
+	tests := []struct {
+		name   string
+		signed string
+		secret string
+	}{
+		{"wrong secret", signed, "another-secret"},
+		{"tampered value", "session.tokem" + signed[idx:], testSecret},
+		{"tampered signature", signed[:idx+1] + strings.Repeat("A", len(signed)-idx-1), testSecret},
+		{"no signature", "session-token", testSecret},
+		{"empty value", signed[idx:], testSecret},
+		{"empty", "", testSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if value, ok := VerifySignedValue(tt.signed, tt.secret); ok {
+				t.Errorf("VerifySignedValue accepted %q as %q", tt.signed, value)
+			}
+		})
+	}
+}
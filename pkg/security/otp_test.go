@@ -0,0 +1,51 @@
+package security
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateOTP(t *testing.T) {
+	digits := regexp.MustCompile(`^[0-9]{6}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		code, err := GenerateOTP(6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !digits.MatchString(code) {
+			t.Fatalf("GenerateOTP(6) = %q, want 6 digits", code)
+		}
+		seen[code] = true
+	}
+	if len(seen) < 190 {
+		t.Errorf("only %d distinct codes in 200", len(seen))
+	}
+}
+
+func TestCheckOTP(t *testing.T) {
+	hash := HashOTP("012345", "1:6281234567890", testSecret)
+
+	tests := []struct {
+		name      string
+		code      string
+		recipient string
+		secret    string
+		want      bool
+	}{
+		{"match", "012345", "1:6281234567890", testSecret, true},
+		{"wrong code", "012346", "1:6281234567890", testSecret, false},
+		{"leading zero dropped", "12345", "1:6281234567890", testSecret, false},
+		{"other phone", "012345", "1:6281234567891", testSecret, false},
+		{"other tenant", "012345", "2:6281234567890", testSecret, false},
+		{"other secret", "012345", "1:6281234567890", "another-secret", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckOTP(tt.code, tt.recipient, tt.secret, hash); got != tt.want {
+				t.Errorf("CheckOTP = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook signature headers. Receivers recompute the signature over
+// "<timestamp>.<body>" with the endpoint secret and compare.
+const (
+	WebhookSignatureHeader = "X-AutoLMK-Signature"
+	WebhookTimestampHeader = "X-AutoLMK-Timestamp"
+)
+
+// ErrInvalidWebhookSignature is returned for a missing, stale or wrong webhook signature
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// SignWebhook returns the signature header value ("sha256=<hex>") of a payload
+// sent at timestamp (Unix seconds)
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhook checks the signature and timestamp headers of a received webhook.
+// Timestamps older than tolerance are rejected to stop replays.
+func VerifyWebhook(secret, signature, timestamp string, body []byte, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+	if age := time.Since(time.Unix(ts, 0)); age > tolerance || age < -tolerance {
+		return ErrInvalidWebhookSignature
+	}
+	if !strings.HasPrefix(signature, "sha256=") ||
+		!hmac.Equal([]byte(signature), []byte(SignWebhook(secret, ts, body))) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
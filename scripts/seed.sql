@@ -9,8 +9,8 @@ INSERT INTO tenants (domain, name, whatsapp_number, pairing_status, status) VALU
 -- Create sample users (password: password123)
 -- Hash generated with: bcrypt.GenerateFromPassword([]byte("password123"), 12)
 INSERT INTO users (tenant_id, email, password_hash, role) VALUES
-(1, 'admin@showroom-jaya.com', '$2a$12$LQv3c1yqBWVHxkd0LHAkCOYz6TtxMQJqhN8/LewY5yvWEZjxo8Wlm', 'owner'),
-(2, 'admin@mobilindo.com', '$2a$12$LQv3c1yqBWVHxkd0LHAkCOYz6TtxMQJqhN8/LewY5yvWEZjxo8Wlm', 'owner');
+(1, 'admin@showroom-jaya.com', '$2a$12$2T1F6uoyWjeZWPIpmbO8OOaOMjv8J8cu19axSb/gBsAEWFfbXsK7q', 'owner'),
+(2, 'admin@mobilindo.com', '$2a$12$2T1F6uoyWjeZWPIpmbO8OOaOMjv8J8cu19axSb/gBsAEWFfbXsK7q', 'owner');
 
 -- Create sample sales for tenant 1
 INSERT INTO sales (tenant_id, phone_number, name, status) VALUES
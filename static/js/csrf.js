@@ -0,0 +1,44 @@
+/**
+ * CSRF token for state-changing requests
+ * Reads the session's token from <meta name="csrf-token"> and attaches it to
+ * HTMX requests, same-origin fetch() calls and plain POST forms.
+ */
+(function () {
+    const meta = document.querySelector('meta[name="csrf-token"]');
+    const token = meta ? meta.content : '';
+    if (!token) return;
+
+    const safeMethods = ['GET', 'HEAD', 'OPTIONS'];
+
+    // HTMX (event bubbles up from the requesting element)
+    document.addEventListener('htmx:configRequest', (event) => {
+        event.detail.headers['X-CSRF-Token'] = token;
+    });
+
+    // fetch() to this site
+    const originalFetch = window.fetch;
+    window.fetch = function (input, init = {}) {
+        const request = input instanceof Request ? input : null;
+        const method = (init.method || (request ? request.method : 'GET')).toUpperCase();
+        const url = new URL(request ? request.url : input, window.location.href);
+
+        if (!safeMethods.includes(method) && url.origin === window.location.origin) {
+            const headers = new Headers(init.headers || (request ? request.headers : undefined));
+            headers.set('X-CSRF-Token', token);
+            init = { ...init, headers };
+        }
+        return originalFetch.call(this, input, init);
+    };
+
+    // Plain HTML forms posting to this site
+    document.addEventListener('submit', (event) => {
+        const form = event.target;
+        if (form.method.toLowerCase() !== 'post' || form.querySelector('input[name="csrf_token"]')) return;
+
+        const input = document.createElement('input');
+        input.type = 'hidden';
+        input.name = 'csrf_token';
+        input.value = token;
+        form.appendChild(input);
+    }, true);
+})();